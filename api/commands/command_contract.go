@@ -13,6 +13,10 @@ const (
 	CmdScan     CommandType = "PERCEPTION_SCAN"
 	CmdHalt     CommandType = "EMERGENCY_HALT"
 	CmdSync     CommandType = "DATA_SYNC"
+
+	// CmdSafetyOverride lifts one safety interlock for a bounded time. It
+	// needs PermSafetyOverride and a fresh step-up.
+	CmdSafetyOverride CommandType = "SAFETY_OVERRIDE"
 )
 
 type IncomingCommand struct {
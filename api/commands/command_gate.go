@@ -0,0 +1,138 @@
+//api/commands/command_gate.go
+
+package commands
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/router"
+	security_decision "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/decision"
+	security_sandbox "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/sandbox"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+var (
+	ErrUnauthenticated = errors.New("command_unauthenticated")
+	ErrStepUpRequired  = errors.New("command_step_up_required")
+)
+
+// Envelope metadata set on every admitted command.
+const (
+	MetadataCommandType = "command_type"
+	MetadataPriority    = router.MetadataPriority
+)
+
+// ActivityChecker is implemented by verification_identity.SessionActivity.
+type ActivityChecker interface {
+	Touch(claims *user_setting.SessionClaims) error
+	RequireFresh(claims *user_setting.SessionClaims, perm user_setting.PermissionKey) error
+}
+
+// Gate admits commands to the router. Every command is validated against
+// the registry and authorized against the session that issued it, as
+// decided by the decision point, before it is dispatched. Commands needing
+// a step-up permission (safety override, admin, config edit) are refused
+// from idle-locked sessions and from sessions that have not
+// re-authenticated within the step-up window. The session token travels
+// with the envelope, so a GuardedRouter verifies it again.
+type Gate struct {
+	registry  *Registry
+	decisions *security_decision.DecisionPoint
+	tokens    router.TokenVerifier
+	activity  ActivityChecker
+	router    router.Router
+}
+
+func NewGate(registry *Registry, decisions *security_decision.DecisionPoint, tokens router.TokenVerifier, activity ActivityChecker, rt router.Router) *Gate {
+	return &Gate{registry: registry, decisions: decisions, tokens: tokens, activity: activity, router: rt}
+}
+
+func (g *Gate) Registry() *Registry { return g.registry }
+
+// Submit admits cmd from the session token proves and dispatches it as a
+// control envelope. It returns the command as dispatched.
+func (g *Gate) Submit(ctx context.Context, token string, cmd IncomingCommand) (IncomingCommand, error) {
+	if token == "" {
+		return cmd, ErrUnauthenticated
+	}
+	claims, err := g.tokens.Verify(token)
+	if err != nil {
+		return cmd, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+
+	cmd, err = g.registry.Validate(cmd)
+	if err != nil {
+		return cmd, err
+	}
+
+	exec := g.decisions.DecideSession(claims)
+	if err := g.registry.Authorize(cmd, exec); err != nil {
+		if spec, ok := g.registry.Lookup(cmd.Type); ok {
+			if perm, missing := spec.MissingPermission(exec); missing {
+				g.decisions.Refuse(g.decisions.Subject(claims), perm, "command "+string(cmd.Type))
+			}
+		}
+		return cmd, err
+	}
+
+	if err := g.requireFresh(claims, cmd); err != nil {
+		return cmd, err
+	}
+
+	if cmd.ID == "" {
+		id := make([]byte, 8)
+		_, _ = rand.Read(id)
+		cmd.ID = hex.EncodeToString(id)
+	}
+	if cmd.CreatedAt.IsZero() {
+		cmd.CreatedAt = time.Now().UTC()
+	}
+
+	payload, err := json.Marshal(cmd)
+	if err != nil {
+		return cmd, err
+	}
+
+	// Commands of a sandbox session drive the simulator, never the router
+	if claims.Sandbox {
+		security_sandbox.Simulate("actuator.command", payload)
+		return cmd, nil
+	}
+
+	return cmd, g.router.Dispatch(ctx, router.Envelope{
+		Type:    router.MessageControl,
+		Payload: payload,
+		Source:  claims.UserID,
+		Metadata: map[string]string{
+			router.MetadataSessionToken: token,
+			MetadataCommandType:         string(cmd.Type),
+			MetadataPriority:            strconv.Itoa(cmd.Priority),
+		},
+	})
+}
+
+// requireFresh applies the idle lock and step-up window to cmd. Critical
+// commands are exempt so a locked session can still halt the machine.
+func (g *Gate) requireFresh(claims *user_setting.SessionClaims, cmd IncomingCommand) error {
+	spec, _ := g.registry.Lookup(cmd.Type)
+	if spec.Critical {
+		return nil
+	}
+
+	if err := g.activity.Touch(claims); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	for _, perm := range spec.Permissions {
+		if err := g.activity.RequireFresh(claims, perm); err != nil {
+			return fmt.Errorf("%w: %s needs re-authentication for %s", ErrStepUpRequired, cmd.Type, perm)
+		}
+	}
+	return nil
+}
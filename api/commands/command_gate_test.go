@@ -0,0 +1,190 @@
+//api/commands/command_gate_test.go
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/router"
+	security_decision "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/decision"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	security_sandbox "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/sandbox"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+	runtime_bus "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/bus"
+)
+
+// tokens maps a token to the session it proves.
+type tokens map[string]*user_setting.SessionClaims
+
+func (t tokens) Verify(token string) (*user_setting.SessionClaims, error) {
+	if c, ok := t[token]; ok {
+		return c, nil
+	}
+	return nil, verification_identity.ErrTokenSignature
+}
+
+// activity locks or freshens sessions by ID.
+type activity struct {
+	locked map[string]bool
+	fresh  map[string]bool
+}
+
+func (a *activity) Touch(c *user_setting.SessionClaims) error {
+	if a.locked[c.SessionID] {
+		return verification_identity.ErrSessionLocked
+	}
+	return nil
+}
+
+func (a *activity) RequireFresh(c *user_setting.SessionClaims, perm user_setting.PermissionKey) error {
+	if verification_identity.StepUpPermissions[perm] && !a.fresh[c.SessionID] {
+		return verification_identity.ErrStepUpRequired
+	}
+	return nil
+}
+
+// dispatcher records what reaches the router.
+type dispatcher struct {
+	router.Router
+	sent []router.Envelope
+}
+
+func (d *dispatcher) Dispatch(ctx context.Context, env router.Envelope) error {
+	d.sent = append(d.sent, env)
+	return nil
+}
+
+type accounts map[string]*internal_environment.MachineIdentity
+
+func (a accounts) Identity(userID string) (*internal_environment.MachineIdentity, error) {
+	if rec, ok := a[userID]; ok {
+		return rec, nil
+	}
+	return nil, errors.New("user_not_found")
+}
+
+// testGate admits commands on an attested, safety-critical vehicle for
+// alice (driver), root (admin), mech (sandbox) and a guest.
+func testGate(t *testing.T) (*Gate, *activity, *dispatcher) {
+	t.Helper()
+
+	var caps internal_environment.CapabilitySet
+	for _, c := range []internal_environment.Capability{internal_environment.CapNetwork, internal_environment.CapCANBus, internal_environment.CapSafetyCritical} {
+		caps.Add(c)
+	}
+	decisions := security_decision.NewDecisionPoint(nil, security_decision.Environment{
+		Platform: internal_environment.PlatformVehicle,
+		Caps:     caps,
+		Trust:    user_setting.TrustDevice,
+	}).WithDirectory(accounts{
+		"alice": {EntityType: internal_environment.EntityPersonal},
+		"root":  {EntityType: internal_environment.EntityPersonal, Admin: true},
+		"mech":  {EntityType: internal_environment.EntityTester},
+	})
+
+	session := func(user string) *user_setting.SessionClaims {
+		return &user_setting.SessionClaims{UserID: user, SessionID: "s-" + user, Entity: internal_environment.EntityPersonal}
+	}
+	toks := tokens{"alice": session("alice"), "root": session("root"), "mech": session("mech"), "guest": session("guest-1")}
+	toks["mech"].Sandbox = true
+	toks["guest"].Guest = true
+
+	act := &activity{locked: map[string]bool{}, fresh: map[string]bool{}}
+	rt := &dispatcher{}
+	return NewGate(DefaultRegistry(), decisions, toks, act, rt), act, rt
+}
+
+func override() IncomingCommand {
+	return IncomingCommand{Type: CmdSafetyOverride, Priority: 2, Params: map[string]interface{}{
+		"interlock": "door", "duration": 30, "reason": "service",
+	}}
+}
+
+func TestSubmitNeedsAValidToken(t *testing.T) {
+	g, _, rt := testGate(t)
+	halt := IncomingCommand{Type: CmdHalt}
+
+	for _, token := range []string{"", "forged"} {
+		if _, err := g.Submit(context.Background(), token, halt); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("token %q = %v, want %v", token, err, ErrUnauthenticated)
+		}
+	}
+	if _, err := g.Submit(context.Background(), "guest", halt); !errors.Is(err, ErrCommandDenied) {
+		t.Errorf("guest halt = %v, want %v", err, ErrCommandDenied)
+	}
+	if len(rt.sent) != 0 {
+		t.Errorf("refused commands dispatched: %+v", rt.sent)
+	}
+}
+
+func TestLockedSessionCanStillHalt(t *testing.T) {
+	g, act, rt := testGate(t)
+	act.locked["s-alice"] = true
+
+	cmd, err := g.Submit(context.Background(), "alice", IncomingCommand{Type: CmdHalt, Priority: 8})
+	if err != nil {
+		t.Fatalf("halt from a locked session = %v", err)
+	}
+	if cmd.ID == "" || cmd.Priority != PriorityCritical {
+		t.Errorf("dispatched halt = %+v", cmd)
+	}
+	if len(rt.sent) != 1 {
+		t.Fatalf("%d envelopes dispatched, want 1", len(rt.sent))
+	}
+	env := rt.sent[0]
+	if env.Source != "alice" || env.Metadata[MetadataCommandType] != string(CmdHalt) ||
+		env.Metadata[MetadataPriority] != "0" || env.Metadata[router.MetadataSessionToken] != "alice" {
+		t.Errorf("envelope = %+v", env)
+	}
+
+	if _, err := g.Submit(context.Background(), "alice", IncomingCommand{Type: CmdScan, Priority: 5}); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("scan from a locked session = %v, want %v", err, ErrUnauthenticated)
+	}
+}
+
+func TestSafetyOverrideNeedsStepUp(t *testing.T) {
+	g, act, rt := testGate(t)
+
+	if _, err := g.Submit(context.Background(), "alice", override()); !errors.Is(err, ErrCommandDenied) {
+		t.Fatalf("override by a driver = %v, want %v", err, ErrCommandDenied)
+	}
+	if _, err := g.Submit(context.Background(), "root", override()); !errors.Is(err, ErrStepUpRequired) {
+		t.Fatalf("override without step-up = %v, want %v", err, ErrStepUpRequired)
+	}
+	if len(rt.sent) != 0 {
+		t.Fatalf("override dispatched before step-up")
+	}
+
+	act.fresh["s-root"] = true
+	if _, err := g.Submit(context.Background(), "root", override()); err != nil {
+		t.Fatalf("override after step-up = %v", err)
+	}
+	if len(rt.sent) != 1 || rt.sent[0].Metadata[MetadataCommandType] != string(CmdSafetyOverride) {
+		t.Errorf("dispatched = %+v", rt.sent)
+	}
+}
+
+func TestSandboxCommandsGoToTheSimulator(t *testing.T) {
+	g, _, rt := testGate(t)
+
+	bus := runtime_bus.NewMessageBus()
+	simulated := bus.Subscribe(security_sandbox.TopicSimulated)
+	security_sandbox.AttachSessions(bus)
+	defer security_sandbox.AttachSessions(nil)
+
+	if _, err := g.Submit(context.Background(), "mech", IncomingCommand{Type: CmdScan, Priority: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rt.sent) != 0 {
+		t.Fatalf("sandbox command reached the router: %+v", rt.sent)
+	}
+	select {
+	case <-simulated:
+	case <-time.After(time.Second):
+		t.Fatal("sandbox command not simulated")
+	}
+}
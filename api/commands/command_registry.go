@@ -0,0 +1,327 @@
+//api/commands/command_registry.go
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/policy"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+	runtime_types "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/types"
+)
+
+var (
+	ErrUnknownCommand = errors.New("unknown_command")
+	ErrCommandInvalid = errors.New("command_invalid")
+	ErrCommandDenied  = errors.New("command_denied")
+)
+
+// Priorities run from 0 (critical) to 10 (low). Priority 0 is reserved
+// for critical commands.
+const (
+	PriorityCritical = 0
+	PriorityLowest   = 10
+)
+
+type ParamKind string
+
+const (
+	ParamString ParamKind = "string"
+	ParamNumber ParamKind = "number"
+	ParamBool   ParamKind = "bool"
+)
+
+// ParamSpec describes one command parameter. Min and Max bound numbers;
+// OneOf lists the accepted strings.
+type ParamSpec struct {
+	Name     string
+	Kind     ParamKind
+	Required bool
+	Min      *float64
+	Max      *float64
+	OneOf    []string
+}
+
+// CommandSpec is what a command type requires of the session issuing it.
+// Empty Platforms means any platform.
+type CommandSpec struct {
+	Type        CommandType
+	Permissions []user_setting.PermissionKey
+	MinTrust    user_setting.TrustLevel
+	Platforms   []internal_environment.PlatformClass
+	Params      []ParamSpec
+
+	// Critical commands are accepted from any authenticated account
+	// session whatever its permissions, trust and platform, always run at
+	// PriorityCritical, and drop parameters that fail the schema instead
+	// of being refused over them. Guest sessions, which anyone can open
+	// with POST /login, cannot issue them.
+	Critical bool
+}
+
+// Registry holds the command types the device accepts.
+type Registry struct {
+	specs map[CommandType]CommandSpec
+}
+
+// NewRegistry checks specs and indexes them by type.
+func NewRegistry(specs ...CommandSpec) (*Registry, error) {
+	r := &Registry{specs: make(map[CommandType]CommandSpec, len(specs))}
+	for _, s := range specs {
+		if s.Type == "" {
+			return nil, errors.New("command spec without type")
+		}
+		if _, dup := r.specs[s.Type]; dup {
+			return nil, fmt.Errorf("duplicate command spec %s", s.Type)
+		}
+		for _, p := range s.Permissions {
+			if !policy.KnownPermission(p) {
+				return nil, fmt.Errorf("command %s: unknown permission %q", s.Type, p)
+			}
+		}
+		r.specs[s.Type] = s
+	}
+	return r, nil
+}
+
+// DefaultRegistry is the command set of the edge API.
+func DefaultRegistry() *Registry {
+	r, err := NewRegistry(
+		CommandSpec{
+			Type:        CmdNavigate,
+			Permissions: []user_setting.PermissionKey{user_setting.PermBasicRuntime, user_setting.PermHardwareIO},
+			MinTrust:    user_setting.TrustDevice,
+			Platforms: []internal_environment.PlatformClass{
+				internal_environment.PlatformVehicle, internal_environment.PlatformRobot,
+			},
+			Params: []ParamSpec{
+				{Name: "lat", Kind: ParamNumber, Required: true, Min: bound(-90), Max: bound(90)},
+				{Name: "lon", Kind: ParamNumber, Required: true, Min: bound(-180), Max: bound(180)},
+				{Name: "speed", Kind: ParamNumber, Min: bound(0)},
+			},
+		},
+		CommandSpec{
+			Type:        CmdScan,
+			Permissions: []user_setting.PermissionKey{user_setting.PermBasicRuntime},
+			MinTrust:    user_setting.TrustUser,
+			Params: []ParamSpec{
+				{Name: "sensor", Kind: ParamString, OneOf: []string{"camera", "lidar", "radar", "all"}},
+				{Name: "range", Kind: ParamNumber, Min: bound(0)},
+			},
+		},
+		CommandSpec{
+			Type:     CmdHalt,
+			Critical: true,
+			Params: []ParamSpec{
+				{Name: "reason", Kind: ParamString},
+			},
+		},
+		CommandSpec{
+			Type:        CmdSync,
+			Permissions: []user_setting.PermissionKey{user_setting.PermBasicRuntime},
+			MinTrust:    user_setting.TrustUser,
+			Params: []ParamSpec{
+				{Name: "target", Kind: ParamString, OneOf: []string{"cloud", "peer"}},
+				{Name: "full", Kind: ParamBool},
+			},
+		},
+		CommandSpec{
+			Type:        CmdSafetyOverride,
+			Permissions: []user_setting.PermissionKey{user_setting.PermSafetyOverride, user_setting.PermHardwareIO},
+			MinTrust:    user_setting.TrustAdmin,
+			Platforms: []internal_environment.PlatformClass{
+				internal_environment.PlatformVehicle, internal_environment.PlatformRobot, internal_environment.PlatformIndustrial,
+			},
+			Params: []ParamSpec{
+				{Name: "interlock", Kind: ParamString, Required: true},
+				{Name: "duration", Kind: ParamNumber, Required: true, Min: bound(1), Max: bound(600)},
+				{Name: "reason", Kind: ParamString, Required: true},
+			},
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (r *Registry) Lookup(t CommandType) (CommandSpec, bool) {
+	s, ok := r.specs[t]
+	return s, ok
+}
+
+// Types lists the registered command types in order.
+func (r *Registry) Types() []CommandType {
+	out := make([]CommandType, 0, len(r.specs))
+	for t := range r.specs {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// Validate checks cmd against its spec and returns it normalised: critical
+// commands get PriorityCritical and lose parameters the schema rejects.
+func (r *Registry) Validate(cmd IncomingCommand) (IncomingCommand, error) {
+	spec, ok := r.specs[cmd.Type]
+	if !ok {
+		return cmd, fmt.Errorf("%w: %q", ErrUnknownCommand, cmd.Type)
+	}
+
+	if spec.Critical {
+		cmd.Priority = PriorityCritical
+		params := make(map[string]interface{}, len(cmd.Params))
+		for _, p := range spec.Params {
+			if v, ok := cmd.Params[p.Name]; ok && p.check(v) == nil {
+				params[p.Name] = v
+			}
+		}
+		cmd.Params = params
+		return cmd, nil
+	}
+
+	if cmd.Priority <= PriorityCritical || cmd.Priority > PriorityLowest {
+		return cmd, fmt.Errorf("%w: %s: priority must be %d-%d", ErrCommandInvalid, cmd.Type, PriorityCritical+1, PriorityLowest)
+	}
+
+	known := make(map[string]bool, len(spec.Params))
+	for _, p := range spec.Params {
+		known[p.Name] = true
+		v, ok := cmd.Params[p.Name]
+		if !ok {
+			if p.Required {
+				return cmd, fmt.Errorf("%w: %s: missing parameter %s", ErrCommandInvalid, cmd.Type, p.Name)
+			}
+			continue
+		}
+		if err := p.check(v); err != nil {
+			return cmd, fmt.Errorf("%w: %s: %v", ErrCommandInvalid, cmd.Type, err)
+		}
+	}
+	for name := range cmd.Params {
+		if !known[name] {
+			return cmd, fmt.Errorf("%w: %s: unknown parameter %s", ErrCommandInvalid, cmd.Type, name)
+		}
+	}
+	return cmd, nil
+}
+
+// Authorize checks that the session decided as exec may issue cmd.
+func (r *Registry) Authorize(cmd IncomingCommand, exec runtime_types.ExecutionContext) error {
+	spec, ok := r.specs[cmd.Type]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownCommand, cmd.Type)
+	}
+	if !exec.Valid() {
+		return fmt.Errorf("%w: %s: no authenticated session", ErrCommandDenied, cmd.Type)
+	}
+	if spec.Critical {
+		if exec.IsGuest() {
+			return fmt.Errorf("%w: %s cannot be issued by a guest session", ErrCommandDenied, cmd.Type)
+		}
+		return nil
+	}
+
+	if perm, missing := spec.MissingPermission(exec); missing {
+		return fmt.Errorf("%w: %s requires %s", ErrCommandDenied, cmd.Type, perm)
+	}
+	if exec.SecurityTier() < spec.MinTrust {
+		return fmt.Errorf("%w: %s requires %s trust", ErrCommandDenied, cmd.Type, trustName(spec.MinTrust))
+	}
+	if len(spec.Platforms) > 0 && !spec.runsOn(exec.Platform()) {
+		return fmt.Errorf("%w: %s is not available on %s", ErrCommandDenied, cmd.Type, exec.Platform())
+	}
+	return nil
+}
+
+// MissingPermission returns the first required permission exec lacks.
+func (s CommandSpec) MissingPermission(exec runtime_types.ExecutionContext) (user_setting.PermissionKey, bool) {
+	for _, p := range s.Permissions {
+		if !exec.HasPermission(p) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+func (s CommandSpec) runsOn(platform internal_environment.PlatformClass) bool {
+	for _, p := range s.Platforms {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
+
+func (p ParamSpec) check(v interface{}) error {
+	switch p.Kind {
+	case ParamString:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("parameter %s must be a string", p.Name)
+		}
+		if len(p.OneOf) > 0 && !contains(p.OneOf, s) {
+			return fmt.Errorf("parameter %s must be one of %s", p.Name, strings.Join(p.OneOf, ", "))
+		}
+
+	case ParamNumber:
+		n, ok := number(v)
+		if !ok {
+			return fmt.Errorf("parameter %s must be a number", p.Name)
+		}
+		if p.Min != nil && n < *p.Min {
+			return fmt.Errorf("parameter %s must be at least %g", p.Name, *p.Min)
+		}
+		if p.Max != nil && n > *p.Max {
+			return fmt.Errorf("parameter %s must be at most %g", p.Name, *p.Max)
+		}
+
+	case ParamBool:
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("parameter %s must be true or false", p.Name)
+		}
+
+	default:
+		return fmt.Errorf("parameter %s has unknown kind %q", p.Name, p.Kind)
+	}
+	return nil
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func trustName(t user_setting.TrustLevel) string {
+	for name, level := range policy.TrustLevels {
+		if level == t {
+			return name
+		}
+	}
+	return fmt.Sprintf("level %d", t)
+}
+
+func bound(v float64) *float64 { return &v }
@@ -0,0 +1,147 @@
+//api/commands/command_registry_test.go
+
+package commands
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+	runtime_types "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/types"
+)
+
+// decided is an execution context as the decision point would issue it.
+func decided(g runtime_types.Grant) runtime_types.ExecutionContext {
+	g.IssuedAt = time.Now()
+	return runtime_types.NewExecutionContext(g)
+}
+
+func TestValidateChecksParameters(t *testing.T) {
+	r := DefaultRegistry()
+	nav := func(params map[string]interface{}) error {
+		_, err := r.Validate(IncomingCommand{Type: CmdNavigate, Priority: 5, Params: params})
+		return err
+	}
+
+	if err := nav(map[string]interface{}{"lat": 48.1, "lon": 11.5, "speed": 3}); err != nil {
+		t.Fatalf("valid navigate = %v", err)
+	}
+
+	for name, params := range map[string]map[string]interface{}{
+		"missing required": {"lat": 48.1},
+		"unknown":          {"lat": 48.1, "lon": 11.5, "altitude": 500},
+		"below minimum":    {"lat": 48.1, "lon": 11.5, "speed": -1},
+		"above maximum":    {"lat": 91.0, "lon": 11.5},
+		"wrong kind":       {"lat": "north", "lon": 11.5},
+	} {
+		if err := nav(params); !errors.Is(err, ErrCommandInvalid) {
+			t.Errorf("%s: %v, want %v", name, err, ErrCommandInvalid)
+		}
+	}
+
+	if _, err := r.Validate(IncomingCommand{Type: CmdScan, Priority: 5, Params: map[string]interface{}{"sensor": "sonar"}}); !errors.Is(err, ErrCommandInvalid) {
+		t.Errorf("sensor outside its choices = %v", err)
+	}
+	if _, err := r.Validate(IncomingCommand{Type: "SELF_DESTRUCT", Priority: 5}); !errors.Is(err, ErrUnknownCommand) {
+		t.Errorf("unregistered type = %v, want %v", err, ErrUnknownCommand)
+	}
+}
+
+func TestValidateBoundsPriority(t *testing.T) {
+	r := DefaultRegistry()
+
+	for _, p := range []int{PriorityCritical, -1, PriorityLowest + 1} {
+		if _, err := r.Validate(IncomingCommand{Type: CmdScan, Priority: p}); !errors.Is(err, ErrCommandInvalid) {
+			t.Errorf("priority %d = %v, want %v", p, err, ErrCommandInvalid)
+		}
+	}
+	for _, p := range []int{PriorityCritical + 1, PriorityLowest} {
+		if _, err := r.Validate(IncomingCommand{Type: CmdScan, Priority: p}); err != nil {
+			t.Errorf("priority %d = %v", p, err)
+		}
+	}
+}
+
+func TestValidateNormalisesCriticalCommands(t *testing.T) {
+	r := DefaultRegistry()
+
+	cmd, err := r.Validate(IncomingCommand{Type: CmdHalt, Priority: 9, Params: map[string]interface{}{
+		"reason": "obstacle",
+		"extra":  true,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Priority != PriorityCritical || len(cmd.Params) != 1 || cmd.Params["reason"] != "obstacle" {
+		t.Errorf("halt = priority %d, params %v", cmd.Priority, cmd.Params)
+	}
+
+	// A malformed parameter never stops a halt.
+	cmd, err = r.Validate(IncomingCommand{Type: CmdHalt, Params: map[string]interface{}{"reason": 42}})
+	if err != nil || len(cmd.Params) != 0 {
+		t.Errorf("halt with a bad reason = %v, %v", cmd.Params, err)
+	}
+}
+
+func TestAuthorizeChecksPermissionTrustAndPlatform(t *testing.T) {
+	r := DefaultRegistry()
+	nav := IncomingCommand{Type: CmdNavigate}
+	driver := runtime_types.Grant{
+		Platform:    internal_environment.PlatformVehicle,
+		Trust:       user_setting.TrustDevice,
+		Permissions: []user_setting.PermissionKey{user_setting.PermBasicRuntime, user_setting.PermHardwareIO},
+	}
+
+	if err := r.Authorize(nav, decided(driver)); err != nil {
+		t.Fatalf("driver = %v", err)
+	}
+
+	for name, change := range map[string]func(g *runtime_types.Grant){
+		"hardware_io": func(g *runtime_types.Grant) { g.Permissions = g.Permissions[:1] },
+		"trust":       func(g *runtime_types.Grant) { g.Trust = user_setting.TrustUser },
+		"not available": func(g *runtime_types.Grant) {
+			g.Platform = internal_environment.PlatformComputer
+		},
+	} {
+		g := driver
+		change(&g)
+		err := r.Authorize(nav, decided(g))
+		if !errors.Is(err, ErrCommandDenied) || !strings.Contains(err.Error(), name) {
+			t.Errorf("without %s: %v", name, err)
+		}
+	}
+
+	if err := r.Authorize(nav, runtime_types.ExecutionContext{}); !errors.Is(err, ErrCommandDenied) {
+		t.Errorf("undecided context = %v, want %v", err, ErrCommandDenied)
+	}
+}
+
+func TestAuthorizeCriticalCommands(t *testing.T) {
+	r := DefaultRegistry()
+	halt := IncomingCommand{Type: CmdHalt}
+
+	if err := r.Authorize(halt, decided(runtime_types.Grant{UserID: "alice", Trust: user_setting.TrustUntrusted})); err != nil {
+		t.Errorf("account without permissions = %v", err)
+	}
+	if err := r.Authorize(halt, decided(runtime_types.Grant{UserID: "guest-1", Guest: true})); !errors.Is(err, ErrCommandDenied) {
+		t.Errorf("guest = %v, want %v", err, ErrCommandDenied)
+	}
+	if err := r.Authorize(halt, runtime_types.ExecutionContext{}); !errors.Is(err, ErrCommandDenied) {
+		t.Errorf("undecided context = %v, want %v", err, ErrCommandDenied)
+	}
+}
+
+func TestNewRegistryRejectsBadSpecs(t *testing.T) {
+	if _, err := NewRegistry(CommandSpec{Type: CmdScan}, CommandSpec{Type: CmdScan}); err == nil {
+		t.Error("duplicate spec accepted")
+	}
+	if _, err := NewRegistry(CommandSpec{Type: CmdScan, Permissions: []user_setting.PermissionKey{"teleport"}}); err == nil {
+		t.Error("unknown permission accepted")
+	}
+	if _, err := NewRegistry(CommandSpec{}); err == nil {
+		t.Error("spec without type accepted")
+	}
+}
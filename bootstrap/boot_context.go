@@ -24,3 +24,7 @@ type BootContext struct {
 	vault  verification_persistence.VaultStore
 	logger *zap.Logger
 }
+
+func (b BootContext) Vault() verification_persistence.VaultStore {
+	return b.vault
+}
@@ -3,6 +3,7 @@
 package bootstrap_phase
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -26,7 +27,9 @@ func BuildAuthInterface(mode user_setting.InteractionMode) auth.AuthInterface {
 	}
 }
 
-func PhaseInterface(caps *internal_environment.CapabilityProfile) (*user_setting.UserSession, error) {
+// PhaseInterface runs the login flow of the resolved interface on
+// authManager, which carries the vault and the boot decision point.
+func PhaseInterface(caps *internal_environment.CapabilityProfile, authManager *auth.AuthManager) (*user_setting.UserSession, error) {
 
 	mode := mutual_interaction.ResolveInteractionMode(nil, caps.Set)
 
@@ -35,9 +38,7 @@ func PhaseInterface(caps *internal_environment.CapabilityProfile) (*user_setting
 		return nil, errors.New("failed to build auth interface")
 	}
 
-	authManager := auth.AuthManager{}
-
-	result, err := ui.StartAuthFlow(&authManager)
+	result, err := ui.StartAuthFlow(authManager)
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +75,7 @@ func (h *HybridAuthUI) StartAuthFlow(auth *auth.AuthManager) (*user_setting.User
 		return auth.Login(creds.UserID, creds.Password)
 
 	case "signup":
-		return auth.Register()
+		return auth.Register(context.Background())
 
 	default:
 		return nil, errors.New("invalid choice")
@@ -0,0 +1,47 @@
+//bootstrap/phases/measurement_phase.go
+
+package bootstrap_phase
+
+import (
+	"fmt"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_measurement "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/measurement"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/pkg/logging"
+)
+
+// PhaseMeasurement records what is about to run: binary, config and policy
+// files, module and plugin artifacts and the resolved environment. The log
+// is signed with the device identity and persisted per boot so a verifier
+// can replay it later, and its final digest is carried in the environment
+// attestation.
+func PhaseMeasurement(vault verification_persistence.VaultStore, env *internal_environment.EnvConfig) (*verification_measurement.EventLog, error) {
+	log, err := verification_measurement.MeasureBoot(verification_measurement.DefaultBootArtifacts(), env)
+	if err != nil {
+		return nil, fmt.Errorf("measured boot failed: %w", err)
+	}
+
+	device, err := verification_identity.LoadDeviceIdentity(vault)
+	if err != nil {
+		return nil, fmt.Errorf("device identity unavailable to sign the measurement log: %w", err)
+	}
+	if device != nil {
+		log.Sign(device)
+	} else {
+		logging.Warn("[phase_measurement] device identity not provisioned, boot %s left unsigned", log.BootID)
+	}
+
+	if err := verification_measurement.SaveEventLog(vault, log); err != nil {
+		return nil, fmt.Errorf("failed to persist measurement log: %w", err)
+	}
+
+	if env != nil {
+		env.Attestation.BootID = log.BootID
+		env.Attestation.Measurement = log.Final
+	}
+
+	logging.Info("[phase_measurement] boot %s measured %d events, digest %s", log.BootID, len(log.Events), log.Final)
+	return log, nil
+}
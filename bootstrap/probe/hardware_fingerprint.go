@@ -5,6 +5,8 @@ package probe
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"os"
@@ -28,6 +30,43 @@ type HardwareFingerprint struct {
 	Buses   map[string]bool
 }
 
+// Digest reduces the fingerprint to a stable hex SHA-256. MAC addresses and
+// bus detection are left out because they change with link state and
+// plugged peripherals.
+func (f HardwareFingerprint) Digest() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "tpm=%s\ncpu=%s\ndmi=%s\n", f.TPM, f.CPU, f.DMI)
+	for _, p := range f.PCI {
+		fmt.Fprintf(h, "pci=%s\n", p)
+	}
+	for _, s := range f.Storage {
+		fmt.Fprintf(h, "disk=%s\n", s)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Components hashes each part of Digest on its own, leaving out the parts
+// that were not measured, so a changed part can be told from a missing one.
+func (f HardwareFingerprint) Components() map[string]string {
+	parts := map[string]string{
+		"tpm":     f.TPM,
+		"cpu":     f.CPU,
+		"dmi":     f.DMI,
+		"pci":     strings.Join(f.PCI, "\n"),
+		"storage": strings.Join(f.Storage, "\n"),
+	}
+
+	out := make(map[string]string, len(parts))
+	for name, v := range parts {
+		if v == "" {
+			continue
+		}
+		sum := sha256.Sum256([]byte(v))
+		out[name] = hex.EncodeToString(sum[:])
+	}
+	return out
+}
+
 type fingerprintBuilder struct {
 	mu sync.Mutex
 	fp HardwareFingerprint
@@ -0,0 +1,54 @@
+//bootstrap/resolver/boot_binary_check.go
+
+package bootstrap_resolver
+
+import (
+	"fmt"
+
+	core_verification "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/verification"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/pkg/logging"
+)
+
+// attestBinary verifies the running binary and records the trust it gives
+// this boot on env. A binary the signed release manifest or the sealed
+// baseline vouches for boots at strong trust. One nothing vouches for (a
+// version the manifest does not list, an update since the baseline was
+// sealed, no baseline, no release key provisioned) boots degraded at weak
+// trust, which never reaches device-trust permissions. A revoked or
+// downgraded binary, a tampered one whose digest does not match the
+// version it claims, or a manifest that fails its signature or rollback
+// check, does not boot.
+func (bm *BootManager) attestBinary(env *internal_environment.EnvConfig) error {
+	err := core_verification.VerifyAgainstGolden(bm.Vault, bm.Identity.MachineID)
+	switch {
+	case err == nil:
+		env.Attestation.Level = internal_environment.TrustStrong
+		env.Attestation.Degraded = ""
+		return nil
+
+	case core_verification.Unverified(err):
+		logging.Warn("[verification] binary not verified (%v); booting degraded at weak trust", err)
+		env.Attestation.Level = internal_environment.TrustWeak
+		env.Attestation.Degraded = err.Error()
+		return nil
+
+	default:
+		return fmt.Errorf("golden verification failed: %w", err)
+	}
+}
+
+// sealFirstBootBaseline records the binary a unit first booted with as its
+// golden hash, unless a release manifest or a baseline already covers it.
+// The boot that seals it has already been attested as degraded: nothing
+// vouched for the binary yet.
+func (bm *BootManager) sealFirstBootBaseline() error {
+	manifest, err := core_verification.LoadManifest(bm.Vault)
+	if err != nil || manifest != nil {
+		return err
+	}
+	if _, err := bm.Vault.LoadGoldenHash(bm.Identity.MachineID); err == nil {
+		return nil
+	}
+	return core_verification.SealBaseline(bm.Vault, bm.Identity.MachineID)
+}
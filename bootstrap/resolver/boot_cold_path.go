@@ -3,11 +3,15 @@
 package bootstrap_resolver
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/bootstrap/probe"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
 	internal_boot "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/boot"
 	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/pkg/logging"
 )
 
 // ------------------------------------------------------------
@@ -26,6 +30,31 @@ func (bm *BootManager) runColdBoot() (*internal_environment.BootSequence, error)
 
 	bm.Identity.BindHardware(fullProfile)
 
+	if err := bm.attestBinary(fullProfile); err != nil {
+		return nil, err
+	}
+
+	// 2. Device identity bound to this hardware. A probe that failed or
+	// timed out leaves a partial fingerprint, which can keep an identity
+	// but never rebind one; if it cannot decide, the boot runs degraded.
+	fp, probeErrors := probe.CollectHardwareFingerprint(context.Background())
+	if len(probeErrors) > 0 {
+		logging.Warn("[IDENTITY] hardware fingerprint incomplete: %v", probeErrors)
+	}
+	claim := verification_identity.HardwareClaim{
+		Digest:     fp.Digest(),
+		Components: fp.Components(),
+		Partial:    len(probeErrors) > 0,
+	}
+	if _, err := verification_identity.ProvisionDeviceIdentity(bm.Vault, bm.Identity.MachineID, claim); err != nil {
+		if !errors.Is(err, verification_identity.ErrFingerprintIncomplete) {
+			return nil, fmt.Errorf("device identity provisioning failed: %w", err)
+		}
+		logging.Warn("[IDENTITY] %v; booting degraded at weak trust", err)
+		fullProfile.Attestation.Level = internal_environment.TrustWeak
+		fullProfile.Attestation.Degraded = err.Error()
+	}
+
 	return &internal_environment.BootSequence{
 		Env:      fullProfile,
 		Mode:     internal_boot.BootCold,
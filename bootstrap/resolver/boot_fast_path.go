@@ -4,7 +4,6 @@ package bootstrap_resolver
 
 import (
 	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/bootstrap/probe"
-	core_verification "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/verification"
 	internal_boot "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/boot"
 	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
 )
@@ -14,12 +13,11 @@ import (
 // ------------------------------------------------------------
 func (bm *BootManager) runFastBoot(env *internal_environment.EnvConfig) (*internal_environment.BootSequence, error) {
 	// 1. Verify against golden
-	marker, err := bm.Vault.LoadFirstBootMarker()
-	if err != nil || env.SchemaVersion != internal_environment.CurrentVersion {
+	if _, err := bm.Vault.LoadFirstBootMarker(); err != nil || env.SchemaVersion != internal_environment.CurrentVersion {
 		return bm.runColdBoot()
 	}
-	if err := core_verification.VerifyAgainstGolden(bm.Vault, marker.MachineID); err != nil {
-		return bm.runColdBoot()
+	if err := bm.attestBinary(env); err != nil {
+		return nil, err
 	}
 
 	// 2. Passive sanity scan
@@ -5,6 +5,7 @@ package bootstrap_resolver
 import (
 	"fmt"
 
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
 	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/keys"
 	internal_boot "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/boot"
 	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
@@ -12,6 +13,32 @@ import (
 
 // DecideBootPath determines whether to run fast or cold boot
 func (bm *BootManager) DecideBootPath() (*internal_environment.BootSequence, error) {
+	// A vault imported from another unit must re-attest this hardware
+	pending, err := verification_persistence.ReattestationPending(bm.Vault)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check re-attestation state: %w", err)
+	}
+	if pending {
+		marker := &internal_boot.FirstBootMarker{
+			MachineID: bm.Identity.MachineID,
+		}
+		if err := bm.Vault.MarkFirstBoot(marker); err != nil {
+			return nil, err
+		}
+
+		bs, err := bm.runColdBoot()
+		if err != nil {
+			return nil, err
+		}
+		if err := bm.sealFirstBootBaseline(); err != nil {
+			return nil, err
+		}
+		if err := verification_persistence.ClearReattestation(bm.Vault); err != nil {
+			return nil, err
+		}
+		return bs, nil
+	}
+
 	// Load last known environment
 	lastkey := keys.LastKnownEnvKey(bm.Identity.MachineID)
 	env, err := bm.Vault.LoadConfig(lastkey)
@@ -28,7 +55,11 @@ func (bm *BootManager) DecideBootPath() (*internal_environment.BootSequence, err
 			return nil, err
 		}
 
-		return bm.runColdBoot()
+		bs, err := bm.runColdBoot()
+		if err != nil {
+			return nil, err
+		}
+		return bs, bm.sealFirstBootBaseline()
 	}
 	// Perform fast boot
 	return bm.runFastBoot(env)
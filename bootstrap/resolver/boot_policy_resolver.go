@@ -4,83 +4,40 @@ package bootstrap_resolver
 import (
 	"fmt"
 
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/policy"
+	security_decision "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/decision"
 	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
-	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
 	runtime_types "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/types"
 )
 
-func ResolveBootContext(bs *internal_environment.BootSequence) (*runtime_types.ExecutionContext, error) {
+// ResolveDecisionPoint sets up the decision point for the attested boot.
+// It exists before anyone logs in, so the boot login is decided under the
+// loaded policies, and is kept to decide later sessions and API requests
+// the same way.
+func ResolveDecisionPoint(bs *internal_environment.BootSequence, policies *policy.Set) (*security_decision.DecisionPoint, error) {
 
 	if bs == nil {
 		return nil, fmt.Errorf("bootstrap sequence is nil")
 	}
 
-	if bs.Env == nil {
-		return nil, fmt.Errorf("missing environment config")
+	env, err := security_decision.AttestedEnvironment(bs.Env, bs.Attested, bs.Capabilities)
+	if err != nil {
+		return nil, err
 	}
 
-	if !bs.Attested || !bs.Env.Attestation.Valid {
-		return nil, fmt.Errorf("environment attestation invalid")
-	}
-
-	if bs.UserSession == nil {
-		return nil, fmt.Errorf("missing authenticated session")
-	}
-
-	env := bs.Env
-	session := bs.UserSession
-
-	entity := bs.Entity
-	tier := bs.Tier
-	service := bs.Service
-	caps := bs.Capabilities
-
-	// --- Trust resolution ---
-	var trust internal_environment.BootTrust
-
-	switch env.Attestation.Level {
-	case internal_environment.TrustStrong:
-		trust = internal_environment.TrustStrong
-	case internal_environment.TrustWeak:
-		trust = internal_environment.TrustWeak
-	default:
-		trust = internal_environment.TrustInvalid
-	}
-
-	// --- Permissions ---
-	perms := make(map[user_setting.PermissionKey]bool)
-	perms[user_setting.PermUser] = true
+	return security_decision.NewDecisionPoint(policies, env), nil
+}
 
-	switch entity {
-	case internal_environment.EntityOrganization:
-		perms[user_setting.PermDiagnostics] = true
-	case internal_environment.EntityTester:
-		perms[user_setting.PermDiagnostics] = true
-		perms[user_setting.PermConfigEdit] = true
-	}
+// ResolveBootContext decides the boot session with point.
+func ResolveBootContext(bs *internal_environment.BootSequence, point *security_decision.DecisionPoint) (runtime_types.ExecutionContext, error) {
 
-	switch tier {
-	case user_setting.TierEnterprise:
-		perms[user_setting.PermDiagnostics] = true
-		perms[user_setting.PermConfigEdit] = true
-	}
-
-	if caps.Has(internal_environment.CapCANBus) || caps.Has(internal_environment.CapIndustrialIO) {
-		perms[user_setting.PermHardwareIO] = true
-	}
-
-	if caps.Has(internal_environment.CapSafetyCritical) && trust == internal_environment.TrustStrong {
-		perms[user_setting.PermAdmin] = true
-		perms[user_setting.PermSafetyOverride] = true
+	if bs == nil {
+		return runtime_types.ExecutionContext{}, fmt.Errorf("bootstrap sequence is nil")
 	}
 
-	for p, allowed := range session.Permissions {
-		if allowed && perms[p] {
-			perms[p] = true // keep allowed
-		}
+	if bs.UserSession == nil {
+		return runtime_types.ExecutionContext{}, fmt.Errorf("missing authenticated session")
 	}
 
-	bootctx := &runtime_types.ExecutionContext{}
-
-	return bootctx, nil
+	return point.DecideSession(&bs.UserSession.Claims), nil
 }
@@ -0,0 +1,112 @@
+//cmd/aios-companion/main.go
+
+// aios-companion is the reference companion app. It pairs with a device
+// and then answers login challenges, so the enterprise vehicle flow can be
+// exercised without a phone.
+//
+//	aios-companion pair 'aios-pair://192.168.1.20:7421?code=123456'
+//	aios-companion serve 192.168.1.20:7422
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	security_pairing "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/pairing"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "aios-companion:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: aios-companion pair <uri>|<endpoint> <code> | serve <endpoint> [--state file]")
+	}
+
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	state := fs.String("state", "aios-companion.json", "file holding the companion key")
+	name := fs.String("name", hostname(), "name shown on the device")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch args[0] {
+	case "pair":
+		var endpoint, code string
+		switch fs.NArg() {
+		case 1:
+			var err error
+			if endpoint, code, err = security_pairing.ParseOfferURI(fs.Arg(0)); err != nil {
+				return err
+			}
+		case 2:
+			endpoint, code = fs.Arg(0), fs.Arg(1)
+		default:
+			return errors.New("usage: aios-companion pair <uri> | <endpoint> <code>")
+		}
+
+		client, err := security_pairing.LoadClient(*state)
+		if errors.Is(err, os.ErrNotExist) {
+			client, err = security_pairing.NewClient(*name)
+		}
+		if err != nil {
+			return err
+		}
+
+		in := bufio.NewReader(os.Stdin)
+		confirm := func(sas string) bool {
+			fmt.Printf("Does the device show %s %s? [y/N] ", sas[:3], sas[3:])
+			line, _ := in.ReadString('\n')
+			return strings.EqualFold(strings.TrimSpace(line), "y")
+		}
+
+		if err := client.Pair(ctx, endpoint, code, confirm); err != nil {
+			return err
+		}
+		if err := client.Save(*state); err != nil {
+			return err
+		}
+		fmt.Printf("paired as %s with %s\n", client.TokenID, client.Device)
+		return nil
+
+	case "serve":
+		if fs.NArg() != 1 {
+			return errors.New("usage: aios-companion serve <endpoint>")
+		}
+		client, err := security_pairing.LoadClient(*state)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("answering login challenges for %s at %s\n", client.Device, fs.Arg(0))
+		if err := client.ServeLogins(ctx, fs.Arg(0)); !errors.Is(err, context.Canceled) {
+			return err
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown command: %s", args[0])
+	}
+}
+
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "companion"
+	}
+	return name
+}
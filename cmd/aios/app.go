@@ -4,12 +4,27 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
+	"time"
 
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/api/commands"
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/auth"
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/router"
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	security_decision "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/decision"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	security_sandbox "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/sandbox"
+	security_scratch "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/scratch"
+	security_users "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/users"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
 	modules_adapter "github.com/MIAUSEproject-founderKJ/multi-platform-AI/modules/adapter"
+	transport_filter "github.com/MIAUSEproject-founderKJ/multi-platform-AI/modules/data_transport/filter"
 	kernel_registry "github.com/MIAUSEproject-founderKJ/multi-platform-AI/modules/kernel_extension/registry"
 	kernel_supervisor "github.com/MIAUSEproject-founderKJ/multi-platform-AI/modules/kernel_extension/supervisor"
+	runtime_bus "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/bus"
 	runtime_engine "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/engine"
 	runtime_supervisor "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/supervisor"
 	"go.uber.org/zap"
@@ -19,34 +34,150 @@ import (
 // APP COMPOSITION
 // ============================================================
 
+// configWatchInterval is how often the runtime looks for config changes
+// made by another process.
+const configWatchInterval = 5 * time.Second
+
+// scratchSweepInterval is how often guest scratch areas of expired
+// sessions are wiped.
+const scratchSweepInterval = time.Minute
+
 type App struct {
 	log        *zap.Logger
 	supervisor *runtime_supervisor.Supervisor
 	server     *http.Server
+	tokens     *verification_identity.TokenService
+	users      *security_users.Directory
+	activity   *verification_identity.SessionActivity
+	sandbox    *security_sandbox.Sandbox
+	guest      *security_scratch.Area
+	auth       *auth.AuthManager
+	switcher   *auth.SessionSwitcher
+	audit      *security_audit.Log
+	bus        *runtime_bus.MessageBus
+	router     router.Router
+	decisions  *security_decision.DecisionPoint
+	commands   *commands.Gate
+
+	vault        verification_persistence.VaultStore
+	unsubConfig  func()
+	cancelConfig context.CancelFunc
 }
 
-func buildApp(log *zap.Logger, sys *SystemContext) (*App, error) {
+func buildApp(log *zap.Logger, sys *SystemContext) (_ *App, err error) {
 
-	if sys.Exec == nil {
+	if !sys.Execution.Valid() || sys.Decisions == nil {
 		return nil, errors.New("missing execution context")
 	}
 
+	// --- Sandbox (tester / mechanic sessions) ---
+	// Entered before anything opens the vault or the network, so every
+	// consumer below writes to the shadow overlay. Only the audit chain
+	// stays on the real vault: what a sandbox session did must outlive it.
+	vault := sys.Boot.Vault()
+	var sandbox *security_sandbox.Sandbox
+	if sys.Session.IsSandbox() {
+		sandbox, err = security_sandbox.Enter(sys.Session, vault, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				sandbox.Exit()
+			}
+		}()
+		vault = sandbox.Vault
+		log.Warn("SANDBOX_MODE", zap.String("user", sys.Session.Claims.UserID))
+	}
+
+	// --- Guest sessions store nothing outside their scratch area ---
+	var guest *security_scratch.Area
+	if sys.Session.IsGuest() {
+		guest, err = security_scratch.Open(sys.Session.Claims.SessionID, sys.Session.Claims.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		guestVault, err := guest.Vault(vault)
+		if err != nil {
+			return nil, err
+		}
+		vault = guestVault
+	}
+
+	// --- Session tokens (every API request is verified against these) ---
+	tokens, err := verification_identity.NewTokenService(vault)
+	if err != nil {
+		return nil, err
+	}
+
 	// --- Runtime ---
-	rtx, err := runtime_engine.Build(sys.Exec, sys.Session, log)
+	rtx, err := runtime_engine.Build(sys.Execution, sys.Session, log)
 	if err != nil {
 		return nil, err
 	}
+	if sandbox != nil {
+		sandbox.Attach(rtx.Infra.Bus)
+	}
+	security_sandbox.AttachSessions(rtx.Infra.Bus)
+
+	// --- User administration (every change lands in the audit chain) ---
+	device, err := verification_identity.LoadDeviceIdentity(vault)
+	if err != nil {
+		return nil, err
+	}
+	auditLog := security_audit.NewLog(sys.Boot.Vault(), device)
+	users := security_users.NewDirectory(vault, auditLog)
+	decisions := sys.Decisions.WithAudit(auditLog).WithDirectory(users)
+
+	// --- Config changes reach running modules over the bus ---
+	unsubConfig := auth.SubscribeConfig(runtime_engine.ConfigChangePublisher(rtx.Infra.Bus))
+
+	// --- Remote logins (POST /login) get a token signed by tokens ---
+	activity := verification_identity.NewSessionActivity(nil, 0)
+	authManager := &auth.AuthManager{
+		Vault:     vault,
+		Audit:     auditLog,
+		Activity:  activity,
+		Tokens:    tokens,
+		Platform:  sys.Session.Claims.Platform,
+		Decisions: decisions,
+		Remote:    true,
+	}
+
+	// --- Driver / operator changes replace the boot session in place ---
+	switcher := auth.NewSessionSwitcher(authManager, runtime_engine.UserSwitchPublisher(rtx.Infra.Bus))
+	switcher.Adopt(sys.Session)
+
+	// --- Admitted commands reach the actuators over the bus; critical ones
+	// run at once instead of queueing ---
+	rtx.Infra.Router.Handle(func(ctx context.Context, env router.Envelope) error {
+		rtx.Infra.Bus.Publish(runtime_bus.Message{Topic: "actuator.command", Data: env.Payload})
+		return nil
+	})
+
+	// --- Commands reach the router only with a session token it verifies ---
+	guarded := router.NewGuardedRouter(rtx.Infra.Router, tokens)
+
+	// --- Runtime handed to modules; each sees only its own secrets ---
+	moduleRuntime := &runtime_engine.RuntimeContext{
+		Router:  rtx.Infra.Router,
+		Bus:     rtx.Infra.Bus,
+		Secrets: runtime_engine.NewSecretStore(vault, rtx.Infra.Bus),
+		Audit:   auditLog,
+	}
 
 	// --- Modules ---
 	registry := kernel_registry.DefaultRegistry()
 
-	// filtering + capability enforcement should already be resolved in boot
-	ordered, err := kernel_supervisor.ResolveDependencies(registry)
+	// Only modules the boot's execution context permits are started; the
+	// others are refused in the audit log
+	permitted := transport_filter.FilterModules(registry, sys.Execution, decisions, decisions.Subject(&sys.Session.Claims))
+	ordered, err := kernel_supervisor.ResolveDependencies(permitted)
 	if err != nil {
 		return nil, err
 	}
 
-	modules := modules_adapter.AdaptModules(ordered, rtx)
+	modules := modules_adapter.AdaptModules(ordered, moduleRuntime)
 
 	if len(modules) == 0 {
 		return nil, errors.New("no modules available after adaptation")
@@ -58,6 +189,21 @@ func buildApp(log *zap.Logger, sys *SystemContext) (*App, error) {
 	return &App{
 		log:        log,
 		supervisor: sup,
+		tokens:     tokens,
+		users:      users,
+		activity:   activity,
+		sandbox:    sandbox,
+		guest:      guest,
+		auth:       authManager,
+		switcher:   switcher,
+		audit:      auditLog,
+		bus:        rtx.Infra.Bus,
+		router:     rtx.Infra.Router,
+		decisions:  decisions,
+		commands:   commands.NewGate(commands.DefaultRegistry(), decisions, tokens, activity, guarded),
+
+		vault:       vault,
+		unsubConfig: unsubConfig,
 	}, nil
 }
 
@@ -75,6 +221,17 @@ func (app *App) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Pick up config saved by `aios config` while we run
+	watchCtx, cancel := context.WithCancel(ctx)
+	app.cancelConfig = cancel
+	go auth.WatchConfig(watchCtx, app.vault, configWatchInterval)
+	go security_scratch.Watch(watchCtx, scratchSweepInterval)
+	go app.watchUserSwitches(watchCtx, app.bus.Subscribe(user_setting.TopicUserSwitched))
+
+	if err := app.router.Start(watchCtx); err != nil {
+		return err
+	}
+
 	app.startHTTP()
 	return nil
 }
@@ -83,5 +240,47 @@ func (app *App) Stop(ctx context.Context) error {
 	if app.server != nil {
 		_ = app.server.Shutdown(ctx)
 	}
-	return app.supervisor.Stop(ctx)
+	if app.cancelConfig != nil {
+		app.cancelConfig()
+	}
+	app.unsubConfig()
+	err := app.supervisor.Stop(ctx)
+	if app.sandbox != nil {
+		app.sandbox.Exit()
+	}
+	if app.guest != nil {
+		if werr := app.guest.Wipe(); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+// watchUserSwitches records every change of active user until ctx ends.
+func (app *App) watchUserSwitches(ctx context.Context, ch chan runtime_bus.Message) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-ch:
+			var ev user_setting.UserSwitched
+			if err := json.Unmarshal(msg.Data, &ev); err != nil {
+				app.log.Warn("USER_SWITCH_PAYLOAD", zap.Error(err))
+				continue
+			}
+
+			app.log.Info("USER_SWITCHED",
+				zap.String("from", ev.PreviousUserID),
+				zap.String("to", ev.UserID))
+
+			if _, err := app.audit.Append(security_audit.Event{
+				Actor:    ev.UserID,
+				Action:   "session.switch",
+				Resource: ev.PreviousUserID,
+				Result:   "ok",
+			}); err != nil {
+				app.log.Error("AUDIT_APPEND", zap.Error(err))
+			}
+		}
+	}
 }
@@ -5,31 +5,41 @@ import (
 	bootstrap "github.com/MIAUSEproject-founderKJ/multi-platform-AI/bootstrap"
 	bootstrap_orchestrator "github.com/MIAUSEproject-founderKJ/multi-platform-AI/bootstrap/orchestrator"
 	bootstrap_resolver "github.com/MIAUSEproject-founderKJ/multi-platform-AI/bootstrap/resolver"
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/policy"
 	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
 )
 
 func attemptBoot() (*SystemContext, error) {
-	vault, err := verification_persistence.OpenVault() //load local secured key
+	vault, err := verification_persistence.OpenStore() //load local secured key
 	if err != nil {
 		return nil, err
 	}
 
-	// Stage 1: Boot orchestration
+	// Stage 1: Policies, loaded before anyone logs in so the boot login is
+	// decided under them
+	policies, err := policy.LoadDir(vault, policy.Dir())
+	if err != nil {
+		return nil, err
+	}
+
+	// Stage 2: Boot orchestration
 	bootCtx := bootstrap.NewBootContext(vault)
 
-	bootSeq, session, err := bootstrap_orchestrator.RunBootSequence(bootCtx)
+	bootSeq, decisions, session, err := bootstrap_orchestrator.RunBootSequence(bootCtx, policies)
 	if err != nil {
 		return nil, err
 	}
 
-	execCtx, err := bootstrap_resolver.ResolveExecutionContext(bootCtx, session)
+	// Stage 3: Policy decision for the boot session
+	execCtx, err := bootstrap_resolver.ResolveBootContext(bootSeq, decisions)
 	if err != nil {
 		return nil, err
 	}
 
 	return &SystemContext{
-		Boot:    bootCtx,
-		Runtime: execCtx,
-		Session: session,
+		Boot:      bootCtx,
+		Execution: execCtx,
+		Decisions: decisions,
+		Session:   session,
 	}, nil
 }
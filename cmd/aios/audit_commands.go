@@ -0,0 +1,95 @@
+//cmd/aios/audit_commands.go
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+func runAuditCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand: verify|query")
+	}
+
+	vault, err := verification_persistence.OpenStore()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "verify":
+		id, err := verification_identity.LoadDeviceIdentity(vault)
+		if err != nil {
+			return err
+		}
+		if id == nil {
+			return errors.New("device identity not provisioned; checkpoints cannot be checked")
+		}
+
+		report, err := security_audit.Verify(vault, id.PublicKey)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("entries: %d, checkpoints: %d, head: %s\n", report.Entries, report.Checkpoints, report.Head)
+		for _, p := range report.Problems {
+			fmt.Printf("  seq %-8d %-20s %s\n", p.Seq, p.Kind, p.Detail)
+		}
+		if !report.OK() {
+			return fmt.Errorf("audit log verification failed: %d problem(s)", len(report.Problems))
+		}
+		fmt.Println("audit log intact")
+		return nil
+
+	case "query":
+		fs := flag.NewFlagSet("audit query", flag.ContinueOnError)
+		actor := fs.String("actor", "", "only entries by this actor")
+		perm := fs.String("perm", "", "only entries for this permission")
+		action := fs.String("action", "", "only entries with this action")
+		since := fs.String("since", "", "start time (RFC 3339)")
+		until := fs.String("until", "", "end time, exclusive (RFC 3339)")
+		limit := fs.Int("limit", 0, "maximum entries to print")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		f := security_audit.Filter{Actor: *actor, Permission: *perm, Action: *action, Limit: *limit}
+		if f.Since, err = parseTimeFlag(*since); err != nil {
+			return err
+		}
+		if f.Until, err = parseTimeFlag(*until); err != nil {
+			return err
+		}
+
+		entries, err := security_audit.Query(vault, f)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			fmt.Printf("%-8d %s %-20s %-24s %-20s %-16s %s\n", e.Seq, e.At.Format(time.RFC3339),
+				e.Actor, e.Action, e.Permission, e.Result, e.Resource)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown audit subcommand: %s", args[0])
+	}
+}
+
+func parseTimeFlag(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: %w", s, err)
+	}
+	return t, nil
+}
@@ -10,6 +10,7 @@ import (
 	"syscall"
 	"time"
 
+	security_scratch "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/scratch"
 	"go.uber.org/zap"
 )
 
@@ -18,12 +19,21 @@ import (
 // ============================================================
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
 	log, _ := zap.NewProduction()
 	defer log.Sync()
 
+	// Guest scratch left behind by a previous run, before anyone logs in
+	if err := security_scratch.WipeAll(); err != nil {
+		log.Fatal("SCRATCH_WIPE_FAILED", zap.Error(err))
+	}
+
 	sys, err := buildSystemContext()
 	if err != nil {
 		log.Fatal("BOOT_FAILED", zap.Error(err))
@@ -0,0 +1,64 @@
+//cmd/aios/command_http.go
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/api/commands"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+)
+
+// handleCommand is POST /api/commands: one IncomingCommand, validated and
+// authorized against the calling session before it reaches the router.
+func (a *App) handleCommand(w http.ResponseWriter, r *http.Request) {
+	var cmd commands.IncomingCommand
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&cmd); err != nil {
+		http.Error(w, "malformed command", http.StatusBadRequest)
+		return
+	}
+	a.submitCommand(w, r, cmd)
+}
+
+// handleHalt is POST /api/commands/halt. It only needs a valid account
+// session: an idle-locked session or a user without permissions can still
+// stop the machine, a guest cannot. The body may give {"reason": "..."}.
+func (a *App) handleHalt(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(io.LimitReader(r.Body, 4<<10)).Decode(&body)
+
+	cmd := commands.IncomingCommand{Type: commands.CmdHalt}
+	if body.Reason != "" {
+		cmd.Params = map[string]interface{}{"reason": body.Reason}
+	}
+	a.submitCommand(w, r, cmd)
+}
+
+func (a *App) submitCommand(w http.ResponseWriter, r *http.Request, cmd commands.IncomingCommand) {
+	cmd, err := a.commands.Submit(r.Context(), verification_identity.BearerToken(r), cmd)
+	if err != nil {
+		status := http.StatusServiceUnavailable
+		switch {
+		case errors.Is(err, commands.ErrUnknownCommand), errors.Is(err, commands.ErrCommandInvalid):
+			status = http.StatusBadRequest
+		case errors.Is(err, commands.ErrCommandDenied):
+			status = http.StatusForbidden
+		case errors.Is(err, commands.ErrUnauthenticated), errors.Is(err, commands.ErrStepUpRequired):
+			status = http.StatusUnauthorized
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":       cmd.ID,
+		"type":     cmd.Type,
+		"priority": cmd.Priority,
+	})
+}
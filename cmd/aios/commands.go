@@ -0,0 +1,65 @@
+//cmd/aios/commands.go
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ============================================================
+// SUBCOMMANDS
+// ============================================================
+
+// command is an administrative entrypoint that runs instead of the
+// normal boot sequence, e.g. `aios vault migrate`.
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var cliCommands = map[string]command{
+	"config":      {usage: "config get|history|diff|set|rollback <user> [key [value]|revision...] [--as admin]", run: runConfigCommand},
+	"audit":       {usage: "audit verify|query [--actor a] [--perm p] [--since t] [--until t]", run: runAuditCommand},
+	"vault":       {usage: "vault migrate|export|import", run: runVaultCommand},
+	"golden":      {usage: "golden install|status", run: runGoldenCommand},
+	"device":      {usage: "device show|csr [--org name] [--out file]", run: runDeviceCommand},
+	"secret":      {usage: "secret put|grant|list|delete <name> --as <admin> [--modules a,b] [--from file]", run: runSecretCommand},
+	"lockout":     {usage: "lockout status|unlock <user:id|source:addr> [--admin name]|policy [platform]", run: runLockoutCommand},
+	"policy":      {usage: "policy list|validate <file>|sign <file> [--as admin]|test <fixtures> [--dir d] [--policy a.json,...]|explain --user u --perm p [--platform p --trust t --caps a,b]", run: runPolicyCommand},
+	"pair":        {usage: "pair start|list|remove <user> [token-id] [--listen addr] [--as admin]", run: runPairCommand},
+	"measurement": {usage: "measurement show|verify [--boot id] [--expect digest]", run: runMeasurementCommand},
+	"token":       {usage: "token enroll|list|revoke|simulate <user> [--kind k] [--id id]|devices|add-device [--as admin]", run: runTokenCommand},
+	"user":        {usage: "user add|list|show|disable|enable|passwd|set-entity|set-tier|delete|reset-issue|reset-cancel <id> [--as admin] [--ttl d] [--out file]|reset <token|@file>", run: runUserCommand},
+}
+
+// runCommand dispatches os.Args[1:] to a registered subcommand and
+// returns the process exit code.
+func runCommand(args []string) int {
+	cmd, ok := cliCommands[args[0]]
+	if !ok {
+		printUsage()
+		return 2
+	}
+
+	if err := cmd.run(args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "aios %s: %v\n", args[0], err)
+		return 1
+	}
+
+	return 0
+}
+
+func printUsage() {
+	names := make([]string, 0, len(cliCommands))
+	for name := range cliCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "usage: aios [command]")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  aios %s\n", cliCommands[name].usage)
+	}
+}
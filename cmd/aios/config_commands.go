@@ -0,0 +1,215 @@
+//cmd/aios/config_commands.go
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/auth"
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	security_users "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/users"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+func runConfigCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand: get|set|diff|history|rollback")
+	}
+
+	vault, err := verification_persistence.OpenStore()
+	if err != nil {
+		return err
+	}
+
+	device, err := verification_identity.LoadDeviceIdentity(vault)
+	if err != nil {
+		return err
+	}
+	audit := security_audit.NewLog(vault, device)
+
+	fs := flag.NewFlagSet("config "+args[0], flag.ContinueOnError)
+	as := fs.String("as", os.Getenv("AIOS_ADMIN"), "admin reading or changing another user's config")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	userID := fs.Arg(0)
+	if userID == "" {
+		return fmt.Errorf("usage: aios config %s <user-id> ...", args[0])
+	}
+	if err := requireUser(vault, userID); err != nil {
+		return err
+	}
+
+	// Reading a config is as private as changing it: only its owner or an
+	// admin gets past here
+	actor, err := configActor(vault, audit, userID, *as)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "get":
+		cfg, err := auth.LoadUserConfig(vault, userID)
+		if err != nil {
+			return err
+		}
+		if cfg == nil {
+			cfg = auth.DefaultCustomizedConfig()
+		}
+
+		keys := user_setting.ConfigKeys
+		if fs.NArg() > 1 {
+			keys = []user_setting.ConfigKey{user_setting.ConfigKey(fs.Arg(1))}
+		}
+		for _, key := range keys {
+			v, err := cfg.Get(key)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%-16s %s\n", key, v)
+		}
+		return nil
+
+	case "set":
+		if fs.NArg() != 3 {
+			return errors.New("usage: aios config set <user-id> <key> <value>")
+		}
+		key := user_setting.ConfigKey(fs.Arg(1))
+
+		cfg, err := auth.LoadUserConfig(vault, userID)
+		if err != nil {
+			return err
+		}
+		if cfg == nil {
+			cfg = auth.DefaultCustomizedConfig()
+		}
+		if err := cfg.Set(key, fs.Arg(2)); err != nil {
+			return err
+		}
+
+		rev, err := auth.CommitUserConfig(vault, userID, actor.Name, "set "+string(key), cfg)
+		if err != nil {
+			return err
+		}
+		recordConfig(audit, actor, "config.set", userID, rev)
+		fmt.Printf("%s saved as revision %d (%s)\n", key, rev.Number, rev.Hash[:12])
+		return nil
+
+	case "history":
+		revs, err := auth.ConfigHistory(vault, userID)
+		if err != nil {
+			return err
+		}
+		for _, r := range revs {
+			fmt.Printf("%4d  %s  %s  %-16s %s\n", r.Number, r.SavedAt.Local().Format(time.RFC3339), r.Hash[:12], r.SavedBy, r.Note)
+		}
+		return nil
+
+	case "diff":
+		// diff <user> [from] [to]: defaults compare the previous revision
+		// with the current one
+		from, to := -1, 0
+		if fs.NArg() > 1 {
+			if from, err = strconv.Atoi(fs.Arg(1)); err != nil {
+				return fmt.Errorf("invalid revision %q", fs.Arg(1))
+			}
+		}
+		if fs.NArg() > 2 {
+			if to, err = strconv.Atoi(fs.Arg(2)); err != nil {
+				return fmt.Errorf("invalid revision %q", fs.Arg(2))
+			}
+		}
+
+		a, err := auth.ConfigRevisionOf(vault, userID, from)
+		if err != nil {
+			return err
+		}
+		b, err := auth.ConfigRevisionOf(vault, userID, to)
+		if err != nil {
+			return err
+		}
+
+		changes := user_setting.DiffConfig(&a.Config, &b.Config)
+		fmt.Printf("revision %d -> %d\n", a.Number, b.Number)
+		if len(changes) == 0 {
+			fmt.Println("no differences")
+		}
+		for _, c := range changes {
+			fmt.Printf("  %-16s %s -> %s\n", c.Key, c.From, c.To)
+		}
+		return nil
+
+	case "rollback":
+		if fs.NArg() != 2 {
+			return errors.New("usage: aios config rollback <user-id> <revision>")
+		}
+		n, err := strconv.Atoi(fs.Arg(1))
+		if err != nil {
+			return fmt.Errorf("invalid revision %q", fs.Arg(1))
+		}
+
+		rev, err := auth.RollbackUserConfig(vault, userID, actor.Name, n)
+		if err != nil {
+			return err
+		}
+		recordConfig(audit, actor, "config.rollback", userID, rev)
+		fmt.Printf("restored as revision %d (%s)\n", rev.Number, rev.Hash[:12])
+		return nil
+
+	default:
+		return fmt.Errorf("unknown config subcommand: %s", args[0])
+	}
+}
+
+// configActor authenticates the caller of a config command on userID: an
+// admin named by --as, or otherwise the user itself with its own password.
+func configActor(vault verification_persistence.VaultStore, audit *security_audit.Log, userID, as string) (security_users.Actor, error) {
+	dir := security_users.NewDirectory(vault, audit)
+	in := bufio.NewReader(os.Stdin)
+
+	if as != "" {
+		actor, err := cliActor(dir, in, as)
+		if err != nil {
+			return actor, err
+		}
+		if !actor.Admin {
+			return actor, security_users.ErrNotAdmin
+		}
+		return actor, nil
+	}
+
+	password, err := promptPassword(in, "password for "+userID)
+	if err != nil {
+		return security_users.Actor{}, err
+	}
+	if err := dir.Reverify(userID, password, ""); err != nil {
+		return security_users.Actor{}, err
+	}
+	return security_users.Actor{Name: userID}, nil
+}
+
+func recordConfig(audit *security_audit.Log, actor security_users.Actor, action, userID string, rev *auth.ConfigRevision) {
+	ev := security_audit.Event{
+		Actor:      actor.Name,
+		Action:     action,
+		Permission: string(user_setting.PermConfigEdit),
+		Resource:   userID,
+		Result:     "ok",
+	}
+	ev.Detail, _ = json.Marshal(map[string]interface{}{
+		"revision": rev.Number,
+		"hash":     rev.Hash,
+		"note":     rev.Note,
+	})
+	_, _ = audit.Append(ev)
+}
@@ -0,0 +1,65 @@
+//cmd/aios/device_commands.go
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+func runDeviceCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand: show|csr")
+	}
+
+	vault, err := verification_persistence.OpenStore()
+	if err != nil {
+		return err
+	}
+
+	id, err := verification_identity.LoadDeviceIdentity(vault)
+	if err != nil {
+		return err
+	}
+	if id == nil {
+		return errors.New("device identity not provisioned; run a cold boot first")
+	}
+
+	switch args[0] {
+	case "show":
+		fmt.Printf("machine:     %s\n", id.MachineID)
+		fmt.Printf("uri:         %s\n", id.URI())
+		fmt.Printf("fingerprint: %s\n", id.Fingerprint)
+		fmt.Printf("public key:  %x\n", id.PublicKey)
+		fmt.Printf("created:     %s\n", id.CreatedAt.Format("2006-01-02 15:04:05"))
+		fmt.Print(string(id.CertificatePEM()))
+		return nil
+
+	case "csr":
+		fs := flag.NewFlagSet("device csr", flag.ContinueOnError)
+		org := fs.String("org", "", "organization for the certificate subject")
+		out := fs.String("out", "", "write the request to this file instead of stdout")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		csr, err := id.CreateCSR(*org)
+		if err != nil {
+			return err
+		}
+
+		if *out == "" {
+			fmt.Print(string(csr))
+			return nil
+		}
+		return os.WriteFile(*out, csr, 0644)
+
+	default:
+		return fmt.Errorf("unknown device subcommand: %s", args[0])
+	}
+}
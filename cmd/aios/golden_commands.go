@@ -0,0 +1,96 @@
+//cmd/aios/golden_commands.go
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	core_verification "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/verification"
+)
+
+func runGoldenCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand: install|status")
+	}
+
+	vault, err := verification_persistence.OpenStore()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "install":
+		fs := flag.NewFlagSet("golden install", flag.ContinueOnError)
+		in := fs.String("in", "golden-manifest.json", "signed manifest path")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(*in)
+		if err != nil {
+			return err
+		}
+
+		var m core_verification.GoldenManifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return fmt.Errorf("malformed manifest: %w", err)
+		}
+
+		pub, err := core_verification.LoadReleaseKey()
+		if err != nil {
+			return err
+		}
+
+		if err := core_verification.InstallManifest(vault, &m, pub); err != nil {
+			return err
+		}
+
+		fmt.Printf("installed golden manifest %d (%d builds)\n", m.Serial, len(m.Builds))
+		return nil
+
+	case "status":
+		m, err := core_verification.LoadManifest(vault)
+		if err != nil {
+			return err
+		}
+		counter, err := core_verification.LoadReleaseCounter(vault)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("running build: %s\n", core_verification.BuildVersion)
+		fmt.Printf("counter: manifest %d, build sequence %d\n", counter.ManifestSerial, counter.BuildSequence)
+		if m == nil {
+			fmt.Println("no golden manifest installed")
+			return nil
+		}
+
+		fmt.Printf("manifest %d issued %s\n", m.Serial, m.IssuedAt.Format("2006-01-02"))
+		for _, b := range m.Builds {
+			state := "allowed"
+			if b.Revoked {
+				state = "revoked: " + b.Reason
+			}
+			fmt.Printf("  %-12s seq %-4d %s  %s\n", b.Version, b.Sequence, b.Digest, state)
+		}
+
+		digest, err := core_verification.MeasureSelf()
+		if err != nil {
+			return err
+		}
+		if _, err := core_verification.ClassifyBuild(m, digest, core_verification.BuildVersion); err != nil {
+			fmt.Printf("this binary: %v\n", err)
+		} else {
+			fmt.Println("this binary: allowed")
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown golden subcommand: %s", args[0])
+	}
+}
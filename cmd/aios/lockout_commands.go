@@ -0,0 +1,96 @@
+//cmd/aios/lockout_commands.go
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	security_lockout "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/lockout"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+)
+
+func runLockoutCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand: status|unlock|policy")
+	}
+
+	vault, err := verification_persistence.OpenStore()
+	if err != nil {
+		return err
+	}
+
+	device, err := verification_identity.LoadDeviceIdentity(vault)
+	if err != nil {
+		return err
+	}
+	audit := security_audit.NewLog(vault, device)
+
+	switch args[0] {
+	case "status":
+		guard := security_lockout.NewGuard(vault, security_lockout.FallbackPolicy, nil)
+		counters, err := guard.Counters()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, c := range counters {
+			state := "backoff"
+			if c.Locked(now) {
+				state = "locked until " + c.LockedUntil.Format(time.RFC3339)
+			}
+			fmt.Printf("%-32s failures=%-4d last=%s %s\n", c.Key, c.Failures, c.LastFailure.Format(time.RFC3339), state)
+		}
+		return nil
+
+	case "unlock":
+		fs := flag.NewFlagSet("lockout unlock", flag.ContinueOnError)
+		admin := fs.String("admin", os.Getenv("USER"), "name recorded in the audit log")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New("usage: aios lockout unlock user:<id>|source:<addr>")
+		}
+
+		key := fs.Arg(0)
+		if !strings.HasPrefix(key, "user:") && !strings.HasPrefix(key, "source:") {
+			key = security_lockout.UserKey(key)
+		}
+
+		guard := security_lockout.NewGuard(vault, security_lockout.FallbackPolicy, audit)
+		if err := guard.Unlock(key, "cli:"+*admin); err != nil {
+			return err
+		}
+		fmt.Printf("unlocked %s\n", key)
+		return nil
+
+	case "policy":
+		platform := internal_environment.PlatformComputer
+		if len(args) > 1 {
+			platform = internal_environment.PlatformClass(args[1])
+		}
+
+		p, err := security_lockout.LoadPolicy(vault, platform)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("platform:         %s\n", platform)
+		fmt.Printf("threshold:        %d (per source %d)\n", p.Threshold, p.SourceThreshold)
+		fmt.Printf("back-off:         %s doubling to %s\n", p.BaseDelay, p.MaxDelay)
+		fmt.Printf("lockout:          %s\n", p.LockoutDuration)
+		fmt.Printf("window:           %s\n", p.Window)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown lockout subcommand: %s", args[0])
+	}
+}
@@ -0,0 +1,77 @@
+//cmd/aios/measurement_commands.go
+
+package main
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"flag"
+	"fmt"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_measurement "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/measurement"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+func runMeasurementCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand: show|verify")
+	}
+
+	fs := flag.NewFlagSet("measurement "+args[0], flag.ContinueOnError)
+	bootID := fs.String("boot", "", "boot ID (default: latest boot)")
+	expect := fs.String("expect", "", "expected final digest (hex)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	vault, err := verification_persistence.OpenStore()
+	if err != nil {
+		return err
+	}
+
+	log, err := verification_measurement.LoadEventLog(vault, *bootID)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "show":
+		fmt.Printf("boot %s started %s\n", log.BootID, log.StartedAt.Format("2006-01-02 15:04:05Z"))
+		for _, ev := range log.Events {
+			fmt.Printf("  %3d %-10s %s %s\n", ev.Index, ev.Type, ev.Digest[:16], ev.Name)
+		}
+		fmt.Printf("final sha256:%s\n", log.Final)
+		return nil
+
+	case "verify":
+		final, err := verification_measurement.Replay(log.Events)
+		if err != nil {
+			return fmt.Errorf("replay failed: %w", err)
+		}
+		if final != log.Final {
+			return fmt.Errorf("replayed digest %s does not match recorded %s", final, log.Final)
+		}
+		if *expect != "" && final != *expect {
+			return fmt.Errorf("replayed digest %s does not match expected %s", final, *expect)
+		}
+
+		// The signature ties the log to this unit's device key
+		device, err := verification_identity.LoadDeviceIdentity(vault)
+		if err != nil {
+			return err
+		}
+		if device == nil {
+			return errors.New("device identity not provisioned; cannot check the log signature")
+		}
+		if err := log.VerifySignature(ed25519.PublicKey(device.PublicKey)); err != nil {
+			return fmt.Errorf("boot %s: %w", log.BootID, err)
+		}
+
+		fmt.Printf("boot %s verified: %d events, sha256:%s, signed by %s\n", log.BootID, len(log.Events), final, device.MachineID)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown measurement subcommand: %s", args[0])
+	}
+}
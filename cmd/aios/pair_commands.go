@@ -0,0 +1,160 @@
+//cmd/aios/pair_commands.go
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	security_authenticator "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/authenticator"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	security_pairing "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/pairing"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	security_users "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/users"
+)
+
+func runPairCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand: start|list|remove")
+	}
+
+	vault, err := verification_persistence.OpenStore()
+	if err != nil {
+		return err
+	}
+
+	device, err := verification_identity.LoadDeviceIdentity(vault)
+	if err != nil {
+		return err
+	}
+	audit := security_audit.NewLog(vault, device)
+	dir := security_users.NewDirectory(vault, audit)
+
+	fs := flag.NewFlagSet("pair "+args[0], flag.ContinueOnError)
+	as := fs.String("as", os.Getenv("AIOS_ADMIN"), "admin account authorizing the change")
+	listen := fs.String("listen", ":7421", "address phones connect to")
+	endpoint := fs.String("endpoint", "", "address shown to the phone (defaults to --listen)")
+	name := fs.String("name", "", "expected phone name, shown when confirming")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	in := bufio.NewReader(os.Stdin)
+
+	switch args[0] {
+	case "start":
+		userID := fs.Arg(0)
+		if userID == "" {
+			return errors.New("usage: aios pair start <user-id> [--listen addr] [--endpoint host:port]")
+		}
+		actor, err := cliActor(dir, in, *as)
+		if err != nil {
+			return err
+		}
+		if !actor.Admin {
+			return errors.New("pair start requires --as <admin>")
+		}
+
+		machineID, err := security_authenticator.DeviceContext(vault)
+		if err != nil {
+			return err
+		}
+
+		confirm := func(user, phone, sas string) bool {
+			if *name != "" && phone != *name {
+				fmt.Printf("phone %q connected, expected %q\n", phone, *name)
+			}
+			fmt.Printf("Does phone %q show %s %s? [y/N] ", phone, sas[:3], sas[3:])
+			line, _ := in.ReadString('\n')
+			return strings.EqualFold(strings.TrimSpace(line), "y")
+		}
+
+		type outcome struct {
+			tokenID string
+			err     error
+		}
+		done := make(chan outcome, 1)
+
+		server := security_pairing.NewServer(vault, machineID, audit, confirm)
+		server.Completed = func(_, tokenID string, err error) {
+			select {
+			case done <- outcome{tokenID, err}:
+			default:
+			}
+		}
+		if err := server.Listen(*listen); err != nil {
+			return err
+		}
+		defer server.Close()
+
+		shown := *endpoint
+		if shown == "" {
+			shown = server.Addr().String()
+		}
+		offer, err := server.Offer(userID, shown)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Pairing code for %s: %s (valid until %s)\n", userID, offer.Code, offer.ExpiresAt.Format(time.Kitchen))
+		fmt.Printf("Pairing link: %s\n", offer.URI())
+
+		select {
+		case res := <-done:
+			if res.err != nil {
+				return res.err
+			}
+			fmt.Printf("paired %s to %s\n", res.tokenID, userID)
+			return nil
+		case <-time.After(time.Until(offer.ExpiresAt)):
+			return errors.New("pairing code expired")
+		}
+
+	case "list":
+		userID := fs.Arg(0)
+		if userID == "" {
+			return errors.New("usage: aios pair list <user-id>")
+		}
+		phones, err := security_pairing.Paired(vault, userID)
+		if err != nil {
+			return err
+		}
+		for _, p := range phones {
+			last := "never"
+			if !p.LastUsed.IsZero() {
+				last = p.LastUsed.Format(time.RFC3339)
+			}
+			fmt.Printf("%-24s %-20s paired=%s last=%s\n", p.TokenID, p.Label, p.EnrolledAt.Format(time.RFC3339), last)
+		}
+		return nil
+
+	case "remove":
+		if fs.NArg() != 2 {
+			return errors.New("usage: aios pair remove <user-id> <token-id>")
+		}
+		actor, err := cliActor(dir, in, *as)
+		if err != nil {
+			return err
+		}
+		if !actor.Admin {
+			return errors.New("pair remove requires --as <admin>")
+		}
+
+		userID, id := fs.Arg(0), fs.Arg(1)
+		if err := security_pairing.Unpair(vault, userID, id); err != nil {
+			return err
+		}
+		recordToken(audit, actor, "pairing.remove", userID, map[string]string{"token_id": id})
+		fmt.Printf("unpaired %s from %s\n", id, userID)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown pair subcommand: %s", args[0])
+	}
+}
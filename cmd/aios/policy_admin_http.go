@@ -0,0 +1,52 @@
+//cmd/aios/policy_admin_http.go
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/policy"
+	security_decision "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/decision"
+	security_users "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/users"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// registerPolicyAdmin mounts the policy API on api:
+//
+//	GET /api/admin/policy/explain?user=u&perm=p[&platform=&entity=&tier=&service=&trust=&session_age=&caps=a,b]
+//
+// explains the decision the running device would make, with the query
+// attributes tried in place of the user's and the device's own.
+func (a *App) registerPolicyAdmin(api *http.ServeMux) {
+
+	api.Handle("GET /api/admin/policy/explain", a.requireAdmin(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("user") == "" || q.Get("perm") == "" {
+			http.Error(w, "user and perm are required", http.StatusBadRequest)
+			return
+		}
+
+		exec := security_decision.ExecutionFromContext(r.Context())
+		actor := security_users.Actor{Name: exec.UserID(), Admin: exec.HasPermission(user_setting.PermAdmin)}
+
+		e, err := explainFor(a.decisions, a.users, actor, explainQuery{
+			User: q.Get("user"),
+			Perm: user_setting.PermissionKey(q.Get("perm")),
+			What: policy.FixtureRequest{
+				Platform:   q.Get("platform"),
+				Entity:     q.Get("entity"),
+				Tier:       q.Get("tier"),
+				Service:    q.Get("service"),
+				Trust:      q.Get("trust"),
+				SessionAge: q.Get("session_age"),
+				Caps:       splitList(q.Get("caps")),
+			},
+		})
+		if err != nil {
+			writeUserError(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(e)
+	}))
+}
@@ -0,0 +1,251 @@
+//cmd/aios/policy_commands.go
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/policy"
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	security_users "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/users"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+func runPolicyCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand: list|validate|sign|retire|test|explain")
+	}
+
+	vault, err := verification_persistence.OpenStore()
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("policy "+args[0], flag.ContinueOnError)
+	dir := fs.String("dir", policy.Dir(), "directory of installed policy files")
+	as := fs.String("as", os.Getenv("AIOS_ADMIN"), "admin approving (sign) or retiring (retire) a policy, or asking for an explanation (explain)")
+	drafts := fs.String("policy", "", "comma-separated policy files to test instead of --dir; signatures are not checked")
+	user := fs.String("user", "", "user to explain a decision for (explain)")
+	perm := fs.String("perm", "", "permission to explain (explain)")
+	var what policy.FixtureRequest
+	fs.StringVar(&what.Platform, "platform", "", "what-if platform (explain)")
+	fs.StringVar(&what.Entity, "entity", "", "what-if entity (explain)")
+	fs.StringVar(&what.Tier, "tier", "", "what-if tier (explain)")
+	fs.StringVar(&what.Service, "service", "", "what-if service (explain)")
+	fs.StringVar(&what.Trust, "trust", "", "what-if trust: a trust level, or attestation strong|weak (explain)")
+	fs.StringVar(&what.SessionAge, "session-age", "", "what-if session age, e.g. 2h (explain)")
+	caps := fs.String("caps", "", "comma-separated capabilities (explain)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		set, err := policy.LoadDir(vault, *dir)
+		if err != nil {
+			return err
+		}
+		for _, p := range set.Policies {
+			fmt.Printf("%-20s v%-4d %-8s %d rules\n", p.Name, p.Version, p.Signer, len(p.Rules))
+		}
+		return nil
+
+	case "validate":
+		if fs.NArg() != 1 {
+			return errors.New("usage: aios policy validate <file>")
+		}
+		p, err := policy.ReadPolicyFile(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		keys, err := policy.LoadTrustedKeys(vault)
+		if err != nil {
+			return err
+		}
+		if err := policy.VerifyPolicy(p, keys); err != nil {
+			return err
+		}
+		fmt.Printf("%s v%d: %d rules valid, signed by %s\n", p.Name, p.Version, len(p.Rules), p.Signer)
+		return nil
+
+	case "sign":
+		if fs.NArg() != 1 {
+			return errors.New("usage: aios policy sign <file> --as <admin>")
+		}
+
+		device, err := verification_identity.LoadDeviceIdentity(vault)
+		if err != nil {
+			return err
+		}
+		if device == nil {
+			return errors.New("device identity not provisioned")
+		}
+		audit := security_audit.NewLog(vault, device)
+
+		actor, err := cliActor(security_users.NewDirectory(vault, audit), bufio.NewReader(os.Stdin), *as)
+		if err != nil {
+			return err
+		}
+		if !actor.Admin {
+			return errors.New("policy sign requires --as <admin>")
+		}
+
+		p, err := policy.ReadPolicyFile(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		p.IssuedAt = time.Now().UTC()
+		policy.SignPolicy(p, policy.SignerDevice, device.Sign)
+		if err := policy.WritePolicyFile(fs.Arg(0), p); err != nil {
+			return err
+		}
+
+		_, _ = audit.Append(security_audit.Event{
+			Actor:      actor.Name,
+			Action:     "policy.sign",
+			Permission: string(user_setting.PermAdmin),
+			Resource:   p.Name,
+			Result:     "ok",
+		})
+		fmt.Printf("signed %s v%d (%s)\n", p.Name, p.Version, p.Hash()[:12])
+		return nil
+
+	case "retire":
+		// A policy deleted from --dir is refused at load until an admin
+		// retires it here
+		if fs.NArg() != 1 {
+			return errors.New("usage: aios policy retire <name> --as <admin>")
+		}
+		name := fs.Arg(0)
+
+		device, err := verification_identity.LoadDeviceIdentity(vault)
+		if err != nil {
+			return err
+		}
+		audit := security_audit.NewLog(vault, device)
+
+		actor, err := cliActor(security_users.NewDirectory(vault, audit), bufio.NewReader(os.Stdin), *as)
+		if err != nil {
+			return err
+		}
+		if !actor.Admin {
+			return errors.New("policy retire requires --as <admin>")
+		}
+
+		paths, err := filepath.Glob(filepath.Join(*dir, "*.json"))
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			if p, err := policy.ReadPolicyFile(path); err == nil && p.Name == name {
+				return fmt.Errorf("%s is still installed as %s; remove the file first", name, path)
+			}
+		}
+
+		if err := policy.RetirePolicy(vault, name); err != nil {
+			return err
+		}
+
+		_, _ = audit.Append(security_audit.Event{
+			Actor:      actor.Name,
+			Action:     "policy.retire",
+			Permission: string(user_setting.PermAdmin),
+			Resource:   name,
+			Result:     "ok",
+		})
+		fmt.Printf("retired %s\n", name)
+		return nil
+
+	case "test":
+		if fs.NArg() != 1 {
+			return errors.New("usage: aios policy test <fixtures.json> [--dir d | --policy a.json,b.json]")
+		}
+
+		var set *policy.Set
+		if *drafts != "" {
+			var policies []*policy.Policy
+			for _, path := range strings.Split(*drafts, ",") {
+				p, err := policy.ReadPolicyFile(strings.TrimSpace(path))
+				if err != nil {
+					return err
+				}
+				policies = append(policies, p)
+			}
+			set, err = policy.NewSet(policies...)
+		} else {
+			set, err = policy.LoadDir(vault, *dir)
+		}
+		if err != nil {
+			return err
+		}
+
+		fixtures, err := policy.ReadFixtures(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, r := range policy.RunFixtures(set, fixtures) {
+			if r.Passed {
+				fmt.Printf("PASS  %s\n", r.Name)
+				continue
+			}
+			failed++
+			fmt.Printf("FAIL  %s\n", r.Name)
+			for _, f := range r.Failures {
+				fmt.Printf("      %s\n", f)
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d fixtures failed", failed, len(fixtures))
+		}
+		fmt.Printf("%d fixtures passed\n", len(fixtures))
+		return nil
+
+	case "explain":
+		if *user == "" || *perm == "" {
+			return errors.New("usage: aios policy explain --user <id> --perm <permission> [--platform p --trust t --caps a,b ...]")
+		}
+
+		device, err := verification_identity.LoadDeviceIdentity(vault)
+		if err != nil {
+			return err
+		}
+		users := security_users.NewDirectory(vault, security_audit.NewLog(vault, device))
+
+		actor, err := cliActor(users, bufio.NewReader(os.Stdin), *as)
+		if err != nil {
+			return err
+		}
+
+		point, err := localDecisionPoint(vault, *dir)
+		if err != nil {
+			return err
+		}
+
+		what.Caps = splitList(*caps)
+		e, err := explainFor(point, users, actor, explainQuery{
+			User: *user,
+			Perm: user_setting.PermissionKey(*perm),
+			What: what,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Print(e.Text())
+		return nil
+
+	default:
+		return fmt.Errorf("unknown policy subcommand: %s", args[0])
+	}
+}
@@ -0,0 +1,111 @@
+//cmd/aios/policy_explain.go
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/policy"
+	security_decision "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/decision"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	security_users "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/users"
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/keys"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// explainQuery is what `aios policy explain` and the admin endpoint take:
+// a user and a permission, plus attributes to try instead of the user's
+// and the device's own. Empty fields of What keep the real value.
+type explainQuery struct {
+	User string
+	Perm user_setting.PermissionKey
+	What policy.FixtureRequest
+}
+
+// explainFor explains how point decides q.Perm for q.User.
+func explainFor(point *security_decision.DecisionPoint, users *security_users.Directory, actor security_users.Actor, q explainQuery) (policy.Explanation, error) {
+	if !policy.KnownPermission(q.Perm) {
+		return policy.Explanation{}, fmt.Errorf("unknown permission %q", q.Perm)
+	}
+
+	acct, err := users.Show(actor, q.User)
+	if err != nil {
+		return policy.Explanation{}, err
+	}
+	entity, err := security_users.ParseEntity(acct.Entity)
+	if err != nil {
+		return policy.Explanation{}, err
+	}
+
+	req := point.Attributes(security_decision.Subject{
+		UserID: acct.UserID,
+		Entity: entity,
+		Tier:   acct.Tier,
+		Admin:  acct.Admin,
+	}).Request()
+
+	what := q.What
+	for _, f := range []struct{ dst, src *string }{
+		{&req.Platform, &what.Platform},
+		{&req.Entity, &what.Entity},
+		{&req.Tier, &what.Tier},
+		{&req.Service, &what.Service},
+		{&req.Trust, &what.Trust},
+		{&req.SessionAge, &what.SessionAge},
+	} {
+		if *f.src != "" {
+			*f.dst = *f.src
+		}
+	}
+	if len(what.Caps) > 0 {
+		req.Caps = what.Caps
+	}
+
+	attrs, err := req.Attributes()
+	if err != nil {
+		return policy.Explanation{}, err
+	}
+	return point.Policies().Explain(attrs, q.Perm), nil
+}
+
+// localDecisionPoint decides like the last boot of this device: installed
+// policies and the last known environment. Capabilities are not stored
+// with the environment, so they only come from --caps.
+func localDecisionPoint(vault verification_persistence.VaultStore, dir string) (*security_decision.DecisionPoint, error) {
+	set, err := policy.LoadDir(vault, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	env := security_decision.Environment{Trust: user_setting.TrustUser}
+
+	device, err := verification_identity.LoadDeviceIdentity(vault)
+	if err != nil {
+		return nil, err
+	}
+	if device != nil {
+		cfg, err := vault.LoadConfig(keys.LastKnownEnvKey(device.MachineID))
+		if err == nil && cfg != nil {
+			if attested, err := security_decision.AttestedEnvironment(cfg, cfg.Attestation.Valid, 0); err == nil {
+				env = attested
+			} else {
+				env.Platform = cfg.Platform.Final
+			}
+		}
+	}
+
+	return security_decision.NewDecisionPoint(set, env), nil
+}
+
+// splitList splits a comma-separated flag or query value.
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -4,9 +4,19 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"time"
 
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/auth"
+	security_decision "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/decision"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	security_sandbox "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/sandbox"
+	security_scratch "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/scratch"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+	runtime_supervisor "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/supervisor"
 	"go.uber.org/zap"
 )
 
@@ -19,7 +29,17 @@ func (a *App) startHTTP() {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
-		status := a.supervisor.HealthStatus()
+		status := struct {
+			runtime_supervisor.HealthStatus
+			Mode    string                   `json:"mode"`
+			Sandbox *security_sandbox.Status `json:"sandbox,omitempty"`
+		}{HealthStatus: a.supervisor.HealthStatus(), Mode: "normal"}
+
+		if sb := security_sandbox.Active(); sb != nil {
+			st := sb.Status()
+			status.Mode = "sandbox"
+			status.Sandbox = &st
+		}
 
 		code := http.StatusOK
 		if !status.Healthy {
@@ -30,6 +50,90 @@ func (a *App) startHTTP() {
 		_ = json.NewEncoder(w).Encode(status)
 	})
 
+	// Everything under /api/ requires a valid session token
+	api := http.NewServeMux()
+
+	api.HandleFunc("/api/session", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(verification_identity.SessionFromContext(r.Context()))
+	})
+
+	api.HandleFunc("GET /api/session/context", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(security_decision.ExecutionFromContext(r.Context()))
+	})
+
+	api.HandleFunc("/api/session/refresh", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token, claims, err := a.tokens.Refresh(verification_identity.BearerToken(r))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"token":      token,
+			"expires_at": claims.ExpiresAt,
+		})
+	})
+
+	api.HandleFunc("/api/session/logout", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		claims := verification_identity.SessionFromContext(r.Context())
+		if err := a.tokens.RevokeSession(claims.SessionID, "logout"); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		a.activity.End(claims.SessionID)
+		if claims.Sandbox {
+			security_sandbox.ExitSession(claims.SessionID)
+		}
+		if claims.Guest {
+			if err := security_scratch.Wipe(claims.SessionID); err != nil {
+				a.log.Error("GUEST_SCRATCH_WIPE", zap.Error(err))
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	a.registerUserAdmin(api)
+	a.registerPolicyAdmin(api)
+
+	api.HandleFunc("POST /api/commands", a.handleCommand)
+
+	// Every request is decided again, so policy changes reach live sessions;
+	// requests of a sandbox session cannot reach the outside network
+	mux.Handle("/api/", verification_identity.RequireSession(a.tokens,
+		verification_identity.TrackActivity(a.activity,
+			security_sandbox.Scope(
+				security_decision.Authorize(a.decisions, api)))))
+
+	// Login is the only way to obtain a session token over the API
+	mux.HandleFunc("POST /login", a.handleLogin)
+
+	// Account recovery authenticates with the reset token alone
+	mux.HandleFunc("POST /recovery/reset", a.handleRecoveryReset)
+
+	// An emergency halt is accepted from any account session, locked or
+	// not, whatever it is permitted; guest sessions are refused
+	mux.Handle("POST /api/commands/halt", verification_identity.RequireSession(a.tokens,
+		http.HandlerFunc(a.handleHalt)))
+
+	// A driver change replaces the unit's active session; like step-up it
+	// must work from a locked session
+	mux.Handle("POST /api/session/switch", verification_identity.RequireSession(a.tokens,
+		http.HandlerFunc(a.handleSwitch)))
+
+	// Re-authentication must stay reachable while the session is locked
+	mux.Handle("/api/session/stepup", verification_identity.RequireSession(a.tokens,
+		http.HandlerFunc(a.handleStepUp)))
+
 	a.server = &http.Server{
 		Addr:              ":8080",
 		Handler:           mux,
@@ -45,3 +149,151 @@ func (a *App) startHTTP() {
 		}
 	}()
 }
+
+// handleLogin is POST /login with an auth.HTTPLoginRequest. It runs the
+// unit's login flow (lockout, authenticators, second factor) for the
+// request alone and answers with the session token.
+func (a *App) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req auth.HTTPLoginRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 16<<10)).Decode(&req); err != nil {
+		http.Error(w, "malformed login request", http.StatusBadRequest)
+		return
+	}
+
+	source, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		source = r.RemoteAddr
+	}
+
+	session, notices, err := a.auth.RemoteLogin(r.Context(), req, "http:"+source)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":    err.Error(),
+			"messages": notices,
+		})
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":      session.Token,
+		"session_id": session.Claims.SessionID,
+		"expires_at": session.Claims.ExpiresAt,
+		"guest":      session.IsGuest(),
+		"sandbox":    session.IsSandbox(),
+		"messages":   notices,
+	})
+}
+
+// handleSwitch is POST /api/session/switch with the auth.HTTPLoginRequest
+// of the next user. Only the unit's active session, or an administrator
+// who has stepped up, may ask; guest sessions never may. The previous
+// session is ended and its tokens revoked once the new login succeeds.
+func (a *App) handleSwitch(w http.ResponseWriter, r *http.Request) {
+	if status, err := a.authorizeSwitch(verification_identity.SessionFromContext(r.Context())); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	var req auth.HTTPLoginRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 16<<10)).Decode(&req); err != nil {
+		http.Error(w, "malformed login request", http.StatusBadRequest)
+		return
+	}
+
+	source, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		source = r.RemoteAddr
+	}
+
+	session, notices, err := a.switcher.SwitchRemote(r.Context(), req, "http:"+source)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":    err.Error(),
+			"messages": notices,
+		})
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":      session.Token,
+		"session_id": session.Claims.SessionID,
+		"expires_at": session.Claims.ExpiresAt,
+		"guest":      session.IsGuest(),
+		"sandbox":    session.IsSandbox(),
+		"messages":   notices,
+	})
+}
+
+var (
+	errSwitchByGuest    = errors.New("guest sessions cannot switch the active user")
+	errSwitchNotAllowed = errors.New("only the active session or an administrator can switch the active user")
+)
+
+// authorizeSwitch decides whether claims may replace the active session
+// and, if not, with which status to answer.
+func (a *App) authorizeSwitch(claims *user_setting.SessionClaims) (int, error) {
+	if claims == nil {
+		return http.StatusUnauthorized, errSwitchNotAllowed
+	}
+	if claims.Guest {
+		return http.StatusForbidden, errSwitchByGuest
+	}
+
+	if active := a.switcher.Active(); active != nil && active.Claims.SessionID == claims.SessionID {
+		return 0, nil
+	}
+
+	if !a.decisions.DecideSession(claims).HasPermission(user_setting.PermAdmin) {
+		return http.StatusForbidden, errSwitchNotAllowed
+	}
+	if err := a.activity.RequireFresh(claims, user_setting.PermAdmin); err != nil {
+		return http.StatusUnauthorized, err
+	}
+	return 0, nil
+}
+
+// stepUpRequest is the body of POST /api/session/stepup: the password, or
+// a second-factor code for enrolled users.
+type stepUpRequest struct {
+	Password string `json:"password,omitempty"`
+	OTP      string `json:"otp,omitempty"`
+}
+
+func (a *App) handleStepUp(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims := verification_identity.SessionFromContext(r.Context())
+	if claims.Guest {
+		http.Error(w, "guest sessions cannot re-authenticate", http.StatusForbidden)
+		return
+	}
+
+	var req stepUpRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 4<<10)).Decode(&req); err != nil {
+		http.Error(w, "malformed request", http.StatusBadRequest)
+		return
+	}
+
+	source, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		source = r.RemoteAddr
+	}
+
+	// Same lockout as POST /login; marks the session fresh on success
+	if err := a.auth.Reverify(claims, req.Password, req.OTP, "http:"+source); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"verified_at": time.Now().UTC(),
+		"valid_for":   verification_identity.StepUpWindow.String(),
+	})
+}
@@ -0,0 +1,148 @@
+//cmd/aios/secret_commands.go
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	security_secrets "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/secrets"
+	security_users "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/users"
+)
+
+func runSecretCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand: put|grant|list|delete")
+	}
+
+	vault, err := verification_persistence.OpenStore()
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("secret "+args[0], flag.ContinueOnError)
+	as := fs.String("as", os.Getenv("AIOS_ADMIN"), "admin account authorizing the command")
+	modules := fs.String("modules", "", "comma-separated module names allowed to read the secret")
+	from := fs.String("from", "-", "file holding the value (- reads stdin)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	// Secrets are device credentials: every subcommand, listing included,
+	// needs an admin, and every change lands in the audit chain.
+	device, err := verification_identity.LoadDeviceIdentity(vault)
+	if err != nil {
+		return err
+	}
+	auditLog := security_audit.NewLog(vault, device)
+
+	in := bufio.NewReader(os.Stdin)
+	actor, err := cliActor(security_users.NewDirectory(vault, auditLog), in, *as)
+	if err != nil {
+		return err
+	}
+	if !actor.Admin {
+		return fmt.Errorf("secret %s requires --as <admin>", args[0])
+	}
+
+	store := security_secrets.NewStore(vault, nil)
+	record := func(action, name string) {
+		_, _ = auditLog.Append(security_audit.Event{Actor: actor.Name, Action: action, Resource: name, Result: "ok"})
+	}
+
+	switch args[0] {
+	case "put":
+		if fs.NArg() != 1 {
+			return errors.New("usage: aios secret put <name> --modules a,b [--from file]")
+		}
+
+		value, err := readSecretValue(*from, in)
+		if err != nil {
+			return err
+		}
+
+		if err := store.Put(fs.Arg(0), value, splitModules(*modules)); err != nil {
+			return err
+		}
+		record("secret.put", fs.Arg(0))
+		fmt.Printf("stored secret %s\n", fs.Arg(0))
+		return nil
+
+	case "grant":
+		if fs.NArg() != 1 {
+			return errors.New("usage: aios secret grant <name> --modules a,b")
+		}
+		if err := store.Grant(fs.Arg(0), splitModules(*modules)); err != nil {
+			return err
+		}
+		record("secret.grant", fs.Arg(0))
+		return nil
+
+	case "list":
+		infos, err := store.List()
+		if err != nil {
+			return err
+		}
+		for _, s := range infos {
+			fmt.Printf("%-24s %-40s %s\n", s.Name, strings.Join(s.AllowedModules, ","),
+				s.UpdatedAt.Format("2006-01-02 15:04:05"))
+		}
+		return nil
+
+	case "delete":
+		if fs.NArg() != 1 {
+			return errors.New("usage: aios secret delete <name>")
+		}
+		if err := store.Delete(fs.Arg(0)); err != nil {
+			return err
+		}
+		record("secret.delete", fs.Arg(0))
+		return nil
+
+	default:
+		return fmt.Errorf("unknown secret subcommand: %s", args[0])
+	}
+}
+
+// readSecretValue reads the value from a file or stdin so it never has to
+// appear on the command line or in shell history. stdin is what is left
+// of in after the admin password prompt.
+func readSecretValue(path string, stdin io.Reader) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimRight(data, "\r\n")
+	if len(data) == 0 {
+		return nil, errors.New("empty secret value")
+	}
+	return data, nil
+}
+
+func splitModules(s string) []string {
+	var out []string
+	for _, m := range strings.Split(s, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			out = append(out, m)
+		}
+	}
+	return out
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/bootstrap"
+	security_decision "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/decision"
 	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
 	runtime_types "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/types"
 )
@@ -18,6 +19,7 @@ import (
 type SystemContext struct {
 	Boot      *bootstrap.BootContext
 	Execution runtime_types.ExecutionContext
+	Decisions *security_decision.DecisionPoint
 	Session   *user_setting.UserSession
 }
 
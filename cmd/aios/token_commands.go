@@ -0,0 +1,212 @@
+//cmd/aios/token_commands.go
+
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	security_authenticator "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/authenticator"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	security_users "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/users"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+func runTokenCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand: enroll|list|revoke|devices|add-device|simulate")
+	}
+
+	vault, err := verification_persistence.OpenStore()
+	if err != nil {
+		return err
+	}
+
+	device, err := verification_identity.LoadDeviceIdentity(vault)
+	if err != nil {
+		return err
+	}
+	audit := security_audit.NewLog(vault, device)
+	dir := security_users.NewDirectory(vault, audit)
+
+	fs := flag.NewFlagSet("token "+args[0], flag.ContinueOnError)
+	as := fs.String("as", os.Getenv("AIOS_ADMIN"), "admin account authorizing the change")
+	kind := fs.String("kind", "", "token kind: key_fob|nfc_card|pairing_button|biometric|companion_app|service_key")
+	tokenID := fs.String("id", "", "token serial or identifier")
+	label := fs.String("label", "", "free-form description")
+	pubkey := fs.String("pubkey", "", "hex ed25519 public key; omit for an HMAC token")
+	transport := fs.String("transport", "", "device transport for add-device")
+	address := fs.String("address", "", "device address for add-device")
+	listen := fs.String("listen", "127.0.0.1:7420", "address the simulator listens on")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	// Only the read-only subcommands run without an admin
+	var actor security_users.Actor
+	if args[0] != "list" && args[0] != "devices" {
+		if actor, err = cliActor(dir, bufio.NewReader(os.Stdin), *as); err != nil {
+			return err
+		}
+		if !actor.Admin {
+			return fmt.Errorf("token %s requires --as <admin>", args[0])
+		}
+	}
+
+	switch args[0] {
+	case "enroll":
+		userID := fs.Arg(0)
+		if userID == "" || *kind == "" || *tokenID == "" {
+			return errors.New("usage: aios token enroll --kind k --id token-id [--pubkey hex] [--label text] <user-id>")
+		}
+		if err := requireUser(vault, userID); err != nil {
+			return err
+		}
+
+		k := security_authenticator.Kind(*kind)
+		if *pubkey != "" {
+			pub, err := hex.DecodeString(*pubkey)
+			if err != nil {
+				return fmt.Errorf("invalid public key: %w", err)
+			}
+			if err := security_authenticator.EnrollEd25519(vault, userID, k, *tokenID, *label, pub); err != nil {
+				return err
+			}
+		} else {
+			secret, err := security_authenticator.EnrollHMAC(vault, userID, k, *tokenID, *label)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("HMAC secret (write it to the token now, it is not shown again):\n  %s\n", hex.EncodeToString(secret))
+		}
+
+		recordToken(audit, actor, "token.enroll", userID, map[string]string{"token_id": *tokenID, "kind": *kind})
+		fmt.Printf("enrolled %s %s for %s\n", k, *tokenID, userID)
+		return nil
+
+	case "list":
+		userID := fs.Arg(0)
+		if userID == "" {
+			return errors.New("usage: aios token list <user-id>")
+		}
+		tokens, err := security_authenticator.Tokens(vault, userID)
+		if err != nil {
+			return err
+		}
+		for _, t := range tokens {
+			last := "never"
+			if !t.LastUsed.IsZero() {
+				last = t.LastUsed.Format(time.RFC3339)
+			}
+			fmt.Printf("%-24s %-15s %-12s enrolled=%s last=%s %s\n", t.TokenID, t.Kind, t.Algorithm, t.EnrolledAt.Format(time.RFC3339), last, t.Label)
+		}
+		return nil
+
+	case "revoke":
+		if fs.NArg() != 2 {
+			return errors.New("usage: aios token revoke <user-id> <token-id>")
+		}
+		userID, id := fs.Arg(0), fs.Arg(1)
+		if err := security_authenticator.Revoke(vault, userID, id); err != nil {
+			return err
+		}
+		recordToken(audit, actor, "token.revoke", userID, map[string]string{"token_id": id})
+		fmt.Printf("revoked %s for %s\n", id, userID)
+		return nil
+
+	case "devices":
+		cfg, err := security_authenticator.LoadConfig(vault)
+		if err != nil {
+			return err
+		}
+		for _, d := range cfg.Devices {
+			fmt.Printf("%-15s %-10s %s\n", d.Kind, d.Transport, d.Address)
+		}
+		fmt.Printf("transports: %s\n", strings.Join(security_authenticator.Transports(), ", "))
+		return nil
+
+	case "add-device":
+		if *kind == "" || *transport == "" || *address == "" {
+			return errors.New("usage: aios token add-device --kind k --transport tcp|serial|hid --address addr")
+		}
+		cfg, err := security_authenticator.LoadConfig(vault)
+		if err != nil {
+			return err
+		}
+		dev := security_authenticator.DeviceConfig{
+			Kind:      security_authenticator.Kind(*kind),
+			Transport: *transport,
+			Address:   *address,
+		}
+		cfg.Devices = append(cfg.Devices, dev)
+		if err := security_authenticator.SaveConfig(vault, cfg); err != nil {
+			return err
+		}
+		recordToken(audit, actor, "token.device_add", "", dev)
+		fmt.Printf("added %s reader via %s at %s\n", dev.Kind, dev.Transport, dev.Address)
+		return nil
+
+	case "simulate":
+		// Enrolls a software token for a user and serves it on TCP, so a
+		// bench unit can log in without the real reader.
+		userID := fs.Arg(0)
+		if userID == "" || *kind == "" {
+			return errors.New("usage: aios token simulate --kind k [--id token-id] [--listen addr] <user-id>")
+		}
+		if err := requireUser(vault, userID); err != nil {
+			return err
+		}
+
+		id := *tokenID
+		if id == "" {
+			id = "sim-" + *kind
+		}
+		sim, err := security_authenticator.NewEd25519Simulator(security_authenticator.Kind(*kind), id)
+		if err != nil {
+			return err
+		}
+		if err := security_authenticator.EnrollEd25519(vault, userID, sim.Kind(), id, "simulator", sim.PublicKey()); err != nil {
+			return err
+		}
+		recordToken(audit, actor, "token.enroll", userID, map[string]string{"token_id": id, "kind": *kind, "label": "simulator"})
+
+		fmt.Printf("simulated %s %s for %s on %s (add it with: aios token add-device --kind %s --transport tcp --address %s)\n",
+			*kind, id, userID, *listen, *kind, *listen)
+		return sim.ListenAndServe(*listen)
+
+	default:
+		return fmt.Errorf("unknown token subcommand: %s", args[0])
+	}
+}
+
+func requireUser(vault verification_persistence.VaultStore, userID string) error {
+	exists, err := vault.Exists("users", userID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("unknown user %s", userID)
+	}
+	return nil
+}
+
+func recordToken(audit *security_audit.Log, actor security_users.Actor, action, userID string, detail interface{}) {
+	ev := security_audit.Event{
+		Actor:      actor.Name,
+		Action:     action,
+		Permission: string(user_setting.PermAdmin),
+		Resource:   userID,
+		Result:     "ok",
+	}
+	ev.Detail, _ = json.Marshal(detail)
+	_, _ = audit.Append(ev)
+}
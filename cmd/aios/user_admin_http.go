@@ -0,0 +1,213 @@
+//cmd/aios/user_admin_http.go
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	security_decision "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/decision"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	security_sandbox "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/sandbox"
+	security_users "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/users"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// userChange is the body of PATCH /api/admin/users/{id}. Only the fields
+// present are applied.
+type userChange struct {
+	Disabled *bool                  `json:"disabled,omitempty"`
+	Password *string                `json:"password,omitempty"`
+	Entity   *string                `json:"entity,omitempty"`
+	Tier     *user_setting.TierType `json:"tier,omitempty"`
+}
+
+// registerUserAdmin mounts the user administration API on api. Every call
+// needs PermAdmin in the request's decided context and a fresh step-up;
+// the directory checks the permission again and audits the change.
+func (a *App) registerUserAdmin(api *http.ServeMux) {
+
+	actor := func(r *http.Request) security_users.Actor {
+		exec := security_decision.ExecutionFromContext(r.Context())
+		return security_users.Actor{Name: exec.UserID(), Admin: exec.HasPermission(user_setting.PermAdmin)}
+	}
+
+	api.Handle("GET /api/admin/users", a.requireAdmin(func(w http.ResponseWriter, r *http.Request) {
+		accounts, err := a.usersFor(r).List(actor(r))
+		if err != nil {
+			writeUserError(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(accounts)
+	}))
+
+	api.Handle("POST /api/admin/users", a.requireAdmin(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			security_users.NewAccount
+			Entity string `json:"entity"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 16<<10)).Decode(&req); err != nil {
+			http.Error(w, "malformed request", http.StatusBadRequest)
+			return
+		}
+
+		entity, err := security_users.ParseEntity(req.Entity)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.NewAccount.Entity = entity
+
+		acct, err := a.usersFor(r).Add(actor(r), req.NewAccount)
+		if err != nil {
+			writeUserError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(acct)
+	}))
+
+	api.Handle("GET /api/admin/users/{id}", a.requireAdmin(func(w http.ResponseWriter, r *http.Request) {
+		acct, err := a.usersFor(r).Show(actor(r), r.PathValue("id"))
+		if err != nil {
+			writeUserError(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(acct)
+	}))
+
+	api.Handle("PATCH /api/admin/users/{id}", a.requireAdmin(func(w http.ResponseWriter, r *http.Request) {
+		var change userChange
+		if err := json.NewDecoder(io.LimitReader(r.Body, 16<<10)).Decode(&change); err != nil {
+			http.Error(w, "malformed request", http.StatusBadRequest)
+			return
+		}
+
+		if err := applyUserChange(a.usersFor(r), actor(r), r.PathValue("id"), change); err != nil {
+			writeUserError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	api.Handle("DELETE /api/admin/users/{id}", a.requireAdmin(func(w http.ResponseWriter, r *http.Request) {
+		if err := a.usersFor(r).Delete(actor(r), r.PathValue("id")); err != nil {
+			writeUserError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	api.Handle("POST /api/admin/users/{id}/reset", a.requireAdmin(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			TTL string `json:"ttl"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(io.LimitReader(r.Body, 16<<10)).Decode(&req); err != nil {
+				http.Error(w, "malformed request", http.StatusBadRequest)
+				return
+			}
+		}
+
+		var ttl time.Duration
+		if req.TTL != "" {
+			var err error
+			if ttl, err = time.ParseDuration(req.TTL); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		reset, err := a.usersFor(r).IssueReset(actor(r), r.PathValue("id"), ttl)
+		if err != nil {
+			writeUserError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(reset)
+	}))
+}
+
+// handleRecoveryReset serves POST /recovery/reset. It sits outside /api/
+// because whoever holds a reset token has no session; the token is the
+// credential.
+func (a *App) handleRecoveryReset(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 16<<10)).Decode(&req); err != nil {
+		http.Error(w, "malformed request", http.StatusBadRequest)
+		return
+	}
+
+	outcome, err := a.users.RedeemReset(req.Token, req.Password)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(outcome)
+}
+
+func (a *App) requireAdmin(h http.HandlerFunc) http.Handler {
+	return security_decision.RequirePermission(a.decisions, user_setting.PermAdmin,
+		verification_identity.RequireStepUp(a.activity, user_setting.PermAdmin, h))
+}
+
+func applyUserChange(users *security_users.Directory, actor security_users.Actor, userID string, change userChange) error {
+	if change.Password != nil {
+		if err := users.SetPassword(actor, userID, *change.Password); err != nil {
+			return err
+		}
+	}
+	if change.Entity != nil {
+		entity, err := security_users.ParseEntity(*change.Entity)
+		if err != nil {
+			return err
+		}
+		if err := users.SetEntity(actor, userID, entity); err != nil {
+			return err
+		}
+	}
+	if change.Tier != nil {
+		if err := users.SetTier(actor, userID, *change.Tier); err != nil {
+			return err
+		}
+	}
+	if change.Disabled != nil {
+		if err := users.SetDisabled(actor, userID, *change.Disabled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// usersFor returns the directory the session of r administers: the real
+// one, or for a sandbox session one over its shadow vault, so its changes
+// are discarded at logout.
+func (a *App) usersFor(r *http.Request) *security_users.Directory {
+	claims := verification_identity.SessionFromContext(r.Context())
+	if claims == nil || !claims.Sandbox || security_sandbox.Active() != nil {
+		return a.users
+	}
+	return security_users.NewDirectory(security_sandbox.VaultFor(claims, a.vault), a.audit)
+}
+
+func writeUserError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, security_users.ErrNotAdmin):
+		status = http.StatusForbidden
+	case errors.Is(err, security_users.ErrUserNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, security_users.ErrUserExists), errors.Is(err, security_users.ErrLastAdmin):
+		status = http.StatusConflict
+	case errors.Is(err, security_users.ErrResetMalformed), errors.Is(err, security_users.ErrResetSignature),
+		errors.Is(err, security_users.ErrResetExpired), errors.Is(err, security_users.ErrResetUsed):
+		status = http.StatusUnauthorized
+	}
+	http.Error(w, err.Error(), status)
+}
@@ -0,0 +1,222 @@
+//cmd/aios/user_commands.go
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	security_users "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/users"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+	"golang.org/x/term"
+)
+
+func runUserCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand: add|list|show|disable|enable|passwd|set-entity|set-tier|delete|reset-issue|reset-cancel|reset")
+	}
+
+	vault, err := verification_persistence.OpenStore()
+	if err != nil {
+		return err
+	}
+
+	device, err := verification_identity.LoadDeviceIdentity(vault)
+	if err != nil {
+		return err
+	}
+	dir := security_users.NewDirectory(vault, security_audit.NewLog(vault, device))
+
+	fs := flag.NewFlagSet("user "+args[0], flag.ContinueOnError)
+	as := fs.String("as", os.Getenv("AIOS_ADMIN"), "admin account authorizing the change")
+	entity := fs.String("entity", "personal", "entity for add: personal|organization|tester|stranger")
+	tier := fs.String("tier", "", "tier for add (defaults from entity)")
+	admin := fs.Bool("admin", false, "make the new account an admin")
+	ttl := fs.Duration("ttl", security_users.DefaultResetLifetime, "lifetime of a reset token")
+	out := fs.String("out", "", "also write the reset token to this file")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	in := bufio.NewReader(os.Stdin)
+
+	actor, err := cliActor(dir, in, *as)
+	if err != nil {
+		return err
+	}
+
+	// Every subcommand except list names one user
+	userID := fs.Arg(0)
+	if args[0] != "list" && userID == "" {
+		return fmt.Errorf("usage: aios user %s <user-id>", args[0])
+	}
+
+	switch args[0] {
+	case "add":
+		kind, err := security_users.ParseEntity(*entity)
+		if err != nil {
+			return err
+		}
+		password, err := promptPassword(in, "password for "+userID)
+		if err != nil {
+			return err
+		}
+
+		acct, err := dir.Add(actor, security_users.NewAccount{
+			UserID:   userID,
+			Password: password,
+			Entity:   kind,
+			Tier:     user_setting.TierType(*tier),
+			Admin:    *admin,
+		})
+		if err != nil {
+			return err
+		}
+		printAccount(*acct)
+		return nil
+
+	case "list":
+		accounts, err := dir.List(actor)
+		if err != nil {
+			return err
+		}
+		for _, acct := range accounts {
+			printAccount(acct)
+		}
+		return nil
+
+	case "show":
+		acct, err := dir.Show(actor, userID)
+		if err != nil {
+			return err
+		}
+		printAccount(*acct)
+		return nil
+
+	case "disable", "enable":
+		return dir.SetDisabled(actor, userID, args[0] == "disable")
+
+	case "passwd":
+		password, err := promptPassword(in, "new password for "+userID)
+		if err != nil {
+			return err
+		}
+		return dir.SetPassword(actor, userID, password)
+
+	case "set-entity":
+		kind, err := security_users.ParseEntity(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		return dir.SetEntity(actor, userID, kind)
+
+	case "set-tier":
+		return dir.SetTier(actor, userID, user_setting.TierType(fs.Arg(1)))
+
+	case "delete":
+		return dir.Delete(actor, userID)
+
+	case "reset-issue":
+		reset, err := dir.IssueReset(actor, userID, *ttl)
+		if err != nil {
+			return err
+		}
+		if *out != "" {
+			if err := os.WriteFile(*out, []byte(reset.Token+"\n"), 0o600); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("reset token for %s, valid until %s, single use:\n\n%s\n\n", userID, reset.ExpiresAt.Format(time.RFC3339), reset.Token)
+		fmt.Printf("link: %s\n", reset.URI)
+		fmt.Printf("redeem with: aios user reset <token> (or POST /recovery/reset)\n")
+		return nil
+
+	case "reset-cancel":
+		return dir.CancelReset(actor, userID)
+
+	case "reset":
+		// The argument is the token itself, or @file as written by --out
+		token := userID
+		if strings.HasPrefix(token, "@") {
+			data, err := os.ReadFile(token[1:])
+			if err != nil {
+				return err
+			}
+			token = string(data)
+		}
+
+		password, err := promptPassword(in, "new password")
+		if err != nil {
+			return err
+		}
+		outcome, err := dir.RedeemReset(token, password)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("password of %s reset; sessions revoked, %d companion pairing(s) removed\n", outcome.UserID, outcome.PairingsRemoved)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown user subcommand: %s", args[0])
+	}
+}
+
+// cliActor authenticates the admin named by --as. With no admin given the
+// command runs unauthenticated, which the directory only accepts for
+// creating the first admin.
+func cliActor(dir *security_users.Directory, in *bufio.Reader, as string) (security_users.Actor, error) {
+	if as == "" {
+		return security_users.Actor{Name: "cli"}, nil
+	}
+
+	password := os.Getenv("AIOS_ADMIN_PASSWORD")
+	if password == "" {
+		var err error
+		if password, err = promptPassword(in, "password for admin "+as); err != nil {
+			return security_users.Actor{}, err
+		}
+	}
+
+	return dir.AuthenticateAdmin(as, password)
+}
+
+// promptPassword reads a password without echo when stdin is a terminal
+// and nothing typed ahead is buffered in in; piped input is read a line at
+// a time from in.
+func promptPassword(in *bufio.Reader, prompt string) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s: ", prompt)
+
+	if fd := int(os.Stdin.Fd()); in.Buffered() == 0 && term.IsTerminal(fd) {
+		pw, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil || len(pw) == 0 {
+			return "", errors.New("no password given")
+		}
+		return string(pw), nil
+	}
+
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New("no password given")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func printAccount(a security_users.Account) {
+	flags := ""
+	if a.Admin {
+		flags += " admin"
+	}
+	if a.Disabled {
+		flags += " disabled"
+	}
+	fmt.Printf("%-24s %-13s %-11s %s%s\n", a.UserID, a.Entity, a.Tier, a.CreatedAt.Format(time.RFC3339), flags)
+}
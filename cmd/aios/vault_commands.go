@@ -0,0 +1,147 @@
+//cmd/aios/vault_commands.go
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+func runVaultCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand: migrate|export|import")
+	}
+
+	switch args[0] {
+	case "migrate":
+		return runVaultMigrate(args[1:])
+	case "export":
+		return runVaultExport(args[1:])
+	case "import":
+		return runVaultImport(args[1:])
+	default:
+		return fmt.Errorf("unknown vault subcommand: %s", args[0])
+	}
+}
+
+// runVaultMigrate copies the directory-of-JSON vault into a bolt database.
+// Set AIOS_VAULT_BACKEND=bolt afterwards to boot from the new store.
+func runVaultMigrate(args []string) error {
+	fs := flag.NewFlagSet("vault migrate", flag.ContinueOnError)
+	out := fs.String("out", "", "bolt database path (default: <vault>/"+verification_persistence.BoltVaultFile+")")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	src, err := verification_persistence.OpenVault()
+	if err != nil {
+		return err
+	}
+
+	path := *out
+	if path == "" {
+		path = filepath.Join(src.BaseDir, verification_persistence.BoltVaultFile)
+	}
+
+	dst, err := verification_persistence.OpenBoltVault(path, src.Key)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	report, err := verification_persistence.MigrateDirectoryToBolt(src, dst)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("migrated into %s\n", report.Database)
+	fmt.Printf("  records: %d\n  configs: %d\n  golden:  %d\n  markers: %d\n",
+		report.Records, report.Configs, report.Golden, report.Markers)
+	for _, s := range report.Skipped {
+		fmt.Printf("  skipped: %s\n", s)
+	}
+
+	return nil
+}
+
+// runVaultExport writes an encrypted, fleet-signed bundle of users, configs,
+// policies and provisioning state for transfer to a replacement unit.
+func runVaultExport(args []string) error {
+	fs := flag.NewFlagSet("vault export", flag.ContinueOnError)
+	out := fs.String("out", "vault-bundle.json", "bundle output path")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fleet, err := verification_persistence.LoadFleetKey()
+	if err != nil {
+		return err
+	}
+
+	vault, err := verification_persistence.OpenStore()
+	if err != nil {
+		return err
+	}
+
+	bundle, err := verification_persistence.ExportBundle(vault, fleet)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(*out, data, 0600); err != nil {
+		return err
+	}
+
+	fmt.Printf("exported vault bundle for fleet %s to %s\n", fleet.ID, *out)
+	return nil
+}
+
+// runVaultImport verifies a bundle against the local fleet key and loads it.
+// Hardware-bound state is not imported; the next boot re-attests this unit.
+func runVaultImport(args []string) error {
+	fs := flag.NewFlagSet("vault import", flag.ContinueOnError)
+	in := fs.String("in", "vault-bundle.json", "bundle input path")
+	force := fs.Bool("force", false, "overwrite records that already exist")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fleet, err := verification_persistence.LoadFleetKey()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+
+	var bundle verification_persistence.VaultBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return fmt.Errorf("malformed bundle: %w", err)
+	}
+
+	vault, err := verification_persistence.OpenStore()
+	if err != nil {
+		return err
+	}
+
+	n, err := verification_persistence.ImportBundle(vault, &bundle, fleet, *force)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("imported %d records from %s; this unit will re-attest on next boot\n", n, bundle.SourceMachine)
+	return nil
+}
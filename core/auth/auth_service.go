@@ -3,20 +3,25 @@
 package auth
 
 import (
-	"bufio"
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
 	"errors"
 	"fmt"
-	"os"
+	"slices"
 	"strings"
 	"time"
 
 	bootstrap "github.com/MIAUSEproject-founderKJ/multi-platform-AI/bootstrap"
 	bootstrap_phase "github.com/MIAUSEproject-founderKJ/multi-platform-AI/bootstrap/phases"
 	bootstrap_resolver "github.com/MIAUSEproject-founderKJ/multi-platform-AI/bootstrap/resolver"
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	security_authenticator "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/authenticator"
 	security_decision "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/decision"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	security_lockout "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/lockout"
+	security_password "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/password"
 	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	security_totp "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/totp"
+	security_users "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/users"
 	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/mutual_interaction"
 
 	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
@@ -26,10 +31,34 @@ import (
 
 type AuthManager struct {
 	Vault    verification_persistence.VaultStore
+	Provider CredentialProvider
+	Audit    *security_audit.Log
+	Lockout  *security_lockout.Guard
+	Activity *verification_identity.SessionActivity
+	Tokens   *verification_identity.TokenService
+	UserID   string
+
+	// Remote logins (the HTTP login endpoint) only receive a session
+	// token; the console runtime is left as it is.
+	Remote bool
+
+	// GuestLifetime bounds guest sessions; zero means
+	// user_setting.DefaultGuestLifetime.
+	GuestLifetime time.Duration
+	DisableGuest  bool
+
 	Identity *internal_environment.MachineIdentity
 	Platform internal_environment.PlatformClass
 	Entity   internal_environment.EntityKind
 	Tier     user_setting.TierType
+
+	// Decisions grants session permissions; nil decides provisionally
+	// until the environment is attested.
+	Decisions *security_decision.DecisionPoint
+
+	// companionVerified is set when the login was answered by a paired
+	// companion app, which then also satisfies a companion step.
+	companionVerified bool
 }
 
 type AuthInterface interface {
@@ -39,6 +68,14 @@ type AuthInterface interface {
 type Credentials struct {
 	UserID   string
 	Password string
+
+	// Source identifies where the attempt came from (terminal, env, a
+	// remote address) for per-source lockout.
+	Source string
+
+	// Companion signs in without a password: the user's paired companion
+	// app answers a challenge instead.
+	Companion bool
 }
 
 // detectEntityAndTier inspects the user identity to assign entity and tier
@@ -63,82 +100,35 @@ func (am *AuthManager) detectEntityAndTier() {
 		am.Entity = internal_environment.EntityStranger
 		am.Tier = user_setting.TierUnknown
 	}
-}
-
-func PromptForCredentials(vault verification_persistence.VaultStore) (string, string) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Print("[AUTH] Enter User ID: ")
-	userID, _ := reader.ReadString('\n')
-	userID = strings.TrimSpace(userID)
-
-	// Check if user exists
-	var existing internal_environment.MachineIdentity
-	found, _ := vault.Read("users", userID, &existing)
 
-	if !found {
-		fmt.Println("[AUTH] User not found. Starting registration...")
-		_, err := PromptForRegistration(vault)
-		if err != nil {
-			fmt.Println("[AUTH] Registration failed:", err)
-			os.Exit(1)
-		}
+	// An administrator may have assigned a tier explicitly
+	if am.Identity.TierType != "" {
+		am.Tier = am.Identity.TierType
 	}
-
-	fmt.Print("[AUTH] Enter Password: ")
-	password, _ := reader.ReadString('\n')
-	password = strings.TrimSpace(password)
-
-	return userID, password
-}
-func hashPassword(pw string) string {
-	h := sha256.Sum256([]byte(pw))
-	return hex.EncodeToString(h[:])
 }
 
-func PromptForRegistration(vault verification_persistence.VaultStore) (*internal_environment.MachineIdentity, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Println("=== User Registration ===")
-
-	fmt.Print("Enter new User ID: ")
-	userID, _ := reader.ReadString('\n')
-	userID = strings.TrimSpace(userID)
-
-	fmt.Print("Enter Password: ")
-	password, _ := reader.ReadString('\n')
-	password = strings.TrimSpace(password)
-
-	fmt.Print("Select Entity Type (personal / organization / tester): ")
-	entityStr, _ := reader.ReadString('\n')
-	entityStr = strings.TrimSpace(entityStr)
+func hashPassword(pw string) (string, error) {
+	return security_password.Hash(pw)
+}
 
-	entityType := internal_environment.EntityPersonal
-	switch strings.ToLower(entityStr) {
-	case "organization":
-		entityType = internal_environment.EntityOrganization
-	case "tester":
-		entityType = internal_environment.EntityTester
+// register creates the account described by reg.
+func (am *AuthManager) register(reg *Registration) error {
+	if reg.UserID == "" || reg.Password == "" {
+		return errors.New("user ID and password required")
 	}
-
-	identity := &internal_environment.MachineIdentity{
-		MachineID:    fmt.Sprintf("machine-%s", userID),
-		EntityType:   entityType,
-		OS:           "unknown",
-		Arch:         "unknown",
-		PasswordHash: hashPassword(password),
+	if reg.UserID == GuestUserID {
+		return errors.New("user ID reserved for guest sessions")
 	}
 
-	if vault == nil {
-		return nil, errors.New("vault not initialized")
+	exists, err := am.Vault.Exists("users", reg.UserID)
+	if err != nil {
+		return err
 	}
-
-	if err := vault.Write("users", userID, identity); err != nil {
-		return nil, fmt.Errorf("failed to write user to vault: %w", err)
+	if exists {
+		return errors.New("username already taken")
 	}
 
-	fmt.Println("[PromptForRegistration] Registration successful for user:", userID)
-	return identity, nil
+	return am.RegisterUser(reg.UserID, reg.Password, reg.Entity)
 }
 
 // verifyUserCredentials checks the Vault or database for valid credentials
@@ -159,178 +149,292 @@ func (am *AuthManager) verifyUserCredentials(userID, password string) (bool, *in
 		return false, nil
 	}
 
-	// Validate password (simplified: plaintext match for demo; ideally hashed)
-	hash := hashPassword(password)
-	if stored.PasswordHash != hash {
+	needsRehash, err := security_password.Verify(password, stored.PasswordHash)
+	if err != nil {
+		return false, nil
+	}
+
+	if stored.Disabled {
+		fmt.Println("[verifyUserCredentials] Account disabled:", userID)
 		return false, nil
 	}
 
+	// Legacy SHA-256 and bcrypt hashes are replaced on the first successful login
+	if needsRehash {
+		if hash, err := hashPassword(password); err == nil {
+			stored.PasswordHash = hash
+			if err := am.Vault.Write("users", userID, &stored); err != nil {
+				fmt.Println("[verifyUserCredentials] Password hash upgrade failed:", err)
+			}
+		}
+	}
+
 	fmt.Println("[verifyUserCredentials] User verified from vault:", userID)
 	return true, &stored
 }
 
-func (am *AuthManager) RegisterUser(userID, password string, entityType internal_environment.EntityKind) error {
+// verifyCompanion is verifyUserCredentials for a silent login: instead of
+// a password, a companion app paired to userID must answer a challenge
+// bound to this unit with the key enrolled at pairing.
+func (am *AuthManager) verifyCompanion(ctx context.Context, userID string) (bool, *internal_environment.MachineIdentity) {
 	if am.Vault == nil {
-		return errors.New("vault not initialized")
+		return false, nil
 	}
 
-	identity := &internal_environment.MachineIdentity{
-		MachineID:    fmt.Sprintf("machine-%s", userID),
-		EntityType:   entityType,
-		OS:           "unknown",
-		Arch:         "unknown",
-		PasswordHash: hashPassword(password),
+	var stored internal_environment.MachineIdentity
+	found, err := am.Vault.Read("users", userID, &stored)
+	if err != nil || !found || stored.Disabled {
+		return false, nil
 	}
 
-	return am.Vault.Write("users", userID, identity)
-}
-
-func PromptForUserConfig() *user_setting.CustomizedConfig {
-	cfg := DefaultCustomizedConfig()
-	reader := bufio.NewReader(os.Stdin)
+	cfg, err := security_authenticator.LoadConfig(am.Vault)
+	if err != nil {
+		return false, nil
+	}
+	machineID, err := security_authenticator.DeviceContext(am.Vault)
+	if err != nil {
+		return false, nil
+	}
 
-	fmt.Println("\n=== User Configuration ===")
-	fmt.Println("Press ENTER to keep default")
+	am.provider().Notify("Waiting for the companion app paired to " + userID)
 
-	fmt.Printf("Main language [%s]: ", cfg.MainLang)
-	if v, _ := reader.ReadString('\n'); strings.TrimSpace(v) != "" {
-		cfg.MainLang = strings.TrimSpace(v)
+	ctx, cancel := context.WithTimeout(ctx, security_authenticator.ChallengeMaxAge)
+	defer cancel()
+	step := security_authenticator.Step{AnyOf: []security_authenticator.Kind{security_authenticator.KindCompanionApp}}
+	if _, err := security_authenticator.RunStep(ctx, am.Vault, cfg, userID, machineID, step); err != nil {
+		fmt.Println("[verifyCompanion]", err)
+		return false, nil
 	}
 
-	fmt.Printf("Power mode (low/balanced/high) [%s]: ", cfg.PowerMode)
-	if v, _ := reader.ReadString('\n'); strings.TrimSpace(v) != "" {
-		cfg.PowerMode = strings.TrimSpace(v)
-	}
+	am.companionVerified = true
+	fmt.Println("[verifyCompanion] User verified by companion app:", userID)
+	return true, &stored
+}
 
-	fmt.Printf("Privacy mode (standard/strict/offline) [%s]: ", cfg.PrivacyMode)
-	if v, _ := reader.ReadString('\n'); strings.TrimSpace(v) != "" {
-		cfg.PrivacyMode = strings.TrimSpace(v)
+func (am *AuthManager) RegisterUser(userID, password string, entityType internal_environment.EntityKind) error {
+	if am.Vault == nil {
+		return errors.New("vault not initialized")
 	}
 
-	fmt.Printf("Update mode (auto/manual) [%s]: ", cfg.UpdateMode)
-	if v, _ := reader.ReadString('\n'); strings.TrimSpace(v) != "" {
-		cfg.UpdateMode = strings.TrimSpace(v)
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
 	}
 
-	fmt.Println("[CONFIG] Completed")
+	identity := &internal_environment.MachineIdentity{
+		MachineID:    fmt.Sprintf("machine-%s", userID),
+		EntityType:   entityType,
+		OS:           "unknown",
+		Arch:         "unknown",
+		PasswordHash: hash,
+	}
 
-	return cfg
+	return am.Vault.Write("users", userID, identity)
 }
 
 func DefaultCustomizedConfig() *user_setting.CustomizedConfig {
-	return &user_setting.CustomizedConfig{
-		Version:      "v1",
+	cfg := &user_setting.CustomizedConfig{
+		Version:      user_setting.ConfigVersion,
 		LastModified: time.Now(),
 	}
+	return cfg.WithDefaults()
 }
-func (am *AuthManager) Register() (*user_setting.UserSession, error) {
-	reader := bufio.NewReader(os.Stdin)
 
-	fmt.Print("User ID: ")
-	userID, _ := reader.ReadString('\n')
-	userID = strings.TrimSpace(userID)
+// Register asks the provider for a new account and logs it in.
+func (am *AuthManager) Register(ctx context.Context) (*user_setting.UserSession, error) {
+	p := am.provider()
 
-	fmt.Print("Password: ")
-	password, _ := reader.ReadString('\n')
-	password = strings.TrimSpace(password)
+	creds, err := p.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	entityType := internal_environment.EntityPersonal
+	reg, err := p.Registration(ctx, creds.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if reg.Password == "" {
+		reg.Password = creds.Password
+	}
 
-	if err := am.RegisterUser(userID, password, entityType); err != nil {
+	if err := am.register(reg); err != nil {
+		p.Complete(err)
 		return nil, err
 	}
 
 	// immediately login after registration
-	return am.Login(userID, password)
+	return am.Login(reg.UserID, reg.Password)
 }
 
 func (am *AuthManager) Login(userID, password string) (*user_setting.UserSession, error) {
+	guard, err := am.guard()
+	if err != nil {
+		return nil, err
+	}
+	if err := guard.Check(userID, ""); err != nil {
+		return nil, err
+	}
+
 	verified, identity := am.verifyUserCredentials(userID, password)
 	if !verified {
+		if lerr := guard.RecordFailure(userID, ""); lerr != nil {
+			return nil, lerr
+		}
 		return nil, errors.New("invalid credentials")
 	}
+	_ = guard.RecordSuccess(userID)
 
+	am.UserID = userID
 	am.Identity = identity
 	am.detectEntityAndTier()
 
 	return am.platformLoginFlow()
 }
 
-func (am *AuthManager) LoginOrSignUpInteractive() (*user_setting.UserSession, error) {
-	for {
-		userID, password := PromptForCredentials(am.Vault)
-
-		verified, identity := am.verifyUserCredentials(userID, password)
-		if verified {
-			am.Identity = identity
-			am.detectEntityAndTier()
-			return am.platformLoginFlow() // ✅ exits loop
-		}
+// LoginOrSignUp runs the login loop against the configured provider.
+// Unknown users are offered registration. The loop ends on success, when
+// the provider runs out of credentials, or after MaxLoginAttempts.
+func (am *AuthManager) LoginOrSignUp(ctx context.Context) (*user_setting.UserSession, error) {
+	p := am.provider()
 
-		fmt.Println("[AUTH] Invalid credentials or user not found. Try again.")
+	guard, err := am.guard()
+	if err != nil {
+		return nil, err
 	}
-}
 
-// platformLoginFlow performs the platform-specific verification and session creation
-func (am *AuthManager) platformLoginFlow() (*user_setting.UserSession, error) {
-	var err error
+	for attempt := 0; attempt < MaxLoginAttempts; attempt++ {
+		creds, err := p.Credentials(ctx)
+		if err != nil {
+			return nil, err
+		}
 
-	switch am.Platform {
+		if creds.UserID == GuestUserID && !am.DisableGuest {
+			session, err := am.GuestLogin()
+			p.Complete(err)
+			return session, err
+		}
 
-	// ------------------------------
-	// Vehicles / Autonomous Mobility
-	// ------------------------------
-	case internal_environment.PlatformVehicle, internal_environment.PlatformRobot:
-		switch am.Entity {
-		case internal_environment.EntityPersonal:
-			err = am.verifyKeyFobOrBiometrics()
-		case internal_environment.EntityOrganization:
-			err = am.verifyBiometricsAndAppHandshake()
-		case internal_environment.EntityStranger:
-			err = am.guestLoginVehicle()
-		case internal_environment.EntityTester:
-			err = am.verifyMechanicAccess()
-		default:
-			err = fmt.Errorf("unknown vehicle entity type")
+		if err := waitForGuard(ctx, guard, creds); err != nil {
+			p.Complete(err)
+			return nil, err
 		}
 
-	// ------------------------------
-	// Industrial / Embedded / Factory
-	// ------------------------------
-	case internal_environment.PlatformIndustrial, internal_environment.PlatformEmbedded:
-		err = am.verifyNFCCardOrButton()
+		exists, err := am.Vault.Exists("users", creds.UserID)
+		if err != nil {
+			return nil, err
+		}
 
-	// ------------------------------
-	// PCs / Laptops / Productivity
-	// ------------------------------
-	case internal_environment.PlatformComputer, internal_environment.PlatformMobile:
-		switch am.Entity {
-		case internal_environment.EntityPersonal:
-			err = am.verifyPasswordOrOSBiometrics()
-		case internal_environment.EntityOrganization:
-			err = am.verifyPasswordOrOSBiometrics()
-			if err == nil {
-				err = am.verify2FAEnterprise()
+		if !exists && !creds.Companion {
+			reg, err := p.Registration(ctx, creds.UserID)
+			if errors.Is(err, ErrRegistrationDeclined) {
+				p.Complete(errors.New("invalid credentials"))
+				continue
+			}
+			if err != nil {
+				return nil, err
 			}
-		case internal_environment.EntityStranger:
-			err = am.guestLoginPC()
-		case internal_environment.EntityTester:
-			err = am.enableDebugLogin()
-		default:
-			err = fmt.Errorf("unknown PC entity type")
+			if reg.Password == "" {
+				reg.Password = creds.Password
+			}
+			if err := am.register(reg); err != nil {
+				p.Complete(err)
+				continue
+			}
+			creds = &Credentials{UserID: reg.UserID, Password: reg.Password}
 		}
 
-	// ------------------------------
-	// Fallback / Unknown
-	// ------------------------------
-	default:
-		err = fmt.Errorf("unsupported platform: %s", am.Platform)
+		var verified bool
+		var identity *internal_environment.MachineIdentity
+		if creds.Companion {
+			verified, identity = am.verifyCompanion(ctx, creds.UserID)
+		} else {
+			verified, identity = am.verifyUserCredentials(creds.UserID, creds.Password)
+		}
+		if !verified {
+			if lerr := guard.RecordFailure(creds.UserID, creds.Source); lerr != nil {
+				p.Complete(lerr)
+				return nil, lerr
+			}
+			p.Complete(errors.New("invalid credentials"))
+			continue
+		}
+		_ = guard.RecordSuccess(creds.UserID)
+
+		am.UserID = creds.UserID
+		am.Identity = identity
+		am.detectEntityAndTier()
+
+		session, err := am.platformLoginFlow()
+		p.Complete(err)
+		return session, err
 	}
 
+	return nil, errors.New("too many failed login attempts")
+}
+
+// guard returns the lockout guard, building one from the platform policy
+// stored in the vault on first use.
+func (am *AuthManager) guard() (*security_lockout.Guard, error) {
+	if am.Lockout != nil {
+		return am.Lockout, nil
+	}
+	if am.Vault == nil {
+		return nil, errors.New("vault not initialized")
+	}
+
+	policy, err := security_lockout.LoadPolicy(am.Vault, am.Platform)
 	if err != nil {
 		return nil, err
 	}
 
+	var auditor security_lockout.Auditor
+	if am.Audit != nil {
+		auditor = am.Audit
+	}
+
+	am.Lockout = security_lockout.NewGuard(am.Vault, policy, auditor)
+	return am.Lockout, nil
+}
+
+// waitForGuard sits out a back-off delay and fails immediately on lockout.
+func waitForGuard(ctx context.Context, guard *security_lockout.Guard, creds *Credentials) error {
+	err := guard.Check(creds.UserID, creds.Source)
+
+	var locked *security_lockout.LockedError
+	if !errors.As(err, &locked) || locked.Lockout {
+		return err
+	}
+
+	select {
+	case <-time.After(time.Until(locked.Until)):
+		return guard.Check(creds.UserID, creds.Source)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// LoginOrSignUpInteractive is LoginOrSignUp on a terminal.
+func (am *AuthManager) LoginOrSignUpInteractive() (*user_setting.UserSession, error) {
+	if am.Provider == nil {
+		am.Provider = NewTerminalProvider()
+	}
+	return am.LoginOrSignUp(context.Background())
+}
+
+// platformLoginFlow runs the authenticator steps configured for the
+// platform and entity, then the second factor, and creates the session.
+func (am *AuthManager) platformLoginFlow() (*user_setting.UserSession, error) {
+	if err := am.verifyAuthenticators(); err != nil {
+		return nil, err
+	}
+
+	// Second factor follows entity/tier policy, not platform
+	if requiresSecondFactor(am.Entity, am.Tier) {
+		if err := am.verify2FAEnterprise(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Determine default service based on platform
 	service := user_setting.ServiceUnknown
 	switch am.Platform {
@@ -346,90 +450,127 @@ func (am *AuthManager) platformLoginFlow() (*user_setting.UserSession, error) {
 }
 
 // ------------------------------------------------------------
-// Vehicle / Autonomous Mobility Auth
+// External authenticators (key fob, NFC card, biometrics, ...)
 // ------------------------------------------------------------
 
-func (am *AuthManager) verifyKeyFobOrBiometrics() error {
-	// Simulate querying vehicle key-fob API or biometric reader
-	time.Sleep(100 * time.Millisecond) // simulate latency
-	verified := true                   // replace with actual hardware API
-	if !verified {
-		return errors.New("key-fob / biometrics verification failed")
+// verifyAuthenticators selects the rule for the platform and entity from
+// the authenticator configuration and requires every step to pass.
+func (am *AuthManager) verifyAuthenticators() error {
+	cfg, err := security_authenticator.LoadConfig(am.Vault)
+	if err != nil {
+		return err
 	}
-	fmt.Println("[func (am *AuthManager) verifyKeyFobOrBiometrics] Vehicle: Key-fob or biometric verified")
-	return nil
-}
 
-func (am *AuthManager) verifyBiometricsAndAppHandshake() error {
-	// Enterprise vehicle: requires both biometrics + companion app handshake
-	time.Sleep(150 * time.Millisecond)
-	success := true // replace with real verification logic
-	if !success {
-		return errors.New("biometric + app handshake failed")
+	entity := security_users.EntityName(am.Entity)
+	rule, err := cfg.Select(am.Platform, entity)
+	if errors.Is(err, security_authenticator.ErrNoRule) {
+		return fmt.Errorf("unsupported platform: %s (%s)", am.Platform, entity)
+	}
+	if err != nil {
+		return err
+	}
+
+	machineID, err := security_authenticator.DeviceContext(am.Vault)
+	if err != nil {
+		return err
 	}
-	fmt.Println("[func (am *AuthManager) verifyBiometricsAndAppHandshake] Vehicle: Biometric + App handshake verified")
-	return nil
-}
 
-func (am *AuthManager) guestLoginVehicle() error {
-	// Minimal verification for passengers
-	fmt.Println("[AUTH] Vehicle: Guest login activated (limited privileges)")
+	for _, step := range rule.Steps {
+		if am.companionVerified && slices.Contains(step.AnyOf, security_authenticator.KindCompanionApp) {
+			continue
+		}
+		am.provider().Notify("Present " + authenticatorPrompt(step))
+
+		ctx, cancel := context.WithTimeout(context.Background(), security_authenticator.ChallengeMaxAge)
+		kind, err := security_authenticator.RunStep(ctx, am.Vault, cfg, am.UserID, machineID, step)
+		cancel()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("[AUTH] %s verified for %s\n", kind, am.UserID)
+	}
 	return nil
 }
 
-func (am *AuthManager) verifyMechanicAccess() error {
-	// Mechanic / Tester: full system access in sandbox
-	time.Sleep(50 * time.Millisecond)
-	fmt.Println("[AUTH] Vehicle: Mechanic/Tester full access granted")
-	return nil
+func authenticatorPrompt(step security_authenticator.Step) string {
+	names := make([]string, len(step.AnyOf))
+	for i, k := range step.AnyOf {
+		names[i] = strings.ReplaceAll(string(k), "_", " ")
+	}
+	return strings.Join(names, " or ")
 }
 
-// ------------------------------------------------------------
-// Industrial / Embedded / Factory Auth
-// ------------------------------------------------------------
+func (am *AuthManager) verify2FAEnterprise() error {
+	if am.UserID == "" {
+		return errors.New("2FA requires an authenticated user")
+	}
 
-func (am *AuthManager) verifyNFCCardOrButton() error {
-	// NFC card or physical pairing button
-	time.Sleep(80 * time.Millisecond)
-	valid := true
-	if !valid {
-		return errors.New("NFC/button verification failed")
+	enrolled, err := security_totp.Enrolled(am.Vault, am.UserID)
+	if err != nil {
+		return err
+	}
+	if !enrolled {
+		return am.enrollTOTP()
 	}
-	fmt.Println("[func (am *AuthManager) verifyNFCCardOrButton] Industrial: NFC card or pairing button verified")
-	return nil
-}
 
-// ------------------------------------------------------------
-// Personal / Enterprise PC Auth
-// ------------------------------------------------------------
+	code, err := am.provider().SecondFactor(context.Background(), "Authenticator code (or recovery code)")
+	if err != nil {
+		return fmt.Errorf("2FA failed: %w", err)
+	}
+	code = strings.TrimSpace(code)
 
-func (am *AuthManager) verifyPasswordOrOSBiometrics() error {
-	// Standard login: password + optional OS-biometrics
-	time.Sleep(50 * time.Millisecond)
-	fmt.Println("[func (am *AuthManager) verifyPasswordOrOSBiometrics] PC: Password/OS-biometrics verified")
-	return nil
-}
+	if err := security_totp.Verify(am.Vault, am.UserID, code); err == nil {
+		fmt.Println("[func (am *AuthManager) verify2FAEnterprise] PC: Enterprise 2FA verified")
+		return nil
+	} else if !errors.Is(err, security_totp.ErrInvalidCode) {
+		return fmt.Errorf("2FA failed: %w", err)
+	}
 
-func (am *AuthManager) verify2FAEnterprise() error {
-	// Enterprise 2FA: email/code, token, or app approval
-	time.Sleep(100 * time.Millisecond)
-	fmt.Println("[func (am *AuthManager) verify2FAEnterprise] PC: Enterprise 2FA verified")
+	remaining, err := security_totp.UseRecoveryCode(am.Vault, am.UserID, code)
+	if err != nil {
+		return errors.New("2FA failed: invalid code")
+	}
+
+	am.provider().Notify(fmt.Sprintf("Recovery code accepted; %d remaining", remaining))
 	return nil
 }
 
-func (am *AuthManager) guestLoginPC() error {
-	// Local-only guest session
-	fmt.Println("[func (am *AuthManager) guestLoginPC] PC: Guest session started (restricted access)")
+// enrollTOTP runs first-time enrollment: show the otpauth URI and recovery
+// codes once, then require a valid code before the login completes.
+func (am *AuthManager) enrollTOTP() error {
+	enrollment, err := security_totp.Enroll(am.Vault, am.UserID, "AIOS")
+	if err != nil {
+		return err
+	}
+
+	p := am.provider()
+	p.Notify("Two-factor enrollment required for this account. Add this to your authenticator app:\n  " + enrollment.URI)
+	p.Notify("Recovery codes (store them safely, each works once):\n  " + strings.Join(enrollment.RecoveryCodes, "\n  "))
+
+	code, err := p.SecondFactor(context.Background(), "Enter the current code to confirm")
+	if err != nil {
+		return fmt.Errorf("2FA enrollment failed: %w", err)
+	}
+
+	if err := security_totp.Confirm(am.Vault, am.UserID, strings.TrimSpace(code)); err != nil {
+		return fmt.Errorf("2FA enrollment failed: %w", err)
+	}
+
+	p.Notify("Two-factor authentication enabled")
 	return nil
 }
 
-// ------------------------------------------------------------
-// Debug / Tester Auth
-// ------------------------------------------------------------
+func (am *AuthManager) decisions() *security_decision.DecisionPoint {
+	if am.Decisions != nil {
+		return am.Decisions
+	}
+	return security_decision.Provisional(am.Platform)
+}
 
-func (am *AuthManager) enableDebugLogin() error {
-	fmt.Println("[AUTH] Debug/Test login granted (sandbox environment)")
-	return nil
+// requiresSecondFactor applies the 2FA policy: organization accounts and
+// enterprise tier always need a TOTP code.
+func requiresSecondFactor(entity internal_environment.EntityKind, tier user_setting.TierType) bool {
+	return entity == internal_environment.EntityOrganization || tier == user_setting.TierEnterprise
 }
 
 // ------------------------------------------------------------
@@ -437,22 +578,22 @@ func (am *AuthManager) enableDebugLogin() error {
 // ------------------------------------------------------------
 
 func (am *AuthManager) createSession(service user_setting.ServiceType) (*user_setting.UserSession, error) {
+	if am.Entity == internal_environment.EntityStranger {
+		return am.createGuestSession(service)
+	}
 
 	// ----------------------------
 	// 1. AUTHORIZATION
 	// ----------------------------
-	authCtx := &security_decision.AuthorizationContext{
-		Platform: am.Platform,
-		Entity:   am.Entity,
-		Tier:     am.Tier,
-		Service:  service,
-	}
-
-	authz := security_decision.AuthorizationService{
-		Resolver: &security_decision.DefaultPermissionResolver{},
-	}
-
-	permMap := authz.Authorize(authCtx)
+	exec := am.decisions().Decide(security_decision.Subject{
+		UserID:  am.UserID,
+		Entity:  am.Entity,
+		Tier:    am.Tier,
+		Service: service,
+		Admin:   am.Identity != nil && am.Identity.Admin,
+		Sandbox: am.Entity == internal_environment.EntityTester,
+	})
+	permMap := exec.Permissions()
 
 	// Build permission mask
 	var permMask internal_verification.PermissionMask
@@ -466,36 +607,48 @@ func (am *AuthManager) createSession(service user_setting.ServiceType) (*user_se
 	builder := user_setting.SessionBuilder{}
 
 	buildCtx := &user_setting.BuildContext{
+		UserID:   am.UserID,
 		Platform: am.Platform,
 		Entity:   am.Entity,
 		Tier:     am.Tier,
 		Service:  service,
+
+		// Testers and mechanics always run sandboxed
+		Sandbox: am.Entity == internal_environment.EntityTester,
 	}
 
-	session := builder.Build(buildCtx, permMap)
-	session.PermMask = permMask
+	claims := builder.Build(buildCtx, permMap)
+	session := &user_setting.UserSession{
+		Identity: &user_setting.UserIdentity{Username: am.UserID},
+		Claims:   *claims,
+		PermMask: permMask,
+	}
 
 	// ----------------------------
 	// 3. CONFIG
 	// ----------------------------
-	cfg, err := LoadUserConfig(am.Vault, am.Identity.MachineID)
+	cfg, err := LoadUserConfig(am.Vault, am.UserID)
 	if err != nil {
 		return nil, err
 	}
 
 	if cfg == nil {
-		cfg = PromptForUserConfig()
-		_ = SaveUserConfig(am.Vault, am.Identity.MachineID, cfg)
+		cfg, err = am.provider().UserConfig(context.Background(), DefaultCustomizedConfig())
+		if err != nil {
+			return nil, err
+		}
+		_ = SaveUserConfig(am.Vault, am.UserID, cfg)
 	}
 
 	cfg.WithDefaults()
+	session.Config = cfg.UserCoreConfig
 
-	session.Config = &user_setting.UserConfig{
-		MainLang:      cfg.MainLang,
-		PowerMode:     cfg.PowerMode,
-		PrivacyMode:   cfg.PrivacyMode,
-		UpdateMode:    cfg.UpdateMode,
-		PreferredMode: cfg.PreferredMode,
+	if profile, err := LoadProfile(am.Vault, am.UserID); err == nil && profile != nil {
+		session.Preferences = &profile.Preferences
+	}
+
+	if err := am.issueToken(session); err != nil {
+		return nil, err
 	}
 
 	// ----------------------------
@@ -508,51 +661,103 @@ func (am *AuthManager) createSession(service user_setting.ServiceType) (*user_se
 	return session, nil
 }
 
-func LoadUserConfig(vault verification_persistence.VaultStore, userID string) (*user_setting.CustomizedConfig, error) {
-	var cfg user_setting.CustomizedConfig
+// tokens returns the session token service, loading the unit's signing
+// key from the vault on first use.
+func (am *AuthManager) tokens() (*verification_identity.TokenService, error) {
+	if am.Tokens != nil {
+		return am.Tokens, nil
+	}
+	if am.Vault == nil {
+		return nil, errors.New("vault not initialized")
+	}
 
-	found, err := vault.Read("configs", userID, &cfg)
+	tokens, err := verification_identity.NewTokenService(am.Vault)
 	if err != nil {
 		return nil, err
 	}
+	am.Tokens = tokens
+	return tokens, nil
+}
 
-	if !found {
-		return nil, nil
+// issueToken signs the session claims. Issue may fill in the session ID
+// and cap the expiry, so the session keeps the claims as signed.
+func (am *AuthManager) issueToken(session *user_setting.UserSession) error {
+	tokens, err := am.tokens()
+	if err != nil {
+		return err
+	}
+
+	token, err := tokens.Issue(&session.Claims)
+	if err != nil {
+		return fmt.Errorf("session token: %w", err)
+	}
+	session.Token = token
+	return nil
+}
+
+// LoadUserConfig returns the config stored in the profile of userID, or
+// nil if the user has no profile yet.
+func LoadUserConfig(vault verification_persistence.VaultStore, userID string) (*user_setting.CustomizedConfig, error) {
+	p, err := LoadProfile(vault, userID)
+	if err != nil || p == nil {
+		return nil, err
 	}
 
+	cfg := p.Config
 	cfg.WithDefaults()
 	cfg.Migrate()
 
 	return &cfg, nil
 }
 
+// SaveUserConfig stores cfg in the profile of userID, keeping its
+// preferences, and records it in the config history.
 func SaveUserConfig(vault verification_persistence.VaultStore, userID string, cfg *user_setting.CustomizedConfig) error {
-	cfg.LastModified = time.Now()
-	return vault.Write("configs", userID, cfg)
+	_, err := CommitUserConfig(vault, userID, userID, "", cfg)
+	return err
 }
 
-func (am *AuthManager) HandleConfigUpdate(session *user_setting.UserSession) {
-	reader := bufio.NewReader(os.Stdin)
+// HandleConfigUpdate runs a configuration command entered by the user.
+func (am *AuthManager) HandleConfigUpdate(session *user_setting.UserSession, cmd string) error {
+	if strings.TrimSpace(cmd) != "update config" {
+		return fmt.Errorf("unknown config command: %s", cmd)
+	}
 
-	fmt.Print("[CONFIG] Enter command: ")
-	cmd, _ := reader.ReadString('\n')
-	cmd = strings.TrimSpace(cmd)
+	if err := am.StepUp(context.Background(), session, user_setting.PermConfigEdit); err != nil {
+		return err
+	}
 
-	if cmd == "update config" {
-		fmt.Println("[CONFIG] Updating configuration...")
-		newCfg := PromptForUserConfig()
+	am.provider().Notify("Updating configuration...")
 
-		_ = SaveUserConfig(am.Vault, am.Identity.MachineID, newCfg)
+	current, err := LoadUserConfig(am.Vault, am.UserID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		current = DefaultCustomizedConfig()
+	}
 
-		session.Config = newCfg
+	newCfg, err := am.provider().UserConfig(context.Background(), current)
+	if err != nil {
+		return err
+	}
 
-		if orch, ok := session.Orchestrator.(*bootstrap_phase.Orchestrator); ok {
-			orch.Broadcast("Configuration updated successfully")
-		}
+	if err := SaveUserConfig(am.Vault, am.UserID, newCfg); err != nil {
+		return err
 	}
+
+	session.Config = newCfg.UserCoreConfig
+
+	if orch, ok := session.Orchestrator.(*bootstrap_phase.Orchestrator); ok {
+		orch.Broadcast("Configuration updated successfully")
+	}
+	return nil
 }
 
 func (am *AuthManager) initializeRuntime(session *user_setting.UserSession) error {
+	if am.Remote {
+		return nil
+	}
 
 	cp, err := bootstrap_resolver.DeviceCapabilitiesResolver()
 	if err != nil {
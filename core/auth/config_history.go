@@ -0,0 +1,293 @@
+// core/auth/config_history.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// configHistoryCollection keeps the saved configs of a user, keyed by
+// user ID next to the profile holding the current one.
+const configHistoryCollection = "config_history"
+
+// ConfigHistoryLimit is how many revisions are kept per user. Older ones
+// are dropped; revision numbers keep counting.
+const ConfigHistoryLimit = 50
+
+var ErrNoSuchRevision = errors.New("config_revision_not_found")
+
+// ConfigRevision is one saved version of a user config.
+type ConfigRevision struct {
+	Number  int                           `json:"number"`
+	Hash    string                        `json:"hash"`
+	Config  user_setting.CustomizedConfig `json:"config"`
+	SavedBy string                        `json:"saved_by"`
+	Note    string                        `json:"note,omitempty"`
+	SavedAt time.Time                     `json:"saved_at"`
+}
+
+type configHistory struct {
+	Revisions []ConfigRevision `json:"revisions"`
+}
+
+func (h *configHistory) head() *ConfigRevision {
+	if len(h.Revisions) == 0 {
+		return nil
+	}
+	return &h.Revisions[len(h.Revisions)-1]
+}
+
+// ConfigHistory returns the kept configs of userID, oldest first.
+func ConfigHistory(vault verification_persistence.VaultStore, userID string) ([]ConfigRevision, error) {
+	var h configHistory
+	if _, err := vault.Read(configHistoryCollection, userID, &h); err != nil {
+		return nil, err
+	}
+	return h.Revisions, nil
+}
+
+// ConfigRevisionOf returns revision n of userID. Zero or a negative n
+// counts back from the latest, so 0 is the current config and -1 the one
+// before it.
+func ConfigRevisionOf(vault verification_persistence.VaultStore, userID string, n int) (*ConfigRevision, error) {
+	revs, err := ConfigHistory(vault, userID)
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 && len(revs) > 0 {
+		n += revs[len(revs)-1].Number
+	}
+	for i := range revs {
+		if revs[i].Number == n {
+			return &revs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %d", ErrNoSuchRevision, n)
+}
+
+// CommitUserConfig validates cfg and makes it the config of userID,
+// recording a revision unless it hashes the same as the current one.
+// Subscribers are told about the change. The returned revision is the
+// current one either way.
+func CommitUserConfig(vault verification_persistence.VaultStore, userID, by, note string, cfg *user_setting.CustomizedConfig) (*ConfigRevision, error) {
+	cfg.Version = user_setting.ConfigVersion
+	cfg.WithDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var (
+		rev      ConfigRevision
+		previous *ConfigRevision
+		changed  bool
+	)
+	now := time.Now().UTC()
+
+	err := verification_persistence.Atomically(vault, func(tx verification_persistence.VaultTx) error {
+		p, err := LoadProfileTx(tx, userID)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			p = &Profile{UserID: userID}
+		}
+
+		var h configHistory
+		if _, err := tx.Read(configHistoryCollection, userID, &h); err != nil {
+			return err
+		}
+
+		// A config saved before history existed becomes revision 1, so
+		// the first change can be rolled back
+		if h.head() == nil && !p.Config.LastModified.IsZero() {
+			old := p.Config
+			old.Migrate()
+			h.Revisions = append(h.Revisions, ConfigRevision{
+				Number:  1,
+				Hash:    old.Hash(),
+				Config:  old,
+				SavedBy: userID,
+				Note:    "existing config",
+				SavedAt: p.Config.LastModified,
+			})
+		}
+
+		if head := h.head(); head != nil {
+			if head.Hash == cfg.Hash() {
+				rev = *head
+				return nil
+			}
+			prev := *head
+			previous = &prev
+		}
+
+		number := 1
+		if head := h.head(); head != nil {
+			number = head.Number + 1
+		}
+
+		cfg.LastModified = now
+		rev = ConfigRevision{
+			Number:  number,
+			Hash:    cfg.Hash(),
+			Config:  *cfg,
+			SavedBy: by,
+			Note:    note,
+			SavedAt: now,
+		}
+		h.Revisions = append(h.Revisions, rev)
+		if len(h.Revisions) > ConfigHistoryLimit {
+			h.Revisions = append([]ConfigRevision(nil), h.Revisions[len(h.Revisions)-ConfigHistoryLimit:]...)
+		}
+		changed = true
+
+		p.Config = *cfg
+		p.UpdatedAt = now
+		if err := tx.Write(profilesCollection, userID, p); err != nil {
+			return err
+		}
+		return tx.Write(configHistoryCollection, userID, &h)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		configSubscribers.changed(userID, previous, &rev)
+	}
+	return &rev, nil
+}
+
+// RollbackUserConfig makes revision n of userID current again. The
+// rollback is itself a new revision, so it can be undone the same way.
+func RollbackUserConfig(vault verification_persistence.VaultStore, userID, by string, n int) (*ConfigRevision, error) {
+	target, err := ConfigRevisionOf(vault, userID, n)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := target.Config
+	return CommitUserConfig(vault, userID, by, fmt.Sprintf("rollback to %d", target.Number), &cfg)
+}
+
+// ------------------------------------------------------------
+// Change notification
+// ------------------------------------------------------------
+
+type configNotifier struct {
+	mu   sync.Mutex
+	next int
+	subs map[int]func(user_setting.ConfigChanged)
+
+	// seen is the latest revision announced per user, so WatchConfig
+	// does not repeat changes committed in this process.
+	seen map[string]int
+}
+
+var configSubscribers = &configNotifier{
+	subs: map[int]func(user_setting.ConfigChanged){},
+	seen: map[string]int{},
+}
+
+// SubscribeConfig calls fn after every saved config change, including
+// changes made by another process once WatchConfig notices them. The
+// returned function cancels the subscription.
+func SubscribeConfig(fn func(user_setting.ConfigChanged)) (cancel func()) {
+	n := configSubscribers
+	n.mu.Lock()
+	id := n.next
+	n.next++
+	n.subs[id] = fn
+	n.mu.Unlock()
+
+	return func() {
+		n.mu.Lock()
+		delete(n.subs, id)
+		n.mu.Unlock()
+	}
+}
+
+func (n *configNotifier) changed(userID string, previous, rev *ConfigRevision) {
+	n.mu.Lock()
+	if rev.Number <= n.seen[userID] {
+		n.mu.Unlock()
+		return
+	}
+	n.seen[userID] = rev.Number
+	subs := make([]func(user_setting.ConfigChanged), 0, len(n.subs))
+	for _, fn := range n.subs {
+		subs = append(subs, fn)
+	}
+	n.mu.Unlock()
+
+	ev := user_setting.ConfigChanged{
+		UserID:   userID,
+		Revision: rev.Number,
+		Hash:     rev.Hash,
+		Config:   rev.Config,
+		At:       rev.SavedAt,
+	}
+	var before *user_setting.CustomizedConfig
+	if previous != nil {
+		ev.PreviousHash = previous.Hash
+		before = &previous.Config
+	}
+	ev.Changes = user_setting.DiffConfig(before, &rev.Config)
+
+	for _, fn := range subs {
+		fn(ev)
+	}
+}
+
+// WatchConfig polls the config history every interval until ctx ends and
+// announces revisions committed elsewhere, e.g. by `aios config set`
+// while the runtime is up. Revisions present at start are not announced.
+func WatchConfig(ctx context.Context, vault verification_persistence.VaultStore, interval time.Duration) {
+	poll := func(announce bool) {
+		users, err := vault.List(configHistoryCollection)
+		if err != nil {
+			return
+		}
+		for _, userID := range users {
+			revs, err := ConfigHistory(vault, userID)
+			if err != nil || len(revs) == 0 {
+				continue
+			}
+
+			head := &revs[len(revs)-1]
+			if !announce {
+				configSubscribers.mu.Lock()
+				if configSubscribers.seen[userID] < head.Number {
+					configSubscribers.seen[userID] = head.Number
+				}
+				configSubscribers.mu.Unlock()
+				continue
+			}
+
+			var previous *ConfigRevision
+			if len(revs) > 1 {
+				previous = &revs[len(revs)-2]
+			}
+			configSubscribers.changed(userID, previous, head)
+		}
+	}
+
+	poll(false)
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			poll(true)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// core/auth/config_history_test.go
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+func testVault(t *testing.T) verification_persistence.VaultStore {
+	t.Helper()
+	return &verification_persistence.IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{1}, 32)}
+}
+
+// commitSetting commits the current config of userID with key set to value.
+func commitSetting(t *testing.T, v verification_persistence.VaultStore, userID string, key user_setting.ConfigKey, value string) *ConfigRevision {
+	t.Helper()
+	cfg, err := LoadUserConfig(v, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg == nil {
+		cfg = DefaultCustomizedConfig()
+	}
+	if err := cfg.Set(key, value); err != nil {
+		t.Fatal(err)
+	}
+	rev, err := CommitUserConfig(v, userID, "admin", "set "+string(key), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rev
+}
+
+func TestCommitValidatesEnumeratedSettings(t *testing.T) {
+	v := testVault(t)
+
+	cfg := DefaultCustomizedConfig()
+	cfg.PowerMode = "turbo"
+	if _, err := CommitUserConfig(v, "cfg-validate", "admin", "", cfg); err == nil {
+		t.Fatal("power_mode turbo committed")
+	}
+	cfg = DefaultCustomizedConfig()
+	cfg.MainLang = "not a language"
+	if _, err := CommitUserConfig(v, "cfg-validate", "admin", "", cfg); err == nil {
+		t.Fatal("invalid language tag committed")
+	}
+	if revs, _ := ConfigHistory(v, "cfg-validate"); len(revs) != 0 {
+		t.Errorf("rejected configs recorded: %+v", revs)
+	}
+
+	if err := cfg.Set(user_setting.KeyPrivacyMode, "STRICT"); err != nil || cfg.PrivacyMode != user_setting.PrivacyStrict {
+		t.Errorf("Set is not case-insensitive: %q, %v", cfg.PrivacyMode, err)
+	}
+}
+
+func TestCommitNumbersRevisionsAndSkipsIdenticalConfigs(t *testing.T) {
+	v := testVault(t)
+	const user = "cfg-numbering"
+
+	var events []user_setting.ConfigChanged
+	cancel := SubscribeConfig(func(ev user_setting.ConfigChanged) {
+		if ev.UserID == user {
+			events = append(events, ev)
+		}
+	})
+	defer cancel()
+
+	first := commitSetting(t, v, user, user_setting.KeyPowerMode, "low")
+	second := commitSetting(t, v, user, user_setting.KeyPowerMode, "high")
+	if first.Number != 1 || second.Number != 2 || first.Hash == second.Hash {
+		t.Fatalf("revisions %d (%s), %d (%s)", first.Number, first.Hash, second.Number, second.Hash)
+	}
+
+	same := commitSetting(t, v, user, user_setting.KeyPowerMode, "high")
+	if same.Number != 2 || same.Hash != second.Hash {
+		t.Errorf("identical config = revision %d, want 2", same.Number)
+	}
+	if revs, _ := ConfigHistory(v, user); len(revs) != 2 {
+		t.Errorf("%d revisions after an identical commit, want 2", len(revs))
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("%d notifications, want 2", len(events))
+	}
+	if ev := events[1]; ev.Revision != 2 || ev.PreviousHash != first.Hash ||
+		len(ev.Changes) != 1 || ev.Changes[0] != (user_setting.ConfigChange{Key: user_setting.KeyPowerMode, From: "low", To: "high"}) {
+		t.Errorf("notification = %+v", ev)
+	}
+
+	current, err := LoadUserConfig(v, user)
+	if err != nil || current.PowerMode != user_setting.PowerHigh {
+		t.Errorf("profile config = %+v, %v", current, err)
+	}
+}
+
+func TestRollbackIsANewRevision(t *testing.T) {
+	v := testVault(t)
+	const user = "cfg-rollback"
+
+	first := commitSetting(t, v, user, user_setting.KeyUpdateMode, "manual")
+	commitSetting(t, v, user, user_setting.KeyUpdateMode, "auto")
+
+	rev, err := RollbackUserConfig(v, user, "admin", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.Number != 3 || rev.Hash != first.Hash || rev.Note != "rollback to 1" {
+		t.Fatalf("rollback = revision %d (%s, %q)", rev.Number, rev.Hash, rev.Note)
+	}
+
+	// -1 counts back from the latest: the rollback itself can be undone.
+	undo, err := RollbackUserConfig(v, user, "admin", -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if undo.Number != 4 || undo.Config.UpdateMode != user_setting.UpdateAuto {
+		t.Errorf("undo = revision %d with %s", undo.Number, undo.Config.UpdateMode)
+	}
+
+	if _, err := RollbackUserConfig(v, user, "admin", 42); !errors.Is(err, ErrNoSuchRevision) {
+		t.Errorf("rollback to a missing revision = %v, want %v", err, ErrNoSuchRevision)
+	}
+}
+
+func TestHistoryIsCappedAndKeepsCounting(t *testing.T) {
+	v := testVault(t)
+	const user = "cfg-cap"
+
+	modes := []string{"low", "balanced", "high"}
+	for i := 0; i < ConfigHistoryLimit+5; i++ {
+		commitSetting(t, v, user, user_setting.KeyPowerMode, modes[i%len(modes)])
+	}
+
+	revs, err := ConfigHistory(v, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != ConfigHistoryLimit {
+		t.Fatalf("%d revisions kept, want %d", len(revs), ConfigHistoryLimit)
+	}
+	if revs[0].Number != 6 || revs[len(revs)-1].Number != ConfigHistoryLimit+5 {
+		t.Errorf("kept revisions %d..%d", revs[0].Number, revs[len(revs)-1].Number)
+	}
+
+	head, err := ConfigRevisionOf(v, user, 0)
+	if err != nil || head.Number != ConfigHistoryLimit+5 {
+		t.Errorf("current revision = %+v, %v", head, err)
+	}
+	if _, err := ConfigRevisionOf(v, user, 1); !errors.Is(err, ErrNoSuchRevision) {
+		t.Errorf("dropped revision = %v, want %v", err, ErrNoSuchRevision)
+	}
+}
+
+func TestDiffConfig(t *testing.T) {
+	a := DefaultCustomizedConfig()
+	b := *a
+	if changes := user_setting.DiffConfig(a, &b); len(changes) != 0 {
+		t.Fatalf("identical configs differ: %+v", changes)
+	}
+
+	b.MainLang = "pt-BR"
+	b.PrivacyMode = user_setting.PrivacyOffline
+	changes := user_setting.DiffConfig(a, &b)
+	want := []user_setting.ConfigChange{
+		{Key: user_setting.KeyMainLang, From: a.MainLang, To: "pt-BR"},
+		{Key: user_setting.KeyPrivacyMode, From: string(a.PrivacyMode), To: "offline"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+
+	// Against nothing, every setting is a change.
+	if changes := user_setting.DiffConfig(nil, a); len(changes) != len(user_setting.ConfigKeys) {
+		t.Errorf("diff from nil = %+v", changes)
+	}
+}
@@ -0,0 +1,92 @@
+// core/auth/credential_provider.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+var (
+	// ErrNoCredentials ends an auth flow: the provider has nothing more to
+	// offer (input closed, script exhausted, request cancelled).
+	ErrNoCredentials = errors.New("no_credentials")
+
+	// ErrRegistrationDeclined is returned by Registration when the user does
+	// not want to create an account.
+	ErrRegistrationDeclined = errors.New("registration_declined")
+)
+
+// MaxLoginAttempts bounds LoginOrSignUp before it gives up.
+const MaxLoginAttempts = 5
+
+// Registration is what a provider collects to create an account.
+type Registration struct {
+	UserID   string
+	Password string
+	Entity   internal_environment.EntityKind
+}
+
+// Factors a step-up prompt can ask for.
+const (
+	FactorPassword = "password"
+	FactorOTP      = "otp"
+)
+
+// StepUpRequest asks the signed-in user to prove presence again before a
+// privileged action or to unlock an idle session.
+type StepUpRequest struct {
+	UserID     string
+	Permission user_setting.PermissionKey // empty when unlocking
+	Factor     string                     // FactorPassword or FactorOTP
+}
+
+// Prompt is a human-readable description of the request.
+func (r StepUpRequest) Prompt() string {
+	what := "Session locked"
+	if r.Permission != "" {
+		what = fmt.Sprintf("%q requires re-authentication", r.Permission)
+	}
+	if r.Factor == FactorOTP {
+		return what + "; enter authenticator code for " + r.UserID
+	}
+	return what + "; enter password for " + r.UserID
+}
+
+// CredentialProvider is how AuthManager talks to whoever is logging in.
+// Each UI adapter supplies its own; AuthManager never reads a terminal
+// itself.
+type CredentialProvider interface {
+	// Credentials returns the next login attempt.
+	Credentials(ctx context.Context) (*Credentials, error)
+
+	// Registration is called when userID does not exist.
+	Registration(ctx context.Context, userID string) (*Registration, error)
+
+	// SecondFactor asks for a one-time code.
+	SecondFactor(ctx context.Context, prompt string) (string, error)
+
+	// Reauthenticate asks the signed-in user for req.Factor again.
+	Reauthenticate(ctx context.Context, req StepUpRequest) (string, error)
+
+	// UserConfig lets the user adjust defaults on first login. Returning
+	// defaults unchanged is always acceptable.
+	UserConfig(ctx context.Context, defaults *user_setting.CustomizedConfig) (*user_setting.CustomizedConfig, error)
+
+	// Notify shows information the user must see (enrollment URIs,
+	// recovery codes, lockout notices).
+	Notify(msg string)
+
+	// Complete reports the outcome of the last attempt; nil means success.
+	Complete(err error)
+}
+
+func (am *AuthManager) provider() CredentialProvider {
+	if am.Provider == nil {
+		am.Provider = DefaultProvider(NewTerminalProvider())
+	}
+	return am.Provider
+}
@@ -0,0 +1,730 @@
+// core/auth/credential_providers.go
+package auth
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// ------------------------------------------------------------
+// Terminal
+// ------------------------------------------------------------
+
+// TerminalProvider prompts on Out and reads answers from In.
+type TerminalProvider struct {
+	In  io.Reader
+	Out io.Writer
+
+	once   sync.Once
+	reader *bufio.Reader
+}
+
+func NewTerminalProvider() *TerminalProvider {
+	return &TerminalProvider{In: os.Stdin, Out: os.Stdout}
+}
+
+func (t *TerminalProvider) ask(prompt string) (string, error) {
+	t.once.Do(func() { t.reader = bufio.NewReader(t.In) })
+
+	fmt.Fprint(t.Out, prompt)
+	line, err := t.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", ErrNoCredentials
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func (t *TerminalProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	userID, err := t.ask("[AUTH] Enter User ID (or \"guest\"): ")
+	if err != nil {
+		return nil, err
+	}
+	if userID == GuestUserID {
+		return &Credentials{UserID: userID, Source: "terminal"}, nil
+	}
+	password, err := t.ask("[AUTH] Enter Password (empty to use your paired phone): ")
+	if err != nil {
+		return nil, err
+	}
+	return &Credentials{UserID: userID, Password: password, Source: "terminal", Companion: password == ""}, nil
+}
+
+func (t *TerminalProvider) Registration(ctx context.Context, userID string) (*Registration, error) {
+	answer, err := t.ask(fmt.Sprintf("[AUTH] User %q not found. Register? (y/N): ", userID))
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+		return nil, ErrRegistrationDeclined
+	}
+
+	fmt.Fprintln(t.Out, "=== User Registration ===")
+
+	password, err := t.ask("Enter Password: ")
+	if err != nil {
+		return nil, err
+	}
+
+	entityStr, err := t.ask("Select Entity Type (personal / organization / tester): ")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Registration{
+		UserID:   userID,
+		Password: password,
+		Entity:   ParseEntityKind(entityStr),
+	}, nil
+}
+
+func (t *TerminalProvider) SecondFactor(ctx context.Context, prompt string) (string, error) {
+	return t.ask("[AUTH] " + prompt + ": ")
+}
+
+func (t *TerminalProvider) Reauthenticate(ctx context.Context, req StepUpRequest) (string, error) {
+	return t.ask("[AUTH] " + req.Prompt() + ": ")
+}
+
+func (t *TerminalProvider) UserConfig(ctx context.Context, cfg *user_setting.CustomizedConfig) (*user_setting.CustomizedConfig, error) {
+	fmt.Fprintln(t.Out, "\n=== User Configuration ===")
+	fmt.Fprintln(t.Out, "Press ENTER to keep the current value")
+
+	for _, key := range user_setting.ConfigKeys {
+		current, _ := cfg.Get(key)
+		prompt := string(key)
+		if choices, ok := user_setting.ConfigChoices[key]; ok && len(choices) <= 4 {
+			prompt += " (" + strings.Join(choices, "/") + ")"
+		}
+
+		for {
+			v, err := t.ask(fmt.Sprintf("%s [%s]: ", prompt, current))
+			if err != nil {
+				return cfg, nil
+			}
+			if v == "" {
+				break
+			}
+			if err := cfg.Set(key, v); err != nil {
+				fmt.Fprintln(t.Out, "[CONFIG]", err)
+				continue
+			}
+			break
+		}
+	}
+
+	fmt.Fprintln(t.Out, "[CONFIG] Completed")
+	return cfg, nil
+}
+
+func (t *TerminalProvider) Notify(msg string) {
+	fmt.Fprintln(t.Out, "[AUTH]", msg)
+}
+
+func (t *TerminalProvider) Complete(err error) {
+	if err != nil {
+		fmt.Fprintln(t.Out, "[AUTH] Login failed:", err)
+	}
+}
+
+// ------------------------------------------------------------
+// Environment / file (headless units, service managers)
+// ------------------------------------------------------------
+
+// EnvProvider reads a single set of credentials from the environment:
+// AIOS_USER plus AIOS_PASSWORD or AIOS_PASSWORD_FILE, and optionally
+// AIOS_OTP. It never registers users and offers one attempt only.
+type EnvProvider struct {
+	mu   sync.Mutex
+	used bool
+}
+
+// EnvCredentialsPresent reports whether EnvProvider has anything to offer.
+func EnvCredentialsPresent() bool {
+	return os.Getenv("AIOS_USER") != "" &&
+		(os.Getenv("AIOS_PASSWORD") != "" || os.Getenv("AIOS_PASSWORD_FILE") != "")
+}
+
+func (e *EnvProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.used || !EnvCredentialsPresent() {
+		return nil, ErrNoCredentials
+	}
+	e.used = true
+
+	password, err := envPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credentials{UserID: os.Getenv("AIOS_USER"), Password: password, Source: "env"}, nil
+}
+
+func envPassword() (string, error) {
+	if path := os.Getenv("AIOS_PASSWORD_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read password file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	return os.Getenv("AIOS_PASSWORD"), nil
+}
+
+func (e *EnvProvider) Registration(ctx context.Context, userID string) (*Registration, error) {
+	return nil, ErrRegistrationDeclined
+}
+
+func (e *EnvProvider) SecondFactor(ctx context.Context, prompt string) (string, error) {
+	if code := os.Getenv("AIOS_OTP"); code != "" {
+		return code, nil
+	}
+	return "", ErrNoCredentials
+}
+
+// Reauthenticate answers from the same variables as the login, so
+// headless units step up without a prompt.
+func (e *EnvProvider) Reauthenticate(ctx context.Context, req StepUpRequest) (string, error) {
+	var answer string
+	if req.Factor == FactorOTP {
+		answer = os.Getenv("AIOS_OTP")
+	} else {
+		pw, err := envPassword()
+		if err != nil {
+			return "", err
+		}
+		answer = pw
+	}
+
+	if answer == "" || os.Getenv("AIOS_USER") != req.UserID {
+		return "", ErrNoCredentials
+	}
+	return answer, nil
+}
+
+func (e *EnvProvider) UserConfig(ctx context.Context, cfg *user_setting.CustomizedConfig) (*user_setting.CustomizedConfig, error) {
+	return cfg, nil
+}
+
+func (e *EnvProvider) Notify(msg string) {
+	fmt.Fprintln(os.Stderr, "[AUTH]", msg)
+}
+
+func (e *EnvProvider) Complete(err error) {}
+
+// DefaultProvider returns an EnvProvider when credentials are present in
+// the environment, so headless units never block on a prompt, and
+// fallback otherwise.
+func DefaultProvider(fallback CredentialProvider) CredentialProvider {
+	if EnvCredentialsPresent() {
+		return &EnvProvider{}
+	}
+	return fallback
+}
+
+// ------------------------------------------------------------
+// HTTP login (GUI front-ends, companion apps on the local network)
+// ------------------------------------------------------------
+
+// HTTPLoginRequest is the JSON body accepted by HTTPProvider.Handler.
+type HTTPLoginRequest struct {
+	UserID   string `json:"user_id"`
+	Password string `json:"password"`
+	OTP      string `json:"otp,omitempty"`
+
+	// Companion asks the paired companion app to confirm the login in
+	// place of Password.
+	Companion bool `json:"companion,omitempty"`
+}
+
+type httpAttempt struct {
+	req    HTTPLoginRequest
+	source string
+	result chan error
+}
+
+// HTTPProvider receives credentials from POST requests. Each request blocks
+// until the auth flow reports the outcome through Complete, which becomes
+// the HTTP response.
+type HTTPProvider struct {
+	attempts chan *httpAttempt
+
+	mu      sync.Mutex
+	current *httpAttempt
+	notices []string
+}
+
+func NewHTTPProvider() *HTTPProvider {
+	return &HTTPProvider{attempts: make(chan *httpAttempt)}
+}
+
+func (h *HTTPProvider) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req HTTPLoginRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, 16<<10)).Decode(&req); err != nil {
+			http.Error(w, "malformed login request", http.StatusBadRequest)
+			return
+		}
+
+		source, _, splitErr := net.SplitHostPort(r.RemoteAddr)
+		if splitErr != nil {
+			source = r.RemoteAddr
+		}
+
+		attempt := &httpAttempt{req: req, source: "http:" + source, result: make(chan error, 1)}
+
+		select {
+		case h.attempts <- attempt:
+		case <-r.Context().Done():
+			return
+		}
+
+		var err error
+		select {
+		case err = <-attempt.result:
+		case <-r.Context().Done():
+			return
+		}
+
+		h.mu.Lock()
+		notices := h.notices
+		h.notices = nil
+		h.mu.Unlock()
+
+		resp := map[string]interface{}{"ok": err == nil, "messages": notices}
+		if err != nil {
+			resp["error"] = err.Error()
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+func (h *HTTPProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	select {
+	case a := <-h.attempts:
+		h.mu.Lock()
+		h.current = a
+		h.mu.Unlock()
+		return &Credentials{UserID: a.req.UserID, Password: a.req.Password, Source: a.source, Companion: a.req.Companion}, nil
+	case <-ctx.Done():
+		return nil, ErrNoCredentials
+	}
+}
+
+func (h *HTTPProvider) currentRequest() *HTTPLoginRequest {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.current == nil {
+		return nil
+	}
+	return &h.current.req
+}
+
+// Registration always declines: anyone who can reach the port could
+// otherwise create accounts. Users are added with `aios user add`.
+func (h *HTTPProvider) Registration(ctx context.Context, userID string) (*Registration, error) {
+	return nil, ErrRegistrationDeclined
+}
+
+func (h *HTTPProvider) SecondFactor(ctx context.Context, prompt string) (string, error) {
+	req := h.currentRequest()
+	if req == nil || req.OTP == "" {
+		return "", errors.New("otp required")
+	}
+	return req.OTP, nil
+}
+
+// Reauthenticate waits for the next POST and reads the password or otp
+// field from it. The response carries the outcome passed to Complete.
+func (h *HTTPProvider) Reauthenticate(ctx context.Context, req StepUpRequest) (string, error) {
+	h.Notify(req.Prompt())
+
+	creds, err := h.Credentials(ctx)
+	if err != nil {
+		return "", err
+	}
+	if creds.UserID != req.UserID {
+		return "", errors.New("re-authentication must come from the signed-in user")
+	}
+
+	if req.Factor == FactorOTP {
+		if r := h.currentRequest(); r != nil {
+			return r.OTP, nil
+		}
+		return "", errors.New("otp required")
+	}
+	return creds.Password, nil
+}
+
+func (h *HTTPProvider) UserConfig(ctx context.Context, cfg *user_setting.CustomizedConfig) (*user_setting.CustomizedConfig, error) {
+	return cfg, nil
+}
+
+func (h *HTTPProvider) Notify(msg string) {
+	h.mu.Lock()
+	h.notices = append(h.notices, msg)
+	h.mu.Unlock()
+}
+
+func (h *HTTPProvider) Complete(err error) {
+	h.mu.Lock()
+	a := h.current
+	h.current = nil
+	h.mu.Unlock()
+
+	if a != nil {
+		a.result <- err
+	}
+}
+
+// ------------------------------------------------------------
+// TUI (full-screen terminal form)
+// ------------------------------------------------------------
+
+// TUIProvider runs the auth flow as a full-screen form. Every field
+// redraws the screen with the title, the notices collected so far and the
+// outcome of the last attempt, so nothing scrolls away mid-login.
+type TUIProvider struct {
+	In    io.Reader
+	Out   io.Writer
+	Title string
+
+	once    sync.Once
+	reader  *bufio.Reader
+	notices []string
+	status  string
+}
+
+func NewTUIProvider() *TUIProvider {
+	return &TUIProvider{In: os.Stdin, Out: os.Stdout, Title: "AIOS sign-in"}
+}
+
+func (t *TUIProvider) field(label string) (string, error) {
+	t.once.Do(func() { t.reader = bufio.NewReader(t.In) })
+
+	t.draw()
+	fmt.Fprintf(t.Out, "  %s: ", label)
+	line, err := t.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", ErrNoCredentials
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func (t *TUIProvider) draw() {
+	bar := strings.Repeat("─", len(t.Title)+4)
+	fmt.Fprintf(t.Out, "\x1b[2J\x1b[H┌%s┐\n│  %s  │\n└%s┘\n\n", bar, t.Title, bar)
+	for _, n := range t.notices {
+		fmt.Fprintln(t.Out, "  "+n)
+	}
+	if t.status != "" {
+		fmt.Fprintln(t.Out, "  "+t.status)
+	}
+	if len(t.notices) > 0 || t.status != "" {
+		fmt.Fprintln(t.Out)
+	}
+}
+
+func (t *TUIProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	userID, err := t.field("User ID (or \"guest\")")
+	if err != nil {
+		return nil, err
+	}
+	if userID == GuestUserID {
+		return &Credentials{UserID: userID, Source: "tui"}, nil
+	}
+	password, err := t.field("Password (empty to confirm on your paired phone)")
+	if err != nil {
+		return nil, err
+	}
+	return &Credentials{UserID: userID, Password: password, Source: "tui", Companion: password == ""}, nil
+}
+
+func (t *TUIProvider) Registration(ctx context.Context, userID string) (*Registration, error) {
+	t.status = fmt.Sprintf("User %q not found.", userID)
+	answer, err := t.field("Create this account? (y/N)")
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+		return nil, ErrRegistrationDeclined
+	}
+
+	t.status = "New account " + userID
+	password, err := t.field("Password")
+	if err != nil {
+		return nil, err
+	}
+	again, err := t.field("Repeat password")
+	if err != nil {
+		return nil, err
+	}
+	if again != password {
+		t.status = "Passwords did not match."
+		return nil, ErrRegistrationDeclined
+	}
+
+	entity, err := t.field("Entity type (personal / organization / tester)")
+	if err != nil {
+		return nil, err
+	}
+	t.status = ""
+
+	return &Registration{UserID: userID, Password: password, Entity: ParseEntityKind(entity)}, nil
+}
+
+func (t *TUIProvider) SecondFactor(ctx context.Context, prompt string) (string, error) {
+	return t.field(prompt)
+}
+
+func (t *TUIProvider) Reauthenticate(ctx context.Context, req StepUpRequest) (string, error) {
+	t.status = req.Prompt()
+	defer func() { t.status = "" }()
+	return t.field(req.Factor)
+}
+
+func (t *TUIProvider) UserConfig(ctx context.Context, cfg *user_setting.CustomizedConfig) (*user_setting.CustomizedConfig, error) {
+	t.status = "User configuration: press ENTER to keep the current value"
+	defer func() { t.status = "" }()
+
+	for _, key := range user_setting.ConfigKeys {
+		current, _ := cfg.Get(key)
+		label := string(key)
+		if choices, ok := user_setting.ConfigChoices[key]; ok && len(choices) <= 4 {
+			label += " (" + strings.Join(choices, "/") + ")"
+		}
+
+		for {
+			v, err := t.field(fmt.Sprintf("%s [%s]", label, current))
+			if err != nil {
+				return cfg, nil
+			}
+			if v == "" {
+				break
+			}
+			if err := cfg.Set(key, v); err != nil {
+				t.status = err.Error()
+				continue
+			}
+			t.status = "User configuration: press ENTER to keep the current value"
+			break
+		}
+	}
+	return cfg, nil
+}
+
+func (t *TUIProvider) Notify(msg string) {
+	t.notices = append(t.notices, msg)
+}
+
+// Complete shows the outcome. Notices stay on screen until the attempt
+// they belong to has finished.
+func (t *TUIProvider) Complete(err error) {
+	if err != nil {
+		t.status = "Login failed: " + err.Error()
+	} else {
+		t.status = "Signed in."
+	}
+	t.draw()
+	t.notices = nil
+}
+
+// ------------------------------------------------------------
+// Voice
+// ------------------------------------------------------------
+
+// VoiceIO is the speech front-end a VoiceProvider talks through: Say
+// speaks, Hear returns the next transcribed utterance.
+type VoiceIO interface {
+	Say(msg string)
+	Hear(ctx context.Context) (string, error)
+}
+
+// VoiceProvider logs in by speech. Secrets are never spoken: the user
+// names the account and confirms on the paired phone, and only one-time
+// codes, which are useless once used, are read out. It does not register
+// users or take password step-ups.
+type VoiceProvider struct {
+	IO VoiceIO
+}
+
+var spokenDigits = map[string]string{
+	"zero": "0", "oh": "0", "one": "1", "two": "2", "three": "3", "four": "4",
+	"five": "5", "six": "6", "seven": "7", "eight": "8", "nine": "9",
+}
+
+// digits turns "four 2 seven ..." into "427...". Anything that is not a
+// digit or a digit word is dropped.
+func digits(utterance string) string {
+	var b strings.Builder
+	for _, w := range strings.Fields(strings.ToLower(utterance)) {
+		if d, ok := spokenDigits[w]; ok {
+			b.WriteString(d)
+			continue
+		}
+		for _, r := range w {
+			if r >= '0' && r <= '9' {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+func (v *VoiceProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	v.IO.Say("Who is signing in? Say your user name, or guest.")
+	heard, err := v.IO.Hear(ctx)
+	if err != nil {
+		return nil, ErrNoCredentials
+	}
+
+	userID := strings.ToLower(strings.Join(strings.Fields(heard), ""))
+	if userID == "" {
+		return nil, ErrNoCredentials
+	}
+	if userID == GuestUserID {
+		return &Credentials{UserID: userID, Source: "voice"}, nil
+	}
+
+	v.IO.Say("Confirm the sign-in for " + userID + " on your paired phone.")
+	return &Credentials{UserID: userID, Source: "voice", Companion: true}, nil
+}
+
+func (v *VoiceProvider) Registration(ctx context.Context, userID string) (*Registration, error) {
+	v.IO.Say("There is no account named " + userID + ".")
+	return nil, ErrRegistrationDeclined
+}
+
+func (v *VoiceProvider) SecondFactor(ctx context.Context, prompt string) (string, error) {
+	v.IO.Say(prompt + ". Read the digits one by one.")
+	heard, err := v.IO.Hear(ctx)
+	if err != nil {
+		return "", ErrNoCredentials
+	}
+	return digits(heard), nil
+}
+
+func (v *VoiceProvider) Reauthenticate(ctx context.Context, req StepUpRequest) (string, error) {
+	if req.Factor != FactorOTP {
+		v.IO.Say("Passwords are not taken by voice. Use another interface to continue.")
+		return "", ErrNoCredentials
+	}
+	return v.SecondFactor(ctx, req.Prompt())
+}
+
+func (v *VoiceProvider) UserConfig(ctx context.Context, cfg *user_setting.CustomizedConfig) (*user_setting.CustomizedConfig, error) {
+	return cfg, nil
+}
+
+// Notify speaks the first line of msg only. The lines below it carry
+// enrollment URIs and recovery codes, which must not be read aloud, so
+// two-factor enrollment has to happen on another interface.
+func (v *VoiceProvider) Notify(msg string) {
+	headline, _, _ := strings.Cut(msg, "\n")
+	v.IO.Say(strings.TrimSuffix(headline, ":"))
+}
+
+func (v *VoiceProvider) Complete(err error) {
+	if err != nil {
+		v.IO.Say("Sign-in failed.")
+	}
+}
+
+// ------------------------------------------------------------
+// Scripted (tests, simulators)
+// ------------------------------------------------------------
+
+// ScriptedProvider replays fixed answers. Results records every Complete
+// call so a test can assert on the outcome of each attempt.
+type ScriptedProvider struct {
+	Logins        []Credentials
+	Registrations map[string]Registration
+	Codes         []string
+	StepUps       []string
+	Config        *user_setting.CustomizedConfig
+
+	Notices []string
+	Results []error
+}
+
+func (s *ScriptedProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	if len(s.Logins) == 0 {
+		return nil, ErrNoCredentials
+	}
+	c := s.Logins[0]
+	s.Logins = s.Logins[1:]
+	return &c, nil
+}
+
+func (s *ScriptedProvider) Registration(ctx context.Context, userID string) (*Registration, error) {
+	r, ok := s.Registrations[userID]
+	if !ok {
+		return nil, ErrRegistrationDeclined
+	}
+	return &r, nil
+}
+
+func (s *ScriptedProvider) SecondFactor(ctx context.Context, prompt string) (string, error) {
+	if len(s.Codes) == 0 {
+		return "", ErrNoCredentials
+	}
+	c := s.Codes[0]
+	s.Codes = s.Codes[1:]
+	return c, nil
+}
+
+func (s *ScriptedProvider) Reauthenticate(ctx context.Context, req StepUpRequest) (string, error) {
+	if len(s.StepUps) == 0 {
+		return "", ErrNoCredentials
+	}
+	a := s.StepUps[0]
+	s.StepUps = s.StepUps[1:]
+	return a, nil
+}
+
+func (s *ScriptedProvider) UserConfig(ctx context.Context, cfg *user_setting.CustomizedConfig) (*user_setting.CustomizedConfig, error) {
+	if s.Config != nil {
+		return s.Config, nil
+	}
+	return cfg, nil
+}
+
+func (s *ScriptedProvider) Notify(msg string) {
+	s.Notices = append(s.Notices, msg)
+}
+
+func (s *ScriptedProvider) Complete(err error) {
+	s.Results = append(s.Results, err)
+}
+
+// ParseEntityKind maps the names used in prompts and requests to an
+// EntityKind, defaulting to personal.
+func ParseEntityKind(s string) internal_environment.EntityKind {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "organization":
+		return internal_environment.EntityOrganization
+	case "tester":
+		return internal_environment.EntityTester
+	default:
+		return internal_environment.EntityPersonal
+	}
+}
@@ -0,0 +1,107 @@
+// core/auth/credential_providers_test.go
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type scriptedVoice struct {
+	heard  []string
+	spoken []string
+}
+
+func (s *scriptedVoice) Say(msg string) { s.spoken = append(s.spoken, msg) }
+
+func (s *scriptedVoice) Hear(ctx context.Context) (string, error) {
+	if len(s.heard) == 0 {
+		return "", ErrNoCredentials
+	}
+	h := s.heard[0]
+	s.heard = s.heard[1:]
+	return h, nil
+}
+
+func TestHTTPProviderNeverRegisters(t *testing.T) {
+	h := NewHTTPProvider()
+	h.current = &httpAttempt{req: HTTPLoginRequest{UserID: "mallory", Password: "pw"}}
+
+	if _, err := h.Registration(context.Background(), "mallory"); !errors.Is(err, ErrRegistrationDeclined) {
+		t.Fatalf("Registration = %v, want %v", err, ErrRegistrationDeclined)
+	}
+}
+
+func TestVoiceLoginConfirmsOnThePairedPhone(t *testing.T) {
+	io := &scriptedVoice{heard: []string{"Alice"}}
+	v := &VoiceProvider{IO: io}
+
+	creds, err := v.Credentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.UserID != "alice" || creds.Password != "" || !creds.Companion {
+		t.Fatalf("credentials = %+v, want a companion login for alice", creds)
+	}
+
+	if _, err := v.Registration(context.Background(), "bob"); !errors.Is(err, ErrRegistrationDeclined) {
+		t.Errorf("Registration = %v, want %v", err, ErrRegistrationDeclined)
+	}
+	if _, err := v.Reauthenticate(context.Background(), StepUpRequest{UserID: "alice", Factor: FactorPassword}); err == nil {
+		t.Error("password step-up accepted by voice")
+	}
+}
+
+func TestVoiceReadsCodesAndKeepsSecretsQuiet(t *testing.T) {
+	io := &scriptedVoice{heard: []string{"four two 7 oh one nine"}}
+	v := &VoiceProvider{IO: io}
+
+	code, err := v.SecondFactor(context.Background(), "Enter the current code")
+	if err != nil || code != "427019" {
+		t.Fatalf("SecondFactor = %q, %v", code, err)
+	}
+
+	v.Notify("Recovery codes (store them safely, each works once):\n  abcde-fghjk")
+	last := io.spoken[len(io.spoken)-1]
+	if strings.Contains(last, "abcde") {
+		t.Fatalf("recovery code spoken: %q", last)
+	}
+}
+
+func TestTUIRegistrationNeedsMatchingPasswords(t *testing.T) {
+	var out bytes.Buffer
+	tui := &TUIProvider{In: strings.NewReader("y\npw-one\npw-two\n"), Out: &out, Title: "test"}
+
+	if _, err := tui.Registration(context.Background(), "bob"); !errors.Is(err, ErrRegistrationDeclined) {
+		t.Fatalf("mismatched passwords = %v, want %v", err, ErrRegistrationDeclined)
+	}
+
+	tui = &TUIProvider{In: strings.NewReader("y\npw\npw\ntester\n"), Out: &out, Title: "test"}
+	reg, err := tui.Registration(context.Background(), "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reg.UserID != "bob" || reg.Password != "pw" || reg.Entity != ParseEntityKind("tester") {
+		t.Fatalf("registration = %+v", reg)
+	}
+}
+
+func TestTUIKeepsNoticesOnScreenUntilComplete(t *testing.T) {
+	var out bytes.Buffer
+	tui := &TUIProvider{In: strings.NewReader("123456\n"), Out: &out, Title: "test"}
+
+	tui.Notify("Two-factor enrollment required")
+	if _, err := tui.SecondFactor(context.Background(), "Code"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Two-factor enrollment required") {
+		t.Fatal("notice not drawn with the next field")
+	}
+
+	tui.Complete(nil)
+	if len(tui.notices) != 0 {
+		t.Error("notices kept after the attempt completed")
+	}
+}
@@ -0,0 +1,104 @@
+// core/auth/guest_session.go
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	security_sandbox "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/sandbox"
+	security_scratch "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/scratch"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// GuestUserID entered at any login prompt starts a guest session instead
+// of a password login. It cannot be registered as an account.
+const GuestUserID = "guest"
+
+var ErrGuestDisabled = errors.New("guest_login_disabled")
+
+// GuestLogin starts a time-boxed guest session. No vault identity is
+// involved; the platform's guest path still runs.
+func (am *AuthManager) GuestLogin() (*user_setting.UserSession, error) {
+	if am.DisableGuest {
+		return nil, ErrGuestDisabled
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+
+	am.UserID = "guest-" + hex.EncodeToString(suffix)
+	am.Identity = nil
+	am.detectEntityAndTier()
+
+	return am.platformLoginFlow()
+}
+
+// createGuestSession replaces the regular permission derivation with
+// GuestPermissions and gives the session a scratch area instead of a
+// stored configuration.
+func (am *AuthManager) createGuestSession(service user_setting.ServiceType) (*user_setting.UserSession, error) {
+	builder := user_setting.SessionBuilder{}
+
+	claims := builder.BuildGuest(&user_setting.BuildContext{
+		UserID:   am.UserID,
+		Platform: am.Platform,
+		Entity:   internal_environment.EntityStranger,
+		Tier:     user_setting.TierUnknown,
+		Service:  service,
+	}, am.GuestLifetime)
+
+	scratch, err := security_scratch.Open(claims.SessionID, claims.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("guest scratch area: %w", err)
+	}
+
+	session := &user_setting.UserSession{
+		Identity:   &user_setting.UserIdentity{Username: am.UserID},
+		Config:     DefaultCustomizedConfig().UserCoreConfig,
+		Claims:     *claims,
+		ScratchDir: scratch.Path(),
+	}
+
+	if err := am.issueToken(session); err != nil {
+		_ = scratch.Wipe()
+		return nil, err
+	}
+
+	if err := am.initializeRuntime(session); err != nil {
+		_ = scratch.Wipe()
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// endSession revokes the token of session, stops tracking its activity
+// and logs it out.
+func (am *AuthManager) endSession(session *user_setting.UserSession, reason string) error {
+	if tokens, err := am.tokens(); err == nil {
+		if err := tokens.RevokeSession(session.Claims.SessionID, reason); err != nil {
+			return err
+		}
+	}
+	if am.Activity != nil {
+		am.Activity.End(session.Claims.SessionID)
+	}
+	return am.Logout(session)
+}
+
+// Logout ends session. A guest's scratch area is wiped and a sandbox
+// session's shadow vault discarded.
+func (am *AuthManager) Logout(session *user_setting.UserSession) error {
+	if session.IsSandbox() {
+		security_sandbox.ExitSession(session.Claims.SessionID)
+	}
+	if session.IsGuest() {
+		return security_scratch.Wipe(session.Claims.SessionID)
+	}
+	return nil
+}
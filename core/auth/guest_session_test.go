@@ -0,0 +1,48 @@
+// core/auth/guest_session_test.go
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	security_scratch "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/scratch"
+)
+
+func TestGuestScratchIsWipedOnLogoutAndExpiry(t *testing.T) {
+	saved := security_scratch.Root
+	security_scratch.Root = filepath.Join(t.TempDir(), "aios-guest")
+	defer func() { security_scratch.Root = saved }()
+
+	am := testManager(t, &ScriptedProvider{Logins: []Credentials{{UserID: GuestUserID}, {UserID: GuestUserID}}})
+	am.GuestLifetime = time.Minute
+
+	first, err := am.LoginOrSignUp(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := am.LoginOrSignUp(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ScratchDir == "" || first.ScratchDir == second.ScratchDir {
+		t.Fatalf("scratch dirs %q and %q", first.ScratchDir, second.ScratchDir)
+	}
+
+	if err := am.Logout(first); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(first.ScratchDir); !os.IsNotExist(err) {
+		t.Errorf("scratch survived logout: %v", err)
+	}
+
+	// The second guest never logs out.
+	if _, err := security_scratch.Sweep(second.Claims.ExpiresAt.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(second.ScratchDir); !os.IsNotExist(err) {
+		t.Errorf("scratch survived expiry: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+// core/auth/login_flow_test.go
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+)
+
+// testManager is a remote AuthManager on a fresh vault, so logins stop at
+// the session token and never start a runtime.
+func testManager(t *testing.T, p CredentialProvider) *AuthManager {
+	t.Helper()
+	return &AuthManager{
+		Vault:    &verification_persistence.IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{1}, 32)},
+		Provider: p,
+		Platform: internal_environment.PlatformComputer,
+		Remote:   true,
+	}
+}
+
+func errText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestLoginRetriesUntilTheRightPassword(t *testing.T) {
+	p := &ScriptedProvider{Logins: []Credentials{
+		{UserID: "alice", Password: "wrong", Source: "test"},
+		{UserID: "alice", Password: "correct horse", Source: "test"},
+	}}
+	am := testManager(t, p)
+	if err := am.RegisterUser("alice", "correct horse", internal_environment.EntityPersonal); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := am.LoginOrSignUp(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Claims.UserID != "alice" || session.Token == "" {
+		t.Fatalf("session = %+v", session.Claims)
+	}
+
+	if len(p.Results) != 2 || errText(p.Results[0]) != "invalid credentials" || p.Results[1] != nil {
+		t.Fatalf("results = %v, want [invalid credentials <nil>]", p.Results)
+	}
+
+	claims, err := am.Tokens.Verify(session.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.SessionID != session.Claims.SessionID {
+		t.Errorf("token names session %s, session is %s", claims.SessionID, session.Claims.SessionID)
+	}
+}
+
+func TestUnknownUserRegistersThenLogsIn(t *testing.T) {
+	p := &ScriptedProvider{
+		Logins:        []Credentials{{UserID: "bob", Password: "s3cret-pass"}},
+		Registrations: map[string]Registration{"bob": {UserID: "bob", Entity: internal_environment.EntityPersonal}},
+	}
+	am := testManager(t, p)
+
+	session, err := am.LoginOrSignUp(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Claims.UserID != "bob" {
+		t.Fatalf("logged in as %s", session.Claims.UserID)
+	}
+
+	var stored internal_environment.MachineIdentity
+	if found, err := am.Vault.Read("users", "bob", &stored); err != nil || !found {
+		t.Fatalf("account not stored: %v", err)
+	}
+	if stored.PasswordHash == "" || stored.PasswordHash == "s3cret-pass" {
+		t.Fatalf("password stored as %q", stored.PasswordHash)
+	}
+}
+
+func TestDeclinedRegistrationEndsWithTheProvider(t *testing.T) {
+	p := &ScriptedProvider{Logins: []Credentials{{UserID: "carol", Password: "pw"}}}
+	am := testManager(t, p)
+
+	if _, err := am.LoginOrSignUp(context.Background()); !errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("err = %v, want %v", err, ErrNoCredentials)
+	}
+	if exists, _ := am.Vault.Exists("users", "carol"); exists {
+		t.Fatal("declined registration created an account")
+	}
+	if len(p.Results) != 1 || errText(p.Results[0]) != "invalid credentials" {
+		t.Fatalf("results = %v", p.Results)
+	}
+}
+
+func TestDisabledAccountCannotLogIn(t *testing.T) {
+	p := &ScriptedProvider{Logins: []Credentials{{UserID: "dave", Password: "pw-dave"}}}
+	am := testManager(t, p)
+	if err := am.RegisterUser("dave", "pw-dave", internal_environment.EntityPersonal); err != nil {
+		t.Fatal(err)
+	}
+
+	var id internal_environment.MachineIdentity
+	if _, err := am.Vault.Read("users", "dave", &id); err != nil {
+		t.Fatal(err)
+	}
+	id.Disabled = true
+	if err := am.Vault.Write("users", "dave", &id); err != nil {
+		t.Fatal(err)
+	}
+
+	if session, err := am.LoginOrSignUp(context.Background()); err == nil {
+		t.Fatalf("disabled account logged in: %+v", session.Claims)
+	}
+}
+
+func TestRemoteLoginNeverRegisters(t *testing.T) {
+	am := testManager(t, nil)
+
+	_, _, err := am.RemoteLogin(context.Background(), HTTPLoginRequest{UserID: "erin", Password: "pw"}, "http")
+	if err == nil {
+		t.Fatal("remote login of an unknown user succeeded")
+	}
+	if exists, _ := am.Vault.Exists("users", "erin"); exists {
+		t.Fatal("remote login registered an account")
+	}
+}
+
+func TestGuestLoginIsNotRefreshable(t *testing.T) {
+	p := &ScriptedProvider{Logins: []Credentials{{UserID: GuestUserID}}}
+	am := testManager(t, p)
+
+	session, err := am.LoginOrSignUp(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !session.IsGuest() {
+		t.Fatalf("guest login produced %+v", session.Claims)
+	}
+	if _, _, err := am.Tokens.Refresh(session.Token); !errors.Is(err, verification_identity.ErrGuestRefresh) {
+		t.Fatalf("refresh = %v, want %v", err, verification_identity.ErrGuestRefresh)
+	}
+
+	am.DisableGuest = true
+	if _, err := am.GuestLogin(); !errors.Is(err, ErrGuestDisabled) {
+		t.Fatalf("GuestLogin with guests disabled = %v", err)
+	}
+}
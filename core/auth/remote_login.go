@@ -0,0 +1,78 @@
+// core/auth/remote_login.go
+package auth
+
+import (
+	"context"
+	"errors"
+
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// RemoteLogin signs in with the credentials of one request (the HTTP login
+// endpoint) and returns the session, whose Token the caller hands back.
+// It runs the same lockout, authenticator and second-factor checks as a
+// console login on a fresh AuthManager, never registers an account and
+// never prompts: anything the request does not carry fails the login.
+// Notices are the messages the flow produced for the user.
+func (am *AuthManager) RemoteLogin(ctx context.Context, req HTTPLoginRequest, source string) (*user_setting.UserSession, []string, error) {
+	login := am.fork()
+	login.Remote = true
+	return login.loginRequest(ctx, req, source)
+}
+
+// loginRequest runs the login flow of am with the request as provider.
+func (am *AuthManager) loginRequest(ctx context.Context, req HTTPLoginRequest, source string) (*user_setting.UserSession, []string, error) {
+	p := &requestProvider{req: req, source: source}
+	am.Provider = p
+
+	session, err := am.LoginOrSignUp(ctx)
+	if errors.Is(err, ErrNoCredentials) && p.result != nil {
+		err = p.result
+	}
+	return session, p.notices, err
+}
+
+// requestProvider answers an auth flow from a single login request.
+type requestProvider struct {
+	req    HTTPLoginRequest
+	source string
+	used   bool
+
+	notices []string
+	result  error
+}
+
+func (p *requestProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	if p.used {
+		return nil, ErrNoCredentials
+	}
+	p.used = true
+	return &Credentials{UserID: p.req.UserID, Password: p.req.Password, Source: p.source, Companion: p.req.Companion}, nil
+}
+
+func (p *requestProvider) Registration(ctx context.Context, userID string) (*Registration, error) {
+	return nil, ErrRegistrationDeclined
+}
+
+func (p *requestProvider) SecondFactor(ctx context.Context, prompt string) (string, error) {
+	if p.req.OTP == "" {
+		return "", errors.New("otp required")
+	}
+	return p.req.OTP, nil
+}
+
+func (p *requestProvider) Reauthenticate(ctx context.Context, req StepUpRequest) (string, error) {
+	return "", ErrNoCredentials
+}
+
+func (p *requestProvider) UserConfig(ctx context.Context, cfg *user_setting.CustomizedConfig) (*user_setting.CustomizedConfig, error) {
+	return cfg, nil
+}
+
+func (p *requestProvider) Notify(msg string) {
+	p.notices = append(p.notices, msg)
+}
+
+func (p *requestProvider) Complete(err error) {
+	p.result = err
+}
@@ -0,0 +1,116 @@
+// core/auth/step_up.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	security_totp "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/totp"
+	security_users "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/users"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// activity returns the idle/step-up tracker, creating the default one on
+// first use.
+func (am *AuthManager) activity() *verification_identity.SessionActivity {
+	if am.Activity == nil {
+		am.Activity = verification_identity.NewSessionActivity(nil, 0)
+	}
+	return am.Activity
+}
+
+// EnsureActive records activity on session. An idle-locked session is
+// unlocked by re-authenticating through the provider.
+func (am *AuthManager) EnsureActive(ctx context.Context, session *user_setting.UserSession) error {
+	err := am.activity().Touch(&session.Claims)
+	if !errors.Is(err, verification_identity.ErrSessionLocked) {
+		return err
+	}
+	return am.reauthenticate(ctx, session, "")
+}
+
+// StepUp must be called before any action needing perm. For the
+// permissions in verification_identity.StepUpPermissions the user is asked
+// to re-authenticate unless they did so within the step-up window.
+func (am *AuthManager) StepUp(ctx context.Context, session *user_setting.UserSession, perm user_setting.PermissionKey) error {
+	if !session.Claims.Permissions[perm] {
+		ev, e := am.decisions().Denial(am.decisions().Subject(&session.Claims), perm, "step-up")
+		if am.Audit != nil {
+			_, _ = am.Audit.Append(ev)
+		}
+		return fmt.Errorf("permission %q not granted to this session: %s", perm, e.Decision.Reason)
+	}
+
+	if err := am.EnsureActive(ctx, session); err != nil {
+		return err
+	}
+
+	err := am.activity().RequireFresh(&session.Claims, perm)
+	if errors.Is(err, verification_identity.ErrStepUpRequired) || errors.Is(err, verification_identity.ErrSessionLocked) {
+		return am.reauthenticate(ctx, session, perm)
+	}
+	return err
+}
+
+// Reverify checks a step-up answer for claims: the password, or the
+// second-factor code of an enrolled user. It runs under the same lockout
+// as a login, so step-up cannot be used to guess passwords, and marks the
+// session fresh on success.
+func (am *AuthManager) Reverify(claims *user_setting.SessionClaims, password, otp, source string) error {
+	if claims.Guest {
+		return verification_identity.ErrSessionLocked
+	}
+	userID := claims.UserID
+
+	guard, err := am.guard()
+	if err != nil {
+		return err
+	}
+	if err := guard.Check(userID, source); err != nil {
+		return err
+	}
+
+	var auditor security_users.Auditor
+	if am.Audit != nil {
+		auditor = am.Audit
+	}
+	if err := security_users.NewDirectory(am.Vault, auditor).Reverify(userID, password, otp); err != nil {
+		if lerr := guard.RecordFailure(userID, source); lerr != nil {
+			err = lerr
+		}
+		return err
+	}
+
+	_ = guard.RecordSuccess(userID)
+	am.activity().Reverified(claims)
+	return nil
+}
+
+func (am *AuthManager) reauthenticate(ctx context.Context, session *user_setting.UserSession, perm user_setting.PermissionKey) error {
+	if session.IsGuest() {
+		return verification_identity.ErrSessionLocked
+	}
+
+	userID := session.Claims.UserID
+	req := StepUpRequest{UserID: userID, Permission: perm, Factor: FactorPassword}
+	if enrolled, err := security_totp.Enrolled(am.Vault, userID); err == nil && enrolled {
+		req.Factor = FactorOTP
+	}
+
+	p := am.provider()
+	answer, err := p.Reauthenticate(ctx, req)
+	if err != nil {
+		p.Complete(err)
+		return err
+	}
+
+	if req.Factor == FactorOTP {
+		err = am.Reverify(&session.Claims, "", answer, "")
+	} else {
+		err = am.Reverify(&session.Claims, answer, "", "")
+	}
+	p.Complete(err)
+	return err
+}
@@ -0,0 +1,106 @@
+// core/auth/user_profile.go
+package auth
+
+import (
+	"errors"
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+const (
+	profilesCollection = "profiles"
+
+	// legacyConfigCollection held configs keyed by "machine-<userID>"
+	// before profiles were keyed by user ID.
+	legacyConfigCollection = "configs"
+)
+
+// Profile is everything stored per user: configuration and preferences.
+// It is keyed by user ID and independent of the device identity, so any
+// number of users can share one unit.
+type Profile struct {
+	UserID      string                        `json:"user_id"`
+	Config      user_setting.CustomizedConfig `json:"config"`
+	Preferences user_setting.UserPreferences  `json:"preferences"`
+	UpdatedAt   time.Time                     `json:"updated_at"`
+}
+
+// LoadProfile returns the profile of userID, or nil if the user has none
+// yet. A config stored under the legacy machine key is moved over on first
+// load.
+func LoadProfile(vault verification_persistence.VaultStore, userID string) (*Profile, error) {
+	if userID == "" {
+		return nil, errors.New("profile requires a user ID")
+	}
+
+	var p Profile
+	found, err := vault.Read(profilesCollection, userID, &p)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return &p, nil
+	}
+
+	var migrated *Profile
+	err = verification_persistence.Atomically(vault, func(tx verification_persistence.VaultTx) error {
+		migrated, err = LoadProfileTx(tx, userID)
+		return err
+	})
+	return migrated, err
+}
+
+// LoadProfileTx is LoadProfile inside a caller's transaction; a legacy
+// config is moved over as part of it.
+func LoadProfileTx(tx verification_persistence.VaultTx, userID string) (*Profile, error) {
+	if userID == "" {
+		return nil, errors.New("profile requires a user ID")
+	}
+
+	var p Profile
+	found, err := tx.Read(profilesCollection, userID, &p)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return &p, nil
+	}
+
+	return migrateLegacyProfile(tx, userID)
+}
+
+// SaveProfile stores p under its user ID.
+func SaveProfile(vault verification_persistence.VaultStore, p *Profile) error {
+	if p.UserID == "" {
+		return errors.New("profile requires a user ID")
+	}
+	p.UpdatedAt = time.Now()
+	return vault.Write(profilesCollection, p.UserID, p)
+}
+
+// DeleteProfile removes the profile of userID, e.g. with the account.
+func DeleteProfile(vault verification_persistence.VaultStore, userID string) error {
+	return vault.Delete(profilesCollection, userID)
+}
+
+func migrateLegacyProfile(tx verification_persistence.VaultTx, userID string) (*Profile, error) {
+	legacyKey := "machine-" + userID
+
+	var cfg user_setting.CustomizedConfig
+	found, err := tx.Read(legacyConfigCollection, legacyKey, &cfg)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	p := &Profile{UserID: userID, Config: cfg, UpdatedAt: time.Now()}
+	if err := tx.Write(profilesCollection, userID, p); err != nil {
+		return nil, err
+	}
+	if err := tx.Delete(legacyConfigCollection, legacyKey); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
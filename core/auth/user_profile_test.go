@@ -0,0 +1,74 @@
+// core/auth/user_profile_test.go
+package auth
+
+import (
+	"context"
+	"testing"
+
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+func TestProfilesAreKeyedByUserID(t *testing.T) {
+	am := testManager(t, nil)
+
+	for user, lang := range map[string]string{"alice": "fr", "bob": "de"} {
+		if err := am.RegisterUser(user, user+"-pw", internal_environment.EntityPersonal); err != nil {
+			t.Fatal(err)
+		}
+		cfg := DefaultCustomizedConfig()
+		cfg.MainLang = lang
+		p := &Profile{UserID: user, Config: *cfg, Preferences: user_setting.UserPreferences{AIStyle: user}}
+		if err := SaveProfile(am.Vault, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for user, lang := range map[string]string{"alice": "fr", "bob": "de"} {
+		session, _, err := am.fork().RemoteLogin(context.Background(), HTTPLoginRequest{UserID: user, Password: user + "-pw"}, "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if session.Config.MainLang != lang {
+			t.Errorf("%s logged in with language %q, want %q", user, session.Config.MainLang, lang)
+		}
+		if session.Preferences == nil || session.Preferences.AIStyle != user {
+			t.Errorf("%s logged in with preferences %+v", user, session.Preferences)
+		}
+	}
+
+	if err := DeleteProfile(am.Vault, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := LoadProfile(am.Vault, "alice"); err != nil || p != nil {
+		t.Errorf("deleted profile = %+v, %v", p, err)
+	}
+	if p, err := LoadProfile(am.Vault, "bob"); err != nil || p == nil || p.Config.MainLang != "de" {
+		t.Errorf("bob's profile after deleting alice's = %+v, %v", p, err)
+	}
+}
+
+func TestLegacyConfigMovesToTheProfile(t *testing.T) {
+	am := testManager(t, nil)
+
+	cfg := DefaultCustomizedConfig()
+	cfg.MainLang = "ja"
+	if err := am.Vault.Write(legacyConfigCollection, "machine-carol", cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadProfile(am.Vault, "carol")
+	if err != nil || p == nil || p.UserID != "carol" || p.Config.MainLang != "ja" {
+		t.Fatalf("migrated profile = %+v, %v", p, err)
+	}
+	if exists, _ := am.Vault.Exists(legacyConfigCollection, "machine-carol"); exists {
+		t.Error("legacy config kept after the move")
+	}
+	if exists, _ := am.Vault.Exists(profilesCollection, "carol"); !exists {
+		t.Error("profile not stored under the user ID")
+	}
+
+	if _, err := LoadProfile(am.Vault, ""); err == nil {
+		t.Error("profile without a user ID loaded")
+	}
+}
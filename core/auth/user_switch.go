@@ -0,0 +1,135 @@
+// core/auth/user_switch.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+var ErrNoActiveSession = errors.New("no_active_session")
+
+// SessionSwitcher owns the active user session of the process and swaps it
+// without a reboot, e.g. on a driver change in a shared vehicle. Each login
+// runs on a fresh AuthManager so no state leaks from one user to the next.
+type SessionSwitcher struct {
+	mu     sync.Mutex
+	base   *AuthManager
+	active *user_setting.UserSession
+	notify func(user_setting.UserSwitched)
+}
+
+// NewSessionSwitcher switches users with the vault, provider and policy of
+// base. notify is called after every successful switch; it may be nil.
+func NewSessionSwitcher(base *AuthManager, notify func(user_setting.UserSwitched)) *SessionSwitcher {
+	return &SessionSwitcher{base: base, notify: notify}
+}
+
+// Active returns the current session, or nil before the first login.
+func (s *SessionSwitcher) Active() *user_setting.UserSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active
+}
+
+// Adopt makes session, typically the one the unit booted with, the active
+// session without announcing a switch.
+func (s *SessionSwitcher) Adopt(session *user_setting.UserSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active = session
+}
+
+// Switch logs in the next user through the provider. The previous session
+// stays active if the login fails and is ended only once the new one
+// exists.
+func (s *SessionSwitcher) Switch(ctx context.Context) (*user_setting.UserSession, error) {
+	return s.switchTo(func(am *AuthManager) (*user_setting.UserSession, error) {
+		return am.LoginOrSignUp(ctx)
+	})
+}
+
+// SwitchRemote is Switch with the credentials of one login request, e.g.
+// from an in-vehicle display or a companion. It returns the notices of the
+// login flow as RemoteLogin does.
+func (s *SessionSwitcher) SwitchRemote(ctx context.Context, req HTTPLoginRequest, source string) (*user_setting.UserSession, []string, error) {
+	var notices []string
+	next, err := s.switchTo(func(am *AuthManager) (*user_setting.UserSession, error) {
+		session, n, err := am.loginRequest(ctx, req, source)
+		notices = n
+		return session, err
+	})
+	return next, notices, err
+}
+
+func (s *SessionSwitcher) switchTo(login func(am *AuthManager) (*user_setting.UserSession, error)) (*user_setting.UserSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	am := s.base.fork()
+	next, err := login(am)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := s.active
+	s.active = next
+
+	if previous != nil {
+		if err := am.endSession(previous, "user_switch"); err != nil {
+			am.provider().Notify("previous session cleanup failed: " + err.Error())
+		}
+	}
+
+	if s.notify != nil {
+		ev := user_setting.UserSwitched{
+			UserID:    am.UserID,
+			SessionID: next.Claims.SessionID,
+			Guest:     next.IsGuest(),
+			Sandbox:   next.IsSandbox(),
+			At:        time.Now().UTC(),
+		}
+		if previous != nil {
+			ev.PreviousUserID = previous.Claims.UserID
+			ev.PreviousSessionID = previous.Claims.SessionID
+		}
+		s.notify(ev)
+	}
+
+	return next, nil
+}
+
+// End logs the active user out without starting another session.
+func (s *SessionSwitcher) End() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active == nil {
+		return ErrNoActiveSession
+	}
+
+	err := s.base.endSession(s.active, "logout")
+	s.active = nil
+	return err
+}
+
+// fork returns an AuthManager sharing am's configuration but none of the
+// per-login state.
+func (am *AuthManager) fork() *AuthManager {
+	return &AuthManager{
+		Vault:         am.Vault,
+		Provider:      am.Provider,
+		Audit:         am.Audit,
+		Lockout:       am.Lockout,
+		Activity:      am.Activity,
+		Tokens:        am.Tokens,
+		Remote:        am.Remote,
+		Platform:      am.Platform,
+		Decisions:     am.Decisions,
+		GuestLifetime: am.GuestLifetime,
+		DisableGuest:  am.DisableGuest,
+	}
+}
@@ -0,0 +1,115 @@
+// core/auth/user_switch_test.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	security_scratch "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/scratch"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// switchFixture registers alice and bob, logs alice in and hands her
+// session to a switcher that records what it announces.
+func switchFixture(t *testing.T, p *ScriptedProvider) (*SessionSwitcher, *user_setting.UserSession, *[]user_setting.UserSwitched) {
+	t.Helper()
+	am := testManager(t, p)
+	for user, pw := range map[string]string{"alice": "alice-pw", "bob": "bob-pw"} {
+		if err := am.RegisterUser(user, pw, internal_environment.EntityPersonal); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Forks share the token service, and with it the revocation list.
+	if _, err := am.tokens(); err != nil {
+		t.Fatal(err)
+	}
+
+	alice, _, err := am.RemoteLogin(context.Background(), HTTPLoginRequest{UserID: "alice", Password: "alice-pw"}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []user_setting.UserSwitched
+	s := NewSessionSwitcher(am, func(ev user_setting.UserSwitched) { events = append(events, ev) })
+	s.Adopt(alice)
+	return s, alice, &events
+}
+
+func TestFailedSwitchKeepsTheActiveSession(t *testing.T) {
+	s, alice, events := switchFixture(t, &ScriptedProvider{Logins: []Credentials{{UserID: "bob", Password: "wrong"}}})
+
+	if _, err := s.Switch(context.Background()); err == nil {
+		t.Fatal("switch with a wrong password succeeded")
+	}
+	if s.Active() != alice {
+		t.Fatalf("active session = %+v, want alice's", s.Active().Claims)
+	}
+	if _, err := s.base.Tokens.Verify(alice.Token); err != nil {
+		t.Errorf("alice's token after a failed switch: %v", err)
+	}
+	if len(*events) != 0 {
+		t.Errorf("failed switch announced %+v", *events)
+	}
+}
+
+func TestSwitchRevokesThePreviousSession(t *testing.T) {
+	s, alice, events := switchFixture(t, &ScriptedProvider{Logins: []Credentials{{UserID: "bob", Password: "bob-pw"}}})
+
+	bob, err := s.Switch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Active() != bob || bob.Claims.UserID != "bob" {
+		t.Fatalf("active session = %+v, want bob's", s.Active().Claims)
+	}
+	if _, err := s.base.Tokens.Verify(alice.Token); !errors.Is(err, verification_identity.ErrTokenRevoked) {
+		t.Errorf("alice's token after the switch = %v, want %v", err, verification_identity.ErrTokenRevoked)
+	}
+	if _, err := s.base.Tokens.Verify(bob.Token); err != nil {
+		t.Errorf("bob's token: %v", err)
+	}
+
+	if len(*events) != 1 {
+		t.Fatalf("%d switch notifications, want 1", len(*events))
+	}
+	ev := (*events)[0]
+	if ev.PreviousUserID != "alice" || ev.PreviousSessionID != alice.Claims.SessionID ||
+		ev.UserID != "bob" || ev.SessionID != bob.Claims.SessionID || ev.Guest || ev.At.IsZero() {
+		t.Errorf("notification = %+v", ev)
+	}
+}
+
+func TestSwitchRemoteToAGuest(t *testing.T) {
+	saved := security_scratch.Root
+	security_scratch.Root = filepath.Join(t.TempDir(), "aios-guest")
+	defer func() { security_scratch.Root = saved }()
+
+	s, alice, events := switchFixture(t, &ScriptedProvider{})
+
+	guest, _, err := s.SwitchRemote(context.Background(), HTTPLoginRequest{UserID: GuestUserID}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.End() }()
+
+	if !guest.IsGuest() || s.Active() != guest {
+		t.Fatalf("active session = %+v, want the guest", s.Active().Claims)
+	}
+	if ev := (*events)[0]; !ev.Guest || ev.PreviousUserID != "alice" {
+		t.Errorf("notification = %+v", ev)
+	}
+	if _, err := s.base.Tokens.Verify(alice.Token); !errors.Is(err, verification_identity.ErrTokenRevoked) {
+		t.Errorf("alice's token after the switch = %v", err)
+	}
+}
+
+func TestEndWithoutAnActiveSession(t *testing.T) {
+	s := NewSessionSwitcher(testManager(t, nil), nil)
+	if err := s.End(); !errors.Is(err, ErrNoActiveSession) {
+		t.Fatalf("End = %v, want %v", err, ErrNoActiveSession)
+	}
+}
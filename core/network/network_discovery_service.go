@@ -4,8 +4,10 @@ package network
 
 import (
 	"context"
+	"encoding/base64"
 	"os"
 
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
 	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/pkg/logging"
 	"github.com/hashicorp/mdns"
 )
@@ -15,10 +17,18 @@ type DiscoveryService struct {
 }
 
 // StartBroadcasting makes this node visible to other nodes in the swarm.
-func StartBroadcasting(nodeID string, port int) (*DiscoveryService, error) {
+// When identity is set its public key and hardware fingerprint are
+// advertised so peers can pin them before the signed mesh handshake.
+func StartBroadcasting(nodeID string, port int, identity *verification_identity.DeviceIdentity) (*DiscoveryService, error) {
 	// 1. Setup service metadata (including the Platform Class for quick filtering)
 	host, _ := os.Hostname()
 	info := []string{"version=1.0", "node_id=" + nodeID}
+	if identity != nil {
+		info = append(info,
+			"pubkey="+base64.StdEncoding.EncodeToString(identity.PublicKey),
+			"fp="+identity.Fingerprint,
+		)
+	}
 
 	service, err := mdns.NewMDNSService(host, "_strata-aios._tcp", "", "", port, nil, info)
 	if err != nil {
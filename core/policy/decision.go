@@ -7,4 +7,7 @@ type Decision struct {
 
 	Granted []string
 	Denied  []string
+
+	// Matched lists the rules that applied, as policy/rule.
+	Matched []string
 }
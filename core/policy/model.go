@@ -3,15 +3,58 @@
 package policy
 
 import (
+	"time"
+
 	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
 	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
 )
 
+type Effect string
+
+const (
+	Allow Effect = "allow"
+	Deny  Effect = "deny"
+)
+
+// Who signed a policy file. Release-signed policies come from the vendor;
+// device-signed ones were approved by an admin of this unit.
+const (
+	SignerRelease = "release"
+	SignerDevice  = "device"
+	SignerBuiltin = "builtin"
+)
+
+// Policy is one policy file. Version increases with every published
+// revision of the same Name; a lower version than the one last loaded is
+// refused as a rollback.
 type Policy struct {
-	Name string
+	Name      string    `json:"name"`
+	Version   uint64    `json:"version"`
+	IssuedAt  time.Time `json:"issued_at"`
+	Signer    string    `json:"signer"`
+	Rules     []Rule    `json:"rules"`
+	Signature []byte    `json:"signature,omitempty"`
+}
 
-	RequireCaps internal_environment.CapabilitySet
+// Rule grants or denies Permissions when its When condition holds. An
+// empty When always holds. See rule_lang.go for the condition syntax.
+type Rule struct {
+	ID          string                       `json:"id"`
+	Effect      Effect                       `json:"effect"`
+	Permissions []user_setting.PermissionKey `json:"permissions"`
+	When        string                       `json:"when,omitempty"`
+	Reason      string                       `json:"reason,omitempty"`
+
+	cond condition
+}
 
-	Grant []user_setting.PermissionKey
-	Deny  []user_setting.PermissionKey
+// Attributes describe the request a policy decides on.
+type Attributes struct {
+	Platform   internal_environment.PlatformClass
+	Entity     internal_environment.EntityKind
+	Tier       user_setting.TierType
+	Service    user_setting.ServiceType
+	Trust      user_setting.TrustLevel
+	SessionAge time.Duration
+	Caps       internal_environment.CapabilitySet
 }
@@ -0,0 +1,146 @@
+// core/policy/policy_explain.go
+
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	security_users "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/users"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// RuleTrace is one rule as evaluated for an explanation.
+type RuleTrace struct {
+	Policy string `json:"policy"`
+	Rule   string `json:"rule"`
+	Effect Effect `json:"effect"`
+	When   string `json:"when,omitempty"`
+
+	// Names reports whether the rule is about the permission at all;
+	// Matched whether its condition held.
+	Names    bool `json:"names"`
+	Matched  bool `json:"matched"`
+	Deciding bool `json:"deciding,omitempty"`
+}
+
+// Explanation is how a set decided one permission for one request.
+type Explanation struct {
+	Permission user_setting.PermissionKey `json:"permission"`
+	Request    FixtureRequest             `json:"request"`
+	Rules      []RuleTrace                `json:"rules"`
+	Decision   Decision                   `json:"decision"`
+
+	// Deciding is the rule that decided, as policy/rule, or empty for
+	// the default deny.
+	Deciding string `json:"deciding,omitempty"`
+}
+
+// Explain evaluates every rule of every policy against a and reports
+// each result along with the decision Check reaches.
+func (s *Set) Explain(a Attributes, perm user_setting.PermissionKey) Explanation {
+	d, deciding := s.check(a, perm)
+
+	e := Explanation{
+		Permission: perm,
+		Request:    a.Request(),
+		Decision:   d,
+	}
+	if deciding != nil {
+		e.Deciding = deciding.name()
+	}
+
+	for _, p := range s.Policies {
+		for i := range p.Rules {
+			r := &p.Rules[i]
+			t := RuleTrace{
+				Policy:  p.Name,
+				Rule:    r.ID,
+				Effect:  r.Effect,
+				When:    r.When,
+				Matched: r.cond != nil && r.cond.eval(&a),
+			}
+			for _, rp := range r.Permissions {
+				if rp == perm {
+					t.Names = true
+				}
+			}
+			t.Deciding = deciding != nil && deciding.rule == r
+			e.Rules = append(e.Rules, t)
+		}
+	}
+	return e
+}
+
+// Text renders e for a terminal: one line per rule, then the decision.
+func (e Explanation) Text() string {
+	var b strings.Builder
+
+	req := e.Request
+	fmt.Fprintf(&b, "request: platform=%s entity=%s tier=%s service=%s trust=%s",
+		orDash(req.Platform), orDash(req.Entity), orDash(req.Tier), orDash(req.Service), orDash(req.Trust))
+	if req.SessionAge != "" {
+		fmt.Fprintf(&b, " session_age=%s", req.SessionAge)
+	}
+	fmt.Fprintf(&b, " caps=[%s]\n", strings.Join(req.Caps, ", "))
+
+	for _, t := range e.Rules {
+		mark := "  "
+		if t.Deciding {
+			mark = "=>"
+		}
+		result := "no match"
+		if t.Matched {
+			result = "match"
+		}
+		scope := ""
+		if !t.Names {
+			scope = fmt.Sprintf(" (not about %s)", e.Permission)
+		}
+		when := t.When
+		if when == "" {
+			when = "always"
+		}
+		fmt.Fprintf(&b, "%s %-32s %-5s %-8s %s%s\n", mark, t.Policy+"/"+t.Rule, t.Effect, result, when, scope)
+	}
+
+	verdict := "DENY"
+	if e.Decision.Allowed {
+		verdict = "ALLOW"
+	}
+	fmt.Fprintf(&b, "%s %s: %s\n", verdict, e.Permission, e.Decision.Reason)
+	return b.String()
+}
+
+// Request spells a the way conditions and fixtures do.
+func (a Attributes) Request() FixtureRequest {
+	r := FixtureRequest{
+		Platform: string(a.Platform),
+		Entity:   security_users.EntityName(a.Entity),
+		Tier:     string(a.Tier),
+		Service:  string(a.Service),
+	}
+	for name, level := range TrustLevels {
+		if level == a.Trust {
+			r.Trust = name
+		}
+	}
+	if a.SessionAge > 0 {
+		r.SessionAge = a.SessionAge.String()
+	}
+	for name, c := range Capabilities {
+		if a.Caps.Has(c) {
+			r.Caps = append(r.Caps, name)
+		}
+	}
+	sort.Strings(r.Caps)
+	return r
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
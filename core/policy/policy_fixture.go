@@ -0,0 +1,144 @@
+// core/policy/policy_fixture.go
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	security_users "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/users"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// Fixture is a request with the decisions a policy set must reach for
+// it. Permissions listed in neither Allow nor Deny are not checked.
+type Fixture struct {
+	Name    string                       `json:"name"`
+	Request FixtureRequest               `json:"request"`
+	Allow   []user_setting.PermissionKey `json:"allow,omitempty"`
+	Deny    []user_setting.PermissionKey `json:"deny,omitempty"`
+}
+
+// FixtureRequest spells Attributes the way conditions do.
+type FixtureRequest struct {
+	Platform   string   `json:"platform,omitempty"`
+	Entity     string   `json:"entity,omitempty"`
+	Tier       string   `json:"tier,omitempty"`
+	Service    string   `json:"service,omitempty"`
+	Trust      string   `json:"trust,omitempty"`
+	SessionAge string   `json:"session_age,omitempty"`
+	Caps       []string `json:"caps,omitempty"`
+}
+
+// FixtureResult is the outcome of one fixture.
+type FixtureResult struct {
+	Name     string
+	Passed   bool
+	Failures []string
+}
+
+// Attributes converts the request. Omitted fields are the zero value,
+// which for entity is personal and for trust untrusted. Trust also
+// accepts the attestation levels strong and weak, which boot as device and
+// user trust.
+func (r FixtureRequest) Attributes() (Attributes, error) {
+	a := Attributes{
+		Platform: internal_environment.PlatformClass(r.Platform),
+		Tier:     user_setting.TierType(r.Tier),
+		Service:  user_setting.ServiceType(r.Service),
+	}
+
+	if r.Entity != "" {
+		e, err := security_users.ParseEntity(r.Entity)
+		if err != nil {
+			return a, err
+		}
+		a.Entity = e
+	}
+	if r.Trust != "" {
+		level, ok := TrustLevels[r.Trust]
+		if alias, isAlias := attestationTrust[r.Trust]; isAlias {
+			level, ok = alias, true
+		}
+		if !ok {
+			return a, fmt.Errorf("unknown trust level %q", r.Trust)
+		}
+		a.Trust = level
+	}
+	if r.SessionAge != "" {
+		d, err := time.ParseDuration(r.SessionAge)
+		if err != nil {
+			return a, fmt.Errorf("invalid session_age: %w", err)
+		}
+		a.SessionAge = d
+	}
+	for _, name := range r.Caps {
+		cap, ok := Capabilities[name]
+		if !ok {
+			return a, fmt.Errorf("unknown capability %q", name)
+		}
+		a.Caps.Add(cap)
+	}
+	return a, nil
+}
+
+// attestationTrust is the trust each attestation level boots with.
+var attestationTrust = map[string]user_setting.TrustLevel{
+	"strong": user_setting.TrustDevice,
+	"weak":   user_setting.TrustUser,
+}
+
+// ReadFixtures loads a JSON array of fixtures.
+func ReadFixtures(path string) ([]Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var fixtures []Fixture
+	if err := dec.Decode(&fixtures); err != nil {
+		return nil, fmt.Errorf("malformed fixtures: %w", err)
+	}
+	return fixtures, nil
+}
+
+// RunFixtures checks every fixture against s.
+func RunFixtures(s *Set, fixtures []Fixture) []FixtureResult {
+	results := make([]FixtureResult, 0, len(fixtures))
+
+	for i, f := range fixtures {
+		res := FixtureResult{Name: f.Name}
+		if res.Name == "" {
+			res.Name = fmt.Sprintf("fixture %d", i+1)
+		}
+
+		attrs, err := f.Request.Attributes()
+		if err != nil {
+			res.Failures = append(res.Failures, err.Error())
+			results = append(results, res)
+			continue
+		}
+
+		for _, perm := range f.Allow {
+			if d := s.Check(attrs, perm); !d.Allowed {
+				res.Failures = append(res.Failures, fmt.Sprintf("%s: expected allow, %s", perm, d.Reason))
+			}
+		}
+		for _, perm := range f.Deny {
+			if d := s.Check(attrs, perm); d.Allowed {
+				res.Failures = append(res.Failures, fmt.Sprintf("%s: expected deny, %s", perm, d.Reason))
+			}
+		}
+
+		res.Passed = len(res.Failures) == 0
+		results = append(results, res)
+	}
+	return results
+}
@@ -0,0 +1,271 @@
+// core/policy/policy_loader.go
+
+package policy
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	core_verification "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/verification"
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/apppath"
+)
+
+// policyVersionsCollection remembers the last version loaded per policy
+// name, for rollback protection.
+const policyVersionsCollection = "policy_versions"
+
+var (
+	ErrPolicySignature = errors.New("policy_signature_invalid")
+	ErrPolicyRollback  = errors.New("policy_rollback")
+	ErrPolicyMissing   = errors.New("policy_missing")
+	ErrPolicyNotLoaded = errors.New("policy_not_loaded")
+)
+
+// TrustedKeys are the keys a policy signature may verify against. Either
+// may be nil when unavailable.
+type TrustedKeys struct {
+	Release ed25519.PublicKey
+	Device  ed25519.PublicKey
+}
+
+type loadedVersion struct {
+	Version  uint64    `json:"version"`
+	Hash     string    `json:"hash"`
+	LoadedAt time.Time `json:"loaded_at"`
+
+	// Retired policies were removed by an admin and may stay absent.
+	Retired bool `json:"retired,omitempty"`
+}
+
+// Dir is where policy files live: one <name>.json per policy.
+func Dir() string {
+	return filepath.Join(apppath.GetConfigDir(), "policies")
+}
+
+// LoadTrustedKeys returns the release key, if configured, and the device
+// key, if the unit is provisioned.
+func LoadTrustedKeys(v verification_persistence.VaultStore) (TrustedKeys, error) {
+	var keys TrustedKeys
+	if pub, err := core_verification.LoadReleaseKey(); err == nil {
+		keys.Release = pub
+	}
+
+	device, err := verification_identity.LoadDeviceIdentity(v)
+	if err != nil {
+		return keys, err
+	}
+	if device != nil {
+		keys.Device = device.PublicKey
+	}
+	return keys, nil
+}
+
+func (p *Policy) signedBytes() []byte {
+	unsigned := *p
+	unsigned.Signature = nil
+	data, _ := json.Marshal(unsigned)
+	return data
+}
+
+// Hash identifies the signed content of p.
+func (p *Policy) Hash() string {
+	sum := sha256.Sum256(p.signedBytes())
+	return hex.EncodeToString(sum[:])
+}
+
+// SignPolicy seals p. sign is the release key for vendor policies or the
+// device key (DeviceIdentity.Sign) for policies approved on the unit.
+func SignPolicy(p *Policy, signer string, sign func(msg []byte) []byte) {
+	p.Signer = signer
+	p.Signature = sign(p.signedBytes())
+}
+
+// VerifyPolicy checks the signature of p against the key its Signer
+// names.
+func VerifyPolicy(p *Policy, keys TrustedKeys) error {
+	var pub ed25519.PublicKey
+	switch p.Signer {
+	case SignerRelease:
+		pub = keys.Release
+	case SignerDevice:
+		pub = keys.Device
+	default:
+		return fmt.Errorf("%w: %s: unknown signer %q", ErrPolicySignature, p.Name, p.Signer)
+	}
+
+	if len(pub) != ed25519.PublicKeySize || len(p.Signature) == 0 || !ed25519.Verify(pub, p.signedBytes(), p.Signature) {
+		return fmt.Errorf("%w: %s", ErrPolicySignature, p.Name)
+	}
+	return nil
+}
+
+// ReadPolicyFile parses and validates a policy file without checking its
+// signature. Unknown fields are rejected so a misspelt key cannot silently
+// drop a condition.
+func ReadPolicyFile(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var p Policy
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrPolicyInvalid, filepath.Base(path), err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// WritePolicyFile stores p as indented JSON.
+func WritePolicyFile(path string, p *Policy) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// LoadDir loads every policy file in dir. Each must validate, carry a good
+// signature and not be older than the version last loaded, and every
+// policy loaded before must still be there unless it was retired; any
+// failure fails the whole load, so a unit never runs on a partial policy
+// set. With no policy files, and none expected, the Builtin policy
+// applies.
+func LoadDir(v verification_persistence.VaultStore, dir string) (*Set, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	policies := make([]*Policy, 0, len(paths))
+	if len(paths) > 0 {
+		keys, err := LoadTrustedKeys(v)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, path := range paths {
+			p, err := ReadPolicyFile(path)
+			if err != nil {
+				return nil, err
+			}
+			if err := VerifyPolicy(p, keys); err != nil {
+				return nil, err
+			}
+			policies = append(policies, p)
+		}
+	}
+
+	set, err := NewSet(policies...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordVersions(v, policies); err != nil {
+		return nil, err
+	}
+
+	if len(policies) == 0 {
+		return NewSet(Builtin())
+	}
+	return set, nil
+}
+
+// RetirePolicy lets a policy loaded before stay absent from the policy
+// directory. Its version is kept, so it can only come back newer.
+func RetirePolicy(v verification_persistence.VaultStore, name string) error {
+	return verification_persistence.Atomically(v, func(tx verification_persistence.VaultTx) error {
+		var last loadedVersion
+		found, err := tx.Read(policyVersionsCollection, name, &last)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("%w: %s", ErrPolicyNotLoaded, name)
+		}
+		last.Retired = true
+		return tx.Write(policyVersionsCollection, name, last)
+	})
+}
+
+// recordVersions refuses policies older than the last loaded, or a
+// version number reused for different content, and remembers the rest.
+// A retired policy comes back only at a higher version. A recorded policy
+// that is absent and not retired was deleted behind the admins' back and
+// is refused like a rollback.
+func recordVersions(v verification_persistence.VaultStore, policies []*Policy) error {
+	return verification_persistence.Atomically(v, func(tx verification_persistence.VaultTx) error {
+		present := make(map[string]bool, len(policies))
+		for _, p := range policies {
+			present[p.Name] = true
+		}
+
+		recorded, err := tx.List(policyVersionsCollection)
+		if err != nil {
+			return err
+		}
+		for _, name := range recorded {
+			if present[name] {
+				continue
+			}
+			var last loadedVersion
+			if _, err := tx.Read(policyVersionsCollection, name, &last); err != nil {
+				return err
+			}
+			if !last.Retired {
+				return fmt.Errorf("%w: %s version %d was loaded before; restore it or retire it with aios policy retire", ErrPolicyMissing, name, last.Version)
+			}
+		}
+
+		for _, p := range policies {
+			var last loadedVersion
+			found, err := tx.Read(policyVersionsCollection, p.Name, &last)
+			if err != nil {
+				return err
+			}
+
+			hash := p.Hash()
+			if found {
+				if last.Retired && p.Version <= last.Version {
+					return fmt.Errorf("%w: %s was retired at version %d; reinstall it with a higher version", ErrPolicyRollback, p.Name, last.Version)
+				}
+				if p.Version < last.Version {
+					return fmt.Errorf("%w: %s version %d < loaded %d", ErrPolicyRollback, p.Name, p.Version, last.Version)
+				}
+				if p.Version == last.Version {
+					if hash != last.Hash {
+						return fmt.Errorf("%w: %s version %d reused for different rules", ErrPolicyRollback, p.Name, p.Version)
+					}
+					continue
+				}
+			}
+
+			err = tx.Write(policyVersionsCollection, p.Name, loadedVersion{
+				Version:  p.Version,
+				Hash:     hash,
+				LoadedAt: time.Now().UTC(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,113 @@
+// core/policy/policy_loader_test.go
+
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// installPolicy writes a device-signed policy named name at version to dir.
+func installPolicy(t *testing.T, device *verification_identity.DeviceIdentity, dir, name string, version uint64) string {
+	t.Helper()
+	p := &Policy{
+		Name:    name,
+		Version: version,
+		Rules: []Rule{
+			{ID: "diagnostics", Effect: Allow, Permissions: []user_setting.PermissionKey{user_setting.PermDiagnostics}},
+		},
+	}
+	SignPolicy(p, SignerDevice, device.Sign)
+
+	path := filepath.Join(dir, name+".json")
+	if err := WritePolicyFile(path, p); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDirRefusesDeletedPolicy(t *testing.T) {
+	v := &verification_persistence.IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{1}, 32)}
+	device, err := verification_identity.ProvisionDeviceIdentity(v, "m1", verification_identity.HardwareClaim{
+		Digest:     "tpm=t;cpu=c;",
+		Components: map[string]string{"tpm": "t", "cpu": "c"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	site := installPolicy(t, device, dir, "site", 2)
+	installPolicy(t, device, dir, "fleet", 1)
+
+	set, err := LoadDir(v, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Policies) != 2 {
+		t.Fatalf("loaded %d policies, want 2", len(set.Policies))
+	}
+
+	// Deleting one file, or all of them, must not fall back to fewer rules
+	if err := os.Remove(site); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDir(v, dir); !errors.Is(err, ErrPolicyMissing) {
+		t.Fatalf("deleted policy: err = %v, want ErrPolicyMissing", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "fleet.json")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDir(v, dir); !errors.Is(err, ErrPolicyMissing) {
+		t.Fatalf("empty directory: err = %v, want ErrPolicyMissing", err)
+	}
+
+	// Retired policies may stay away; the builtin policy applies again
+	for _, name := range []string{"site", "fleet"} {
+		if err := RetirePolicy(v, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	set, err = LoadDir(v, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Policies) != 1 || set.Policies[0].Name != "builtin" {
+		t.Fatalf("after retiring: %d policies, want builtin", len(set.Policies))
+	}
+
+	// A retired policy keeps its version, so it cannot come back older
+	installPolicy(t, device, dir, "site", 1)
+	if _, err := LoadDir(v, dir); !errors.Is(err, ErrPolicyRollback) {
+		t.Fatalf("older retired policy: err = %v, want ErrPolicyRollback", err)
+	}
+
+	// Nor at the version it was retired with
+	installPolicy(t, device, dir, "site", 2)
+	if _, err := LoadDir(v, dir); !errors.Is(err, ErrPolicyRollback) {
+		t.Fatalf("retired policy at its version: err = %v, want ErrPolicyRollback", err)
+	}
+
+	// Reinstalled newer it is expected again
+	installPolicy(t, device, dir, "site", 3)
+	if _, err := LoadDir(v, dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(site); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDir(v, dir); !errors.Is(err, ErrPolicyMissing) {
+		t.Fatalf("reinstalled policy deleted: err = %v, want ErrPolicyMissing", err)
+	}
+
+	if err := RetirePolicy(v, "unknown"); !errors.Is(err, ErrPolicyNotLoaded) {
+		t.Errorf("retire unknown: err = %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+// core/policy/policy_set.go
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+var ErrPolicyInvalid = errors.New("policy_invalid")
+
+// knownPermissions are the permission keys a rule may name.
+var knownPermissions = []user_setting.PermissionKey{
+	user_setting.PermUser,
+	user_setting.PermAdmin,
+	user_setting.PermBasicRuntime,
+	user_setting.PermConfigEdit,
+	user_setting.PermDiagnostics,
+	user_setting.PermHardwareIO,
+	user_setting.PermSafetyOverride,
+}
+
+// Set is the policies in force. Decisions are deny-overrides: a
+// permission is granted when at least one matching rule allows it and no
+// matching rule denies it. Anything no rule allows is denied.
+type Set struct {
+	Policies []*Policy
+}
+
+// NewSet validates policies and combines them. Policy names must be
+// unique.
+func NewSet(policies ...*Policy) (*Set, error) {
+	seen := map[string]bool{}
+	for _, p := range policies {
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("%w: duplicate policy %q", ErrPolicyInvalid, p.Name)
+		}
+		seen[p.Name] = true
+	}
+	return &Set{Policies: policies}, nil
+}
+
+// Validate compiles every rule condition and checks effects, permission
+// keys and rule IDs.
+func (p *Policy) Validate() error {
+	fail := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s: %s", ErrPolicyInvalid, p.Name, fmt.Sprintf(format, args...))
+	}
+
+	if p.Name == "" {
+		return fmt.Errorf("%w: policy has no name", ErrPolicyInvalid)
+	}
+	if p.Version == 0 {
+		return fail("version must be at least 1")
+	}
+
+	ids := map[string]bool{}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.ID == "" {
+			return fail("rule %d has no id", i+1)
+		}
+		if ids[r.ID] {
+			return fail("duplicate rule id %q", r.ID)
+		}
+		ids[r.ID] = true
+
+		if r.Effect != Allow && r.Effect != Deny {
+			return fail("rule %s: effect must be allow or deny, not %q", r.ID, r.Effect)
+		}
+		if len(r.Permissions) == 0 {
+			return fail("rule %s: no permissions", r.ID)
+		}
+		for _, perm := range r.Permissions {
+			if !KnownPermission(perm) {
+				return fail("rule %s: unknown permission %q", r.ID, perm)
+			}
+		}
+
+		cond, err := parseCondition(r.When)
+		if err != nil {
+			return fail("rule %s: %v", r.ID, err)
+		}
+		r.cond = cond
+	}
+	return nil
+}
+
+// match is one rule that applied to a request.
+type match struct {
+	policy string
+	rule   *Rule
+}
+
+func (m match) name() string { return m.policy + "/" + m.rule.ID }
+
+func (s *Set) matches(a *Attributes) []match {
+	var out []match
+	for _, p := range s.Policies {
+		for i := range p.Rules {
+			r := &p.Rules[i]
+			if r.cond != nil && r.cond.eval(a) {
+				out = append(out, match{policy: p.Name, rule: r})
+			}
+		}
+	}
+	return out
+}
+
+// Evaluate decides every permission for a. Allowed reports whether
+// anything was granted at all.
+func (s *Set) Evaluate(a Attributes) Decision {
+	allowed := map[user_setting.PermissionKey]bool{}
+	denied := map[user_setting.PermissionKey]bool{}
+
+	d := Decision{Granted: []string{}, Denied: []string{}}
+	for _, m := range s.matches(&a) {
+		d.Matched = append(d.Matched, m.name())
+		for _, perm := range m.rule.Permissions {
+			if m.rule.Effect == Deny {
+				denied[perm] = true
+			} else {
+				allowed[perm] = true
+			}
+		}
+	}
+
+	for perm := range allowed {
+		if !denied[perm] {
+			d.Granted = append(d.Granted, string(perm))
+		}
+	}
+	for perm := range denied {
+		d.Denied = append(d.Denied, string(perm))
+	}
+	sort.Strings(d.Granted)
+	sort.Strings(d.Denied)
+
+	d.Allowed = len(d.Granted) > 0
+	if d.Allowed {
+		d.Reason = "granted by " + strings.Join(d.Matched, ", ")
+	} else {
+		d.Reason = "no rule grants any permission"
+	}
+	return d
+}
+
+// Permissions is Evaluate as the permission map sessions carry.
+func (s *Set) Permissions(a Attributes) map[user_setting.PermissionKey]bool {
+	out := map[user_setting.PermissionKey]bool{}
+	for _, p := range s.Evaluate(a).Granted {
+		out[user_setting.PermissionKey(p)] = true
+	}
+	return out
+}
+
+// Check decides a single permission. The reason names the deciding rule:
+// the first deny if any, else the first allow, else the default deny.
+func (s *Set) Check(a Attributes, perm user_setting.PermissionKey) Decision {
+	d, _ := s.check(a, perm)
+	return d
+}
+
+// check is Check that also returns the deciding rule, nil for the
+// default deny.
+func (s *Set) check(a Attributes, perm user_setting.PermissionKey) (Decision, *match) {
+	var allows, denies []match
+	for _, m := range s.matches(&a) {
+		for _, p := range m.rule.Permissions {
+			if p != perm {
+				continue
+			}
+			if m.rule.Effect == Deny {
+				denies = append(denies, m)
+			} else {
+				allows = append(allows, m)
+			}
+		}
+	}
+
+	d := Decision{Granted: []string{}, Denied: []string{}}
+	for _, m := range append(denies, allows...) {
+		d.Matched = append(d.Matched, m.name())
+	}
+
+	switch {
+	case len(denies) > 0:
+		d.Denied = []string{string(perm)}
+		d.Reason = "denied by " + denies[0].name() + ruleReason(denies[0].rule)
+		return d, &denies[0]
+	case len(allows) > 0:
+		d.Allowed = true
+		d.Granted = []string{string(perm)}
+		d.Reason = "allowed by " + allows[0].name() + ruleReason(allows[0].rule)
+		return d, &allows[0]
+	default:
+		d.Denied = []string{string(perm)}
+		d.Reason = "no rule allows " + string(perm)
+		return d, nil
+	}
+}
+
+func ruleReason(r *Rule) string {
+	if r.Reason == "" {
+		return ""
+	}
+	return ": " + r.Reason
+}
+
+// KnownPermission reports whether rules may name p.
+func KnownPermission(p user_setting.PermissionKey) bool {
+	for _, k := range knownPermissions {
+		if p == k {
+			return true
+		}
+	}
+	return false
+}
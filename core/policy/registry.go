@@ -2,37 +2,83 @@
 package policy
 
 import (
-	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
 	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
 )
 
-var BasePolicies = []Policy{
-	{
-		Name:        "desktop_personal",
-		RequireCaps: internal_environment.CapNetwork,
-		Grant: []user_setting.PermissionKey{
-			user_setting.PermBasicRuntime,
-			user_setting.PermConfigEdit,
+// Builtin is the policy applied when no policy files are installed. It is
+// compiled in and needs no signature.
+func Builtin() *Policy {
+	return &Policy{
+		Name:    "builtin",
+		Version: 1,
+		Signer:  SignerBuiltin,
+		Rules: []Rule{
+			{
+				ID:          "authenticated",
+				Effect:      Allow,
+				Permissions: []user_setting.PermissionKey{user_setting.PermUser},
+			},
+			{
+				ID:          "admin_account",
+				Effect:      Allow,
+				When:        "trust >= admin",
+				Permissions: []user_setting.PermissionKey{user_setting.PermAdmin},
+			},
+			{
+				ID:          "organization_diagnostics",
+				Effect:      Allow,
+				When:        "entity in [organization, tester] or tier == enterprise",
+				Permissions: []user_setting.PermissionKey{user_setting.PermDiagnostics},
+			},
+			{
+				ID:          "tester_config",
+				Effect:      Allow,
+				When:        "entity == tester or tier == enterprise",
+				Permissions: []user_setting.PermissionKey{user_setting.PermConfigEdit},
+			},
+			{
+				ID:          "field_bus",
+				Effect:      Allow,
+				When:        "caps has can_bus or caps has industrial_io",
+				Permissions: []user_setting.PermissionKey{user_setting.PermHardwareIO},
+			},
+			{
+				ID:          "safety_override",
+				Effect:      Allow,
+				When:        "caps has safety_critical and trust >= admin",
+				Permissions: []user_setting.PermissionKey{user_setting.PermSafetyOverride},
+			},
+			{
+				ID:     "desktop_personal",
+				Effect: Allow,
+				When:   "caps has network",
+				Permissions: []user_setting.PermissionKey{
+					user_setting.PermBasicRuntime,
+					user_setting.PermConfigEdit,
+				},
+			},
+			{
+				ID:     "safety_critical",
+				Effect: Allow,
+				When:   "caps has safety_critical",
+				Permissions: []user_setting.PermissionKey{
+					user_setting.PermHardwareIO,
+					user_setting.PermDiagnostics,
+				},
+			},
+			{
+				ID:          "safety_critical_config",
+				Effect:      Deny,
+				When:        "caps has safety_critical",
+				Permissions: []user_setting.PermissionKey{user_setting.PermConfigEdit},
+				Reason:      "configuration is fixed on safety-critical units",
+			},
+			{
+				ID:          "secure_enclave",
+				Effect:      Allow,
+				When:        "caps has secure_enclave",
+				Permissions: []user_setting.PermissionKey{user_setting.PermAdmin},
+			},
 		},
-	},
-
-	{
-		Name:        "safety_critical",
-		RequireCaps: internal_environment.CapSafetyCritical,
-		Grant: []user_setting.PermissionKey{
-			user_setting.PermHardwareIO,
-			user_setting.PermDiagnostics,
-		},
-		Deny: []user_setting.PermissionKey{
-			user_setting.PermConfigEdit,
-		},
-	},
-
-	{
-		Name:        "secure_enclave",
-		RequireCaps: internal_environment.CapSecureEnclave,
-		Grant: []user_setting.PermissionKey{
-			user_setting.PermAdmin,
-		},
-	},
+	}
 }
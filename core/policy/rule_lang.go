@@ -0,0 +1,449 @@
+// core/policy/rule_lang.go
+
+package policy
+
+// Conditions are boolean expressions over the request attributes:
+//
+//	platform in [vehicle, robot] and entity == organization
+//	trust >= device or (tier == enterprise and session_age < 8h)
+//	caps has safety_critical and not service == personal_ai
+//
+// Attributes and their operators:
+//
+//	platform, entity, tier, service   == != in
+//	trust                             == != < <= > >= in  (untrusted < user < device < admin < system)
+//	session_age                       == != < <= > >=     (Go durations: 30m, 8h)
+//	caps                              has                 (one capability or [a, b] for all of them)
+//
+// "not" binds tighter than "and", which binds tighter than "or". Every
+// name and value is checked when the policy loads.
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	security_users "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/users"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+type condition interface {
+	eval(a *Attributes) bool
+}
+
+type attrKind int
+
+const (
+	kindEnum attrKind = iota
+	kindOrdered
+	kindDuration
+	kindSet
+)
+
+var attrKinds = map[string]attrKind{
+	"platform":    kindEnum,
+	"entity":      kindEnum,
+	"tier":        kindEnum,
+	"service":     kindEnum,
+	"trust":       kindOrdered,
+	"session_age": kindDuration,
+	"caps":        kindSet,
+}
+
+var kindOps = map[attrKind][]string{
+	kindEnum:     {"==", "!=", "in"},
+	kindOrdered:  {"==", "!=", "<", "<=", ">", ">=", "in"},
+	kindDuration: {"==", "!=", "<", "<=", ">", ">="},
+	kindSet:      {"has"},
+}
+
+var enumValues = map[string][]string{
+	"platform": {
+		string(internal_environment.PlatformComputer), string(internal_environment.PlatformMobile),
+		string(internal_environment.PlatformEmbedded), string(internal_environment.PlatformIndustrial),
+		string(internal_environment.PlatformVehicle), string(internal_environment.PlatformRobot),
+		string(internal_environment.PlatformUnknown),
+	},
+	"entity": {"personal", "organization", "stranger", "tester"},
+	"tier": {
+		string(user_setting.TierPersonal), string(user_setting.TierEnterprise),
+		string(user_setting.TierTester), string(user_setting.TierUnknown),
+	},
+	"service": {
+		string(user_setting.ServicePersonal), string(user_setting.ServiceEnterprise),
+		string(user_setting.ServiceSystem), string(user_setting.ServiceIndustrial),
+		string(user_setting.ServiceMobility), string(user_setting.ServiceUnknown),
+	},
+}
+
+// TrustLevels names the trust levels in ascending order.
+var TrustLevels = map[string]user_setting.TrustLevel{
+	"untrusted": user_setting.TrustUntrusted,
+	"user":      user_setting.TrustUser,
+	"device":    user_setting.TrustDevice,
+	"admin":     user_setting.TrustAdmin,
+	"system":    user_setting.TrustSystem,
+}
+
+// Capabilities names the capabilities usable with "caps has".
+var Capabilities = map[string]internal_environment.Capability{
+	"display":          internal_environment.CapDisplay,
+	"keyboard":         internal_environment.CapKeyboard,
+	"touch":            internal_environment.CapTouch,
+	"microphone":       internal_environment.CapMicrophone,
+	"speaker":          internal_environment.CapSpeaker,
+	"camera":           internal_environment.CapCamera,
+	"gpu":              internal_environment.CapGPU,
+	"secure_enclave":   internal_environment.CapSecureEnclave,
+	"network":          internal_environment.CapNetwork,
+	"can_bus":          internal_environment.CapCANBus,
+	"biometric":        internal_environment.CapBiometric,
+	"high_freq_sensor": internal_environment.CapHighFreqSensor,
+	"file_system":      internal_environment.CapFileSystem,
+	"safety_critical":  internal_environment.CapSafetyCritical,
+	"persistent_cloud": internal_environment.CapPersistentCloudLink,
+	"industrial_io":    internal_environment.CapIndustrialIO,
+	"local_storage":    internal_environment.CapLocalStorage,
+}
+
+// parseCondition compiles a rule condition. An empty source always holds.
+func parseCondition(src string) (condition, error) {
+	if strings.TrimSpace(src) == "" {
+		return always{}, nil
+	}
+
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{toks: toks}
+	c, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q", p.peek())
+	}
+	return c, nil
+}
+
+// ------------------------------------------------------------
+// Lexer
+// ------------------------------------------------------------
+
+func lex(src string) ([]string, error) {
+	var toks []string
+	r := []rune(src)
+
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.ContainsRune("()[],", c):
+			toks = append(toks, string(c))
+			i++
+		case strings.ContainsRune("=!<>", c):
+			if i+1 < len(r) && r[i+1] == '=' {
+				toks = append(toks, string(r[i:i+2]))
+				i += 2
+				continue
+			}
+			if c == '=' || c == '!' {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i)
+			}
+			toks = append(toks, string(c))
+			i++
+		case unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '.' || c == '-':
+			j := i
+			for j < len(r) && (unicode.IsLetter(r[j]) || unicode.IsDigit(r[j]) || r[j] == '_' || r[j] == '.' || r[j] == '-') {
+				j++
+			}
+			toks = append(toks, string(r[i:j]))
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", c, i)
+		}
+	}
+	return toks, nil
+}
+
+// ------------------------------------------------------------
+// Parser
+// ------------------------------------------------------------
+
+type parser struct {
+	toks []string
+	pos  int
+}
+
+func (p *parser) done() bool { return p.pos >= len(p.toks) }
+
+func (p *parser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *parser) expect(tok string) error {
+	if got := p.next(); got != tok {
+		if got == "" {
+			return fmt.Errorf("expected %q at end of condition", tok)
+		}
+		return fmt.Errorf("expected %q, got %q", tok, got)
+	}
+	return nil
+}
+
+func (p *parser) or() (condition, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "or" {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = orCond{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (condition, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "and" {
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = andCond{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (condition, error) {
+	switch p.peek() {
+	case "not":
+		p.next()
+		c, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return notCond{c}, nil
+	case "(":
+		p.next()
+		c, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return c, p.expect(")")
+	default:
+		return p.comparison()
+	}
+}
+
+func (p *parser) comparison() (condition, error) {
+	attr := p.next()
+	kind, ok := attrKinds[attr]
+	if !ok {
+		if attr == "" {
+			return nil, fmt.Errorf("condition ends early")
+		}
+		return nil, fmt.Errorf("unknown attribute %q", attr)
+	}
+
+	op := p.next()
+	if !contains(kindOps[kind], op) {
+		return nil, fmt.Errorf("%s does not support %q (use %s)", attr, op, strings.Join(kindOps[kind], " "))
+	}
+
+	var values []string
+	if op == "in" || (op == "has" && p.peek() == "[") {
+		list, err := p.list()
+		if err != nil {
+			return nil, err
+		}
+		values = list
+	} else {
+		v := p.next()
+		if v == "" {
+			return nil, fmt.Errorf("%s %s needs a value", attr, op)
+		}
+		values = []string{v}
+	}
+
+	return compile(attr, kind, op, values)
+}
+
+func (p *parser) list() ([]string, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	var out []string
+	for {
+		v := p.next()
+		if v == "" || v == "]" || v == "," {
+			return nil, fmt.Errorf("malformed list")
+		}
+		out = append(out, v)
+		switch p.next() {
+		case ",":
+		case "]":
+			return out, nil
+		default:
+			return nil, fmt.Errorf("malformed list")
+		}
+	}
+}
+
+// compile checks the values against the attribute domain and builds the
+// comparison.
+func compile(attr string, kind attrKind, op string, values []string) (condition, error) {
+	c := cmpCond{attr: attr, op: op}
+
+	switch kind {
+	case kindEnum:
+		for _, v := range values {
+			if !contains(enumValues[attr], v) {
+				return nil, fmt.Errorf("unknown %s %q (want %s)", attr, v, strings.Join(enumValues[attr], "|"))
+			}
+		}
+		c.strs = values
+
+	case kindOrdered:
+		for _, v := range values {
+			level, ok := TrustLevels[v]
+			if !ok {
+				return nil, fmt.Errorf("unknown trust level %q", v)
+			}
+			c.nums = append(c.nums, int64(level))
+		}
+
+	case kindDuration:
+		d, err := time.ParseDuration(values[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", attr, values[0], err)
+		}
+		c.nums = []int64{int64(d)}
+
+	case kindSet:
+		for _, v := range values {
+			cap, ok := Capabilities[v]
+			if !ok {
+				return nil, fmt.Errorf("unknown capability %q", v)
+			}
+			c.caps.Add(cap)
+		}
+	}
+
+	return c, nil
+}
+
+// ------------------------------------------------------------
+// Evaluation
+// ------------------------------------------------------------
+
+type always struct{}
+
+func (always) eval(*Attributes) bool { return true }
+
+type andCond struct{ l, r condition }
+
+func (c andCond) eval(a *Attributes) bool { return c.l.eval(a) && c.r.eval(a) }
+
+type orCond struct{ l, r condition }
+
+func (c orCond) eval(a *Attributes) bool { return c.l.eval(a) || c.r.eval(a) }
+
+type notCond struct{ c condition }
+
+func (c notCond) eval(a *Attributes) bool { return !c.c.eval(a) }
+
+type cmpCond struct {
+	attr string
+	op   string
+
+	strs []string
+	nums []int64
+	caps internal_environment.CapabilitySet
+}
+
+func (c cmpCond) eval(a *Attributes) bool {
+	switch c.attr {
+	case "platform":
+		return c.enum(string(a.Platform))
+	case "entity":
+		return c.enum(security_users.EntityName(a.Entity))
+	case "tier":
+		return c.enum(string(a.Tier))
+	case "service":
+		return c.enum(string(a.Service))
+	case "trust":
+		return c.ordered(int64(a.Trust))
+	case "session_age":
+		return c.ordered(int64(a.SessionAge))
+	case "caps":
+		return a.Caps.HasAll(c.caps)
+	}
+	return false
+}
+
+func (c cmpCond) enum(v string) bool {
+	switch c.op {
+	case "==":
+		return v == c.strs[0]
+	case "!=":
+		return v != c.strs[0]
+	default: // in
+		return contains(c.strs, v)
+	}
+}
+
+func (c cmpCond) ordered(v int64) bool {
+	switch c.op {
+	case "==":
+		return v == c.nums[0]
+	case "!=":
+		return v != c.nums[0]
+	case "<":
+		return v < c.nums[0]
+	case "<=":
+		return v <= c.nums[0]
+	case ">":
+		return v > c.nums[0]
+	case ">=":
+		return v >= c.nums[0]
+	default: // in
+		for _, n := range c.nums {
+			if v == n {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+// core/policy/rule_lang_test.go
+
+package policy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+func caps(names ...string) internal_environment.CapabilitySet {
+	var set internal_environment.CapabilitySet
+	for _, n := range names {
+		set.Add(Capabilities[n])
+	}
+	return set
+}
+
+func TestConditionEvaluation(t *testing.T) {
+	vehicle := Attributes{
+		Platform:   internal_environment.PlatformVehicle,
+		Entity:     internal_environment.EntityOrganization,
+		Tier:       user_setting.TierEnterprise,
+		Service:    user_setting.ServiceMobility,
+		Trust:      user_setting.TrustDevice,
+		SessionAge: 2 * time.Hour,
+		Caps:       caps("can_bus", "safety_critical"),
+	}
+
+	cases := []struct {
+		when string
+		want bool
+	}{
+		{"", true},
+		{"platform == vehicle", true},
+		{"platform != vehicle", false},
+		{"platform in [robot, vehicle]", true},
+		{"platform in [robot, computer]", false},
+		{"entity == organization and tier == enterprise", true},
+		{"service == autonomous_mobility", true},
+		{"trust >= device", true},
+		{"trust > device", false},
+		{"trust < admin", true},
+		{"trust in [user, admin]", false},
+		{"session_age < 8h", true},
+		{"session_age >= 2h", true},
+		{"session_age > 2h", false},
+		{"caps has can_bus", true},
+		{"caps has [can_bus, safety_critical]", true},
+		{"caps has [can_bus, network]", false},
+
+		// not binds tighter than and, and tighter than or
+		{"not platform == robot and trust >= device", true},
+		{"not (platform == vehicle and trust >= device)", false},
+		{"platform == robot and trust >= device or caps has can_bus", true},
+		{"platform == robot and (trust >= device or caps has can_bus)", false},
+		{"platform == robot or entity == personal and trust >= device", false},
+		{"not not caps has safety_critical", true},
+	}
+
+	for _, tc := range cases {
+		cond, err := parseCondition(tc.when)
+		if err != nil {
+			t.Errorf("%q: %v", tc.when, err)
+			continue
+		}
+		if got := cond.eval(&vehicle); got != tc.want {
+			t.Errorf("%q = %v, want %v", tc.when, got, tc.want)
+		}
+	}
+}
+
+func TestConditionRejectsInvalidSource(t *testing.T) {
+	cases := []struct {
+		when string
+		err  string
+	}{
+		{"colour == red", "unknown attribute"},
+		{"platform == spaceship", "unknown platform"},
+		{"platform < vehicle", "does not support"},
+		{"caps == can_bus", "does not support"},
+		{"session_age in [1h]", "does not support"},
+		{"trust >= root", "unknown trust level"},
+		{"session_age < soon", "invalid session_age"},
+		{"caps has warp_drive", "unknown capability"},
+		{"platform in [vehicle robot]", "malformed list"},
+		{"platform in []", "malformed list"},
+		{"(platform == vehicle", "expected \")\""},
+		{"platform == vehicle)", "unexpected"},
+		{"platform == vehicle and", "ends early"},
+		{"platform =", "unexpected"},
+		{"platform == vehicle & tier == tester", "unexpected"},
+		{"platform ==", "needs a value"},
+	}
+
+	for _, tc := range cases {
+		_, err := parseCondition(tc.when)
+		if err == nil {
+			t.Errorf("%q parsed", tc.when)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%q: error %q, want %q", tc.when, err, tc.err)
+		}
+	}
+}
+
+func TestDenyOverridesAllow(t *testing.T) {
+	set, err := NewSet(&Policy{
+		Name:    "site",
+		Version: 1,
+		Rules: []Rule{
+			{ID: "everyone", Effect: Allow, Permissions: []user_setting.PermissionKey{user_setting.PermConfigEdit}},
+			{ID: "not_on_robots", Effect: Deny, When: "platform == robot", Permissions: []user_setting.PermissionKey{user_setting.PermConfigEdit}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := set.Check(Attributes{Platform: internal_environment.PlatformComputer}, user_setting.PermConfigEdit); !d.Allowed {
+		t.Errorf("computer: %s", d.Reason)
+	}
+	if d := set.Check(Attributes{Platform: internal_environment.PlatformRobot}, user_setting.PermConfigEdit); d.Allowed {
+		t.Error("robot: deny rule did not override the allow")
+	}
+	if d := set.Check(Attributes{}, user_setting.PermAdmin); d.Allowed {
+		t.Error("a permission no rule grants was allowed")
+	}
+}
+
+func TestBuiltinFixtures(t *testing.T) {
+	set, err := NewSet(Builtin())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures, err := ReadFixtures("testdata/builtin_fixtures.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range RunFixtures(set, fixtures) {
+		if !r.Passed {
+			t.Errorf("%s: %s", r.Name, strings.Join(r.Failures, "; "))
+		}
+	}
+}
@@ -11,6 +11,7 @@ package router
 import (
 	"context"
 	"errors"
+	"sync"
 )
 
 type MessageType string
@@ -28,24 +29,73 @@ type Envelope struct {
 	Metadata map[string]string
 }
 
+// MetadataPriority is the Envelope metadata key carrying a command's
+// priority, from 0 (critical) to 10 (low).
+const MetadataPriority = "priority"
+
+// Handler consumes the envelopes a router dispatches.
+type Handler func(ctx context.Context, env Envelope) error
+
 type Router interface {
 	Start(ctx context.Context) error
 	Stop(ctx context.Context) error
 	Dispatch(ctx context.Context, env Envelope) error
 	Next(ctx context.Context) (interface{}, error)
+
+	// Handle makes h the consumer of dispatched envelopes. Start then
+	// drains the queue into h instead of leaving it to Next.
+	Handle(h Handler)
 }
 
+// DefaultRouter queues envelopes for its handler. Critical envelopes
+// (priority 0) never wait behind the queue: they run the handler directly
+// in Dispatch, or jump ahead of the queue for Next when no handler is set.
 type DefaultRouter struct {
-	queue chan interface{}
+	queue  chan interface{}
+	urgent chan interface{}
+
+	mu      sync.RWMutex
+	handler Handler
 }
 
 func NewDefaultRouter() *DefaultRouter {
 	return &DefaultRouter{
-		queue: make(chan interface{}, 1024),
+		queue:  make(chan interface{}, 1024),
+		urgent: make(chan interface{}, 64),
 	}
 }
 
+func (r *DefaultRouter) Handle(h Handler) {
+	r.mu.Lock()
+	r.handler = h
+	r.mu.Unlock()
+}
+
+func (r *DefaultRouter) handlerFunc() Handler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.handler
+}
+
+// Start runs the consumer loop when a handler is set. It ends with ctx or
+// when the router stops.
 func (r *DefaultRouter) Start(ctx context.Context) error {
+	h := r.handlerFunc()
+	if h == nil {
+		return nil
+	}
+
+	go func() {
+		for {
+			evt, err := r.Next(ctx)
+			if err != nil {
+				return
+			}
+			if env, ok := evt.(Envelope); ok {
+				_ = h(ctx, env)
+			}
+		}
+	}()
 	return nil
 }
 
@@ -55,6 +105,18 @@ func (r *DefaultRouter) Stop(ctx context.Context) error {
 }
 
 func (r *DefaultRouter) Dispatch(ctx context.Context, env Envelope) error {
+	if Critical(env) {
+		if h := r.handlerFunc(); h != nil {
+			return h(ctx, env)
+		}
+		select {
+		case r.urgent <- env:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
 	select {
 	case r.queue <- env:
 		return nil
@@ -63,8 +125,17 @@ func (r *DefaultRouter) Dispatch(ctx context.Context, env Envelope) error {
 	}
 }
 
+// Next returns the next envelope, critical ones first.
 func (r *DefaultRouter) Next(ctx context.Context) (interface{}, error) {
 	select {
+	case evt := <-r.urgent:
+		return evt, nil
+	default:
+	}
+
+	select {
+	case evt := <-r.urgent:
+		return evt, nil
 	case evt, ok := <-r.queue:
 		if !ok {
 			return nil, errors.New("router stopped")
@@ -75,6 +146,11 @@ func (r *DefaultRouter) Next(ctx context.Context) (interface{}, error) {
 	}
 }
 
+// Critical reports whether env carries priority 0.
+func Critical(env Envelope) bool {
+	return env.Metadata[MetadataPriority] == "0"
+}
+
 func New() Router {
 	return NewDefaultRouter()
 }
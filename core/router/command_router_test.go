@@ -0,0 +1,79 @@
+//core/router/command_router_test.go
+
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func envelope(source, priority string) Envelope {
+	return Envelope{Type: MessageControl, Source: source, Metadata: map[string]string{MetadataPriority: priority}}
+}
+
+func TestCriticalEnvelopeSkipsFullQueue(t *testing.T) {
+	r := NewDefaultRouter()
+	for i := 0; i < cap(r.queue); i++ {
+		if err := r.Dispatch(context.Background(), envelope("routine", "5")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := r.Dispatch(ctx, envelope("halt", "0")); err != nil {
+		t.Fatalf("critical dispatch into a full queue: %v", err)
+	}
+	evt, err := r.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env := evt.(Envelope); env.Source != "halt" {
+		t.Fatalf("Next returned %q before the critical envelope", env.Source)
+	}
+}
+
+func TestCriticalEnvelopeRunsHandlerDirectly(t *testing.T) {
+	r := NewDefaultRouter()
+	var handled []string
+	r.Handle(func(ctx context.Context, env Envelope) error {
+		handled = append(handled, env.Source)
+		return nil
+	})
+
+	if err := r.Dispatch(context.Background(), envelope("halt", "0")); err != nil {
+		t.Fatal(err)
+	}
+	if len(handled) != 1 || handled[0] != "halt" {
+		t.Fatalf("handled = %v, want [halt] before Dispatch returns", handled)
+	}
+}
+
+func TestStartDrainsQueueIntoHandler(t *testing.T) {
+	r := NewDefaultRouter()
+	got := make(chan string, 1)
+	r.Handle(func(ctx context.Context, env Envelope) error {
+		got <- env.Source
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := r.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Dispatch(ctx, envelope("move", "5")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case src := <-got:
+		if src != "move" {
+			t.Fatalf("handled %q, want move", src)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("queued envelope never reached the handler")
+	}
+}
@@ -0,0 +1,59 @@
+//core/router/session_guard.go
+
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// MetadataSessionToken is the Envelope metadata key carrying the sender's
+// session token.
+const MetadataSessionToken = "session_token"
+
+// MetadataSessionID is set by GuardedRouter after verification so handlers
+// downstream know which session sent the message.
+const MetadataSessionID = "session_id"
+
+// TokenVerifier is implemented by verification_identity.TokenService.
+type TokenVerifier interface {
+	Verify(token string) (*user_setting.SessionClaims, error)
+}
+
+// GuardedRouter verifies the session token of every envelope before
+// passing it to the wrapped router.
+type GuardedRouter struct {
+	Router
+	verifier TokenVerifier
+}
+
+func NewGuardedRouter(inner Router, verifier TokenVerifier) *GuardedRouter {
+	return &GuardedRouter{Router: inner, verifier: verifier}
+}
+
+func (g *GuardedRouter) Dispatch(ctx context.Context, env Envelope) error {
+	token := env.Metadata[MetadataSessionToken]
+	if token == "" {
+		return errors.New("envelope missing session token")
+	}
+
+	claims, err := g.verifier.Verify(token)
+	if err != nil {
+		return fmt.Errorf("envelope from %s rejected: %w", env.Source, err)
+	}
+
+	// The token is not forwarded; modules only see the session it proved.
+	meta := make(map[string]string, len(env.Metadata))
+	for k, v := range env.Metadata {
+		if k != MetadataSessionToken {
+			meta[k] = v
+		}
+	}
+	meta[MetadataSessionID] = claims.SessionID
+	env.Metadata = meta
+
+	return g.Router.Dispatch(ctx, env)
+}
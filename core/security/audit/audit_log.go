@@ -0,0 +1,217 @@
+//core/security/audit/audit_log.go
+
+package security_audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+const (
+	entriesCollection     = "audit"
+	checkpointsCollection = "audit_checkpoints"
+	metaCollection        = "audit_meta"
+	headKey               = "head"
+
+	// DefaultCheckpointInterval is how many entries are appended between
+	// signed checkpoints.
+	DefaultCheckpointInterval = 100
+)
+
+// Topic is the bus topic every module publishes audit events on.
+const Topic = "audit.events"
+
+// Event is what callers submit. Seq, chaining and time are filled in by
+// the log.
+type Event struct {
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	Permission string          `json:"permission,omitempty"`
+	Resource   string          `json:"resource,omitempty"`
+	Result     string          `json:"result,omitempty"`
+	Detail     json.RawMessage `json:"detail,omitempty"`
+}
+
+// Entry is one record of the chain. Hash covers every other field,
+// including Prev, so removing or editing an entry breaks every later link.
+type Entry struct {
+	Seq  uint64    `json:"seq"`
+	At   time.Time `json:"at"`
+	Prev string    `json:"prev"`
+	Hash string    `json:"hash"`
+	Event
+}
+
+// Checkpoint pins the chain head at Seq with the device key.
+type Checkpoint struct {
+	Seq         uint64    `json:"seq"`
+	Hash        string    `json:"hash"`
+	At          time.Time `json:"at"`
+	Certificate []byte    `json:"certificate"`
+	Signature   []byte    `json:"signature"`
+}
+
+type head struct {
+	Seq  uint64 `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+var genesis = hex.EncodeToString(make([]byte, sha256.Size))
+
+// Log is the durable append-only audit log.
+type Log struct {
+	vault    verification_persistence.VaultStore
+	device   *verification_identity.DeviceIdentity
+	interval uint64
+
+	mu sync.Mutex
+}
+
+// NewLog opens the audit log stored in vault. device signs checkpoints; if
+// it is nil, entries are still chained but no checkpoints are written.
+func NewLog(vault verification_persistence.VaultStore, device *verification_identity.DeviceIdentity) *Log {
+	return &Log{
+		vault:    vault,
+		device:   device,
+		interval: DefaultCheckpointInterval,
+	}
+}
+
+// SetCheckpointInterval changes how often checkpoints are signed.
+func (l *Log) SetCheckpointInterval(n uint64) {
+	if n > 0 {
+		l.interval = n
+	}
+}
+
+// Append adds ev to the chain and returns the stored entry. Entries are
+// created exclusively, so processes sharing the vault (the runtime and the
+// CLI) extend one chain rather than each writing its own next entry.
+func (l *Log) Append(ev Event) (*Entry, error) {
+	if ev.Actor == "" || ev.Action == "" {
+		return nil, errors.New("audit event requires actor and action")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var entry Entry
+
+	err := verification_persistence.Atomically(l.vault, func(tx verification_persistence.VaultTx) error {
+		for {
+			h, err := readHead(tx)
+			if err != nil {
+				return err
+			}
+
+			entry = Entry{
+				Seq:   h.Seq + 1,
+				At:    time.Now().UTC(),
+				Prev:  h.Hash,
+				Event: ev,
+			}
+			entry.Hash = entry.computeHash()
+
+			// Another process sharing the vault took this sequence number:
+			// chain after its entry instead of forking.
+			err = verification_persistence.Create(tx, entriesCollection, seqKey(entry.Seq), entry)
+			if errors.Is(err, verification_persistence.ErrRecordExists) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			break
+		}
+
+		if err := tx.Write(metaCollection, headKey, head{Seq: entry.Seq, Hash: entry.Hash}); err != nil {
+			return err
+		}
+
+		if l.device != nil && entry.Seq%l.interval == 0 {
+			return tx.Write(checkpointsCollection, seqKey(entry.Seq), l.checkpoint(entry.Seq, entry.Hash))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Checkpoint signs the current head immediately, e.g. at shutdown.
+func (l *Log) Checkpoint() (*Checkpoint, error) {
+	if l.device == nil {
+		return nil, errors.New("no device identity to sign checkpoint")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h, err := readHead(l.vault)
+	if err != nil || h.Seq == 0 {
+		return nil, err
+	}
+
+	cp := l.checkpoint(h.Seq, h.Hash)
+	if err := l.vault.Write(checkpointsCollection, seqKey(h.Seq), cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func (l *Log) checkpoint(seq uint64, hash string) Checkpoint {
+	cp := Checkpoint{
+		Seq:         seq,
+		Hash:        hash,
+		At:          time.Now().UTC(),
+		Certificate: l.device.Certificate,
+	}
+	cp.Signature = l.device.Sign(cp.signedBytes())
+	return cp
+}
+
+func (c *Checkpoint) signedBytes() []byte {
+	return []byte(fmt.Sprintf("aios-audit-checkpoint\x00%d\x00%s\x00%d", c.Seq, c.Hash, c.At.UnixNano()))
+}
+
+func (e *Entry) computeHash() string {
+	unhashed := *e
+	unhashed.Hash = ""
+	data, _ := json.Marshal(unhashed)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// readHead returns the last entry of the chain. The stored head is only a
+// hint: an entry another process appended after it was read, or whose
+// head write was cut short, is followed from there.
+func readHead(tx verification_persistence.VaultTx) (head, error) {
+	h := head{Hash: genesis}
+	if _, err := tx.Read(metaCollection, headKey, &h); err != nil {
+		return h, err
+	}
+
+	for {
+		var next Entry
+		found, err := tx.Read(entriesCollection, seqKey(h.Seq+1), &next)
+		if err != nil || !found {
+			return h, err
+		}
+		h = head{Seq: next.Seq, Hash: next.Hash}
+	}
+}
+
+// seqKey zero-pads so lexical key order matches sequence order.
+func seqKey(seq uint64) string {
+	return fmt.Sprintf("%016d", seq)
+}
@@ -0,0 +1,73 @@
+//core/security/audit/audit_log_test.go
+
+package security_audit
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+// Two logs over one directory vault stand in for the runtime and a CLI
+// process appending at the same time.
+func TestAppendSharedVaultKeepsOneChain(t *testing.T) {
+	vault := &verification_persistence.IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{1}, 32)}
+	logs := []*Log{NewLog(vault, nil), NewLog(vault, nil)}
+
+	const perLog = 25
+	var wg sync.WaitGroup
+	for i, l := range logs {
+		wg.Add(1)
+		go func(i int, l *Log) {
+			defer wg.Done()
+			for n := 0; n < perLog; n++ {
+				if _, err := l.Append(Event{Actor: fmt.Sprintf("proc%d", i), Action: "test.append"}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i, l)
+	}
+	wg.Wait()
+
+	report, err := Verify(vault, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() {
+		t.Fatalf("chain forked: %+v", report.Problems)
+	}
+	if report.Entries != 2*perLog {
+		t.Errorf("chain holds %d entries, want %d", report.Entries, 2*perLog)
+	}
+}
+
+func TestReadHeadFollowsUnrecordedEntries(t *testing.T) {
+	vault := &verification_persistence.IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{1}, 32)}
+	l := NewLog(vault, nil)
+
+	first, err := l.Append(Event{Actor: "a", Action: "one"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := l.Append(Event{Actor: "a", Action: "two"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A writer that stopped between its entry and its head update.
+	if err := vault.Write(metaCollection, headKey, head{Seq: first.Seq, Hash: first.Hash}); err != nil {
+		t.Fatal(err)
+	}
+
+	third, err := l.Append(Event{Actor: "a", Action: "three"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Seq != 3 || third.Prev != second.Hash {
+		t.Errorf("appended seq %d after %s, want 3 after entry 2", third.Seq, third.Prev)
+	}
+}
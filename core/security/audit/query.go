@@ -0,0 +1,69 @@
+//core/security/audit/query.go
+
+package security_audit
+
+import (
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+// Filter selects entries for Query. Zero fields match everything.
+type Filter struct {
+	Actor      string
+	Permission string
+	Action     string
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+}
+
+func (f Filter) match(e *Entry) bool {
+	if f.Actor != "" && e.Actor != f.Actor {
+		return false
+	}
+	if f.Permission != "" && e.Permission != f.Permission {
+		return false
+	}
+	if f.Action != "" && e.Action != f.Action {
+		return false
+	}
+	if !f.Since.IsZero() && e.At.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.At.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Query returns matching entries in sequence order.
+func Query(v verification_persistence.VaultStore, f Filter) ([]Entry, error) {
+	var out []Entry
+
+	err := view(v, func(tx verification_persistence.VaultTx) error {
+		h, err := readHead(tx)
+		if err != nil {
+			return err
+		}
+
+		for seq := uint64(1); seq <= h.Seq; seq++ {
+			var e Entry
+			found, err := tx.Read(entriesCollection, seqKey(seq), &e)
+			if err != nil {
+				return err
+			}
+			if !found || !f.match(&e) {
+				continue
+			}
+
+			out = append(out, e)
+			if f.Limit > 0 && len(out) >= f.Limit {
+				return nil
+			}
+		}
+		return nil
+	})
+
+	return out, err
+}
@@ -0,0 +1,130 @@
+//core/security/audit/verify.go
+
+package security_audit
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"sort"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+// Problem kinds reported by Verify.
+const (
+	ProblemMissing    = "entry_missing"
+	ProblemModified   = "entry_modified"
+	ProblemBrokenLink = "chain_broken"
+	ProblemCheckpoint = "checkpoint_invalid"
+	ProblemTruncated  = "log_truncated"
+)
+
+type Problem struct {
+	Seq    uint64 `json:"seq"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// VerifyReport is the outcome of walking the whole chain.
+type VerifyReport struct {
+	Entries     uint64    `json:"entries"`
+	Checkpoints int       `json:"checkpoints"`
+	Head        string    `json:"head"`
+	Problems    []Problem `json:"problems,omitempty"`
+}
+
+func (r *VerifyReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *VerifyReport) add(seq uint64, kind, format string, args ...interface{}) {
+	r.Problems = append(r.Problems, Problem{Seq: seq, Kind: kind, Detail: fmt.Sprintf(format, args...)})
+}
+
+// Verify recomputes every entry hash and link and checks each checkpoint
+// signature against devicePub. Deleting or editing an entry shows up as a
+// missing entry, a modified entry or a broken link; cutting entries off the
+// end shows up against the last signed checkpoint.
+func Verify(v verification_persistence.VaultStore, devicePub ed25519.PublicKey) (*VerifyReport, error) {
+	report := &VerifyReport{}
+
+	err := view(v, func(tx verification_persistence.VaultTx) error {
+		h, err := readHead(tx)
+		if err != nil {
+			return err
+		}
+		report.Entries = h.Seq
+		report.Head = h.Hash
+
+		hashes := make(map[uint64]string, h.Seq)
+		prev := genesis
+
+		for seq := uint64(1); seq <= h.Seq; seq++ {
+			var e Entry
+			found, err := tx.Read(entriesCollection, seqKey(seq), &e)
+			if err != nil {
+				return err
+			}
+			if !found {
+				report.add(seq, ProblemMissing, "entry %d not found", seq)
+				prev = ""
+				continue
+			}
+
+			if e.Seq != seq || e.computeHash() != e.Hash {
+				report.add(seq, ProblemModified, "entry %d content does not match its hash", seq)
+			}
+			if prev != "" && e.Prev != prev {
+				report.add(seq, ProblemBrokenLink, "entry %d does not link to entry %d", seq, seq-1)
+			}
+
+			hashes[seq] = e.Hash
+			prev = e.Hash
+		}
+
+		if prev != "" && prev != h.Hash {
+			report.add(h.Seq, ProblemBrokenLink, "head does not match last entry")
+		}
+
+		keys, err := tx.List(checkpointsCollection)
+		if err != nil {
+			return err
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			var cp Checkpoint
+			if _, err := tx.Read(checkpointsCollection, k, &cp); err != nil {
+				return err
+			}
+			report.Checkpoints++
+
+			if !verification_identity.VerifySignature(devicePub, cp.signedBytes(), cp.Signature) {
+				report.add(cp.Seq, ProblemCheckpoint, "checkpoint %d signature invalid", cp.Seq)
+				continue
+			}
+			if cp.Seq > h.Seq {
+				report.add(cp.Seq, ProblemTruncated, "signed checkpoint at %d but log ends at %d", cp.Seq, h.Seq)
+				continue
+			}
+			if hashes[cp.Seq] != cp.Hash {
+				report.add(cp.Seq, ProblemCheckpoint, "entry %d differs from signed checkpoint", cp.Seq)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func view(v verification_persistence.VaultStore, fn func(tx verification_persistence.VaultTx) error) error {
+	if ts, ok := v.(verification_persistence.TransactionalStore); ok {
+		return ts.View(fn)
+	}
+	return fn(v)
+}
@@ -0,0 +1,91 @@
+//core/security/authenticator/authenticate.go
+
+package security_authenticator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+// DeviceContext is the challenge context of this unit: its machine ID, so
+// a response captured here cannot be replayed against another device.
+func DeviceContext(v verification_persistence.VaultStore) (string, error) {
+	id, err := verification_identity.LoadDeviceIdentity(v)
+	if err != nil {
+		return "", err
+	}
+	if id == nil {
+		return "unprovisioned", nil
+	}
+	return id.MachineID, nil
+}
+
+// Run sends a fresh challenge through a and verifies the answer
+// against the enrollments of userID. machineID binds the challenge to this
+// unit.
+func Run(ctx context.Context, v verification_persistence.VaultStore, userID, machineID string, a Authenticator) error {
+	ch, err := NewChallenge(a.Kind(), machineID)
+	if err != nil {
+		return err
+	}
+
+	resp, err := a.Respond(ctx, ch)
+	if err != nil {
+		return err
+	}
+	return Verify(v, userID, ch, resp)
+}
+
+// RunStep tries every configured device whose kind is in step and for
+// which userID holds a token, until one verifies. The returned error joins
+// the per-device failures.
+func RunStep(ctx context.Context, v verification_persistence.VaultStore, cfg *Config, userID, machineID string, step Step) (Kind, error) {
+	var errs []error
+
+	for _, kind := range step.AnyOf {
+		enrolled, err := HasKind(v, userID, kind)
+		if err != nil {
+			return "", err
+		}
+		if !enrolled {
+			errs = append(errs, fmt.Errorf("%s: %w", kind, ErrNotEnrolled))
+			continue
+		}
+
+		devices := cfg.DevicesFor(kind)
+		if len(devices) == 0 {
+			errs = append(errs, fmt.Errorf("%s: %w (declare one with aios token add-device)", kind, ErrNoDeviceAvailable))
+			continue
+		}
+
+		for _, dev := range devices {
+			a, err := Open(dev)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s via %s: %w", kind, dev.Transport, err))
+				continue
+			}
+
+			err = Run(ctx, v, userID, machineID, a)
+			_ = a.Close()
+			if err == nil {
+				return kind, nil
+			}
+			errs = append(errs, fmt.Errorf("%s via %s: %w", kind, dev.Transport, err))
+		}
+	}
+
+	return "", fmt.Errorf("%s verification failed: %w", stepName(step), errors.Join(errs...))
+}
+
+func stepName(step Step) string {
+	names := make([]string, len(step.AnyOf))
+	for i, k := range step.AnyOf {
+		names[i] = string(k)
+	}
+	return strings.Join(names, " / ")
+}
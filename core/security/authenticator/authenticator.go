@@ -0,0 +1,151 @@
+//core/security/authenticator/authenticator.go
+
+package security_authenticator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kind names a class of external token.
+type Kind string
+
+const (
+	KindKeyFob        Kind = "key_fob"
+	KindNFCCard       Kind = "nfc_card"
+	KindPairingButton Kind = "pairing_button"
+	KindBiometric     Kind = "biometric"
+	KindCompanionApp  Kind = "companion_app"
+	KindServiceKey    Kind = "service_key" // mechanic / tester dongle
+)
+
+// Algorithm is how a token proves possession of its key.
+type Algorithm string
+
+const (
+	AlgHMACSHA256 Algorithm = "hmac-sha256"
+	AlgEd25519    Algorithm = "ed25519"
+)
+
+// ChallengeMaxAge bounds the time between issuing a challenge and
+// verifying the answer, covering a user walking up to a reader.
+const ChallengeMaxAge = 30 * time.Second
+
+const protocolVersion = 1
+
+var (
+	ErrNotEnrolled       = errors.New("authenticator_not_enrolled")
+	ErrBadResponse       = errors.New("authenticator_response_invalid")
+	ErrCounterReplayed   = errors.New("authenticator_counter_replayed")
+	ErrChallengeExpired  = errors.New("authenticator_challenge_expired")
+	ErrUnknownTransport  = errors.New("authenticator_transport_unknown")
+	ErrNoDeviceAvailable = errors.New("authenticator_device_unavailable")
+)
+
+// Challenge is sent to the token. Context binds the answer to this unit
+// (its machine ID) so a response cannot be relayed to another device.
+type Challenge struct {
+	Version  int       `json:"v"`
+	Kind     Kind      `json:"kind"`
+	Nonce    []byte    `json:"nonce"`
+	Context  string    `json:"context"`
+	IssuedAt time.Time `json:"issued_at"`
+}
+
+// Response is the token's answer. Counter must grow with every answer the
+// token gives; one that does not is a replay or a cloned token.
+type Response struct {
+	TokenID   string `json:"token_id"`
+	Counter   uint64 `json:"counter"`
+	Signature []byte `json:"signature"`
+}
+
+// NewChallenge creates a fresh challenge for kind.
+func NewChallenge(kind Kind, context string) (*Challenge, error) {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &Challenge{
+		Version:  protocolVersion,
+		Kind:     kind,
+		Nonce:    nonce,
+		Context:  context,
+		IssuedAt: time.Now().UTC(),
+	}, nil
+}
+
+// SignedMessage is what the token signs or MACs for the answer carrying
+// counter. Every field is length prefixed so no two challenges share an
+// encoding.
+func (c *Challenge) SignedMessage(tokenID string, counter uint64) []byte {
+	var msg []byte
+	for _, part := range [][]byte{
+		[]byte("aios-authenticator-v1"),
+		[]byte(c.Kind),
+		[]byte(tokenID),
+		[]byte(c.Context),
+		c.Nonce,
+	} {
+		msg = binary.BigEndian.AppendUint32(msg, uint32(len(part)))
+		msg = append(msg, part...)
+	}
+	msg = binary.BigEndian.AppendUint64(msg, uint64(c.IssuedAt.UnixNano()))
+	return binary.BigEndian.AppendUint64(msg, counter)
+}
+
+// Authenticator is a plugin driving one physical token reader. It only
+// relays the challenge; verification against the user's enrollment is done
+// by Verify so a misbehaving plugin cannot grant access.
+type Authenticator interface {
+	Kind() Kind
+	Respond(ctx context.Context, ch *Challenge) (*Response, error)
+	Close() error
+}
+
+// Factory opens an Authenticator for a configured device.
+type Factory func(dev DeviceConfig) (Authenticator, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// RegisterTransport makes a transport available to DeviceConfig.Transport.
+// The built-in transports are tcp, serial, hid and simulator.
+func RegisterTransport(name string, f Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = f
+}
+
+// Transports lists the registered transport names.
+func Transports() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open returns the Authenticator for dev.
+func Open(dev DeviceConfig) (Authenticator, error) {
+	registryMu.RLock()
+	f, ok := registry[dev.Transport]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTransport, dev.Transport)
+	}
+	return f(dev)
+}
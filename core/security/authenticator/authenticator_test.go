@@ -0,0 +1,218 @@
+//core/security/authenticator/authenticator_test.go
+
+package security_authenticator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+func testVault(t *testing.T) verification_persistence.VaultStore {
+	t.Helper()
+	return &verification_persistence.IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{1}, 32)}
+}
+
+// answer challenges sim for kind on machine and returns both halves.
+func answer(t *testing.T, sim *Simulator, kind Kind, machine string) (*Challenge, *Response) {
+	t.Helper()
+	ch, err := NewChallenge(kind, machine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := sim.Respond(context.Background(), ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ch, resp
+}
+
+func TestEnrolledTokensVerify(t *testing.T) {
+	v := testVault(t)
+
+	secret, err := EnrollHMAC(v, "alice", KindKeyFob, "fob-1", "car key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Run(context.Background(), v, "alice", "unit-1", NewHMACSimulator(KindKeyFob, "fob-1", secret)); err != nil {
+		t.Fatalf("hmac token = %v", err)
+	}
+
+	reader, err := NewEd25519Simulator(KindBiometric, "finger-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EnrollEd25519(v, "alice", KindBiometric, "finger-1", "", reader.PublicKey()); err != nil {
+		t.Fatal(err)
+	}
+	if err := Run(context.Background(), v, "alice", "unit-1", reader); err != nil {
+		t.Fatalf("ed25519 token = %v", err)
+	}
+
+	tokens, err := Tokens(v, "alice")
+	if err != nil || len(tokens) != 2 {
+		t.Fatalf("tokens = %+v, %v", tokens, err)
+	}
+	for _, tok := range tokens {
+		if tok.LastUsed.IsZero() {
+			t.Errorf("%s: use not recorded", tok.TokenID)
+		}
+	}
+
+	if _, err := EnrollHMAC(v, "alice", KindKeyFob, "fob-1", ""); err == nil {
+		t.Error("token enrolled twice")
+	}
+	if err := EnrollEd25519(v, "alice", KindBiometric, "short", "", reader.PublicKey()[:16]); err == nil {
+		t.Error("short public key enrolled")
+	}
+}
+
+func TestVerifyChecksTheSignature(t *testing.T) {
+	v := testVault(t)
+
+	enrolled, err := NewEd25519Simulator(KindKeyFob, "fob-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EnrollEd25519(v, "alice", KindKeyFob, "fob-1", "", enrolled.PublicKey()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another key claiming the enrolled token ID.
+	impostor, err := NewEd25519Simulator(KindKeyFob, "fob-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, resp := answer(t, impostor, KindKeyFob, "unit-1")
+	if err := Verify(v, "alice", ch, resp); !errors.Is(err, ErrBadResponse) {
+		t.Errorf("other key = %v, want %v", err, ErrBadResponse)
+	}
+
+	// An answer given to another unit does not carry over.
+	ch, resp = answer(t, enrolled, KindKeyFob, "unit-2")
+	ch.Context = "unit-1"
+	if err := Verify(v, "alice", ch, resp); !errors.Is(err, ErrBadResponse) {
+		t.Errorf("relayed answer = %v, want %v", err, ErrBadResponse)
+	}
+
+	// A raised counter must be signed too.
+	ch, resp = answer(t, enrolled, KindKeyFob, "unit-1")
+	resp.Counter += 100
+	if err := Verify(v, "alice", ch, resp); !errors.Is(err, ErrBadResponse) {
+		t.Errorf("altered counter = %v, want %v", err, ErrBadResponse)
+	}
+
+	ch, resp = answer(t, enrolled, KindKeyFob, "unit-1")
+	ch.IssuedAt = ch.IssuedAt.Add(-ChallengeMaxAge - time.Second)
+	if err := Verify(v, "alice", ch, resp); !errors.Is(err, ErrChallengeExpired) {
+		t.Errorf("stale challenge = %v, want %v", err, ErrChallengeExpired)
+	}
+
+	ch, resp = answer(t, enrolled, KindKeyFob, "unit-1")
+	if err := Verify(v, "bob", ch, resp); !errors.Is(err, ErrNotEnrolled) {
+		t.Errorf("other user = %v, want %v", err, ErrNotEnrolled)
+	}
+	ch.Kind = KindNFCCard
+	if err := Verify(v, "alice", ch, resp); !errors.Is(err, ErrNotEnrolled) {
+		t.Errorf("other kind = %v, want %v", err, ErrNotEnrolled)
+	}
+}
+
+func TestVerifyRefusesACounterThatDidNotGrow(t *testing.T) {
+	v := testVault(t)
+
+	secret, err := EnrollHMAC(v, "alice", KindKeyFob, "fob-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fob := NewHMACSimulator(KindKeyFob, "fob-1", secret)
+
+	for i := 0; i < 3; i++ {
+		if err := Run(context.Background(), v, "alice", "unit-1", fob); err != nil {
+			t.Fatalf("use %d = %v", i, err)
+		}
+	}
+
+	ch, resp := answer(t, fob, KindKeyFob, "unit-1")
+	if err := Verify(v, "alice", ch, resp); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(v, "alice", ch, resp); !errors.Is(err, ErrCounterReplayed) {
+		t.Errorf("replayed answer = %v, want %v", err, ErrCounterReplayed)
+	}
+
+	// A copy of the key starts counting again from zero.
+	clone := NewHMACSimulator(KindKeyFob, "fob-1", secret)
+	if err := Run(context.Background(), v, "alice", "unit-1", clone); !errors.Is(err, ErrCounterReplayed) {
+		t.Errorf("cloned token = %v, want %v", err, ErrCounterReplayed)
+	}
+	if err := Run(context.Background(), v, "alice", "unit-1", fob); err != nil {
+		t.Errorf("original token after the clone = %v", err)
+	}
+}
+
+func TestRevokeAllRemovesEveryToken(t *testing.T) {
+	v := testVault(t)
+
+	secret, err := EnrollHMAC(v, "alice", KindKeyFob, "fob-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := EnrollHMAC(v, "alice", KindNFCCard, "card-1", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := EnrollHMAC(v, "bob", KindNFCCard, "card-2", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Revoke(v, "alice", "card-1"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := HasKind(v, "alice", KindNFCCard); ok {
+		t.Error("revoked card still enrolled")
+	}
+	if err := Revoke(v, "alice", "card-1"); !errors.Is(err, ErrNotEnrolled) {
+		t.Errorf("second revoke = %v, want %v", err, ErrNotEnrolled)
+	}
+
+	if err := RevokeAll(v, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if tokens, err := Tokens(v, "alice"); err != nil || len(tokens) != 0 {
+		t.Errorf("tokens after RevokeAll = %+v, %v", tokens, err)
+	}
+	if err := Run(context.Background(), v, "alice", "unit-1", NewHMACSimulator(KindKeyFob, "fob-1", secret)); !errors.Is(err, ErrNotEnrolled) {
+		t.Errorf("revoked fob = %v, want %v", err, ErrNotEnrolled)
+	}
+	if ok, _ := HasKind(v, "bob", KindNFCCard); !ok {
+		t.Error("RevokeAll removed another user's token")
+	}
+}
+
+func TestRunStepUsesTheConfiguredDevices(t *testing.T) {
+	v := testVault(t)
+	step := Step{AnyOf: []Kind{KindKeyFob, KindBiometric}}
+
+	secret, err := EnrollHMAC(v, "alice", KindKeyFob, "fob-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{}
+	if _, err := RunStep(context.Background(), v, cfg, "alice", "unit-1", step); !errors.Is(err, ErrNoDeviceAvailable) || !errors.Is(err, ErrNotEnrolled) {
+		t.Fatalf("no reader = %v", err)
+	}
+
+	AttachSimulator("test-fob", NewHMACSimulator(KindKeyFob, "fob-1", secret))
+	defer DetachSimulator("test-fob")
+	cfg.Devices = []DeviceConfig{{Kind: KindKeyFob, Transport: "simulator", Address: "test-fob"}}
+
+	kind, err := RunStep(context.Background(), v, cfg, "alice", "unit-1", step)
+	if err != nil || kind != KindKeyFob {
+		t.Fatalf("RunStep = %s, %v", kind, err)
+	}
+}
@@ -0,0 +1,131 @@
+//core/security/authenticator/config.go
+
+package security_authenticator
+
+import (
+	"errors"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+)
+
+const (
+	configCollection = "authenticator_config"
+	configKey        = "current"
+)
+
+var ErrNoRule = errors.New("authenticator_no_rule")
+
+// DeviceConfig locates one attached token reader.
+type DeviceConfig struct {
+	Kind      Kind   `json:"kind"`
+	Transport string `json:"transport"` // tcp, serial, hid, simulator, or a registered plugin
+	Address   string `json:"address"`   // host:port, /dev node, or simulator name
+}
+
+// Step passes when a token of any of the listed kinds answers.
+type Step struct {
+	AnyOf []Kind `json:"any_of"`
+}
+
+// Rule lists the steps for a platform and entity combination. Empty
+// Platforms or Entities match anything; an empty Steps list means the
+// password alone is enough.
+type Rule struct {
+	Platforms []internal_environment.PlatformClass `json:"platforms,omitempty"`
+	Entities  []string                             `json:"entities,omitempty"` // personal, organization, stranger, tester
+	Steps     []Step                               `json:"steps"`
+}
+
+// Config selects authenticators at login. Rules are tried in order and
+// the first match wins.
+type Config struct {
+	Rules   []Rule         `json:"rules"`
+	Devices []DeviceConfig `json:"devices"`
+}
+
+// DefaultConfig reproduces the built-in platform behaviour. No devices are
+// configured, so hardware steps fail until the integrator declares them
+// with `aios token add-device` (see Provisioning in the README).
+var DefaultConfig = Config{
+	Rules: []Rule{
+		{
+			Platforms: []internal_environment.PlatformClass{internal_environment.PlatformVehicle, internal_environment.PlatformRobot},
+			Entities:  []string{"personal"},
+			Steps:     []Step{{AnyOf: []Kind{KindKeyFob, KindBiometric}}},
+		},
+		{
+			Platforms: []internal_environment.PlatformClass{internal_environment.PlatformVehicle, internal_environment.PlatformRobot},
+			Entities:  []string{"organization"},
+			Steps:     []Step{{AnyOf: []Kind{KindBiometric}}, {AnyOf: []Kind{KindCompanionApp}}},
+		},
+		{
+			Platforms: []internal_environment.PlatformClass{internal_environment.PlatformVehicle, internal_environment.PlatformRobot},
+			Entities:  []string{"tester"},
+			Steps:     []Step{{AnyOf: []Kind{KindServiceKey}}},
+		},
+		{
+			Platforms: []internal_environment.PlatformClass{internal_environment.PlatformVehicle, internal_environment.PlatformRobot},
+			Entities:  []string{"stranger"},
+		},
+		{
+			Platforms: []internal_environment.PlatformClass{internal_environment.PlatformIndustrial, internal_environment.PlatformEmbedded},
+			Steps:     []Step{{AnyOf: []Kind{KindNFCCard, KindPairingButton}}},
+		},
+		{
+			Platforms: []internal_environment.PlatformClass{internal_environment.PlatformComputer, internal_environment.PlatformMobile},
+		},
+	},
+}
+
+// Select returns the first rule matching platform and entity.
+func (c *Config) Select(platform internal_environment.PlatformClass, entity string) (*Rule, error) {
+	for i := range c.Rules {
+		r := &c.Rules[i]
+		if matches(r.Platforms, platform) && matches(r.Entities, entity) {
+			return r, nil
+		}
+	}
+	return nil, ErrNoRule
+}
+
+// DevicesFor returns the configured devices of kind.
+func (c *Config) DevicesFor(kind Kind) []DeviceConfig {
+	var out []DeviceConfig
+	for _, d := range c.Devices {
+		if d.Kind == kind {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func matches[T comparable](list []T, v T) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadConfig returns the stored configuration, or DefaultConfig.
+func LoadConfig(v verification_persistence.VaultStore) (*Config, error) {
+	var c Config
+	found, err := v.Read(configCollection, configKey, &c)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		c = DefaultConfig
+	}
+	return &c, nil
+}
+
+// SaveConfig replaces the stored configuration.
+func SaveConfig(v verification_persistence.VaultStore, c *Config) error {
+	return v.Write(configCollection, configKey, c)
+}
@@ -0,0 +1,222 @@
+//core/security/authenticator/enrollment.go
+
+package security_authenticator
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+const (
+	enrollmentCollection = "authenticators"
+
+	hmacSecretSize = 32
+)
+
+// Enrollment binds one token to one user. HMAC secrets are only ever held
+// sealed under the vault key.
+type Enrollment struct {
+	TokenID    string    `json:"token_id"`
+	Kind       Kind      `json:"kind"`
+	Algorithm  Algorithm `json:"algorithm"`
+	PublicKey  []byte    `json:"public_key,omitempty"`
+	Secret     []byte    `json:"secret,omitempty"`
+	Label      string    `json:"label,omitempty"`
+	EnrolledAt time.Time `json:"enrolled_at"`
+	LastUsed   time.Time `json:"last_used,omitempty"`
+
+	// Counter is the highest response counter accepted from the token.
+	Counter uint64 `json:"counter,omitempty"`
+}
+
+// TokenInfo describes an enrollment without key material.
+type TokenInfo struct {
+	TokenID    string    `json:"token_id"`
+	Kind       Kind      `json:"kind"`
+	Algorithm  Algorithm `json:"algorithm"`
+	Label      string    `json:"label,omitempty"`
+	EnrolledAt time.Time `json:"enrolled_at"`
+	LastUsed   time.Time `json:"last_used,omitempty"`
+}
+
+// userTokens is the sealed record stored per user.
+type userTokens struct {
+	Tokens []Enrollment `json:"tokens"`
+}
+
+// EnrollEd25519 registers a token that signs challenges with pub.
+func EnrollEd25519(v verification_persistence.VaultStore, userID string, kind Kind, tokenID, label string, pub ed25519.PublicKey) error {
+	if len(pub) != ed25519.PublicKeySize {
+		return errors.New("ed25519 public key must be 32 bytes")
+	}
+	return addEnrollment(v, userID, Enrollment{
+		TokenID:   tokenID,
+		Kind:      kind,
+		Algorithm: AlgEd25519,
+		PublicKey: pub,
+		Label:     label,
+	})
+}
+
+// EnrollHMAC registers a token using a shared HMAC-SHA256 key and returns
+// the new key, which must be written to the token now; it cannot be read
+// back later.
+func EnrollHMAC(v verification_persistence.VaultStore, userID string, kind Kind, tokenID, label string) ([]byte, error) {
+	secret := make([]byte, hmacSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	err := addEnrollment(v, userID, Enrollment{
+		TokenID:   tokenID,
+		Kind:      kind,
+		Algorithm: AlgHMACSHA256,
+		Secret:    secret,
+		Label:     label,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func addEnrollment(v verification_persistence.VaultStore, userID string, e Enrollment) error {
+	if userID == "" || e.TokenID == "" || e.Kind == "" {
+		return errors.New("user ID, token ID and kind required")
+	}
+	e.EnrolledAt = time.Now().UTC()
+
+	return updateTokens(v, userID, func(t *userTokens) error {
+		for _, existing := range t.Tokens {
+			if existing.TokenID == e.TokenID {
+				return fmt.Errorf("token %s already enrolled", e.TokenID)
+			}
+		}
+		t.Tokens = append(t.Tokens, e)
+		return nil
+	})
+}
+
+// Tokens lists the enrollments of userID.
+func Tokens(v verification_persistence.VaultStore, userID string) ([]TokenInfo, error) {
+	var t userTokens
+	if _, err := verification_persistence.ReadSealed(v, enrollmentCollection, userID, &t); err != nil {
+		return nil, err
+	}
+
+	out := make([]TokenInfo, 0, len(t.Tokens))
+	for _, e := range t.Tokens {
+		out = append(out, TokenInfo{
+			TokenID:    e.TokenID,
+			Kind:       e.Kind,
+			Algorithm:  e.Algorithm,
+			Label:      e.Label,
+			EnrolledAt: e.EnrolledAt,
+			LastUsed:   e.LastUsed,
+		})
+	}
+	return out, nil
+}
+
+// HasKind reports whether userID has a token of kind.
+func HasKind(v verification_persistence.VaultStore, userID string, kind Kind) (bool, error) {
+	tokens, err := Tokens(v, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, t := range tokens {
+		if t.Kind == kind {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Revoke removes one token of userID.
+func Revoke(v verification_persistence.VaultStore, userID, tokenID string) error {
+	return updateTokens(v, userID, func(t *userTokens) error {
+		for i, e := range t.Tokens {
+			if e.TokenID == tokenID {
+				t.Tokens = append(t.Tokens[:i], t.Tokens[i+1:]...)
+				return nil
+			}
+		}
+		return ErrNotEnrolled
+	})
+}
+
+// RevokeAll removes every token of userID, e.g. when the account is reset.
+func RevokeAll(v verification_persistence.VaultStore, userID string) error {
+	return v.Delete(enrollmentCollection, userID)
+}
+
+// RevokeAllTx is RevokeAll inside a caller's transaction.
+func RevokeAllTx(tx verification_persistence.VaultTx, userID string) error {
+	return tx.Delete(enrollmentCollection, userID)
+}
+
+// Verify checks resp against ch and the enrollment of userID, and records
+// the use. A counter that did not grow since the last accepted answer is
+// refused. It does not talk to any device.
+func Verify(v verification_persistence.VaultStore, userID string, ch *Challenge, resp *Response) error {
+	if time.Since(ch.IssuedAt) > ChallengeMaxAge {
+		return ErrChallengeExpired
+	}
+
+	return updateTokens(v, userID, func(t *userTokens) error {
+		for i := range t.Tokens {
+			e := &t.Tokens[i]
+			if e.TokenID != resp.TokenID || e.Kind != ch.Kind {
+				continue
+			}
+
+			if !e.check(ch.SignedMessage(resp.TokenID, resp.Counter), resp.Signature) {
+				return ErrBadResponse
+			}
+			if resp.Counter <= e.Counter {
+				return ErrCounterReplayed
+			}
+			e.Counter = resp.Counter
+			e.LastUsed = time.Now().UTC()
+			return nil
+		}
+		return ErrNotEnrolled
+	})
+}
+
+func (e *Enrollment) check(msg, sig []byte) bool {
+	switch e.Algorithm {
+	case AlgHMACSHA256:
+		return hmac.Equal(computeHMAC(e.Secret, msg), sig)
+	case AlgEd25519:
+		return len(e.PublicKey) == ed25519.PublicKeySize && ed25519.Verify(e.PublicKey, msg, sig)
+	default:
+		return false
+	}
+}
+
+func computeHMAC(secret, msg []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
+
+func updateTokens(v verification_persistence.VaultStore, userID string, fn func(t *userTokens) error) error {
+	return verification_persistence.Atomically(v, func(tx verification_persistence.VaultTx) error {
+		var t userTokens
+		if _, err := verification_persistence.ReadSealed(tx, enrollmentCollection, userID, &t); err != nil {
+			return err
+		}
+		if err := fn(&t); err != nil {
+			return err
+		}
+		return verification_persistence.WriteSealed(tx, enrollmentCollection, userID, t)
+	})
+}
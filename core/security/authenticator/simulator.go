@@ -0,0 +1,154 @@
+//core/security/authenticator/simulator.go
+
+package security_authenticator
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// Simulator is a software token for tests, simulators and bench setups.
+// It answers challenges like real hardware, either in process or over a
+// transport via Serve.
+type Simulator struct {
+	TokenID string
+	kind    Kind
+	secret  []byte
+	private ed25519.PrivateKey
+	counter atomic.Uint64
+
+	// Deny makes the simulated user refuse, e.g. to test lockout paths.
+	Deny bool
+}
+
+// NewHMACSimulator simulates a token holding an HMAC secret, e.g. the one
+// returned by EnrollHMAC.
+func NewHMACSimulator(kind Kind, tokenID string, secret []byte) *Simulator {
+	return &Simulator{TokenID: tokenID, kind: kind, secret: secret}
+}
+
+// NewEd25519Simulator simulates a signing token with a fresh key. Enroll
+// its PublicKey with EnrollEd25519.
+func NewEd25519Simulator(kind Kind, tokenID string) (*Simulator, error) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Simulator{TokenID: tokenID, kind: kind, private: priv}, nil
+}
+
+// PublicKey returns the signing key, or nil for HMAC simulators.
+func (s *Simulator) PublicKey() ed25519.PublicKey {
+	if s.private == nil {
+		return nil
+	}
+	return s.private.Public().(ed25519.PublicKey)
+}
+
+func (s *Simulator) Kind() Kind { return s.kind }
+
+func (s *Simulator) Respond(ctx context.Context, ch *Challenge) (*Response, error) {
+	if s.Deny {
+		return nil, errors.New("simulated user declined")
+	}
+	if ch.Kind != s.kind {
+		return nil, fmt.Errorf("simulator is a %s, challenged as %s", s.kind, ch.Kind)
+	}
+
+	resp := &Response{TokenID: s.TokenID, Counter: s.counter.Add(1)}
+	msg := ch.SignedMessage(s.TokenID, resp.Counter)
+	if s.private != nil {
+		resp.Signature = ed25519.Sign(s.private, msg)
+	} else {
+		resp.Signature = computeHMAC(s.secret, msg)
+	}
+	return resp, nil
+}
+
+func (s *Simulator) Close() error { return nil }
+
+// Serve answers challenge frames on rw until it is closed, as the token
+// firmware would.
+func (s *Simulator) Serve(rw io.ReadWriter) error {
+	for {
+		var ch Challenge
+		if err := ReadFrame(rw, &ch); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		resp, err := s.Respond(context.Background(), &ch)
+		if err != nil {
+			resp = &Response{TokenID: s.TokenID}
+		}
+		if err := WriteFrame(rw, resp); err != nil {
+			return err
+		}
+	}
+}
+
+// ListenAndServe serves the simulator on a TCP address, so the tcp
+// transport can be exercised end to end.
+func (s *Simulator) ListenAndServe(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			_ = s.Serve(conn)
+		}()
+	}
+}
+
+// ------------------------------------------------------------
+// In-process simulator transport
+// ------------------------------------------------------------
+
+var (
+	simulatorsMu sync.Mutex
+	simulators   = map[string]*Simulator{}
+)
+
+func init() {
+	RegisterTransport("simulator", func(dev DeviceConfig) (Authenticator, error) {
+		simulatorsMu.Lock()
+		defer simulatorsMu.Unlock()
+
+		s, ok := simulators[dev.Address]
+		if !ok {
+			return nil, fmt.Errorf("%w: no simulator at %q", ErrNoDeviceAvailable, dev.Address)
+		}
+		return s, nil
+	})
+}
+
+// AttachSimulator makes s reachable as a device with transport
+// "simulator" and the given address.
+func AttachSimulator(address string, s *Simulator) {
+	simulatorsMu.Lock()
+	defer simulatorsMu.Unlock()
+	simulators[address] = s
+}
+
+// DetachSimulator removes a simulator attached with AttachSimulator.
+func DetachSimulator(address string) {
+	simulatorsMu.Lock()
+	defer simulatorsMu.Unlock()
+	delete(simulators, address)
+}
@@ -0,0 +1,185 @@
+//core/security/authenticator/transport.go
+
+package security_authenticator
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// Frames on every transport are a 4-byte big-endian length followed by a
+// JSON Challenge (host to token) or Response (token to host).
+const maxFrameSize = 4 << 10
+
+// DefaultExchangeTimeout applies when ctx carries no deadline. It covers
+// the user touching the token or presenting a finger.
+const DefaultExchangeTimeout = 20 * time.Second
+
+func init() {
+	RegisterTransport("tcp", dialTCP)
+	RegisterTransport("serial", openSerial)
+	RegisterTransport("hid", openHID)
+}
+
+// WriteFrame sends v as one frame. Plugins and companion apps reuse it to
+// speak the token protocol.
+func WriteFrame(w io.Writer, v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxFrameSize {
+		return errors.New("authenticator frame too large")
+	}
+
+	frame := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	_, err = w.Write(append(frame, payload...))
+	return err
+}
+
+// ReadFrame reads one frame into v.
+func ReadFrame(r io.Reader, v interface{}) error {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return err
+	}
+
+	n := binary.BigEndian.Uint32(hdr[:])
+	if n > maxFrameSize {
+		return errors.New("authenticator frame too large")
+	}
+
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return err
+	}
+
+	// Report-based transports pad the last report; drop the padding
+	if d, ok := r.(interface{ discard() }); ok {
+		d.discard()
+	}
+
+	return json.Unmarshal(payload, v)
+}
+
+type deadliner interface {
+	SetDeadline(t time.Time) error
+}
+
+// StreamAuthenticator speaks the frame protocol over any byte stream: a
+// TCP connection, a serial line or a HID report stream.
+type StreamAuthenticator struct {
+	kind Kind
+	rw   io.ReadWriteCloser
+}
+
+// NewStreamAuthenticator wraps an open stream, e.g. a reader attached
+// through a transport this package does not know.
+func NewStreamAuthenticator(kind Kind, rw io.ReadWriteCloser) *StreamAuthenticator {
+	return &StreamAuthenticator{kind: kind, rw: rw}
+}
+
+func (s *StreamAuthenticator) Kind() Kind { return s.kind }
+
+func (s *StreamAuthenticator) Respond(ctx context.Context, ch *Challenge) (*Response, error) {
+	if d, ok := s.rw.(deadliner); ok {
+		deadline, set := ctx.Deadline()
+		if !set {
+			deadline = time.Now().Add(DefaultExchangeTimeout)
+		}
+		_ = d.SetDeadline(deadline)
+	}
+
+	if err := WriteFrame(s.rw, ch); err != nil {
+		return nil, fmt.Errorf("%s: send challenge: %w", s.kind, err)
+	}
+
+	var resp Response
+	if err := ReadFrame(s.rw, &resp); err != nil {
+		return nil, fmt.Errorf("%s: read response: %w", s.kind, err)
+	}
+	return &resp, nil
+}
+
+func (s *StreamAuthenticator) Close() error {
+	return s.rw.Close()
+}
+
+// ------------------------------------------------------------
+// Built-in transports
+// ------------------------------------------------------------
+
+func dialTCP(dev DeviceConfig) (Authenticator, error) {
+	conn, err := net.DialTimeout("tcp", dev.Address, 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoDeviceAvailable, err)
+	}
+	return NewStreamAuthenticator(dev.Kind, conn), nil
+}
+
+// openSerial opens a serial reader such as /dev/ttyACM0. Line settings are
+// left to the OS (udev rule or stty); USB CDC-ACM readers ignore them.
+func openSerial(dev DeviceConfig) (Authenticator, error) {
+	f, err := os.OpenFile(dev.Address, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoDeviceAvailable, err)
+	}
+	return NewStreamAuthenticator(dev.Kind, f), nil
+}
+
+// hidReportSize is the report length of supported HID readers.
+const hidReportSize = 64
+
+// openHID opens a hidraw node. Frames are split into 64-byte output
+// reports (report ID 0) and reassembled from input reports.
+func openHID(dev DeviceConfig) (Authenticator, error) {
+	f, err := os.OpenFile(dev.Address, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoDeviceAvailable, err)
+	}
+	return NewStreamAuthenticator(dev.Kind, &hidStream{f: f}), nil
+}
+
+type hidStream struct {
+	f   *os.File
+	buf []byte
+}
+
+func (h *hidStream) Read(p []byte) (int, error) {
+	if len(h.buf) == 0 {
+		report := make([]byte, hidReportSize)
+		n, err := h.f.Read(report)
+		if err != nil {
+			return 0, err
+		}
+		h.buf = report[:n]
+	}
+
+	n := copy(p, h.buf)
+	h.buf = h.buf[n:]
+	return n, nil
+}
+
+func (h *hidStream) Write(p []byte) (int, error) {
+	for off := 0; off < len(p); off += hidReportSize {
+		report := make([]byte, 1+hidReportSize) // leading report ID 0
+		copy(report[1:], p[off:min(off+hidReportSize, len(p))])
+		if _, err := h.f.Write(report); err != nil {
+			return off, err
+		}
+	}
+	return len(p), nil
+}
+
+func (h *hidStream) discard() { h.buf = nil }
+
+func (h *hidStream) SetDeadline(t time.Time) error { return h.f.SetDeadline(t) }
+
+func (h *hidStream) Close() error { return h.f.Close() }
@@ -7,6 +7,7 @@ import (
 	runtime_types "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/types"
 )
 
+// Enforcer answers permission checks from a decided context.
 type Enforcer struct {
 	ctx runtime_types.ExecutionContext
 }
@@ -16,26 +17,9 @@ func NewEnforcer(bootctx runtime_types.ExecutionContext) *Enforcer {
 }
 
 func (e *Enforcer) Allow(p user_setting.PermissionKey) bool {
-
-	v, ok := e.ctx.Permissions[p]
-	if !ok {
-		return false
-	}
-
-	return v
-}
-
-type AuthorizationService struct {
-	Resolver PermissionResolver
+	return e.ctx.HasPermission(p)
 }
 
-func (as *AuthorizationService) Authorize(authbootctx *AuthorizationContext) map[user_setting.PermissionKey]bool {
-	perms := as.Resolver.Resolve(authbootctx)
-
-	permMap := make(map[user_setting.PermissionKey]bool)
-	for _, p := range perms {
-		permMap[p] = true
-	}
-
-	return permMap
+func (e *Enforcer) Context() runtime_types.ExecutionContext {
+	return e.ctx
 }
@@ -0,0 +1,56 @@
+//core/security/decision/decision_http.go
+
+package security_decision
+
+import (
+	"context"
+	"net/http"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+	runtime_types "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/types"
+)
+
+type executionContextKey struct{}
+
+// WithExecution attaches a decided context to ctx.
+func WithExecution(ctx context.Context, exec runtime_types.ExecutionContext) context.Context {
+	return context.WithValue(ctx, executionContextKey{}, exec)
+}
+
+// ExecutionFromContext returns the context placed by Authorize. Without
+// one it returns the zero context, which grants nothing.
+func ExecutionFromContext(ctx context.Context) runtime_types.ExecutionContext {
+	exec, _ := ctx.Value(executionContextKey{}).(runtime_types.ExecutionContext)
+	return exec
+}
+
+// Authorize decides every request for the session placed by
+// verification_identity.RequireSession, so a policy change applies to
+// sessions already issued.
+func Authorize(point *DecisionPoint, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := verification_identity.SessionFromContext(r.Context())
+		if claims == nil {
+			http.Error(w, "session required", http.StatusUnauthorized)
+			return
+		}
+
+		exec := point.DecideSession(claims)
+		next.ServeHTTP(w, r.WithContext(WithExecution(r.Context(), exec)))
+	})
+}
+
+// RequirePermission refuses requests whose decided context lacks perm.
+// The refusal is audited with the explanation of the decision.
+func RequirePermission(point *DecisionPoint, perm user_setting.PermissionKey, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !ExecutionFromContext(r.Context()).HasPermission(perm) {
+			claims := verification_identity.SessionFromContext(r.Context())
+			e := point.Refuse(point.Subject(claims), perm, r.Method+" "+r.URL.Path)
+			http.Error(w, "permission required: "+string(perm)+": "+e.Decision.Reason, http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,82 @@
+//core/security/decision/decision_http_test.go
+
+package security_decision
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// serve runs h for a request carrying c, as RequireSession would place it.
+func serve(h http.Handler, c *user_setting.SessionClaims) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/api/admin/users", nil)
+	if c != nil {
+		r = r.WithContext(verification_identity.WithSession(r.Context(), c))
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestAuthorizeDecidesEveryRequest(t *testing.T) {
+	p := testPoint()
+
+	var seen []user_setting.PermissionKey
+	h := Authorize(p, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for perm := range ExecutionFromContext(r.Context()).Permissions() {
+			seen = append(seen, perm)
+		}
+	}))
+
+	if w := serve(h, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("no session = %d, want 401", w.Code)
+	}
+
+	if w := serve(h, claims("carol")); w.Code != http.StatusOK || len(seen) != 0 {
+		t.Errorf("disabled account = %d with %v", w.Code, seen)
+	}
+	if w := serve(h, claims("alice")); w.Code != http.StatusOK || len(seen) == 0 {
+		t.Errorf("alice = %d with %v", w.Code, seen)
+	}
+}
+
+func TestRequirePermissionRefusesAndAudits(t *testing.T) {
+	log := &recorder{}
+	p := testPoint().WithAudit(log)
+
+	reached := false
+	h := Authorize(p, RequirePermission(p, user_setting.PermAdmin, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	})))
+
+	w := serve(h, claims("alice"))
+	if w.Code != http.StatusForbidden || reached {
+		t.Fatalf("alice = %d, handler reached %v", w.Code, reached)
+	}
+	if !strings.Contains(w.Body.String(), string(user_setting.PermAdmin)) {
+		t.Errorf("refusal body = %q", w.Body.String())
+	}
+	if len(log.events) != 1 || log.events[0].Actor != "alice" || log.events[0].Resource != "POST /api/admin/users" {
+		t.Errorf("audit events = %+v", log.events)
+	}
+
+	guest := claims("guest-1")
+	guest.Guest = true
+	if w := serve(h, guest); w.Code != http.StatusForbidden || reached {
+		t.Errorf("guest = %d, handler reached %v", w.Code, reached)
+	}
+
+	// Without Authorize in front there is no decided context to pass.
+	if w := serve(RequirePermission(p, user_setting.PermUser, http.NotFoundHandler()), claims("root")); w.Code != http.StatusForbidden {
+		t.Errorf("undecided request = %d, want 403", w.Code)
+	}
+
+	if w := serve(h, claims("root")); w.Code != http.StatusOK || !reached {
+		t.Errorf("admin = %d, handler reached %v", w.Code, reached)
+	}
+}
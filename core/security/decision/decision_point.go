@@ -0,0 +1,350 @@
+//core/security/decision/decision_point.go
+
+package security_decision
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/policy"
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+	runtime_types "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/types"
+)
+
+var ErrNotAttested = errors.New("environment_not_attested")
+
+// Environment is the device half of every decision. It is fixed once the
+// boot is attested.
+type Environment struct {
+	Platform internal_environment.PlatformClass
+	Caps     internal_environment.CapabilitySet
+	Trust    user_setting.TrustLevel
+	EnvHash  string
+	BootID   string
+}
+
+// AttestedEnvironment derives the decision environment from an attested
+// boot. Strong attestation is device trust, weak attestation user trust;
+// an environment that failed attestation is refused.
+func AttestedEnvironment(env *internal_environment.EnvConfig, attested bool, caps internal_environment.CapabilitySet) (Environment, error) {
+	if env == nil {
+		return Environment{}, fmt.Errorf("%w: missing environment config", ErrNotAttested)
+	}
+	if !attested || !env.Attestation.Valid {
+		return Environment{}, ErrNotAttested
+	}
+
+	e := Environment{
+		Platform: env.Platform.Final,
+		Caps:     caps,
+		EnvHash:  env.Attestation.EnvHash,
+		BootID:   env.Attestation.BootID,
+	}
+	switch env.Attestation.Level {
+	case internal_environment.TrustStrong:
+		e.Trust = user_setting.TrustDevice
+	case internal_environment.TrustWeak:
+		e.Trust = user_setting.TrustUser
+	default:
+		return Environment{}, fmt.Errorf("%w: attestation level invalid", ErrNotAttested)
+	}
+	return e, nil
+}
+
+// Subject is the session half of a decision.
+type Subject struct {
+	UserID    string
+	SessionID string
+	Entity    internal_environment.EntityKind
+	Tier      user_setting.TierType
+	Service   user_setting.ServiceType
+
+	// Admin accounts are decided at admin trust; guests are never granted
+	// more than user_setting.GuestPermissions; the sessions of a disabled
+	// or deleted account are granted nothing.
+	Admin   bool
+	Guest   bool
+	Sandbox bool
+	Revoked bool
+
+	// Since is when the session was created, for session_age conditions.
+	Since time.Time
+}
+
+// Directory looks up the account behind a session. It is implemented by
+// security_users.Directory.
+type Directory interface {
+	Identity(userID string) (*internal_environment.MachineIdentity, error)
+}
+
+// Subject describes the holder of an issued session. Whether it is an
+// admin, and its entity and tier, come from the directory as they are now,
+// never from the claims, which a session keeps after a demotion; an
+// account the directory no longer has, or has disabled, is Revoked.
+// Without a directory (logins before the runtime exists) the subject is
+// not an admin.
+func (p *DecisionPoint) Subject(c *user_setting.SessionClaims) Subject {
+	if c == nil {
+		return Subject{Entity: internal_environment.EntityStranger, Guest: true}
+	}
+
+	s := Subject{
+		UserID:    c.UserID,
+		SessionID: c.SessionID,
+		Entity:    c.Entity,
+		Tier:      c.Tier,
+		Service:   c.Service,
+		Guest:     c.Guest,
+		Sandbox:   c.Sandbox,
+		Since:     c.CreatedAt,
+	}
+	if c.Guest || p.directory == nil {
+		return s
+	}
+
+	rec, err := p.directory.Identity(c.UserID)
+	if err != nil || rec.Disabled {
+		s.Revoked = true
+		return s
+	}
+	s.Admin = rec.Admin
+	s.Entity = rec.EntityType
+	if rec.TierType != "" {
+		s.Tier = rec.TierType
+	}
+	return s
+}
+
+// DecisionPoint is the only place permissions are derived. Logins, the
+// boot context, module selection and every API request are decided here,
+// from the attested environment, the subject and the policies in force.
+type DecisionPoint struct {
+	policies  *policy.Set
+	env       Environment
+	audit     Auditor
+	directory Directory
+	now       func() time.Time
+}
+
+// Auditor receives denial events.
+type Auditor interface {
+	Append(ev security_audit.Event) (*security_audit.Entry, error)
+}
+
+// NewDecisionPoint decides with set in env. A nil set means the builtin
+// policy.
+func NewDecisionPoint(set *policy.Set, env Environment) *DecisionPoint {
+	if set == nil {
+		set, _ = policy.NewSet(policy.Builtin())
+	}
+	return &DecisionPoint{policies: set, env: env, now: time.Now}
+}
+
+// Provisional decides logins made before the environment is attested:
+// builtin policy, user trust and no capabilities. The boot context is
+// decided again once attestation completes.
+func Provisional(platform internal_environment.PlatformClass) *DecisionPoint {
+	return NewDecisionPoint(nil, Environment{Platform: platform, Trust: user_setting.TrustUser})
+}
+
+// WithAudit returns a copy of p that records the denials it enforces in
+// log.
+func (p *DecisionPoint) WithAudit(log Auditor) *DecisionPoint {
+	c := *p
+	c.audit = log
+	return &c
+}
+
+// WithDirectory returns a copy of p that decides sessions by the current
+// standing of their account in dir.
+func (p *DecisionPoint) WithDirectory(dir Directory) *DecisionPoint {
+	c := *p
+	c.directory = dir
+	return &c
+}
+
+func (p *DecisionPoint) Environment() Environment { return p.env }
+
+func (p *DecisionPoint) Policies() *policy.Set { return p.policies }
+
+// Attributes is the policy request for s in this environment.
+func (p *DecisionPoint) Attributes(s Subject) policy.Attributes {
+	a := policy.Attributes{
+		Platform: p.env.Platform,
+		Entity:   s.Entity,
+		Tier:     s.Tier,
+		Service:  s.Service,
+		Trust:    p.env.Trust,
+		Caps:     p.env.Caps,
+	}
+	switch {
+	case s.Revoked:
+		a.Entity = internal_environment.EntityStranger
+		a.Trust = user_setting.TrustUntrusted
+	case s.Guest:
+		a.Entity = internal_environment.EntityStranger
+		if a.Trust > user_setting.TrustUser {
+			a.Trust = user_setting.TrustUser
+		}
+	case s.Admin:
+		a.Trust = user_setting.TrustAdmin
+	}
+	if !s.Since.IsZero() {
+		a.SessionAge = p.now().Sub(s.Since)
+	}
+	return a
+}
+
+// Decide grants s its permissions and freezes the result.
+func (p *DecisionPoint) Decide(s Subject) runtime_types.ExecutionContext {
+	attrs := p.Attributes(s)
+	d := p.policies.Evaluate(attrs)
+
+	var guest map[user_setting.PermissionKey]bool
+	if s.Guest {
+		guest = user_setting.GuestPermissions()
+	}
+
+	if s.Revoked {
+		d.Granted = nil
+		d.Reason = "account disabled or deleted"
+	}
+
+	perms := make([]user_setting.PermissionKey, 0, len(d.Granted))
+	for _, g := range d.Granted {
+		perm := user_setting.PermissionKey(g)
+		if guest != nil && !guest[perm] {
+			continue
+		}
+		perms = append(perms, perm)
+	}
+
+	names := make([]string, 0, len(p.policies.Policies))
+	for _, pol := range p.policies.Policies {
+		names = append(names, fmt.Sprintf("%s@%d", pol.Name, pol.Version))
+	}
+	sort.Strings(names)
+
+	return runtime_types.NewExecutionContext(runtime_types.Grant{
+		Platform:     attrs.Platform,
+		Capabilities: attrs.Caps,
+		Trust:        attrs.Trust,
+		EnvHash:      p.env.EnvHash,
+		BootID:       p.env.BootID,
+
+		UserID:    s.UserID,
+		SessionID: s.SessionID,
+		Entity:    attrs.Entity,
+		Tier:      s.Tier,
+		Service:   s.Service,
+		Guest:     s.Guest,
+		Sandbox:   s.Sandbox,
+
+		Permissions: perms,
+		Reason:      d.Reason,
+		Matched:     d.Matched,
+		Policies:    names,
+		IssuedAt:    p.now().UTC(),
+	})
+}
+
+// DecideSession decides for the holder of claims.
+func (p *DecisionPoint) DecideSession(claims *user_setting.SessionClaims) runtime_types.ExecutionContext {
+	return p.Decide(p.Subject(claims))
+}
+
+// Explain is Decide for one permission, rule by rule.
+func (p *DecisionPoint) Explain(s Subject, perm user_setting.PermissionKey) policy.Explanation {
+	e := p.policies.Explain(p.Attributes(s), perm)
+	if s.Revoked && e.Decision.Allowed {
+		e.Decision.Allowed = false
+		e.Decision.Granted = []string{}
+		e.Decision.Denied = []string{string(perm)}
+		e.Decision.Reason = "account disabled or deleted"
+		e.Deciding = ""
+		for i := range e.Rules {
+			e.Rules[i].Deciding = false
+		}
+	}
+	if s.Guest && e.Decision.Allowed && !user_setting.GuestPermissions()[perm] {
+		e.Decision.Allowed = false
+		e.Decision.Granted = []string{}
+		e.Decision.Denied = []string{string(perm)}
+		e.Decision.Reason = "guest sessions never hold " + string(perm)
+		e.Deciding = ""
+		for i := range e.Rules {
+			e.Rules[i].Deciding = false
+		}
+	}
+	return e
+}
+
+// Denial is the audit event for refusing s perm on resource. Its detail
+// is the explanation, so the log shows which rule decided.
+func (p *DecisionPoint) Denial(s Subject, perm user_setting.PermissionKey, resource string) (security_audit.Event, policy.Explanation) {
+	e := p.Explain(s, perm)
+	detail, _ := json.Marshal(e)
+
+	actor := s.UserID
+	if actor == "" {
+		actor = "guest"
+	}
+	return security_audit.Event{
+		Actor:      actor,
+		Action:     "policy.deny",
+		Permission: string(perm),
+		Resource:   resource,
+		Result:     "denied",
+		Detail:     detail,
+	}, e
+}
+
+// RefuseModule records that module was not started for s. A module gated
+// on perm is explained as the denial of perm; one the device cannot run is
+// refused with reason alone.
+func (p *DecisionPoint) RefuseModule(s Subject, module string, perm user_setting.PermissionKey, reason string) policy.Explanation {
+	resource := "module " + module
+	if perm != "" {
+		return p.Refuse(s, perm, resource)
+	}
+
+	e := policy.Explanation{
+		Request: p.Attributes(s).Request(),
+		Decision: policy.Decision{
+			Reason:  reason,
+			Granted: []string{},
+			Denied:  []string{},
+		},
+	}
+	detail, _ := json.Marshal(e)
+
+	actor := s.UserID
+	if actor == "" {
+		actor = "guest"
+	}
+	if p.audit != nil {
+		_, _ = p.audit.Append(security_audit.Event{
+			Actor:    actor,
+			Action:   "policy.deny",
+			Resource: resource,
+			Result:   "denied",
+			Detail:   detail,
+		})
+	}
+	return e
+}
+
+// Refuse records the denial of perm to s when p has an audit log and
+// returns the explanation.
+func (p *DecisionPoint) Refuse(s Subject, perm user_setting.PermissionKey, resource string) policy.Explanation {
+	ev, e := p.Denial(s, perm, resource)
+	if p.audit != nil {
+		_, _ = p.audit.Append(ev)
+	}
+	return e
+}
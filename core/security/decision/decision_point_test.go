@@ -0,0 +1,176 @@
+//core/security/decision/decision_point_test.go
+
+package security_decision
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// directory is an account store keyed by user ID; unknown users are gone.
+type directory map[string]*internal_environment.MachineIdentity
+
+func (d directory) Identity(userID string) (*internal_environment.MachineIdentity, error) {
+	if rec, ok := d[userID]; ok {
+		return rec, nil
+	}
+	return nil, errors.New("user_not_found")
+}
+
+type recorder struct {
+	events []security_audit.Event
+}
+
+func (r *recorder) Append(ev security_audit.Event) (*security_audit.Entry, error) {
+	r.events = append(r.events, ev)
+	return &security_audit.Entry{}, nil
+}
+
+// testPoint decides with the builtin policy on an attested desktop with
+// network, for alice (personal), root (admin) and carol (disabled).
+func testPoint() *DecisionPoint {
+	var caps internal_environment.CapabilitySet
+	caps.Add(internal_environment.CapNetwork)
+
+	return NewDecisionPoint(nil, Environment{
+		Platform: internal_environment.PlatformComputer,
+		Caps:     caps,
+		Trust:    user_setting.TrustDevice,
+	}).WithDirectory(directory{
+		"alice": {EntityType: internal_environment.EntityPersonal},
+		"root":  {EntityType: internal_environment.EntityPersonal, Admin: true},
+		"carol": {EntityType: internal_environment.EntityPersonal, Disabled: true},
+	})
+}
+
+func claims(userID string) *user_setting.SessionClaims {
+	return &user_setting.SessionClaims{UserID: userID, SessionID: "s-" + userID, Entity: internal_environment.EntityPersonal}
+}
+
+func TestDecideGrantsByPolicy(t *testing.T) {
+	p := testPoint()
+
+	exec := p.DecideSession(claims("alice"))
+	for _, perm := range []user_setting.PermissionKey{user_setting.PermUser, user_setting.PermBasicRuntime, user_setting.PermConfigEdit} {
+		if !exec.HasPermission(perm) {
+			t.Errorf("alice lacks %s", perm)
+		}
+	}
+	if exec.HasPermission(user_setting.PermAdmin) || exec.SecurityTier() != user_setting.TrustDevice {
+		t.Errorf("alice decided at %v with %v", exec.SecurityTier(), exec.Permissions())
+	}
+	if !exec.Valid() || exec.UserID() != "alice" || exec.SessionID() != "s-alice" {
+		t.Errorf("context = %+v", exec.Grant())
+	}
+}
+
+func TestDecideAdminsAtAdminTrust(t *testing.T) {
+	p := testPoint()
+
+	s := p.Subject(claims("root"))
+	if !s.Admin {
+		t.Fatal("directory admin not decided as admin")
+	}
+	exec := p.Decide(s)
+	if exec.SecurityTier() != user_setting.TrustAdmin || !exec.HasPermission(user_setting.PermAdmin) {
+		t.Errorf("root decided at %v with %v", exec.SecurityTier(), exec.Permissions())
+	}
+
+	// Admin standing comes from the directory, not from the session.
+	p = p.WithDirectory(directory{"root": {EntityType: internal_environment.EntityPersonal}})
+	if exec := p.DecideSession(claims("root")); exec.HasPermission(user_setting.PermAdmin) {
+		t.Error("demoted admin keeps PermAdmin")
+	}
+}
+
+func TestDecideGrantsRevokedSubjectsNothing(t *testing.T) {
+	p := testPoint()
+
+	for _, user := range []string{"carol", "deleted"} {
+		s := p.Subject(claims(user))
+		if !s.Revoked {
+			t.Errorf("%s not revoked", user)
+		}
+		exec := p.Decide(s)
+		if len(exec.Permissions()) != 0 {
+			t.Errorf("%s granted %v", user, exec.Permissions())
+		}
+		if exec.Reason() != "account disabled or deleted" {
+			t.Errorf("%s reason = %q", user, exec.Reason())
+		}
+	}
+}
+
+func TestDecideCapsGuestsToGuestPermissions(t *testing.T) {
+	p := testPoint()
+
+	guest := claims("guest-1")
+	guest.Guest = true
+	// Guest claims are never looked up and never widened.
+	exec := p.DecideSession(guest)
+	allowed := user_setting.GuestPermissions()
+	for perm := range exec.Permissions() {
+		if !allowed[perm] {
+			t.Errorf("guest granted %s", perm)
+		}
+	}
+	if !exec.HasPermission(user_setting.PermUser) || exec.HasPermission(user_setting.PermConfigEdit) {
+		t.Errorf("guest permissions = %v", exec.Permissions())
+	}
+	if !exec.IsGuest() || exec.SecurityTier() > user_setting.TrustUser {
+		t.Errorf("guest decided at %v", exec.SecurityTier())
+	}
+
+	if s := p.Subject(nil); !s.Guest || s.Entity != internal_environment.EntityStranger {
+		t.Errorf("no claims = %+v, want a guest", s)
+	}
+}
+
+func TestExplainMatchesDecide(t *testing.T) {
+	p := testPoint()
+
+	e := p.Explain(p.Subject(claims("alice")), user_setting.PermConfigEdit)
+	if !e.Decision.Allowed || e.Deciding == "" {
+		t.Errorf("alice config_edit = %+v", e.Decision)
+	}
+
+	guest := claims("guest-1")
+	guest.Guest = true
+	e = p.Explain(p.Subject(guest), user_setting.PermConfigEdit)
+	if e.Decision.Allowed || e.Deciding != "" || !strings.Contains(e.Decision.Reason, "guest") {
+		t.Errorf("guest config_edit = %+v (deciding %q)", e.Decision, e.Deciding)
+	}
+
+	e = p.Explain(p.Subject(claims("carol")), user_setting.PermUser)
+	if e.Decision.Allowed || e.Deciding != "" || e.Decision.Reason != "account disabled or deleted" {
+		t.Errorf("disabled user = %+v (deciding %q)", e.Decision, e.Deciding)
+	}
+	for _, r := range e.Rules {
+		if r.Deciding {
+			t.Errorf("rule %+v still marked deciding", r)
+		}
+	}
+}
+
+func TestRefuseAuditsTheExplanation(t *testing.T) {
+	log := &recorder{}
+	p := testPoint().WithAudit(log)
+
+	e := p.Refuse(p.Subject(claims("alice")), user_setting.PermAdmin, "DELETE /api/admin/users/bob")
+	if e.Decision.Allowed {
+		t.Fatal("refused an allowed permission")
+	}
+	if len(log.events) != 1 {
+		t.Fatalf("%d audit events", len(log.events))
+	}
+	ev := log.events[0]
+	if ev.Actor != "alice" || ev.Action != "policy.deny" || ev.Permission != string(user_setting.PermAdmin) ||
+		ev.Resource != "DELETE /api/admin/users/bob" || ev.Result != "denied" || len(ev.Detail) == 0 {
+		t.Errorf("audit event = %+v", ev)
+	}
+}
@@ -0,0 +1,148 @@
+//core/security/identity/attestation.go
+
+package verification_identity
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrAttestationSignature = errors.New("attestation_signature_invalid")
+	ErrAttestationNonce     = errors.New("attestation_nonce_mismatch")
+	ErrHandshakeSignature   = errors.New("handshake_signature_invalid")
+	ErrHandshakeExpired     = errors.New("handshake_expired")
+)
+
+// HandshakeMaxAge bounds how old a mesh hello may be before it is refused.
+const HandshakeMaxAge = 30 * time.Second
+
+// AttestationReport states what this unit booted, signed by its device key.
+// Nonce is supplied by the verifier so a report cannot be replayed.
+type AttestationReport struct {
+	MachineID   string    `json:"machine_id"`
+	Fingerprint string    `json:"fingerprint"`
+	BootID      string    `json:"boot_id"`
+	Measurement string    `json:"measurement"`
+	Nonce       []byte    `json:"nonce"`
+	IssuedAt    time.Time `json:"issued_at"`
+	Certificate []byte    `json:"certificate"`
+	Signature   []byte    `json:"signature"`
+}
+
+func (r *AttestationReport) signedBytes() []byte {
+	unsigned := *r
+	unsigned.Signature = nil
+	data, _ := json.Marshal(unsigned)
+	return data
+}
+
+// SignAttestation produces a report over the given boot measurement.
+func (d *DeviceIdentity) SignAttestation(bootID, measurement string, nonce []byte) *AttestationReport {
+	r := &AttestationReport{
+		MachineID:   d.MachineID,
+		Fingerprint: d.Fingerprint,
+		BootID:      bootID,
+		Measurement: measurement,
+		Nonce:       nonce,
+		IssuedAt:    time.Now().UTC(),
+		Certificate: d.Certificate,
+	}
+	r.Signature = d.Sign(r.signedBytes())
+	return r
+}
+
+// VerifyAttestation checks the report signature against the certificate it
+// carries, that the certificate is bound to the reported fingerprint, and
+// that the report answers nonce. Whether the certificate itself is trusted
+// is the caller's decision.
+func VerifyAttestation(r *AttestationReport, nonce []byte) error {
+	cert, err := ParseDeviceCertificate(r.Certificate)
+	if err != nil {
+		return err
+	}
+
+	if cert.Fingerprint != r.Fingerprint || cert.MachineID != r.MachineID {
+		return ErrFingerprintMismatch
+	}
+
+	if string(r.Nonce) != string(nonce) {
+		return ErrAttestationNonce
+	}
+
+	if !VerifySignature(cert.PublicKey, r.signedBytes(), r.Signature) {
+		return ErrAttestationSignature
+	}
+
+	return nil
+}
+
+// MeshHello is the first message a node sends to a peer. The peer answers
+// with its own hello over the received Challenge, so both sides prove
+// possession of their device key.
+type MeshHello struct {
+	NodeID      string    `json:"node_id"`
+	Certificate []byte    `json:"certificate"`
+	Challenge   []byte    `json:"challenge"`
+	PeerNonce   []byte    `json:"peer_nonce,omitempty"`
+	SentAt      time.Time `json:"sent_at"`
+	Signature   []byte    `json:"signature"`
+}
+
+func (h *MeshHello) signedBytes() []byte {
+	unsigned := *h
+	unsigned.Signature = nil
+	data, _ := json.Marshal(unsigned)
+	return data
+}
+
+// NewMeshHello builds a signed hello. peerNonce is the challenge received
+// from the peer, or nil when opening the handshake.
+func (d *DeviceIdentity) NewMeshHello(peerNonce []byte) (*MeshHello, error) {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+
+	h := &MeshHello{
+		NodeID:      d.MachineID,
+		Certificate: d.Certificate,
+		Challenge:   challenge,
+		PeerNonce:   peerNonce,
+		SentAt:      time.Now().UTC(),
+	}
+	h.Signature = d.Sign(h.signedBytes())
+	return h, nil
+}
+
+// VerifyMeshHello checks a peer's hello. expectedNonce is the challenge we
+// sent, or nil when verifying the opening hello. It returns the peer's
+// public key so later messages can be checked against it.
+func VerifyMeshHello(h *MeshHello, expectedNonce []byte) (ed25519.PublicKey, error) {
+	cert, err := ParseDeviceCertificate(h.Certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	if cert.MachineID != h.NodeID {
+		return nil, fmt.Errorf("%w: certificate issued to %s", ErrHandshakeSignature, cert.MachineID)
+	}
+
+	if time.Since(h.SentAt) > HandshakeMaxAge || time.Until(h.SentAt) > HandshakeMaxAge {
+		return nil, ErrHandshakeExpired
+	}
+
+	if expectedNonce != nil && string(h.PeerNonce) != string(expectedNonce) {
+		return nil, ErrAttestationNonce
+	}
+
+	if !VerifySignature(cert.PublicKey, h.signedBytes(), h.Signature) {
+		return nil, ErrHandshakeSignature
+	}
+
+	return cert.PublicKey, nil
+}
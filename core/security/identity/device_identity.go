@@ -0,0 +1,344 @@
+//core/security/identity/device_identity.go
+
+package verification_identity
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/pkg/logging"
+)
+
+const (
+	deviceCollection = "device"
+	identityKey      = "identity"
+	privateKeyKey    = "identity_key"
+
+	certificateLifetime = 10 * 365 * 24 * time.Hour
+)
+
+// oidHardwareFingerprint carries the hardware fingerprint digest inside the
+// device certificate (private enterprise arc, AIOS device attributes).
+var oidHardwareFingerprint = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 59999, 1, 1}
+
+var (
+	ErrFingerprintMismatch   = errors.New("device_fingerprint_mismatch")
+	ErrFingerprintIncomplete = errors.New("device_fingerprint_incomplete")
+)
+
+// HardwareClaim is the hardware fingerprint one boot measured: the digest
+// and a hash per component (tpm, cpu, dmi, pci, storage). Partial is set
+// when a probe failed or timed out, so absent components prove nothing.
+type HardwareClaim struct {
+	Digest     string
+	Components map[string]string
+	Partial    bool
+}
+
+// DeviceIdentity is the cryptographic identity of this unit: an ed25519
+// keypair and a self-signed certificate bound to the hardware fingerprint.
+// The private key is stored sealed under the vault key and never leaves
+// this struct.
+type DeviceIdentity struct {
+	MachineID   string    `json:"machine_id"`
+	Fingerprint string    `json:"fingerprint"`
+	PublicKey   []byte    `json:"public_key"`
+	Certificate []byte    `json:"certificate"` // DER
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Components are the per-component hashes behind Fingerprint, so a
+	// later boot can tell one replaced part from different hardware.
+	Components map[string]string `json:"components,omitempty"`
+
+	private ed25519.PrivateKey
+}
+
+// ProvisionDeviceIdentity returns the stored identity, generating one on
+// first provisioning. A claim that differs from the stored fingerprint is
+// compared component by component (see matchHardware): one replaced part
+// re-attests the identity under the new fingerprint, keeping its key; a
+// partial claim that agrees with what it could measure keeps the identity
+// as it is; different hardware is refused rather than silently replaced.
+func ProvisionDeviceIdentity(v verification_persistence.VaultStore, machineID string, claim HardwareClaim) (*DeviceIdentity, error) {
+	existing, err := LoadDeviceIdentity(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing == nil {
+		return GenerateDeviceIdentity(v, machineID, claim)
+	}
+	if existing.Fingerprint == claim.Digest {
+		if existing.Components == nil && !claim.Partial {
+			return existing, existing.reattest(v, claim)
+		}
+		return existing, nil
+	}
+
+	if err := matchHardware(existing, claim); err != nil {
+		return nil, err
+	}
+	if claim.Partial {
+		logging.Warn("[IDENTITY] hardware fingerprint incomplete; keeping identity issued for %s", existing.Fingerprint)
+		return existing, nil
+	}
+	if err := existing.reattest(v, claim); err != nil {
+		return nil, err
+	}
+	logging.Info("[IDENTITY] hardware changed within tolerance; identity re-attested for %s", claim.Digest)
+	return existing, nil
+}
+
+// matchHardware decides whether claim describes the hardware existing was
+// issued for. Components measured by both must agree except for at most
+// one replaced part, and at least two must agree (or all the identity
+// has, if it was provisioned from fewer). A differing TPM is always
+// different hardware. A partial claim that cannot decide either way is
+// ErrFingerprintIncomplete.
+func matchHardware(existing *DeviceIdentity, claim HardwareClaim) error {
+	var agree, differ int
+	for name, want := range existing.Components {
+		got, ok := claim.Components[name]
+		switch {
+		case !ok:
+		case got == want:
+			agree++
+		case name == "tpm":
+			return fmt.Errorf("%w: tpm changed, identity issued for %s", ErrFingerprintMismatch, existing.Fingerprint)
+		default:
+			differ++
+		}
+	}
+
+	need := 2
+	if n := len(existing.Components); n < need {
+		need = n
+	}
+
+	switch {
+	case differ > 1:
+		return fmt.Errorf("%w: identity issued for %s", ErrFingerprintMismatch, existing.Fingerprint)
+	case need > 0 && agree >= need && (differ == 0 || !claim.Partial):
+		return nil
+	case claim.Partial:
+		return fmt.Errorf("%w: %d components measured", ErrFingerprintIncomplete, len(claim.Components))
+	default:
+		return fmt.Errorf("%w: identity issued for %s", ErrFingerprintMismatch, existing.Fingerprint)
+	}
+}
+
+// reattest rebinds the identity to claim: same key, new fingerprint and a
+// certificate re-issued for it.
+func (d *DeviceIdentity) reattest(v verification_persistence.VaultStore, claim HardwareClaim) error {
+	d.Fingerprint = claim.Digest
+	d.Components = claim.Components
+
+	cert, err := d.selfSign()
+	if err != nil {
+		return fmt.Errorf("certificate generation failed: %w", err)
+	}
+	d.Certificate = cert
+
+	return v.Write(deviceCollection, identityKey, d)
+}
+
+// GenerateDeviceIdentity creates and stores a new keypair and certificate,
+// replacing any previous identity.
+func GenerateDeviceIdentity(v verification_persistence.VaultStore, machineID string, claim HardwareClaim) (*DeviceIdentity, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	id := &DeviceIdentity{
+		MachineID:   machineID,
+		Fingerprint: claim.Digest,
+		PublicKey:   pub,
+		Components:  claim.Components,
+		CreatedAt:   time.Now().UTC(),
+		private:     priv,
+	}
+
+	id.Certificate, err = id.selfSign()
+	if err != nil {
+		return nil, fmt.Errorf("certificate generation failed: %w", err)
+	}
+
+	err = verification_persistence.Atomically(v, func(tx verification_persistence.VaultTx) error {
+		if err := verification_persistence.WriteSealed(tx, deviceCollection, privateKeyKey, priv.Seed()); err != nil {
+			return err
+		}
+		return tx.Write(deviceCollection, identityKey, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.Info("[IDENTITY] device identity provisioned for %s", machineID)
+	return id, nil
+}
+
+// LoadDeviceIdentity returns the stored identity, or nil if the unit has
+// not been provisioned yet.
+func LoadDeviceIdentity(v verification_persistence.VaultStore) (*DeviceIdentity, error) {
+	var id DeviceIdentity
+	found, err := v.Read(deviceCollection, identityKey, &id)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var seed []byte
+	found, err = verification_persistence.ReadSealed(v, deviceCollection, privateKeyKey, &seed)
+	if err != nil {
+		return nil, fmt.Errorf("device key unreadable: %w", err)
+	}
+	if !found || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("device key missing")
+	}
+
+	id.private = ed25519.NewKeyFromSeed(seed)
+	if !id.private.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(id.PublicKey)) {
+		return nil, errors.New("device key does not match stored identity")
+	}
+
+	return &id, nil
+}
+
+func (d *DeviceIdentity) selfSign() ([]byte, error) {
+	tmpl, err := d.template()
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth}
+
+	return x509.CreateCertificate(rand.Reader, tmpl, tmpl, d.private.Public(), d.private)
+}
+
+func (d *DeviceIdentity) template() (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	if err != nil {
+		return nil, err
+	}
+
+	fp, err := asn1.Marshal(d.Fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   d.MachineID,
+			SerialNumber: d.Fingerprint,
+			Organization: []string{"multi-platform-AI device"},
+		},
+		URIs:      []*url.URL{d.URI()},
+		NotBefore: d.CreatedAt.Add(-time.Hour),
+		NotAfter:  d.CreatedAt.Add(certificateLifetime),
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidHardwareFingerprint, Value: fp},
+		},
+	}, nil
+}
+
+// URI is the device's stable name, used as the certificate SAN.
+func (d *DeviceIdentity) URI() *url.URL {
+	return &url.URL{Scheme: "urn", Opaque: "aios:device:" + d.MachineID}
+}
+
+// CertificatePEM returns the self-signed certificate in PEM form.
+func (d *DeviceIdentity) CertificatePEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: d.Certificate})
+}
+
+// CreateCSR produces a PEM certificate request for an enterprise CA. The
+// request carries the same subject and fingerprint as the self-signed cert.
+func (d *DeviceIdentity) CreateCSR(organization string) ([]byte, error) {
+	tmpl, err := d.template()
+	if err != nil {
+		return nil, err
+	}
+
+	if organization != "" {
+		tmpl.Subject.Organization = []string{organization}
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:         tmpl.Subject,
+		URIs:            tmpl.URIs,
+		ExtraExtensions: tmpl.ExtraExtensions,
+	}, d.private)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}), nil
+}
+
+// Sign signs msg with the device key.
+func (d *DeviceIdentity) Sign(msg []byte) []byte {
+	return ed25519.Sign(d.private, msg)
+}
+
+// VerifySignature checks a device signature made with Sign.
+func VerifySignature(pub ed25519.PublicKey, msg, sig []byte) bool {
+	return len(pub) == ed25519.PublicKeySize && ed25519.Verify(pub, msg, sig)
+}
+
+// DeviceCertificate is what a peer learns from a device certificate.
+type DeviceCertificate struct {
+	MachineID   string
+	Fingerprint string
+	PublicKey   ed25519.PublicKey
+}
+
+// ParseDeviceCertificate parses a self-signed device certificate, checks
+// its self-signature and extracts the hardware fingerprint.
+func ParseDeviceCertificate(der []byte) (*DeviceCertificate, error) {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, ok := cert.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("device certificate is not ed25519")
+	}
+
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		return nil, fmt.Errorf("device certificate signature: %w", err)
+	}
+
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidHardwareFingerprint) {
+			continue
+		}
+
+		var fp string
+		if _, err := asn1.Unmarshal(ext.Value, &fp); err != nil {
+			return nil, err
+		}
+
+		return &DeviceCertificate{
+			MachineID:   cert.Subject.CommonName,
+			Fingerprint: fp,
+			PublicKey:   pub,
+		}, nil
+	}
+
+	return nil, errors.New("device certificate has no hardware fingerprint")
+}
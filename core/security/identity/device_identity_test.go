@@ -0,0 +1,94 @@
+//core/security/identity/device_identity_test.go
+
+package verification_identity
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+func testVault(t *testing.T) *verification_persistence.IsolatedVault {
+	t.Helper()
+	return &verification_persistence.IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{1}, 32)}
+}
+
+func claim(partial bool, parts ...string) HardwareClaim {
+	c := HardwareClaim{Components: map[string]string{}, Partial: partial}
+	for i := 0; i+1 < len(parts); i += 2 {
+		c.Components[parts[i]] = parts[i+1]
+		c.Digest += parts[i] + "=" + parts[i+1] + ";"
+	}
+	return c
+}
+
+func TestProvisionDeviceIdentityToleratesOneReplacedPart(t *testing.T) {
+	v := testVault(t)
+	first, err := ProvisionDeviceIdentity(v, "m1", claim(false, "tpm", "t", "cpu", "c", "dmi", "d", "storage", "s1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := ProvisionDeviceIdentity(v, "m1", claim(false, "tpm", "t", "cpu", "c", "dmi", "d", "storage", "s2"))
+	if err != nil {
+		t.Fatalf("replaced disk: %v", err)
+	}
+	if !bytes.Equal(again.PublicKey, first.PublicKey) {
+		t.Error("re-attestation must keep the device key")
+	}
+
+	cert, err := ParseDeviceCertificate(again.Certificate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Fingerprint != again.Fingerprint || again.Fingerprint == first.Fingerprint {
+		t.Errorf("certificate not re-issued for the new fingerprint: %s", cert.Fingerprint)
+	}
+}
+
+func TestProvisionDeviceIdentityPartialFingerprint(t *testing.T) {
+	v := testVault(t)
+	full := claim(false, "tpm", "t", "cpu", "c", "dmi", "d", "storage", "s")
+	first, err := ProvisionDeviceIdentity(v, "m1", full)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A timed-out storage probe keeps the identity as it is.
+	id, err := ProvisionDeviceIdentity(v, "m1", claim(true, "tpm", "t", "cpu", "c", "dmi", "d"))
+	if err != nil {
+		t.Fatalf("partial fingerprint: %v", err)
+	}
+	if id.Fingerprint != first.Fingerprint {
+		t.Error("a partial fingerprint must not rebind the identity")
+	}
+
+	// Every probe timed out: nothing to decide with.
+	if _, err := ProvisionDeviceIdentity(v, "m1", claim(true)); !errors.Is(err, ErrFingerprintIncomplete) {
+		t.Errorf("empty fingerprint = %v, want %v", err, ErrFingerprintIncomplete)
+	}
+
+	// The next complete boot still matches.
+	if _, err := ProvisionDeviceIdentity(v, "m1", full); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProvisionDeviceIdentityRefusesOtherHardware(t *testing.T) {
+	v := testVault(t)
+	if _, err := ProvisionDeviceIdentity(v, "m1", claim(false, "tpm", "t", "cpu", "c", "dmi", "d", "storage", "s")); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, c := range map[string]HardwareClaim{
+		"tpm":      claim(false, "tpm", "x", "cpu", "c", "dmi", "d", "storage", "s"),
+		"two":      claim(false, "tpm", "t", "cpu", "x", "dmi", "d", "storage", "x"),
+		"disjoint": claim(false, "cpu", "x", "pci", "p"),
+	} {
+		if _, err := ProvisionDeviceIdentity(v, "m1", c); !errors.Is(err, ErrFingerprintMismatch) {
+			t.Errorf("%s: %v, want %v", name, err, ErrFingerprintMismatch)
+		}
+	}
+}
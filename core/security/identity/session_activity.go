@@ -0,0 +1,160 @@
+//core/security/identity/session_activity.go
+
+package verification_identity
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+var (
+	ErrSessionLocked  = errors.New("session_locked")
+	ErrStepUpRequired = errors.New("step_up_required")
+)
+
+// StepUpWindow is how long a re-authentication covers privileged actions.
+const StepUpWindow = 5 * time.Minute
+
+// DefaultIdleTimeouts lock a session after this long without activity. A
+// vehicle or robot keeps its session while in use by the operator on board,
+// so the timeout is long; shared desks and phones lock quickly.
+var DefaultIdleTimeouts = map[internal_environment.PlatformClass]time.Duration{
+	internal_environment.PlatformComputer:   15 * time.Minute,
+	internal_environment.PlatformMobile:     5 * time.Minute,
+	internal_environment.PlatformIndustrial: 10 * time.Minute,
+	internal_environment.PlatformEmbedded:   30 * time.Minute,
+	internal_environment.PlatformVehicle:    2 * time.Hour,
+	internal_environment.PlatformRobot:      30 * time.Minute,
+}
+
+// FallbackIdleTimeout applies to platforms without an entry.
+const FallbackIdleTimeout = 15 * time.Minute
+
+// StepUpPermissions need a fresh re-authentication before every use.
+var StepUpPermissions = map[user_setting.PermissionKey]bool{
+	user_setting.PermSafetyOverride: true,
+	user_setting.PermAdmin:          true,
+	user_setting.PermConfigEdit:     true,
+}
+
+type activity struct {
+	lastSeen   time.Time
+	verifiedAt time.Time
+	locked     bool
+}
+
+// SessionActivity tracks idle time and the last re-authentication of each
+// live session. State is kept in memory: after a restart every session
+// counts as verified at its creation and active from its first request.
+type SessionActivity struct {
+	mu       sync.Mutex
+	idle     map[internal_environment.PlatformClass]time.Duration
+	window   time.Duration
+	sessions map[string]*activity
+	now      func() time.Time
+}
+
+// NewSessionActivity uses idle timeouts per platform (nil for
+// DefaultIdleTimeouts) and a step-up window (zero for StepUpWindow).
+func NewSessionActivity(idle map[internal_environment.PlatformClass]time.Duration, window time.Duration) *SessionActivity {
+	if idle == nil {
+		idle = DefaultIdleTimeouts
+	}
+	if window <= 0 {
+		window = StepUpWindow
+	}
+	return &SessionActivity{
+		idle:     idle,
+		window:   window,
+		sessions: make(map[string]*activity),
+		now:      time.Now,
+	}
+}
+
+func (a *SessionActivity) get(claims *user_setting.SessionClaims) *activity {
+	s, ok := a.sessions[claims.SessionID]
+	if !ok {
+		s = &activity{lastSeen: a.now(), verifiedAt: claims.CreatedAt}
+		a.sessions[claims.SessionID] = s
+	}
+	return s
+}
+
+// IdleTimeout returns the timeout applied on platform.
+func (a *SessionActivity) IdleTimeout(platform internal_environment.PlatformClass) time.Duration {
+	if d, ok := a.idle[platform]; ok {
+		return d
+	}
+	return FallbackIdleTimeout
+}
+
+// Touch records activity on the session. A session idle for longer than
+// its platform timeout is locked and stays locked until Reverified.
+func (a *SessionActivity) Touch(claims *user_setting.SessionClaims) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s := a.get(claims)
+	now := a.now()
+
+	if !s.locked && now.Sub(s.lastSeen) > a.IdleTimeout(claims.Platform) {
+		s.locked = true
+	}
+	if s.locked {
+		return ErrSessionLocked
+	}
+
+	s.lastSeen = now
+	return nil
+}
+
+// Locked reports whether the session is waiting for re-authentication.
+func (a *SessionActivity) Locked(claims *user_setting.SessionClaims) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.get(claims).locked
+}
+
+// RequireFresh fails with ErrStepUpRequired when perm needs step-up and the
+// session has not re-authenticated within the window. Permissions the
+// session does not hold at all are not this check's concern.
+func (a *SessionActivity) RequireFresh(claims *user_setting.SessionClaims, perm user_setting.PermissionKey) error {
+	if !StepUpPermissions[perm] {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s := a.get(claims)
+	if s.locked {
+		return ErrSessionLocked
+	}
+	if a.now().Sub(s.verifiedAt) > a.window {
+		return ErrStepUpRequired
+	}
+	return nil
+}
+
+// Reverified records a successful re-authentication, unlocking the session.
+func (a *SessionActivity) Reverified(claims *user_setting.SessionClaims) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s := a.get(claims)
+	now := a.now()
+	s.verifiedAt = now
+	s.lastSeen = now
+	s.locked = false
+}
+
+// End forgets the session, e.g. on logout.
+func (a *SessionActivity) End(sessionID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.sessions, sessionID)
+}
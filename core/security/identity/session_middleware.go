@@ -0,0 +1,106 @@
+//core/security/identity/session_middleware.go
+
+package verification_identity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+type sessionContextKey struct{}
+
+// WithSession attaches verified claims to ctx.
+func WithSession(ctx context.Context, claims *user_setting.SessionClaims) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, claims)
+}
+
+// SessionFromContext returns the claims placed by RequireSession, or nil.
+func SessionFromContext(ctx context.Context) *user_setting.SessionClaims {
+	claims, _ := ctx.Value(sessionContextKey{}).(*user_setting.SessionClaims)
+	return claims
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer" header.
+func BearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	if len(h) > 7 && strings.EqualFold(h[:7], "bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return ""
+}
+
+// RequireSession rejects requests without a valid session token and makes
+// the verified claims available through SessionFromContext.
+func RequireSession(tokens *TokenService, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := BearerToken(r)
+		if token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="aios"`)
+			http.Error(w, "session token required", http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := tokens.Verify(token)
+		if err != nil {
+			status := http.StatusUnauthorized
+			if !errors.Is(err, ErrTokenMalformed) && !errors.Is(err, ErrTokenSignature) &&
+				!errors.Is(err, ErrTokenExpired) && !errors.Is(err, ErrTokenRevoked) {
+				status = http.StatusInternalServerError
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithSession(r.Context(), claims)))
+	})
+}
+
+// TrackActivity records activity for the session placed by RequireSession
+// and refuses requests while the session is locked for idling.
+func TrackActivity(activity *SessionActivity, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := SessionFromContext(r.Context())
+		if claims == nil {
+			http.Error(w, "session required", http.StatusUnauthorized)
+			return
+		}
+
+		if err := activity.Touch(claims); err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="aios", error="session_locked"`)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireStepUp guards a privileged handler: the session must hold perm
+// and, for StepUpPermissions, have re-authenticated within the window.
+// The 401 body names the permission so the client can prompt for it.
+func RequireStepUp(activity *SessionActivity, perm user_setting.PermissionKey, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := SessionFromContext(r.Context())
+		if claims == nil || !claims.Permissions[perm] {
+			http.Error(w, "permission required: "+string(perm), http.StatusForbidden)
+			return
+		}
+
+		if err := activity.RequireFresh(claims, perm); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"error":      err.Error(),
+				"permission": string(perm),
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
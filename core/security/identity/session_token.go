@@ -0,0 +1,289 @@
+//core/security/identity/session_token.go
+
+package verification_identity
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+const (
+	sessionCollection    = "sessions"
+	sessionKeyKey        = "signing_key"
+	revocationCollection = "session_revocations"
+
+	tokenPrefix = "aios1"
+
+	DefaultSessionLifetime = 12 * time.Hour
+	// MaxSessionAge bounds how long a session can be kept alive by refresh.
+	MaxSessionAge = 7 * 24 * time.Hour
+)
+
+var (
+	ErrTokenMalformed = errors.New("session_token_malformed")
+	ErrTokenSignature = errors.New("session_token_signature_invalid")
+	ErrTokenExpired   = errors.New("session_token_expired")
+	ErrTokenRevoked   = errors.New("session_token_revoked")
+	ErrGuestRefresh   = errors.New("guest_session_not_refreshable")
+)
+
+// tokenBody is what a token carries: the session claims plus the id of
+// this particular token, so a refreshed token can retire its predecessor.
+type tokenBody struct {
+	TokenID string                     `json:"jti"`
+	Claims  user_setting.SessionClaims `json:"claims"`
+	Issued  time.Time                  `json:"iat"`
+	Expires time.Time                  `json:"exp"`
+}
+
+// revocation records a revoked session or token until it would have
+// expired anyway.
+type revocation struct {
+	Reason    string    `json:"reason"`
+	RevokedAt time.Time `json:"revoked_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TokenService issues and verifies session tokens. Tokens are
+// aios1.<base64url claims>.<base64url HMAC-SHA256>, keyed with a per-unit
+// secret stored sealed in the vault.
+type TokenService struct {
+	vault verification_persistence.VaultStore
+	key   []byte
+}
+
+// NewTokenService loads the unit's session signing key, creating it on
+// first use.
+func NewTokenService(v verification_persistence.VaultStore) (*TokenService, error) {
+	var key []byte
+
+	err := verification_persistence.Atomically(v, func(tx verification_persistence.VaultTx) error {
+		found, err := verification_persistence.ReadSealed(tx, sessionCollection, sessionKeyKey, &key)
+		if err != nil || found {
+			return err
+		}
+
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return err
+		}
+		return verification_persistence.WriteSealed(tx, sessionCollection, sessionKeyKey, key)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("session signing key unavailable: %w", err)
+	}
+
+	return &TokenService{vault: v, key: key}, nil
+}
+
+// NewSessionID returns an unguessable session identifier.
+func NewSessionID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Issue signs claims. A missing SessionID, CreatedAt or ExpiresAt is
+// filled in; ExpiresAt is capped at CreatedAt+MaxSessionAge.
+func (s *TokenService) Issue(claims *user_setting.SessionClaims) (string, error) {
+	now := time.Now().UTC()
+
+	if claims.SessionID == "" {
+		claims.SessionID = NewSessionID()
+	}
+	if claims.CreatedAt.IsZero() {
+		claims.CreatedAt = now
+	}
+	if claims.ExpiresAt.IsZero() {
+		claims.ExpiresAt = now.Add(DefaultSessionLifetime)
+	}
+	if limit := claims.CreatedAt.Add(MaxSessionAge); claims.ExpiresAt.After(limit) {
+		claims.ExpiresAt = limit
+	}
+
+	body := tokenBody{
+		TokenID: NewSessionID(),
+		Claims:  *claims,
+		Issued:  now,
+		Expires: claims.ExpiresAt,
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	enc := tokenPrefix + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return enc + "." + base64.RawURLEncoding.EncodeToString(s.mac(enc)), nil
+}
+
+// Verify checks the signature, expiry and revocation list and returns the
+// session claims.
+func (s *TokenService) Verify(token string) (*user_setting.SessionClaims, error) {
+	body, err := s.parse(token)
+	if err != nil {
+		return nil, err
+	}
+	return &body.Claims, nil
+}
+
+// Refresh exchanges a valid token for a new one with a fresh lifetime. The
+// old token is revoked. Sessions cannot be refreshed past MaxSessionAge,
+// and guest sessions cannot be refreshed at all.
+func (s *TokenService) Refresh(token string) (string, *user_setting.SessionClaims, error) {
+	body, err := s.parse(token)
+	if err != nil {
+		return "", nil, err
+	}
+
+	claims := body.Claims
+	if claims.Guest {
+		return "", nil, ErrGuestRefresh
+	}
+	if !time.Now().Before(claims.CreatedAt.Add(MaxSessionAge)) {
+		return "", nil, ErrTokenExpired
+	}
+	claims.ExpiresAt = time.Now().UTC().Add(DefaultSessionLifetime)
+
+	next, err := s.Issue(&claims)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := s.revoke("jti:"+body.TokenID, "refreshed", body.Expires); err != nil {
+		return "", nil, err
+	}
+
+	return next, &claims, nil
+}
+
+// RevokeSession invalidates every token issued for sessionID.
+func (s *TokenService) RevokeSession(sessionID, reason string) error {
+	return s.revoke("sid:"+sessionID, reason, time.Now().UTC().Add(MaxSessionAge))
+}
+
+// RevokeUser invalidates every session of userID created up to now, e.g.
+// after a password reset. Sessions created afterwards are unaffected.
+func (s *TokenService) RevokeUser(userID, reason string) error {
+	return s.revoke("uid:"+userID, reason, time.Now().UTC().Add(MaxSessionAge))
+}
+
+// RevokeToken invalidates a single token, e.g. on logout.
+func (s *TokenService) RevokeToken(token, reason string) error {
+	body, err := s.parse(token)
+	if err != nil {
+		return err
+	}
+	return s.revoke("jti:"+body.TokenID, reason, body.Expires)
+}
+
+// PruneRevocations drops revocation entries for tokens that have expired.
+func (s *TokenService) PruneRevocations() (int, error) {
+	keys, err := s.vault.List(revocationCollection)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	pruned := 0
+
+	for _, k := range keys {
+		var r revocation
+		if _, err := s.vault.Read(revocationCollection, k, &r); err != nil {
+			return pruned, err
+		}
+		if now.After(r.ExpiresAt) {
+			if err := s.vault.Delete(revocationCollection, k); err != nil {
+				return pruned, err
+			}
+			pruned++
+		}
+	}
+
+	return pruned, nil
+}
+
+// RevokeUserTx is RevokeUser inside a vault transaction, for changes that
+// must not commit without the revocation (a password reset).
+func RevokeUserTx(tx verification_persistence.VaultTx, userID, reason string) error {
+	return writeRevocation(tx, "uid:"+userID, reason, time.Now().UTC().Add(MaxSessionAge))
+}
+
+func (s *TokenService) revoke(key, reason string, expires time.Time) error {
+	return writeRevocation(s.vault, key, reason, expires)
+}
+
+func writeRevocation(tx verification_persistence.VaultTx, key, reason string, expires time.Time) error {
+	return tx.Write(revocationCollection, key, revocation{
+		Reason:    reason,
+		RevokedAt: time.Now().UTC(),
+		ExpiresAt: expires,
+	})
+}
+
+func (s *TokenService) parse(token string) (*tokenBody, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != tokenPrefix {
+		return nil, ErrTokenMalformed
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+	if !hmac.Equal(sig, s.mac(parts[0]+"."+parts[1])) {
+		return nil, ErrTokenSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	var body tokenBody
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	if !time.Now().Before(body.Expires) {
+		return nil, ErrTokenExpired
+	}
+
+	for _, k := range []string{"sid:" + body.Claims.SessionID, "jti:" + body.TokenID} {
+		revoked, err := s.vault.Exists(revocationCollection, k)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+
+	var r revocation
+	found, err := s.vault.Read(revocationCollection, "uid:"+body.Claims.UserID, &r)
+	if err != nil {
+		return nil, err
+	}
+	if found && !body.Claims.CreatedAt.After(r.RevokedAt) {
+		return nil, ErrTokenRevoked
+	}
+
+	return &body, nil
+}
+
+func (s *TokenService) mac(data string) []byte {
+	m := hmac.New(sha256.New, s.key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
@@ -0,0 +1,194 @@
+//core/security/identity/session_token_test.go
+
+package verification_identity
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+func testTokens(t *testing.T) (*TokenService, *verification_persistence.IsolatedVault) {
+	t.Helper()
+	v := testVault(t)
+	s, err := NewTokenService(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, v
+}
+
+func TestTokenRoundTripAndKeyPersists(t *testing.T) {
+	s, v := testTokens(t)
+
+	token, err := s.Issue(&user_setting.SessionClaims{UserID: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := s.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != "alice" || claims.SessionID == "" {
+		t.Fatalf("claims = %+v", claims)
+	}
+
+	// A restarted service loads the same key from the vault.
+	again, err := NewTokenService(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := again.Verify(token); err != nil {
+		t.Fatalf("token rejected after restart: %v", err)
+	}
+}
+
+func TestTokenRejectsTamperingAndForeignKeys(t *testing.T) {
+	s, _ := testTokens(t)
+	token, err := s.Issue(&user_setting.SessionClaims{UserID: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Swap in the claims of a token naming another user.
+	forged, err := s.Issue(&user_setting.SessionClaims{UserID: "mallory", Permissions: map[user_setting.PermissionKey]bool{user_setting.PermAdmin: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, f := strings.Split(token, "."), strings.Split(forged, ".")
+	if _, err := s.Verify(p[0] + "." + f[1] + "." + p[2]); !errors.Is(err, ErrTokenSignature) {
+		t.Errorf("swapped claims: %v, want %v", err, ErrTokenSignature)
+	}
+
+	other, _ := testTokens(t)
+	if _, err := other.Verify(token); !errors.Is(err, ErrTokenSignature) {
+		t.Errorf("token of another unit: %v, want %v", err, ErrTokenSignature)
+	}
+
+	if _, err := s.Verify("not-a-token"); !errors.Is(err, ErrTokenMalformed) {
+		t.Errorf("garbage: %v, want %v", err, ErrTokenMalformed)
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	s, _ := testTokens(t)
+	created := time.Now().UTC().Add(-2 * time.Hour)
+	token, err := s.Issue(&user_setting.SessionClaims{UserID: "alice", CreatedAt: created, ExpiresAt: created.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Verify(token); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("expired token: %v, want %v", err, ErrTokenExpired)
+	}
+}
+
+func TestRefreshRetiresThePreviousToken(t *testing.T) {
+	s, _ := testTokens(t)
+	token, err := s.Issue(&user_setting.SessionClaims{UserID: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, claims, err := s.Refresh(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != "alice" {
+		t.Errorf("refreshed claims = %+v", claims)
+	}
+	if _, err := s.Verify(next); err != nil {
+		t.Errorf("refreshed token: %v", err)
+	}
+	if _, err := s.Verify(token); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("previous token: %v, want %v", err, ErrTokenRevoked)
+	}
+
+	guest, err := s.Issue(&user_setting.SessionClaims{Guest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Refresh(guest); !errors.Is(err, ErrGuestRefresh) {
+		t.Errorf("guest refresh: %v, want %v", err, ErrGuestRefresh)
+	}
+}
+
+func TestRevokeSessionAndToken(t *testing.T) {
+	s, _ := testTokens(t)
+	a, _ := s.Issue(&user_setting.SessionClaims{UserID: "alice", SessionID: "s1"})
+	b, _ := s.Issue(&user_setting.SessionClaims{UserID: "alice", SessionID: "s2"})
+	c, _ := s.Issue(&user_setting.SessionClaims{UserID: "alice", SessionID: "s3"})
+
+	if err := s.RevokeSession("s1", "logout"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RevokeToken(b, "logout"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Verify(a); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("revoked session: %v", err)
+	}
+	if _, err := s.Verify(b); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("revoked token: %v", err)
+	}
+	if _, err := s.Verify(c); err != nil {
+		t.Errorf("untouched session: %v", err)
+	}
+}
+
+func TestRevokeUserTxOnlyCommitsWithItsTransaction(t *testing.T) {
+	v, err := verification_persistence.OpenBoltVault(filepath.Join(t.TempDir(), "vault.db"), bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+	s, err := NewTokenService(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := s.Issue(&user_setting.SessionClaims{UserID: "alice", CreatedAt: time.Now().UTC().Add(-time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failed := errors.New("reset failed")
+	err = verification_persistence.Atomically(v, func(tx verification_persistence.VaultTx) error {
+		if err := RevokeUserTx(tx, "alice", "password_reset"); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Atomically = %v", err)
+	}
+	if _, err := s.Verify(token); err != nil {
+		t.Fatalf("revocation of a rolled back reset took effect: %v", err)
+	}
+
+	err = verification_persistence.Atomically(v, func(tx verification_persistence.VaultTx) error {
+		return RevokeUserTx(tx, "alice", "password_reset")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Verify(token); !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("session before the reset: %v, want %v", err, ErrTokenRevoked)
+	}
+
+	// Logging in again after the reset works.
+	fresh, err := s.Issue(&user_setting.SessionClaims{UserID: "alice", CreatedAt: time.Now().UTC().Add(time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Verify(fresh); err != nil {
+		t.Fatalf("session after the reset: %v", err)
+	}
+}
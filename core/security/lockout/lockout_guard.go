@@ -0,0 +1,237 @@
+//core/security/lockout/lockout_guard.go
+
+package security_lockout
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+const counterCollection = "lockout"
+
+// Auditor is satisfied by *security_audit.Log.
+type Auditor interface {
+	Append(ev security_audit.Event) (*security_audit.Entry, error)
+}
+
+// LockedError is returned by Check while a key is locked out or backing off.
+type LockedError struct {
+	Key     string
+	Until   time.Time
+	Lockout bool // false: back-off delay only
+}
+
+func (e *LockedError) Error() string {
+	if e.Lockout {
+		return fmt.Sprintf("login locked for %s until %s", e.Key, e.Until.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("too many attempts for %s; retry after %s", e.Key, time.Until(e.Until).Round(time.Second))
+}
+
+// Counter is the persisted failure state of one user or source.
+type Counter struct {
+	Key          string    `json:"key"`
+	Failures     int       `json:"failures"`
+	FirstFailure time.Time `json:"first_failure"`
+	LastFailure  time.Time `json:"last_failure"`
+	NextAllowed  time.Time `json:"next_allowed"`
+	LockedUntil  time.Time `json:"locked_until,omitempty"`
+}
+
+func (c *Counter) Locked(now time.Time) bool {
+	return now.Before(c.LockedUntil)
+}
+
+// Guard applies Policy to login attempts.
+type Guard struct {
+	vault  verification_persistence.VaultStore
+	policy Policy
+	audit  Auditor
+	now    func() time.Time
+}
+
+func NewGuard(v verification_persistence.VaultStore, policy Policy, audit Auditor) *Guard {
+	return &Guard{vault: v, policy: policy, audit: audit, now: time.Now}
+}
+
+func UserKey(userID string) string   { return "user:" + userID }
+func SourceKey(source string) string { return "source:" + source }
+
+// isCounterKey reports whether key names a user or source counter. Other
+// records a backend reports under the collection are not counters.
+func isCounterKey(key string) bool {
+	return strings.HasPrefix(key, "user:") || strings.HasPrefix(key, "source:")
+}
+
+// Check returns a *LockedError if either the user or the source may not
+// attempt a login right now.
+func (g *Guard) Check(userID, source string) error {
+	now := g.now()
+
+	for _, key := range g.keys(userID, source) {
+		var c Counter
+		found, err := g.vault.Read(counterCollection, key, &c)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+
+		if c.Locked(now) {
+			return &LockedError{Key: key, Until: c.LockedUntil, Lockout: true}
+		}
+		if now.Before(c.NextAllowed) {
+			return &LockedError{Key: key, Until: c.NextAllowed}
+		}
+	}
+
+	return nil
+}
+
+// RecordFailure counts a failed attempt and returns the resulting lock, if
+// any.
+func (g *Guard) RecordFailure(userID, source string) error {
+	now := g.now()
+	var locked error
+
+	err := verification_persistence.Atomically(g.vault, func(tx verification_persistence.VaultTx) error {
+		for _, key := range g.keys(userID, source) {
+			var c Counter
+			if _, err := tx.Read(counterCollection, key, &c); err != nil {
+				return err
+			}
+
+			if c.Failures == 0 || now.Sub(c.FirstFailure) > g.policy.Window {
+				c = Counter{Key: key, FirstFailure: now}
+			}
+
+			c.Failures++
+			c.LastFailure = now
+			c.NextAllowed = now.Add(g.delay(c.Failures))
+
+			threshold := g.policy.Threshold
+			if strings.HasPrefix(key, "source:") {
+				threshold = g.policy.SourceThreshold
+			}
+
+			if threshold > 0 && c.Failures >= threshold && !c.Locked(now) {
+				c.LockedUntil = now.Add(g.policy.LockoutDuration)
+				locked = &LockedError{Key: key, Until: c.LockedUntil, Lockout: true}
+				g.record("auth.lockout", key, "locked", c)
+			}
+
+			if err := tx.Write(counterCollection, key, c); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return locked
+}
+
+// RecordSuccess clears the user's counter. The source counter is left to
+// expire so one good login cannot reset a spraying source.
+func (g *Guard) RecordSuccess(userID string) error {
+	if userID == "" {
+		return nil
+	}
+	return g.vault.Delete(counterCollection, UserKey(userID))
+}
+
+// Unlock clears a counter; key is a UserKey or SourceKey.
+func (g *Guard) Unlock(key, admin string) error {
+	if !isCounterKey(key) {
+		return fmt.Errorf("not a lockout key: %s (want user:<id> or source:<addr>)", key)
+	}
+
+	var c Counter
+	found, err := g.vault.Read(counterCollection, key, &c)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("no lockout state for %s", key)
+	}
+
+	if err := g.vault.Delete(counterCollection, key); err != nil {
+		return err
+	}
+
+	g.recordAs(admin, "auth.unlock", key, "unlocked", c)
+	return nil
+}
+
+// Counters lists the stored failure state, e.g. for `aios lockout status`.
+func (g *Guard) Counters() ([]Counter, error) {
+	keys, err := g.vault.List(counterCollection)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+
+	out := make([]Counter, 0, len(keys))
+	for _, k := range keys {
+		if !isCounterKey(k) {
+			continue
+		}
+		var c Counter
+		if _, err := g.vault.Read(counterCollection, k, &c); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (g *Guard) keys(userID, source string) []string {
+	var keys []string
+	if userID != "" {
+		keys = append(keys, UserKey(userID))
+	}
+	if source != "" {
+		keys = append(keys, SourceKey(source))
+	}
+	return keys
+}
+
+// delay is BaseDelay doubled for every failure after the first.
+func (g *Guard) delay(failures int) time.Duration {
+	d := g.policy.BaseDelay
+	for i := 1; i < failures && d < g.policy.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > g.policy.MaxDelay {
+		d = g.policy.MaxDelay
+	}
+	return d
+}
+
+func (g *Guard) record(action, key, result string, c Counter) {
+	g.recordAs("lockout", action, key, result, c)
+}
+
+func (g *Guard) recordAs(actor, action, key, result string, c Counter) {
+	if g.audit == nil {
+		return
+	}
+
+	detail, _ := json.Marshal(c)
+	_, _ = g.audit.Append(security_audit.Event{
+		Actor:    actor,
+		Action:   action,
+		Resource: key,
+		Result:   result,
+		Detail:   detail,
+	})
+}
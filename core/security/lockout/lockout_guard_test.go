@@ -0,0 +1,72 @@
+//core/security/lockout/lockout_guard_test.go
+
+package security_lockout
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+)
+
+func testGuard(t *testing.T) (*Guard, *verification_persistence.IsolatedVault) {
+	t.Helper()
+	v := &verification_persistence.IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{1}, 32)}
+	policy := Policy{Threshold: 2, SourceThreshold: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond,
+		LockoutDuration: time.Hour, Window: time.Hour}
+	return NewGuard(v, policy, nil), v
+}
+
+func TestCountersListOnlyCounters(t *testing.T) {
+	g, v := testGuard(t)
+
+	// lockout_policy records share the file prefix of the counters.
+	if err := SavePolicy(v, internal_environment.PlatformVehicle, FallbackPolicy); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.RecordFailure("alice", "tty1"); err != nil {
+		t.Fatal(err)
+	}
+
+	counters, err := g.Counters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counters) != 2 {
+		t.Fatalf("counters = %+v, want alice and tty1 only", counters)
+	}
+	for _, c := range counters {
+		if c.Key != UserKey("alice") && c.Key != SourceKey("tty1") {
+			t.Errorf("unexpected counter %q", c.Key)
+		}
+	}
+
+	if err := g.Unlock("policy_"+string(internal_environment.PlatformVehicle), "admin"); err == nil {
+		t.Error("Unlock accepted a key that is not a counter")
+	}
+}
+
+func TestRecordFailureLocksAtThreshold(t *testing.T) {
+	g, _ := testGuard(t)
+
+	if err := g.RecordFailure("bob", ""); err != nil {
+		t.Fatalf("first failure: %v", err)
+	}
+	var locked *LockedError
+	if err := g.RecordFailure("bob", ""); !errors.As(err, &locked) || !locked.Lockout {
+		t.Fatalf("second failure = %v, want lockout", err)
+	}
+	if err := g.Check("bob", ""); !errors.As(err, &locked) {
+		t.Errorf("Check while locked = %v", err)
+	}
+
+	if err := g.Unlock(UserKey("bob"), "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Check("bob", ""); err != nil {
+		t.Errorf("Check after unlock = %v", err)
+	}
+}
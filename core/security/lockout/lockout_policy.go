@@ -0,0 +1,91 @@
+//core/security/lockout/lockout_policy.go
+
+package security_lockout
+
+import (
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+)
+
+const policyCollection = "lockout_policy"
+
+// Policy controls how failed logins are throttled.
+//
+// Every failure inside Window delays the next attempt by BaseDelay,
+// doubling per failure up to MaxDelay. At Threshold failures the key is
+// locked for LockoutDuration or until an admin unlocks it.
+type Policy struct {
+	Threshold       int           `json:"threshold"`
+	BaseDelay       time.Duration `json:"base_delay"`
+	MaxDelay        time.Duration `json:"max_delay"`
+	LockoutDuration time.Duration `json:"lockout_duration"`
+	Window          time.Duration `json:"window"`
+
+	// SourceThreshold applies to a single source (terminal, remote
+	// address) across all user IDs, catching password spraying.
+	SourceThreshold int `json:"source_threshold"`
+}
+
+// DefaultPolicies are tuned per platform. A shared kiosk or office PC locks
+// hard; an industrial HMI must stay usable by the next operator on shift,
+// so it backs off but locks only briefly.
+var DefaultPolicies = map[internal_environment.PlatformClass]Policy{
+	internal_environment.PlatformComputer: {
+		Threshold: 5, SourceThreshold: 20,
+		BaseDelay: time.Second, MaxDelay: 30 * time.Second,
+		LockoutDuration: 15 * time.Minute, Window: time.Hour,
+	},
+	internal_environment.PlatformMobile: {
+		Threshold: 5, SourceThreshold: 20,
+		BaseDelay: time.Second, MaxDelay: 30 * time.Second,
+		LockoutDuration: 15 * time.Minute, Window: time.Hour,
+	},
+	internal_environment.PlatformIndustrial: {
+		Threshold: 10, SourceThreshold: 30,
+		BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second,
+		LockoutDuration: 2 * time.Minute, Window: 15 * time.Minute,
+	},
+	internal_environment.PlatformEmbedded: {
+		Threshold: 10, SourceThreshold: 30,
+		BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second,
+		LockoutDuration: 2 * time.Minute, Window: 15 * time.Minute,
+	},
+	internal_environment.PlatformVehicle: {
+		Threshold: 5, SourceThreshold: 10,
+		BaseDelay: 2 * time.Second, MaxDelay: time.Minute,
+		LockoutDuration: 5 * time.Minute, Window: 30 * time.Minute,
+	},
+	internal_environment.PlatformRobot: {
+		Threshold: 5, SourceThreshold: 10,
+		BaseDelay: 2 * time.Second, MaxDelay: time.Minute,
+		LockoutDuration: 5 * time.Minute, Window: 30 * time.Minute,
+	},
+}
+
+// FallbackPolicy is used for platforms without an entry.
+var FallbackPolicy = DefaultPolicies[internal_environment.PlatformComputer]
+
+// LoadPolicy returns the policy stored for platform in the vault, or the
+// built-in default.
+func LoadPolicy(v verification_persistence.VaultStore, platform internal_environment.PlatformClass) (Policy, error) {
+	var p Policy
+	found, err := v.Read(policyCollection, string(platform), &p)
+	if err != nil {
+		return FallbackPolicy, err
+	}
+	if found {
+		return p, nil
+	}
+
+	if p, ok := DefaultPolicies[platform]; ok {
+		return p, nil
+	}
+	return FallbackPolicy, nil
+}
+
+// SavePolicy overrides the built-in policy for platform.
+func SavePolicy(v verification_persistence.VaultStore, platform internal_environment.PlatformClass, p Policy) error {
+	return v.Write(policyCollection, string(platform), p)
+}
@@ -0,0 +1,225 @@
+//core/security/measurement/event_log.go
+
+package verification_measurement
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// EventType classifies what a measurement covers.
+type EventType string
+
+const (
+	EventBinary    EventType = "binary"
+	EventConfig    EventType = "config"
+	EventPolicy    EventType = "policy"
+	EventModule    EventType = "module"
+	EventPlugin    EventType = "plugin"
+	EventEnvConfig EventType = "env_config"
+)
+
+// MeasurementEvent is one entry of the event log. Extended is the running
+// digest after this event was folded in, like a TPM PCR value.
+type MeasurementEvent struct {
+	Index    int       `json:"index"`
+	Type     EventType `json:"type"`
+	Name     string    `json:"name"`
+	Digest   string    `json:"digest"`
+	Extended string    `json:"extended"`
+	At       time.Time `json:"at"`
+}
+
+var (
+	ErrLogUnsigned  = errors.New("measurement_log_unsigned")
+	ErrLogSignature = errors.New("measurement_log_signature_invalid")
+)
+
+// Signer signs a finished log. It is implemented by
+// verification_identity.DeviceIdentity.
+type Signer interface {
+	Sign(msg []byte) []byte
+}
+
+// EventLog is an ordered, hash-extended record of everything measured
+// during one boot. Replaying the events from a zero register must
+// reproduce Final, and Signature binds Final to the device that booted.
+type EventLog struct {
+	BootID    string             `json:"boot_id"`
+	StartedAt time.Time          `json:"started_at"`
+	Events    []MeasurementEvent `json:"events"`
+	Final     string             `json:"final"`
+	Signature []byte             `json:"signature,omitempty"`
+
+	mu  sync.Mutex
+	pcr [sha256.Size]byte
+}
+
+func NewEventLog() *EventLog {
+	id := make([]byte, 6)
+	rand.Read(id)
+
+	now := time.Now().UTC()
+
+	return &EventLog{
+		BootID:    fmt.Sprintf("%s-%s", now.Format("20060102T150405Z"), hex.EncodeToString(id)),
+		StartedAt: now,
+		Final:     hex.EncodeToString(make([]byte, sha256.Size)),
+	}
+}
+
+// Extend folds a pre-computed digest into the log.
+func (l *EventLog) Extend(t EventType, name string, digest []byte) MeasurementEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pcr = extend(l.pcr, t, name, digest)
+
+	ev := MeasurementEvent{
+		Index:    len(l.Events),
+		Type:     t,
+		Name:     name,
+		Digest:   hex.EncodeToString(digest),
+		Extended: hex.EncodeToString(l.pcr[:]),
+		At:       time.Now().UTC(),
+	}
+
+	l.Events = append(l.Events, ev)
+	l.Final = ev.Extended
+
+	return ev
+}
+
+// MeasureBytes hashes data and extends the log with it.
+func (l *EventLog) MeasureBytes(t EventType, name string, data []byte) MeasurementEvent {
+	sum := sha256.Sum256(data)
+	return l.Extend(t, name, sum[:])
+}
+
+// MeasureFile streams path through SHA-256 and extends the log with it.
+func (l *EventLog) MeasureFile(t EventType, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	l.Extend(t, path, h.Sum(nil))
+	return nil
+}
+
+// MeasureJSON measures the canonical JSON encoding of v.
+func (l *EventLog) MeasureJSON(t EventType, name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	l.MeasureBytes(t, name, data)
+	return nil
+}
+
+// FinalDigest returns the current register value.
+func (l *EventLog) FinalDigest() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.Final
+}
+
+// Replay recomputes the register from events alone and returns the final
+// digest. It fails at the first event whose recorded Extended value does
+// not match, which pinpoints a reordered, altered or removed entry.
+func Replay(events []MeasurementEvent) (string, error) {
+	var pcr [sha256.Size]byte
+
+	for i, ev := range events {
+		if ev.Index != i {
+			return "", fmt.Errorf("event %d out of order (index %d)", i, ev.Index)
+		}
+
+		digest, err := hex.DecodeString(ev.Digest)
+		if err != nil {
+			return "", fmt.Errorf("event %d: malformed digest: %w", i, err)
+		}
+
+		pcr = extend(pcr, ev.Type, ev.Name, digest)
+
+		if hex.EncodeToString(pcr[:]) != ev.Extended {
+			return "", fmt.Errorf("event %d (%s %s): extended value mismatch", i, ev.Type, ev.Name)
+		}
+	}
+
+	return hex.EncodeToString(pcr[:]), nil
+}
+
+// Verify replays the log and checks it against the recorded final digest.
+func (l *EventLog) Verify() error {
+	final, err := Replay(l.Events)
+	if err != nil {
+		return err
+	}
+
+	if final != l.Final {
+		return errors.New("measurement_final_mismatch")
+	}
+
+	return nil
+}
+
+// Sign signs the boot ID and final digest with s. Since Final commits to
+// every event, a verified signature covers the whole log.
+func (l *EventLog) Sign(s Signer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.Signature = s.Sign(l.signedBytes())
+}
+
+// VerifySignature replays the log and checks its signature against pub,
+// the public key of the device identity.
+func (l *EventLog) VerifySignature(pub ed25519.PublicKey) error {
+	if err := l.Verify(); err != nil {
+		return err
+	}
+	if len(l.Signature) == 0 {
+		return ErrLogUnsigned
+	}
+	if len(pub) != ed25519.PublicKeySize || !ed25519.Verify(pub, l.signedBytes(), l.Signature) {
+		return ErrLogSignature
+	}
+	return nil
+}
+
+func (l *EventLog) signedBytes() []byte {
+	return []byte("aios-measurement-v1\x00" + l.BootID + "\x00" + l.StartedAt.UTC().Format(time.RFC3339Nano) + "\x00" + l.Final)
+}
+
+// extend computes SHA-256(pcr || SHA-256(type 0x00 name 0x00 digest)).
+// Binding type and name into the event digest means an entry cannot be
+// relabelled without changing every later register value.
+func extend(pcr [sha256.Size]byte, t EventType, name string, digest []byte) [sha256.Size]byte {
+	var ev bytes.Buffer
+	ev.WriteString(string(t))
+	ev.WriteByte(0)
+	ev.WriteString(name)
+	ev.WriteByte(0)
+	ev.Write(digest)
+
+	evSum := sha256.Sum256(ev.Bytes())
+
+	return sha256.Sum256(append(pcr[:], evSum[:]...))
+}
@@ -5,10 +5,61 @@ package verification_measurement
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/apppath"
+)
+
+const (
+	measurementCollection = "measurements"
+	latestMeasurementKey  = "latest"
+
+	// KeepEventLogs is how many per-boot logs SaveEventLog retains.
+	KeepEventLogs = 32
 )
 
+// LogStore is the part of the vault used to persist event logs.
+type LogStore interface {
+	Read(collection, key string, out interface{}) (bool, error)
+	Write(collection, key string, value interface{}) error
+	List(collection string) ([]string, error)
+	Delete(collection, key string) error
+}
+
+// BootArtifacts lists the on-disk inputs MeasureBoot covers, in the order
+// they are measured. Missing directories are skipped, and so are the
+// directories in Skip wherever they appear: they hold state that changes
+// on every boot, not configuration.
+type BootArtifacts struct {
+	ConfigDir  string
+	PolicyDir  string
+	ModuleDirs []string
+	PluginDirs []string
+	Skip       []string
+}
+
+// DefaultBootArtifacts resolves the standard locations from apppath. In
+// installed mode the config dir is the data root, so the vault, runtime
+// and log directories below it are skipped.
+func DefaultBootArtifacts() BootArtifacts {
+	root := apppath.GetRootDir()
+	cfg := apppath.GetConfigDir()
+
+	return BootArtifacts{
+		ConfigDir:  cfg,
+		PolicyDir:  filepath.Join(cfg, "policies"),
+		ModuleDirs: []string{filepath.Join(root, "modules")},
+		PluginDirs: []string{filepath.Join(root, "plugins")},
+		Skip:       []string{apppath.GetVaultPath(), apppath.GetRuntimeDir(), apppath.GetLogDir()},
+	}
+}
+
 // GenerateEnvHash creates the cryptographic fingerprint of the hardware state
 func GenerateEnvHash(machineName string, osName string, busCount int) string {
 	raw := fmt.Sprintf("%s-%s-%d", machineName, osName, busCount)
@@ -16,6 +67,154 @@ func GenerateEnvHash(machineName string, osName string, busCount int) string {
 	return hex.EncodeToString(hash[:])
 }
 
+// MeasureBoot builds the event log for this boot: the running binary, the
+// loaded config and policy files, module and plugin artifacts, and finally
+// the attested environment.
+func MeasureBoot(a BootArtifacts, env interface{}) (*EventLog, error) {
+	log := NewEventLog()
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if err := log.MeasureFile(EventBinary, exePath); err != nil {
+		return nil, fmt.Errorf("binary measurement failed: %w", err)
+	}
+
+	// Policies live under the config dir but are measured as their own class.
+	if err := measureTree(log, EventConfig, a.ConfigDir, append([]string{a.PolicyDir}, a.Skip...), isConfigFile); err != nil {
+		return nil, err
+	}
+	if err := measureTree(log, EventPolicy, a.PolicyDir, a.Skip, isConfigFile); err != nil {
+		return nil, err
+	}
+	for _, dir := range a.ModuleDirs {
+		if err := measureTree(log, EventModule, dir, a.Skip, isModuleArtifact); err != nil {
+			return nil, err
+		}
+	}
+	for _, dir := range a.PluginDirs {
+		if err := measureTree(log, EventPlugin, dir, a.Skip, isModuleArtifact); err != nil {
+			return nil, err
+		}
+	}
+
+	if env != nil {
+		if err := log.MeasureJSON(EventEnvConfig, "env_config", env); err != nil {
+			return nil, fmt.Errorf("env measurement failed: %w", err)
+		}
+	}
+
+	return log, nil
+}
+
+// measureTree measures every matching file below dir in lexical order,
+// leaving out the directories in skip.
+func measureTree(log *EventLog, t EventType, dir string, skip []string, match func(string) bool) error {
+	if dir == "" {
+		return nil
+	}
+
+	skipped := make(map[string]bool, len(skip))
+	for _, s := range skip {
+		if s != "" {
+			skipped[filepath.Clean(s)] = true
+		}
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if skipped[filepath.Clean(path)] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !match(path) {
+			return nil
+		}
+		return log.MeasureFile(t, path)
+	})
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func isConfigFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml", ".toml", ".sig":
+		return true
+	}
+	return false
+}
+
+func isModuleArtifact(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wasm", ".so", ".json":
+		return true
+	}
+	return false
+}
+
+// SaveEventLog persists log under its boot ID and as the latest log, and
+// drops per-boot logs beyond the KeepEventLogs most recent.
+func SaveEventLog(store LogStore, log *EventLog) error {
+	if err := store.Write(measurementCollection, log.BootID, log); err != nil {
+		return err
+	}
+	if err := store.Write(measurementCollection, latestMeasurementKey, log); err != nil {
+		return err
+	}
+	return pruneEventLogs(store, KeepEventLogs)
+}
+
+// pruneEventLogs deletes all but the keep newest per-boot logs. Boot IDs
+// start with their UTC start time, so lexical order is boot order.
+func pruneEventLogs(store LogStore, keep int) error {
+	keys, err := store.List(measurementCollection)
+	if err != nil {
+		return err
+	}
+
+	var boots []string
+	for _, k := range keys {
+		if k != latestMeasurementKey {
+			boots = append(boots, k)
+		}
+	}
+	sort.Strings(boots)
+
+	for len(boots) > keep {
+		if err := store.Delete(measurementCollection, boots[0]); err != nil {
+			return err
+		}
+		boots = boots[1:]
+	}
+	return nil
+}
+
+// LoadEventLog loads the log of bootID, or the latest one if bootID is empty.
+func LoadEventLog(store LogStore, bootID string) (*EventLog, error) {
+	if bootID == "" {
+		bootID = latestMeasurementKey
+	}
+
+	var log EventLog
+	found, err := store.Read(measurementCollection, bootID, &log)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no measurement log for boot %s", bootID)
+	}
+
+	return &log, nil
+}
+
 // VerifyBinaryIntegrity implements the measured bootstrap check
 func VerifyBinaryIntegrity() (bool, error) {
 	exePath, err := os.Executable()
@@ -35,13 +234,13 @@ func VerifyBinaryIntegrity() (bool, error) {
 	return true, nil
 }
 
-func VerifyBoot() {
-	// Combined verification of the environment and binary
-	hash := calculateHash()
-	fmt.Printf("[verification] Measured Boot Sequence Complete. Target: %s\n", hash)
-}
+// VerifyBoot replays the boot's event log and reports its final digest.
+func VerifyBoot(log *EventLog) error {
+	if err := log.Verify(); err != nil {
+		return err
+	}
 
-func calculateHash() string {
-	// Placeholder for runtime binary hashing
-	return "sha256:7f83b1657ff1...[golden]"
+	fmt.Printf("[verification] Measured Boot Sequence Complete. Boot: %s Digest: sha256:%s (%d events)\n",
+		log.BootID, log.Final, len(log.Events))
+	return nil
 }
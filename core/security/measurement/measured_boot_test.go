@@ -0,0 +1,209 @@
+//core/security/measurement/measured_boot_test.go
+
+package verification_measurement
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+type keySigner ed25519.PrivateKey
+
+func (k keySigner) Sign(msg []byte) []byte { return ed25519.Sign(ed25519.PrivateKey(k), msg) }
+
+func newKey(t *testing.T) (ed25519.PublicKey, keySigner) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, keySigner(priv)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// installedLayout builds the installed-mode tree, where the config dir is
+// the data root and the vault and runtime state live below it.
+func installedLayout(t *testing.T) (string, BootArtifacts) {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "settings.json"), `{"mode":"auto"}`)
+	writeFile(t, filepath.Join(root, "policies", "base.json"), `{"name":"base"}`)
+	writeFile(t, filepath.Join(root, "vault", "sessions", "s1.json"), `{"n":1}`)
+	writeFile(t, filepath.Join(root, "runtime", "state.json"), `{"n":1}`)
+
+	return root, BootArtifacts{
+		ConfigDir: root,
+		PolicyDir: filepath.Join(root, "policies"),
+		Skip:      []string{filepath.Join(root, "vault"), filepath.Join(root, "runtime")},
+	}
+}
+
+func TestMeasureBootCoversConfigNotState(t *testing.T) {
+	root, a := installedLayout(t)
+
+	first, err := MeasureBoot(a, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, ev := range first.Events {
+		names = append(names, string(ev.Type)+" "+ev.Name)
+		if strings.Contains(ev.Name, string(filepath.Separator)+"vault"+string(filepath.Separator)) ||
+			strings.Contains(ev.Name, string(filepath.Separator)+"runtime"+string(filepath.Separator)) {
+			t.Errorf("state measured: %s", ev.Name)
+		}
+	}
+	want := []string{
+		"config " + filepath.Join(root, "settings.json"),
+		"policy " + filepath.Join(root, "policies", "base.json"),
+	}
+	if got := strings.Join(names[1:], "\n"); got != strings.Join(want, "\n") {
+		t.Fatalf("measured:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+
+	// Session, lockout and measurement records change every boot; the
+	// digest must not.
+	writeFile(t, filepath.Join(root, "vault", "sessions", "s2.json"), `{"n":2}`)
+	writeFile(t, filepath.Join(root, "runtime", "state.json"), `{"n":2}`)
+
+	second, err := MeasureBoot(a, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Final != first.Final {
+		t.Error("boot digest changed with vault and runtime state")
+	}
+
+	writeFile(t, filepath.Join(root, "settings.json"), `{"mode":"manual"}`)
+	third, err := MeasureBoot(a, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Final == first.Final {
+		t.Error("boot digest unchanged after a config change")
+	}
+}
+
+func TestReplayDetectsAlteredAndReorderedEvents(t *testing.T) {
+	log := NewEventLog()
+	log.MeasureBytes(EventConfig, "a.json", []byte("a"))
+	log.MeasureBytes(EventPolicy, "b.json", []byte("b"))
+	log.MeasureBytes(EventModule, "c.wasm", []byte("c"))
+	if err := log.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	altered := append([]MeasurementEvent(nil), log.Events...)
+	altered[1].Digest = strings.Repeat("0", 64)
+	if _, err := Replay(altered); err == nil {
+		t.Error("altered digest replayed")
+	}
+
+	relabelled := append([]MeasurementEvent(nil), log.Events...)
+	relabelled[1].Type = EventConfig
+	if _, err := Replay(relabelled); err == nil {
+		t.Error("relabelled event replayed")
+	}
+
+	reordered := append([]MeasurementEvent(nil), log.Events...)
+	reordered[0], reordered[1] = reordered[1], reordered[0]
+	if _, err := Replay(reordered); err == nil {
+		t.Error("reordered events replayed")
+	}
+
+	truncated := &EventLog{BootID: log.BootID, StartedAt: log.StartedAt, Events: log.Events[:2], Final: log.Final}
+	if err := truncated.Verify(); err == nil {
+		t.Error("truncated log verified against its recorded final digest")
+	}
+}
+
+func TestSignedLogVerifiesOnlyWithTheDeviceKey(t *testing.T) {
+	pub, signer := newKey(t)
+	other, _ := newKey(t)
+
+	log := NewEventLog()
+	log.MeasureBytes(EventConfig, "a.json", []byte("a"))
+
+	if err := log.VerifySignature(pub); !errors.Is(err, ErrLogUnsigned) {
+		t.Fatalf("unsigned log: %v, want %v", err, ErrLogUnsigned)
+	}
+
+	log.Sign(signer)
+	if err := log.VerifySignature(pub); err != nil {
+		t.Fatalf("signed log: %v", err)
+	}
+	if err := log.VerifySignature(other); !errors.Is(err, ErrLogSignature) {
+		t.Errorf("another device's key: %v, want %v", err, ErrLogSignature)
+	}
+
+	// A log rebuilt consistently from different events keeps replaying
+	// but no longer carries a valid signature.
+	forged := NewEventLog()
+	forged.BootID, forged.StartedAt = log.BootID, log.StartedAt
+	forged.MeasureBytes(EventConfig, "a.json", []byte("tampered"))
+	forged.Signature = log.Signature
+	if err := forged.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if err := forged.VerifySignature(pub); !errors.Is(err, ErrLogSignature) {
+		t.Errorf("forged log: %v, want %v", err, ErrLogSignature)
+	}
+}
+
+func TestSaveEventLogKeepsRecentBoots(t *testing.T) {
+	v := &verification_persistence.IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{1}, 32)}
+	pub, signer := newKey(t)
+
+	var last *EventLog
+	for i := 0; i < KeepEventLogs+3; i++ {
+		log := NewEventLog()
+		log.BootID = fmt.Sprintf("20260101T0000%02dZ-boot", i)
+		log.MeasureBytes(EventConfig, "a.json", []byte{byte(i)})
+		log.Sign(signer)
+		if err := SaveEventLog(v, log); err != nil {
+			t.Fatal(err)
+		}
+		last = log
+	}
+
+	keys, err := v.List(measurementCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != KeepEventLogs+1 {
+		t.Fatalf("%d records kept, want %d boots and latest", len(keys), KeepEventLogs)
+	}
+	if _, err := LoadEventLog(v, "20260101T000000Z-boot"); err == nil {
+		t.Error("oldest boot log not pruned")
+	}
+
+	latest, err := LoadEventLog(v, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.BootID != last.BootID {
+		t.Fatalf("latest is %s, want %s", latest.BootID, last.BootID)
+	}
+	if err := latest.VerifySignature(pub); err != nil {
+		t.Fatalf("stored log: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+//core/security/pairing/paired_devices.go
+
+package security_pairing
+
+import (
+	security_authenticator "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/authenticator"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+// Paired phones are companion_app tokens of the authenticator enrollment
+// store, so the login flow verifies them like any other token.
+
+// Paired lists the phones paired to userID.
+func Paired(v verification_persistence.VaultStore, userID string) ([]security_authenticator.TokenInfo, error) {
+	tokens, err := security_authenticator.Tokens(v, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []security_authenticator.TokenInfo
+	for _, t := range tokens {
+		if t.Kind == security_authenticator.KindCompanionApp {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// Unpair removes one phone.
+func Unpair(v verification_persistence.VaultStore, userID, tokenID string) error {
+	return security_authenticator.Revoke(v, userID, tokenID)
+}
+
+// UnpairAll removes every phone paired to userID and returns how many
+// there were.
+func UnpairAll(v verification_persistence.VaultStore, userID string) (int, error) {
+	phones, err := Paired(v, userID)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range phones {
+		if err := Unpair(v, userID, p.TokenID); err != nil {
+			return 0, err
+		}
+	}
+	return len(phones), nil
+}
@@ -0,0 +1,214 @@
+//core/security/pairing/pairing_client.go
+
+package security_pairing
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	security_authenticator "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/authenticator"
+)
+
+// Client is the reference companion app. It pairs with a device and then
+// answers login challenges without user interaction. Real apps implement
+// the same frames; this one exists for tests and bench setups.
+type Client struct {
+	Name    string `json:"name"`
+	TokenID string `json:"token_id"`
+	Seed    []byte `json:"seed"`
+
+	// Device is the machine ID of the paired device. Challenges for any
+	// other device are refused so a relay cannot borrow the phone.
+	Device string `json:"device,omitempty"`
+
+	// Counter is the last response counter sent. Answers count from the
+	// clock, so a client restarted from older state still moves forward.
+	Counter uint64 `json:"counter,omitempty"`
+}
+
+// NewClient creates a companion with a fresh signing key.
+func NewClient(name string) (*Client, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		Name:    name,
+		TokenID: "companion-" + hex.EncodeToString(pub[:6]),
+		Seed:    priv.Seed(),
+	}, nil
+}
+
+// LoadClient reads a client saved with Save.
+func LoadClient(path string) (*Client, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Client
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if len(c.Seed) != ed25519.SeedSize {
+		return nil, errors.New("companion state has no key")
+	}
+	return &c, nil
+}
+
+// Save writes the client state, including its private key, to path.
+func (c *Client) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func (c *Client) key() ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(c.Seed)
+}
+
+// Pair runs the phone side of pairing. confirm shows the SAS to the user
+// and reports whether it matches the device screen.
+func (c *Client) Pair(ctx context.Context, endpoint, code string, confirm func(sas string) bool) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(handshakeTimeout))
+
+	if err := security_authenticator.WriteFrame(conn, hello{Type: helloPair, Name: c.Name}); err != nil {
+		return err
+	}
+
+	var raw json.RawMessage
+	if err := security_authenticator.ReadFrame(conn, &raw); err != nil {
+		return err
+	}
+
+	// A device without an open offer answers with a result instead of
+	// its commitment
+	var dc deviceCommit
+	if err := json.Unmarshal(raw, &dc); err != nil || len(dc.Commit) == 0 {
+		var res result
+		_ = json.Unmarshal(raw, &res)
+		return fmt.Errorf("pairing refused: %s", res.Error)
+	}
+
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	phonePub := priv.PublicKey().Bytes()
+	if err := security_authenticator.WriteFrame(conn, phoneKey{Pub: phonePub}); err != nil {
+		return err
+	}
+
+	var dh deviceHello
+	if err := security_authenticator.ReadFrame(conn, &dh); err != nil {
+		return err
+	}
+	if dh.Device != dc.Device || subtle.ConstantTimeCompare(commitment(dh.Pub, dh.Device), dc.Commit) != 1 {
+		return errors.New("pairing aborted: device key does not match its commitment")
+	}
+
+	shared, err := exchange(priv, dh.Pub)
+	if err != nil {
+		return err
+	}
+	sess, err := derive(shared, code, phonePub, dh.Pub, dh.Device)
+	if err != nil {
+		return err
+	}
+	if !confirm(sess.sas) {
+		return ErrSASRejected
+	}
+
+	key := c.key()
+	plaintext, err := json.Marshal(enrollment{
+		TokenID:   c.TokenID,
+		PublicKey: key.Public().(ed25519.PublicKey),
+		Signature: ed25519.Sign(key, sess.transcript),
+	})
+	if err != nil {
+		return err
+	}
+	sealed, err := sess.seal(plaintext)
+	if err != nil {
+		return err
+	}
+	if err := security_authenticator.WriteFrame(conn, sealed); err != nil {
+		return err
+	}
+
+	var res result
+	if err := security_authenticator.ReadFrame(conn, &res); err != nil {
+		return err
+	}
+	if !res.OK {
+		return fmt.Errorf("pairing failed: %s", res.Error)
+	}
+
+	c.Device = dh.Device
+	return nil
+}
+
+// ServeLogins keeps a connection open to the device and answers login
+// challenges until ctx ends, reconnecting as needed.
+func (c *Client) ServeLogins(ctx context.Context, endpoint string) error {
+	if c.Device == "" {
+		return errors.New("companion is not paired")
+	}
+
+	for ctx.Err() == nil {
+		if err := c.answerOnce(ctx, endpoint); err != nil {
+			select {
+			case <-time.After(2 * time.Second):
+			case <-ctx.Done():
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+func (c *Client) answerOnce(ctx context.Context, endpoint string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Unblock the read below when ctx ends
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := security_authenticator.WriteFrame(conn, hello{Type: helloLogin, TokenID: c.TokenID}); err != nil {
+		return err
+	}
+
+	var ch security_authenticator.Challenge
+	if err := security_authenticator.ReadFrame(conn, &ch); err != nil {
+		return err
+	}
+
+	resp := security_authenticator.Response{TokenID: c.TokenID}
+	if ch.Kind == security_authenticator.KindCompanionApp && ch.Context == c.Device {
+		c.Counter = max(c.Counter+1, uint64(time.Now().UnixNano()))
+		resp.Counter = c.Counter
+		resp.Signature = ed25519.Sign(c.key(), ch.SignedMessage(c.TokenID, resp.Counter))
+	}
+	return security_authenticator.WriteFrame(conn, resp)
+}
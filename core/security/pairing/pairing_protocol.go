@@ -0,0 +1,219 @@
+//core/security/pairing/pairing_protocol.go
+
+package security_pairing
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Pairing runs over the same length-prefixed JSON frames as the token
+// protocol of core/security/authenticator:
+//
+//	phone  -> device  hello{type: pair, name}
+//	device -> phone   deviceCommit{commit: H(device pub), device}
+//	phone  -> device  phoneKey{pub}
+//	device -> phone   deviceHello{pub, device}, opening the commitment
+//	        both show the short authentication string (SAS)
+//	phone  -> device  sealedFrame(enrollment) once its user confirmed
+//	device -> phone   result, after the device user confirmed
+//
+// The device commits to its key before it sees the phone's and the phone
+// sends its key before it sees the device's, so a man in the middle must
+// fix both of its keys without knowing the other side's and cannot grind
+// the SAS. The one-time code never crosses the network: it is mixed into
+// the channel key, so only a phone that knows it can seal an enrollment
+// the device opens.
+const protocolName = "aios-pair-v1"
+
+const (
+	// OfferLifetime bounds how long a displayed code stays usable.
+	OfferLifetime = 2 * time.Minute
+
+	codeDigits = 6
+	sasDigits  = 6
+
+	helloPair  = "pair"
+	helloLogin = "login"
+)
+
+var (
+	ErrOfferInvalid  = errors.New("pairing_code_invalid")
+	ErrSASRejected   = errors.New("pairing_sas_rejected")
+	ErrBadEnrollment = errors.New("pairing_enrollment_invalid")
+)
+
+type hello struct {
+	Type    string `json:"type"`
+	Name    string `json:"name,omitempty"`
+	TokenID string `json:"token_id,omitempty"`
+}
+
+type deviceCommit struct {
+	Commit []byte `json:"commit"`
+	Device string `json:"device"`
+}
+
+type phoneKey struct {
+	Pub []byte `json:"pub"`
+}
+
+type deviceHello struct {
+	Pub    []byte `json:"pub"`
+	Device string `json:"device"`
+}
+
+type sealedFrame struct {
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// enrollment carries the phone's long-term signing key and proves
+// possession by signing the transcript.
+type enrollment struct {
+	TokenID   string `json:"token_id"`
+	PublicKey []byte `json:"public_key"`
+	Signature []byte `json:"signature"`
+}
+
+type result struct {
+	OK      bool   `json:"ok"`
+	TokenID string `json:"token_id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Offer is what the device displays to start pairing, as a code to type
+// or as the link URI.
+type Offer struct {
+	UserID    string    `json:"user_id"`
+	Code      string    `json:"code"`
+	Endpoint  string    `json:"endpoint"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// URI is the pairing link: aios-pair://<endpoint>?code=<code>
+func (o *Offer) URI() string {
+	u := url.URL{Scheme: "aios-pair", Host: o.Endpoint, RawQuery: url.Values{"code": {o.Code}}.Encode()}
+	return u.String()
+}
+
+// ParseOfferURI extracts the endpoint and code from a pairing link.
+func ParseOfferURI(s string) (endpoint, code string, err error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme != "aios-pair" || u.Host == "" {
+		return "", "", fmt.Errorf("not a pairing URI: %s", s)
+	}
+	code = u.Query().Get("code")
+	if code == "" {
+		return "", "", errors.New("pairing URI has no code")
+	}
+	return u.Host, code, nil
+}
+
+func newCode() (string, error) {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", codeDigits, binary.BigEndian.Uint32(b[:])%1_000_000), nil
+}
+
+// session is the key agreement state shared by both ends.
+type session struct {
+	transcript []byte
+	key        []byte
+	sas        string
+}
+
+// commitment binds the device to its ephemeral key before the phone sends
+// its own.
+func commitment(devicePub []byte, device string) []byte {
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(protocolName + " commit"), devicePub, []byte(device)} {
+		h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(part))))
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+// exchange runs X25519 against the peer's ephemeral key.
+func exchange(priv *ecdh.PrivateKey, peer []byte) ([]byte, error) {
+	pub, err := ecdh.X25519().NewPublicKey(peer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid peer key: %w", err)
+	}
+	return priv.ECDH(pub)
+}
+
+// derive computes the channel key and the SAS from the shared secret. The
+// transcript binds code, both ephemeral keys and the device identity.
+func derive(shared []byte, code string, phonePub, devicePub []byte, device string) (*session, error) {
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(protocolName), []byte(code), phonePub, devicePub, []byte(device)} {
+		h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(part))))
+		h.Write(part)
+	}
+	transcript := h.Sum(nil)
+
+	key, err := hkdf.Key(sha256.New, shared, transcript, protocolName+" key", 32)
+	if err != nil {
+		return nil, err
+	}
+	sasBytes, err := hkdf.Key(sha256.New, shared, transcript, protocolName+" sas", 4)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session{
+		transcript: transcript,
+		key:        key,
+		sas:        fmt.Sprintf("%0*d", sasDigits, binary.BigEndian.Uint32(sasBytes)%1_000_000),
+	}, nil
+}
+
+func (s *session) seal(plaintext []byte) (*sealedFrame, error) {
+	gcm, err := s.aead()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &sealedFrame{Nonce: nonce, Ciphertext: gcm.Seal(nil, nonce, plaintext, s.transcript)}, nil
+}
+
+func (s *session) open(f *sealedFrame) ([]byte, error) {
+	gcm, err := s.aead()
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Nonce) != gcm.NonceSize() {
+		return nil, ErrBadEnrollment
+	}
+	plaintext, err := gcm.Open(nil, f.Nonce, f.Ciphertext, s.transcript)
+	if err != nil {
+		return nil, ErrBadEnrollment
+	}
+	return plaintext, nil
+}
+
+func (s *session) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,426 @@
+//core/security/pairing/pairing_server.go
+
+package security_pairing
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	security_authenticator "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/authenticator"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+// Auditor is the subset of the audit log the server writes to.
+type Auditor interface {
+	Append(ev security_audit.Event) (*security_audit.Entry, error)
+}
+
+// ConfirmFunc asks the user at the device whether the phone shows the
+// same SAS. It blocks until the user answers.
+type ConfirmFunc func(userID, phoneName, sas string) bool
+
+const (
+	// handshakeTimeout bounds one pairing exchange, including both users
+	// comparing the SAS.
+	handshakeTimeout = 90 * time.Second
+
+	// helloTimeout bounds how long a connection may take to say what it
+	// wants.
+	helloTimeout = 10 * time.Second
+
+	// maxConnections bounds the connections served at once; more are
+	// closed on accept. maxWaitingLogins bounds the phones held for a
+	// login, each for at most ChallengeMaxAge.
+	maxConnections   = 16
+	maxWaitingLogins = 4
+
+	// maxCodeFailures is how many pairings may fail to prove the code
+	// before every open offer is withdrawn.
+	maxCodeFailures = 3
+)
+
+// Server is the local endpoint companion apps connect to, both to pair and,
+// once paired, to answer login challenges silently.
+type Server struct {
+	vault   verification_persistence.VaultStore
+	device  string
+	audit   Auditor
+	confirm ConfirmFunc
+
+	mu       sync.Mutex
+	offers   map[string]*Offer
+	failures int
+	waiting  int
+	ln       net.Listener
+
+	// slots bounds the connections served at once
+	slots chan struct{}
+
+	// logins hands connected phones to a waiting companion authenticator
+	logins chan net.Conn
+
+	// Completed, if set, is called after every pairing attempt that
+	// presented a valid code.
+	Completed func(userID, tokenID string, err error)
+}
+
+// NewServer creates a server able to pair phones for users in v. device is
+// the machine ID challenges and pairings are bound to. A server built with
+// a nil vault only relays login challenges.
+func NewServer(v verification_persistence.VaultStore, device string, auditor Auditor, confirm ConfirmFunc) *Server {
+	return &Server{
+		vault:   v,
+		device:  device,
+		audit:   auditor,
+		confirm: confirm,
+		offers:  map[string]*Offer{},
+		slots:   make(chan struct{}, maxConnections),
+		logins:  make(chan net.Conn),
+	}
+}
+
+// Offer creates a one-time code for pairing a phone to userID. endpoint is
+// the address the phone should connect to, as shown to the user.
+func (s *Server) Offer(userID, endpoint string) (*Offer, error) {
+	if s.vault == nil {
+		return nil, errors.New("pairing server has no vault")
+	}
+	exists, err := s.vault.Exists("users", userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("unknown user %s", userID)
+	}
+
+	code, err := newCode()
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Offer{UserID: userID, Code: code, Endpoint: endpoint, ExpiresAt: time.Now().Add(OfferLifetime)}
+
+	s.mu.Lock()
+	s.offers[code] = o
+	s.mu.Unlock()
+	return o, nil
+}
+
+// hasOffers reports whether any offer is still valid, dropping expired
+// ones.
+func (s *Server) hasOffers() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for c, o := range s.offers {
+		if now.After(o.ExpiresAt) {
+			delete(s.offers, c)
+		}
+	}
+	return len(s.offers) > 0
+}
+
+// take finds the offer whose code opens sealed and consumes it, so a code
+// works exactly once. Every failure counts against the open offers; after
+// maxCodeFailures they are all withdrawn, so the 6-digit code cannot be
+// guessed online.
+func (s *Server) take(shared, phonePub, devicePub []byte, sealed *sealedFrame) (*Offer, *session, []byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for c, o := range s.offers {
+		if now.After(o.ExpiresAt) {
+			delete(s.offers, c)
+			continue
+		}
+		sess, err := derive(shared, o.Code, phonePub, devicePub, s.device)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if plaintext, err := sess.open(sealed); err == nil {
+			delete(s.offers, c)
+			return o, sess, plaintext, nil
+		}
+	}
+
+	s.failures++
+	if s.failures >= maxCodeFailures {
+		s.offers = map[string]*Offer{}
+		s.failures = 0
+	}
+	return nil, nil, nil, ErrOfferInvalid
+}
+
+// Listen starts serving on addr and registers the server for the
+// "companion" transport at that address.
+func (s *Server) Listen(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.ln = ln
+	s.mu.Unlock()
+	attach(addr, s)
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			select {
+			case s.slots <- struct{}{}:
+				go func() {
+					defer func() { <-s.slots }()
+					s.handle(conn)
+				}()
+			default:
+				conn.Close()
+			}
+		}
+	}()
+	return nil
+}
+
+// Addr returns the listening address, or nil before Listen.
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ln == nil {
+		return nil
+	}
+	return s.ln.Addr()
+}
+
+// Close stops accepting phones.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ln == nil {
+		return nil
+	}
+	detach(s)
+	return s.ln.Close()
+}
+
+func (s *Server) handle(conn net.Conn) {
+	_ = conn.SetDeadline(time.Now().Add(helloTimeout))
+
+	var h hello
+	if err := security_authenticator.ReadFrame(conn, &h); err != nil {
+		conn.Close()
+		return
+	}
+
+	switch h.Type {
+	case helloLogin:
+		s.hold(conn)
+
+	case helloPair:
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(handshakeTimeout))
+		tokenID, userID, err := s.pair(conn, &h)
+
+		res := result{OK: err == nil, TokenID: tokenID}
+		if err != nil {
+			res.Error = err.Error()
+		}
+		_ = security_authenticator.WriteFrame(conn, res)
+
+		if userID != "" {
+			s.record(userID, h.Name, tokenID, err)
+			if s.Completed != nil {
+				s.Completed(userID, tokenID, err)
+			}
+		}
+
+	default:
+		conn.Close()
+	}
+}
+
+// hold keeps a phone until a login needs it or the challenge window
+// passes; the app reconnects on its own. At most maxWaitingLogins phones
+// are held.
+func (s *Server) hold(conn net.Conn) {
+	s.mu.Lock()
+	if s.waiting >= maxWaitingLogins {
+		s.mu.Unlock()
+		conn.Close()
+		return
+	}
+	s.waiting++
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.waiting--
+		s.mu.Unlock()
+	}()
+
+	_ = conn.SetDeadline(time.Now().Add(security_authenticator.ChallengeMaxAge))
+	select {
+	case s.logins <- conn:
+	case <-time.After(security_authenticator.ChallengeMaxAge):
+		conn.Close()
+	}
+}
+
+// pair runs the device side of the exchange and enrolls the phone key as
+// a companion_app token of the offer's user.
+func (s *Server) pair(conn net.Conn, h *hello) (tokenID, userID string, err error) {
+	if !s.hasOffers() {
+		return "", "", ErrOfferInvalid
+	}
+
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	devicePub := priv.PublicKey().Bytes()
+
+	// Commit to the device key before seeing the phone's
+	if err := security_authenticator.WriteFrame(conn, deviceCommit{Commit: commitment(devicePub, s.device), Device: s.device}); err != nil {
+		return "", "", err
+	}
+	var pk phoneKey
+	if err := security_authenticator.ReadFrame(conn, &pk); err != nil {
+		return "", "", err
+	}
+	shared, err := exchange(priv, pk.Pub)
+	if err != nil {
+		return "", "", err
+	}
+	if err := security_authenticator.WriteFrame(conn, deviceHello{Pub: devicePub, Device: s.device}); err != nil {
+		return "", "", err
+	}
+
+	// The phone seals its key only after its user confirmed the SAS
+	var sealed sealedFrame
+	if err := security_authenticator.ReadFrame(conn, &sealed); err != nil {
+		return "", "", err
+	}
+	offer, sess, plaintext, err := s.take(shared, pk.Pub, devicePub, &sealed)
+	if err != nil {
+		return "", "", err
+	}
+	userID = offer.UserID
+
+	var e enrollment
+	if err := json.Unmarshal(plaintext, &e); err != nil {
+		return "", userID, ErrBadEnrollment
+	}
+	if len(e.PublicKey) != ed25519.PublicKeySize || e.TokenID == "" ||
+		!ed25519.Verify(e.PublicKey, sess.transcript, e.Signature) {
+		return "", userID, ErrBadEnrollment
+	}
+
+	if s.confirm == nil || !s.confirm(userID, h.Name, sess.sas) {
+		return "", userID, ErrSASRejected
+	}
+
+	err = security_authenticator.EnrollEd25519(s.vault, userID, security_authenticator.KindCompanionApp, e.TokenID, h.Name, e.PublicKey)
+	if err != nil {
+		return "", userID, err
+	}
+	return e.TokenID, userID, nil
+}
+
+func (s *Server) record(userID, phone, tokenID string, err error) {
+	if s.audit == nil {
+		return
+	}
+
+	ev := security_audit.Event{
+		Actor:    userID,
+		Action:   "pairing.pair",
+		Resource: userID,
+		Result:   "ok",
+	}
+	if err != nil {
+		ev.Result = "denied"
+	}
+	ev.Detail, _ = json.Marshal(map[string]string{"phone": phone, "token_id": tokenID})
+	_, _ = s.audit.Append(ev)
+}
+
+// ------------------------------------------------------------
+// Companion transport
+// ------------------------------------------------------------
+
+var (
+	serversMu sync.Mutex
+	servers   = map[string]*Server{}
+)
+
+func init() {
+	security_authenticator.RegisterTransport("companion", openCompanion)
+}
+
+func attach(addr string, s *Server) {
+	serversMu.Lock()
+	defer serversMu.Unlock()
+	servers[addr] = s
+}
+
+func detach(s *Server) {
+	serversMu.Lock()
+	defer serversMu.Unlock()
+	for addr, srv := range servers {
+		if srv == s {
+			delete(servers, addr)
+		}
+	}
+}
+
+// openCompanion returns an authenticator fed by the server on the device
+// address, starting a login-only server there on first use.
+func openCompanion(dev security_authenticator.DeviceConfig) (security_authenticator.Authenticator, error) {
+	serversMu.Lock()
+	s, ok := servers[dev.Address]
+	serversMu.Unlock()
+
+	if !ok {
+		s = NewServer(nil, "", nil, nil)
+		if err := s.Listen(dev.Address); err != nil {
+			return nil, fmt.Errorf("%w: %v", security_authenticator.ErrNoDeviceAvailable, err)
+		}
+	}
+	return &companion{server: s}, nil
+}
+
+// companion relays one challenge to the next paired phone that connects.
+// Which phone answers does not matter: Verify only accepts a token
+// enrolled for the user logging in.
+type companion struct {
+	server *Server
+}
+
+func (c *companion) Kind() security_authenticator.Kind {
+	return security_authenticator.KindCompanionApp
+}
+
+func (c *companion) Respond(ctx context.Context, ch *security_authenticator.Challenge) (*security_authenticator.Response, error) {
+	select {
+	case conn := <-c.server.logins:
+		defer conn.Close()
+		return security_authenticator.NewStreamAuthenticator(c.Kind(), conn).Respond(ctx, ch)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no companion app connected: %w", ctx.Err())
+	}
+}
+
+func (c *companion) Close() error { return nil }
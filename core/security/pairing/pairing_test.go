@@ -0,0 +1,182 @@
+//core/security/pairing/pairing_test.go
+
+package security_pairing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	security_authenticator "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/authenticator"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+const testMachine = "machine-1"
+
+// pairingServer listens on loopback with one user, alice, and records the
+// code the device shows.
+func pairingServer(t *testing.T, confirm bool) (*Server, *verification_persistence.IsolatedVault, *string) {
+	t.Helper()
+	v := &verification_persistence.IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{1}, 32)}
+	if err := v.Write("users", "alice", map[string]string{"id": "alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var shown string
+	s := NewServer(v, testMachine, nil, func(userID, phone, sas string) bool {
+		shown = sas
+		return confirm
+	})
+	if err := s.Listen("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s, v, &shown
+}
+
+func pairPhone(t *testing.T, s *Server) *Client {
+	t.Helper()
+	o, err := s.Offer("alice", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient("phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Pair(context.Background(), o.Endpoint, o.Code, func(string) bool { return true }); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestPairingEnrollsThePhoneOnce(t *testing.T) {
+	s, v, shown := pairingServer(t, true)
+	o, err := s.Offer("alice", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewClient("phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accept := func(string) bool { return true }
+
+	if err := c.Pair(context.Background(), o.Endpoint, "000000", accept); err == nil {
+		t.Fatal("paired with a wrong code")
+	}
+
+	var phoneSAS string
+	if err := c.Pair(context.Background(), o.Endpoint, o.Code, func(sas string) bool { phoneSAS = sas; return true }); err != nil {
+		t.Fatal(err)
+	}
+	if phoneSAS == "" || phoneSAS != *shown {
+		t.Fatalf("phone showed %q, device showed %q", phoneSAS, *shown)
+	}
+
+	if err := c.Pair(context.Background(), o.Endpoint, o.Code, accept); err == nil {
+		t.Fatal("pairing code accepted twice")
+	}
+
+	phones, err := Paired(v, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(phones) != 1 || phones[0].TokenID != c.TokenID {
+		t.Fatalf("paired = %+v, want the phone's token %s", phones, c.TokenID)
+	}
+}
+
+func TestPairingRejectedOnTheDevice(t *testing.T) {
+	s, v, _ := pairingServer(t, false)
+	o, err := s.Offer("alice", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient("phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Pair(context.Background(), o.Endpoint, o.Code, func(string) bool { return true }); err == nil {
+		t.Fatal("paired although the device refused the code")
+	}
+	if phones, _ := Paired(v, "alice"); len(phones) != 0 {
+		t.Fatalf("refused pairing enrolled %+v", phones)
+	}
+}
+
+func TestPairedPhoneAnswersLoginsUntilUnpaired(t *testing.T) {
+	s, v, _ := pairingServer(t, true)
+	c := pairPhone(t, s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.ServeLogins(ctx, s.Addr().String())
+
+	login := func() error {
+		lctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return security_authenticator.Run(lctx, v, "alice", testMachine, &companion{server: s})
+	}
+
+	if err := login(); err != nil {
+		t.Fatalf("login with the paired phone: %v", err)
+	}
+
+	if err := Unpair(v, "alice", c.TokenID); err != nil {
+		t.Fatal(err)
+	}
+	if err := login(); err == nil {
+		t.Fatal("login succeeded with an unpaired phone")
+	}
+}
+
+func TestOfferURIRoundTrip(t *testing.T) {
+	o := &Offer{UserID: "alice", Code: "123456", Endpoint: "10.0.0.2:7443"}
+	endpoint, code, err := ParseOfferURI(o.URI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoint != o.Endpoint || code != o.Code {
+		t.Fatalf("parsed %s %s from %s", endpoint, code, o.URI())
+	}
+
+	if _, _, err := ParseOfferURI("https://10.0.0.2/?code=1"); err == nil {
+		t.Error("accepted a link of another scheme")
+	}
+	if _, _, err := ParseOfferURI("aios-pair://10.0.0.2:7443"); err == nil {
+		t.Error("accepted a link without a code")
+	}
+}
+
+func TestServerBoundsConnections(t *testing.T) {
+	s, _, _ := pairingServer(t, true)
+
+	var conns []net.Conn
+	for i := 0; i < maxConnections+4; i++ {
+		if conn, err := net.Dial("tcp", s.Addr().String()); err == nil {
+			conns = append(conns, conn)
+		}
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// Connections over the bound are closed at once; the others wait for
+	// their hello.
+	closed := 0
+	for _, conn := range conns {
+		conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+		var ne net.Error
+		if _, err := conn.Read(make([]byte, 1)); err != nil && !(errors.As(err, &ne) && ne.Timeout()) {
+			closed++
+		}
+		conn.Close()
+	}
+	if closed < 4 {
+		t.Fatalf("%d of %d connections closed, want at least 4", closed, len(conns))
+	}
+}
@@ -0,0 +1,176 @@
+//core/security/password/password_hasher.go
+
+package security_password
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Params are the argon2id cost parameters. They are encoded into every
+// hash, so raising them later only affects new and upgraded hashes.
+type Params struct {
+	Memory  uint32 // KiB
+	Time    uint32
+	Threads uint8
+	SaltLen uint32
+	KeyLen  uint32
+}
+
+// DefaultParams follow the RFC 9106 second recommended option.
+var DefaultParams = Params{
+	Memory:  64 * 1024,
+	Time:    3,
+	Threads: 2,
+	SaltLen: 16,
+	KeyLen:  32,
+}
+
+// Scheme names returned by Identify.
+const (
+	SchemeArgon2id     = "argon2id"
+	SchemeBcrypt       = "bcrypt"
+	SchemeLegacySHA256 = "sha256"
+	SchemeUnknown      = "unknown"
+)
+
+var (
+	ErrMismatch      = errors.New("password_mismatch")
+	ErrUnknownScheme = errors.New("password_hash_unknown_scheme")
+	ErrMalformedHash = errors.New("password_hash_malformed")
+	ErrEmptyPassword = errors.New("password_empty")
+)
+
+// Hash returns an argon2id hash in PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func Hash(password string) (string, error) {
+	return HashWithParams(password, DefaultParams)
+}
+
+func HashWithParams(password string, p Params) (string, error) {
+	if password == "" {
+		return "", ErrEmptyPassword
+	}
+
+	salt := make([]byte, p.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Time, p.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Identify reports which scheme produced encoded.
+func Identify(encoded string) string {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return SchemeArgon2id
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return SchemeBcrypt
+	case len(encoded) == hex.EncodedLen(sha256.Size) && isHex(encoded):
+		return SchemeLegacySHA256
+	default:
+		return SchemeUnknown
+	}
+}
+
+// Verify checks password against encoded. needsRehash is true when the
+// password matched but the stored hash is a legacy scheme or uses weaker
+// parameters than DefaultParams; the caller should then store Hash(password).
+func Verify(password, encoded string) (needsRehash bool, err error) {
+	switch Identify(encoded) {
+	case SchemeArgon2id:
+		p, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return false, err
+		}
+
+		got := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(got, key) != 1 {
+			return false, ErrMismatch
+		}
+
+		weaker := p.Memory < DefaultParams.Memory || p.Time < DefaultParams.Time ||
+			p.Threads < DefaultParams.Threads || uint32(len(key)) < DefaultParams.KeyLen
+		return weaker, nil
+
+	case SchemeBcrypt:
+		if err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return false, ErrMismatch
+			}
+			return false, err
+		}
+		return true, nil
+
+	case SchemeLegacySHA256:
+		sum := sha256.Sum256([]byte(password))
+		want, _ := hex.DecodeString(encoded)
+		if subtle.ConstantTimeCompare(sum[:], want) != 1 {
+			return false, ErrMismatch
+		}
+		return true, nil
+
+	default:
+		return false, ErrUnknownScheme
+	}
+}
+
+func decodeArgon2id(encoded string) (Params, []byte, []byte, error) {
+	var p Params
+
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrMalformedHash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil {
+		return p, nil, nil, ErrMalformedHash
+	}
+	if p.Memory == 0 || p.Time == 0 || p.Threads == 0 {
+		return p, nil, nil, ErrMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrMalformedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, ErrMalformedHash
+	}
+
+	p.SaltLen = uint32(len(salt))
+	p.KeyLen = uint32(len(key))
+	return p, salt, key, nil
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+//core/security/password/password_hasher_test.go
+
+package security_password
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashRoundTrip(t *testing.T) {
+	encoded, err := Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Identify(encoded) != SchemeArgon2id || !strings.HasPrefix(encoded, "$argon2id$v=19$m=65536,t=3,p=2$") {
+		t.Fatalf("encoded = %s", encoded)
+	}
+
+	rehash, err := Verify("correct horse", encoded)
+	if err != nil || rehash {
+		t.Fatalf("Verify = %v, %v; want match without rehash", rehash, err)
+	}
+
+	again, _ := Hash("correct horse")
+	if again == encoded {
+		t.Error("two hashes of one password share a salt")
+	}
+}
+
+func TestVerifyWrongPassword(t *testing.T) {
+	encoded, err := Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify("battery staple", encoded); !errors.Is(err, ErrMismatch) {
+		t.Fatalf("wrong password = %v, want %v", err, ErrMismatch)
+	}
+	if _, err := Hash(""); !errors.Is(err, ErrEmptyPassword) {
+		t.Errorf("empty password = %v, want %v", err, ErrEmptyPassword)
+	}
+}
+
+func TestVerifyMalformedHash(t *testing.T) {
+	valid, err := Hash("pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(valid, "$")
+
+	for name, encoded := range map[string]string{
+		"missing key":    strings.Join(parts[:5], "$"),
+		"wrong version":  strings.Replace(valid, "v=19", "v=16", 1),
+		"zero memory":    strings.Replace(valid, "m=65536", "m=0", 1),
+		"bad parameters": strings.Replace(valid, "m=65536,t=3,p=2", "m=x", 1),
+		"bad salt":       strings.Join([]string{"", parts[1], parts[2], parts[3], "!!", parts[5]}, "$"),
+		"empty key":      strings.Join([]string{"", parts[1], parts[2], parts[3], parts[4], ""}, "$"),
+	} {
+		if _, err := Verify("pw", encoded); !errors.Is(err, ErrMalformedHash) {
+			t.Errorf("%s: %v, want %v", name, err, ErrMalformedHash)
+		}
+	}
+
+	if _, err := Verify("pw", "plaintext"); !errors.Is(err, ErrUnknownScheme) {
+		t.Errorf("unknown scheme = %v, want %v", err, ErrUnknownScheme)
+	}
+}
+
+func TestVerifyAsksToRehashLegacyHashes(t *testing.T) {
+	sum := sha256.Sum256([]byte("pw"))
+	legacy := hex.EncodeToString(sum[:])
+	if Identify(legacy) != SchemeLegacySHA256 {
+		t.Fatalf("Identify(%s) = %s", legacy, Identify(legacy))
+	}
+	if rehash, err := Verify("pw", legacy); err != nil || !rehash {
+		t.Errorf("legacy sha256 = %v, %v; want match with rehash", rehash, err)
+	}
+	if _, err := Verify("other", legacy); !errors.Is(err, ErrMismatch) {
+		t.Errorf("legacy sha256 mismatch = %v", err)
+	}
+
+	b, err := bcrypt.GenerateFromPassword([]byte("pw"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rehash, err := Verify("pw", string(b)); err != nil || !rehash {
+		t.Errorf("bcrypt = %v, %v; want match with rehash", rehash, err)
+	}
+	if _, err := Verify("other", string(b)); !errors.Is(err, ErrMismatch) {
+		t.Errorf("bcrypt mismatch = %v", err)
+	}
+}
+
+func TestVerifyAsksToRehashWeakerParameters(t *testing.T) {
+	weaker := DefaultParams
+	weaker.Memory, weaker.Time = 8*1024, 1
+
+	encoded, err := HashWithParams("pw", weaker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rehash, err := Verify("pw", encoded); err != nil || !rehash {
+		t.Fatalf("weaker parameters = %v, %v; want match with rehash", rehash, err)
+	}
+
+	shortKey := weaker
+	shortKey.Memory, shortKey.Time, shortKey.KeyLen = DefaultParams.Memory, DefaultParams.Time, 16
+	encoded, err = HashWithParams("pw", shortKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rehash, err := Verify("pw", encoded); err != nil || !rehash {
+		t.Errorf("shorter key = %v, %v; want match with rehash", rehash, err)
+	}
+}
@@ -0,0 +1,288 @@
+//core/security/persistence/bolt_store.go
+
+package verification_persistence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	internal_boot "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/boot"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/pkg/logging"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bucket names used by BoltVault for the records that have dedicated
+// accessors on VaultStore. Generic Read/Write collections get a bucket
+// named after the collection itself.
+const (
+	bucketEnvConfig = "env_config"
+	bucketGolden    = "golden"
+	bucketMarkers   = "markers"
+)
+
+// BoltVaultFile is the single-file database created inside the vault directory.
+const BoltVaultFile = "vault.db"
+
+// BoltVault is a VaultStore backed by an embedded bbolt database.
+// Every record lives in one file and multi-record updates can be grouped
+// into a single transaction with Update.
+type BoltVault struct {
+	Path string
+	Key  []byte
+
+	db *bolt.DB
+}
+
+func OpenBoltVault(path string, key []byte) (*BoltVault, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("vault init failed: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 2 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("vault open failed: %w", err)
+	}
+
+	logging.Info("[VAULT] bolt store opened at %s", path)
+
+	return &BoltVault{
+		Path: path,
+		Key:  key,
+		db:   db,
+	}, nil
+}
+
+func (v *BoltVault) Close() error {
+	return v.db.Close()
+}
+
+// ------------------------------------------------------------
+// Transactions
+// ------------------------------------------------------------
+
+// Update runs fn inside a read-write transaction. Either every write made
+// through tx is committed or none is.
+func (v *BoltVault) Update(fn func(tx VaultTx) error) error {
+	return v.db.Update(func(btx *bolt.Tx) error {
+		return fn(&boltTx{tx: btx, key: v.Key})
+	})
+}
+
+// View runs fn against a consistent read-only snapshot of the vault.
+func (v *BoltVault) View(fn func(tx VaultTx) error) error {
+	return v.db.View(func(btx *bolt.Tx) error {
+		return fn(&boltTx{tx: btx, key: v.Key})
+	})
+}
+
+type boltTx struct {
+	tx  *bolt.Tx
+	key []byte
+}
+
+func (t *boltTx) Read(collection, key string, out interface{}) (bool, error) {
+	return boltGet(t.tx, collection, key, out)
+}
+
+func (t *boltTx) Write(collection, key string, value interface{}) error {
+	return boltPut(t.tx, collection, key, value)
+}
+
+func (t *boltTx) Exists(collection, key string) (bool, error) {
+	b := t.tx.Bucket([]byte(collection))
+	if b == nil {
+		return false, nil
+	}
+	return b.Get([]byte(key)) != nil, nil
+}
+
+func (t *boltTx) List(collection string) ([]string, error) {
+	b := t.tx.Bucket([]byte(collection))
+	if b == nil {
+		return nil, nil
+	}
+
+	var keys []string
+	err := b.ForEach(func(k, _ []byte) error {
+		keys = append(keys, string(k))
+		return nil
+	})
+	return keys, err
+}
+
+func (t *boltTx) Delete(collection, key string) error {
+	b := t.tx.Bucket([]byte(collection))
+	if b == nil {
+		return nil
+	}
+	return b.Delete([]byte(key))
+}
+
+func boltGet(tx *bolt.Tx, bucket, key string, out interface{}) (bool, error) {
+	b := tx.Bucket([]byte(bucket))
+	if b == nil {
+		return false, nil
+	}
+
+	data := b.Get([]byte(key))
+	if data == nil {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func boltPut(tx *bolt.Tx, bucket, key string, value interface{}) error {
+	if !tx.Writable() {
+		return errors.New("vault_tx_read_only")
+	}
+
+	b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return b.Put([]byte(key), data)
+}
+
+func boltPutRaw(tx *bolt.Tx, bucket, key string, data []byte) error {
+	b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), data)
+}
+
+// ------------------------------------------------------------
+// VaultStore
+// ------------------------------------------------------------
+
+func (v *BoltVault) Read(collection, key string, out interface{}) (bool, error) {
+	var found bool
+	err := v.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = boltGet(tx, collection, key, out)
+		return err
+	})
+	return found, err
+}
+
+func (v *BoltVault) Write(collection, key string, value interface{}) error {
+	return v.db.Update(func(tx *bolt.Tx) error {
+		return boltPut(tx, collection, key, value)
+	})
+}
+
+func (v *BoltVault) Exists(collection, key string) (bool, error) {
+	var found bool
+	err := v.View(func(tx VaultTx) error {
+		var err error
+		found, err = tx.Exists(collection, key)
+		return err
+	})
+	return found, err
+}
+
+func (v *BoltVault) List(collection string) ([]string, error) {
+	var keys []string
+	err := v.View(func(tx VaultTx) error {
+		var err error
+		keys, err = tx.List(collection)
+		return err
+	})
+	return keys, err
+}
+
+func (v *BoltVault) Delete(collection, key string) error {
+	return v.Update(func(tx VaultTx) error {
+		return tx.Delete(collection, key)
+	})
+}
+
+// LoadConfig returns (nil, nil) when no config has been saved under name,
+// which DecideBootPath treats as a first boot.
+func (v *BoltVault) LoadConfig(name string) (*internal_environment.EnvConfig, error) {
+	var cfg internal_environment.EnvConfig
+
+	found, err := v.Read(bucketEnvConfig, name, &cfg)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func (v *BoltVault) SaveConfig(name string, cfg *internal_environment.EnvConfig) error {
+	return v.Write(bucketEnvConfig, name, cfg)
+}
+
+func (v *BoltVault) SealGoldenHash(machine string, hash []byte) error {
+	return v.db.Update(func(tx *bolt.Tx) error {
+		return boltPutRaw(tx, bucketGolden, machine, hash)
+	})
+}
+
+func (v *BoltVault) LoadGoldenHash(machine string) (string, error) {
+	var out string
+
+	err := v.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketGolden))
+		if b == nil {
+			return os.ErrNotExist
+		}
+
+		data := b.Get([]byte(machine))
+		if data == nil {
+			return os.ErrNotExist
+		}
+
+		out = string(data)
+		return nil
+	})
+
+	return out, err
+}
+
+func (v *BoltVault) LoadFirstBootMarker() (*internal_boot.FirstBootMarker, error) {
+	var marker internal_boot.FirstBootMarker
+
+	found, err := v.Read(bucketMarkers, firstBootVaultKey, &marker)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("failed to load first bootstrap marker: %w", os.ErrNotExist)
+	}
+
+	return &marker, nil
+}
+
+func (v *BoltVault) MarkFirstBoot(marker *internal_boot.FirstBootMarker) error {
+	return v.Write(bucketMarkers, firstBootVaultKey, marker)
+}
+
+func (v *BoltVault) IsMissingMarker(name string) bool {
+	found, err := v.Exists(bucketMarkers, name)
+	return err != nil || !found
+}
+
+func (v *BoltVault) WriteMarker(name string) error {
+	logging.Info("[VAULT] Sealing state marker: %s", name)
+	return v.db.Update(func(tx *bolt.Tx) error {
+		return boltPutRaw(tx, bucketMarkers, name, []byte("PROVISIONED"))
+	})
+}
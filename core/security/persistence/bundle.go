@@ -0,0 +1,280 @@
+//core/security/persistence/bundle.go
+
+package verification_persistence
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	internal_boot "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/boot"
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/pkg/logging"
+)
+
+const bundleFormat = 1
+
+// ExportCollections are the vault collections carried over to a
+// replacement unit. Hardware-bound records (env configs, golden hashes,
+// the device identity, measurements, the audit chain) and per-device
+// state (sessions, lockout counters, pending resets) are deliberately left
+// out and rebuilt on the new machine.
+var ExportCollections = []string{
+	"users",
+	"configs",
+	"profiles",
+	"config_history",
+	"policies",
+	"policy_versions",
+	"lockout_policy",
+	"totp",
+	"authenticators",
+	"authenticator_config",
+	"secrets",
+	"provisioning",
+}
+
+// sealedCollections hold records sealed under the vault key, which never
+// leaves the unit. They travel opened inside the encrypted bundle and are
+// sealed again under the importing vault's key.
+var sealedCollections = map[string]bool{
+	"totp":           true,
+	"authenticators": true,
+	"secrets":        true,
+}
+
+const (
+	provisioningCollection = "provisioning"
+	reattestationKey       = "reattestation"
+)
+
+// VaultBundle is the on-disk format produced by ExportBundle.
+type VaultBundle struct {
+	Format        int       `json:"format"`
+	FleetID       string    `json:"fleet_id"`
+	SourceMachine string    `json:"source_machine"`
+	CreatedAt     time.Time `json:"created_at"`
+	Payload       []byte    `json:"payload"`
+	Signature     []byte    `json:"signature"`
+}
+
+// BundleRecord is one exported vault record.
+type BundleRecord struct {
+	Collection string          `json:"collection"`
+	Key        string          `json:"key"`
+	Value      json.RawMessage `json:"value"`
+}
+
+type bundleContents struct {
+	Records []BundleRecord `json:"records"`
+}
+
+// ReattestationRequest is written by ImportBundle. While present the next
+// boot takes the cold path and re-attests the hardware.
+type ReattestationRequest struct {
+	Reason        string    `json:"reason"`
+	SourceMachine string    `json:"source_machine"`
+	RequestedAt   time.Time `json:"requested_at"`
+}
+
+// header is the authenticated, unencrypted part of the bundle.
+func (b *VaultBundle) header() []byte {
+	h, _ := json.Marshal(struct {
+		Format        int       `json:"format"`
+		FleetID       string    `json:"fleet_id"`
+		SourceMachine string    `json:"source_machine"`
+		CreatedAt     time.Time `json:"created_at"`
+	}{b.Format, b.FleetID, b.SourceMachine, b.CreatedAt})
+	return h
+}
+
+func (b *VaultBundle) signedBytes() []byte {
+	return append(b.header(), b.Payload...)
+}
+
+// ExportBundle collects ExportCollections from v, encrypts them with the
+// fleet key and signs the result.
+func ExportBundle(v VaultStore, fleet *FleetKey) (*VaultBundle, error) {
+	var contents bundleContents
+
+	read := func(tx VaultTx) error {
+		for _, collection := range ExportCollections {
+			keys, err := tx.List(collection)
+			if err != nil {
+				return err
+			}
+
+			for _, key := range keys {
+				if collection == provisioningCollection && key == reattestationKey {
+					continue
+				}
+
+				var raw json.RawMessage
+				get := tx.Read
+				if sealedCollections[collection] {
+					get = func(collection, key string, out interface{}) (bool, error) {
+						return ReadSealed(tx, collection, key, out)
+					}
+				}
+				if _, err := get(collection, key, &raw); err != nil {
+					return fmt.Errorf("export %s/%s: %w", collection, key, err)
+				}
+
+				contents.Records = append(contents.Records, BundleRecord{
+					Collection: collection,
+					Key:        key,
+					Value:      raw,
+				})
+			}
+		}
+		return nil
+	}
+
+	var err error
+	if ts, ok := v.(TransactionalStore); ok {
+		err = ts.View(read)
+	} else {
+		err = read(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	source := ""
+	if marker, err := v.LoadFirstBootMarker(); err == nil {
+		source = marker.MachineID
+	}
+
+	bundle := &VaultBundle{
+		Format:        bundleFormat,
+		FleetID:       fleet.ID,
+		SourceMachine: source,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	plaintext, err := json.Marshal(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle.Payload, err = Seal(fleet.encryption, plaintext, bundle.header())
+	if err != nil {
+		return nil, err
+	}
+
+	bundle.Signature = ed25519.Sign(fleet.signing, bundle.signedBytes())
+
+	logging.Info("[VAULT] exported %d records for fleet %s", len(contents.Records), fleet.ID)
+	return bundle, nil
+}
+
+// OpenBundle checks that bundle belongs to fleet, verifies its signature
+// and decrypts the records.
+func OpenBundle(bundle *VaultBundle, fleet *FleetKey) ([]BundleRecord, error) {
+	if bundle.Format != bundleFormat {
+		return nil, fmt.Errorf("unsupported bundle format: %d", bundle.Format)
+	}
+
+	if bundle.FleetID != fleet.ID {
+		return nil, errors.New("bundle_fleet_mismatch")
+	}
+
+	if !ed25519.Verify(fleet.public, bundle.signedBytes(), bundle.Signature) {
+		return nil, errors.New("bundle_signature_invalid")
+	}
+
+	plaintext, err := Open(fleet.encryption, bundle.Payload, bundle.header())
+	if err != nil {
+		return nil, errors.New("bundle_payload_tampered")
+	}
+
+	var contents bundleContents
+	if err := json.Unmarshal(plaintext, &contents); err != nil {
+		return nil, err
+	}
+
+	return contents.Records, nil
+}
+
+// ImportBundle verifies bundle and writes its records into v. Any previous
+// hardware binding is reset: the first-boot marker is reinitialised for
+// this machine and a ReattestationRequest forces the next boot onto the
+// cold path.
+func ImportBundle(v VaultStore, bundle *VaultBundle, fleet *FleetKey, overwrite bool) (int, error) {
+	records, err := OpenBundle(bundle, fleet)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, r := range records {
+		if !isExportCollection(r.Collection) {
+			return 0, fmt.Errorf("bundle contains unexpected collection: %s", r.Collection)
+		}
+	}
+
+	err = Atomically(v, func(tx VaultTx) error {
+		for _, r := range records {
+			if !overwrite {
+				exists, err := tx.Exists(r.Collection, r.Key)
+				if err != nil {
+					return err
+				}
+				if exists {
+					return fmt.Errorf("record %s/%s already exists", r.Collection, r.Key)
+				}
+			}
+
+			put := tx.Write
+			if sealedCollections[r.Collection] {
+				put = func(collection, key string, value interface{}) error {
+					return WriteSealed(tx, collection, key, value)
+				}
+			}
+			if err := put(r.Collection, r.Key, r.Value); err != nil {
+				return err
+			}
+		}
+
+		return tx.Write(provisioningCollection, reattestationKey, ReattestationRequest{
+			Reason:        "vault_import",
+			SourceMachine: bundle.SourceMachine,
+			RequestedAt:   time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := v.MarkFirstBoot(&internal_boot.FirstBootMarker{
+		Initialized: false,
+		CreatedAt:   time.Now().UTC(),
+	}); err != nil {
+		return 0, err
+	}
+
+	logging.Info("[VAULT] imported %d records from %s; re-attestation required",
+		len(records), bundle.SourceMachine)
+
+	return len(records), nil
+}
+
+// ReattestationPending reports whether an import is waiting for this unit
+// to re-attest its hardware.
+func ReattestationPending(v VaultStore) (bool, error) {
+	return v.Exists(provisioningCollection, reattestationKey)
+}
+
+// ClearReattestation records that the hardware has been re-attested.
+func ClearReattestation(v VaultStore) error {
+	return v.Delete(provisioningCollection, reattestationKey)
+}
+
+func isExportCollection(c string) bool {
+	for _, e := range ExportCollections {
+		if e == c {
+			return true
+		}
+	}
+	return false
+}
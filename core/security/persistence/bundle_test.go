@@ -0,0 +1,89 @@
+//core/security/persistence/bundle_test.go
+
+package verification_persistence
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testVault(t *testing.T, fill byte) *IsolatedVault {
+	t.Helper()
+	return &IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{fill}, 32)}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	fleet, err := NewFleetKey(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := testVault(t, 1)
+	if err := src.Write("users", "alice", map[string]string{"user_id": "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Write("policy_versions", "safety", map[string]int{"version": 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSealed(src, "totp", "alice", map[string]string{"secret": "JBSWY3DP"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Write("lockout", "user:alice", map[string]int{"failures": 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := ExportBundle(src, fleet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A replacement unit has its own vault key.
+	dst := testVault(t, 2)
+	n, err := ImportBundle(dst, bundle, fleet, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("imported %d records, want 3", n)
+	}
+
+	var version map[string]int
+	if found, err := dst.Read("policy_versions", "safety", &version); err != nil || !found || version["version"] != 3 {
+		t.Errorf("policy_versions/safety = %v, %v, %v", version, found, err)
+	}
+
+	var totp map[string]string
+	if found, err := ReadSealed(dst, "totp", "alice", &totp); err != nil || !found || totp["secret"] != "JBSWY3DP" {
+		t.Errorf("sealed totp/alice after import = %v, %v, %v", totp, found, err)
+	}
+
+	if found, _ := dst.Exists("lockout", "user:alice"); found {
+		t.Error("lockout counters must not travel to a replacement unit")
+	}
+	if pending, _ := ReattestationPending(dst); !pending {
+		t.Error("import must request re-attestation")
+	}
+}
+
+func TestBundleRejectsOtherFleet(t *testing.T) {
+	fleet, _ := NewFleetKey(bytes.Repeat([]byte{7}, 32))
+	other, _ := NewFleetKey(bytes.Repeat([]byte{8}, 32))
+
+	src := testVault(t, 1)
+	if err := src.Write("users", "alice", map[string]string{"user_id": "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := ExportBundle(src, fleet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ImportBundle(testVault(t, 2), bundle, other, false); err == nil {
+		t.Fatal("bundle of another fleet imported")
+	}
+
+	bundle.Payload[0] ^= 1
+	if _, err := OpenBundle(bundle, fleet); err == nil {
+		t.Fatal("tampered bundle opened")
+	}
+}
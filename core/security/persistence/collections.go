@@ -0,0 +1,74 @@
+//core/security/persistence/collections.go
+
+package verification_persistence
+
+import (
+	"sort"
+	"strings"
+)
+
+// Collections are the record collections written through VaultStore.
+// The directory backend stores a record as <collection>_<key>.json, and
+// several collection names contain an underscore themselves, so a file
+// name can only be split against this list.
+var Collections = []string{
+	"audit",
+	"audit_checkpoints",
+	"audit_meta",
+	"authenticator_config",
+	"authenticators",
+	"config_history",
+	"configs",
+	"device",
+	"lockout",
+	"lockout_policy",
+	"measurements",
+	"password_resets",
+	"policies",
+	"policy_versions",
+	"profiles",
+	"provisioning",
+	"release",
+	"secrets",
+	"session_revocations",
+	"sessions",
+	"totp",
+	"users",
+}
+
+// byLength is Collections, longest name first.
+var byLength = func() []string {
+	out := append([]string(nil), Collections...)
+	sort.SliceStable(out, func(i, j int) bool { return len(out[i]) > len(out[j]) })
+	return out
+}()
+
+// splitRecordName splits a directory record name (the file name without
+// .json) into its collection and key. Known collections are matched
+// longest first, so lockout_policy_x is never read as lockout/policy_x.
+// Names of unknown collections are split at the first underscore.
+func splitRecordName(name string) (collection, key string, ok bool) {
+	for _, c := range byLength {
+		if k, found := strings.CutPrefix(name, c+"_"); found && k != "" {
+			return c, k, true
+		}
+	}
+	return strings.Cut(name, "_")
+}
+
+// inCollection reports whether the record name belongs to collection and
+// not to a longer known collection that starts with the same prefix.
+func inCollection(collection, name string) bool {
+	if !strings.HasPrefix(name, collection+"_") {
+		return false
+	}
+	for _, c := range byLength {
+		if len(c) <= len(collection) {
+			break
+		}
+		if strings.HasPrefix(name, c+"_") {
+			return false
+		}
+	}
+	return true
+}
@@ -29,6 +29,9 @@ func (v *IsolatedVault) LoadConfig(name string) (*internal_environment.EnvConfig
 
 	data, err := os.ReadFile(path)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
 		return nil, err
 	}
 
@@ -0,0 +1,68 @@
+//core/security/persistence/fleet_key.go
+
+package verification_persistence
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// FleetKey is shared by every unit of one fleet. Vault bundles are
+// encrypted and signed with keys derived from it, so a bundle can only be
+// imported by a unit provisioned with the same fleet key.
+type FleetKey struct {
+	ID string
+
+	signing    ed25519.PrivateKey
+	public     ed25519.PublicKey
+	encryption []byte
+}
+
+// LoadFleetKey reads the base64 32-byte fleet secret from AIOS_FLEET_KEY.
+// Unlike LoadSecureKey there is no ephemeral fallback: a generated fleet
+// key would make every exported bundle unreadable on the next unit.
+func LoadFleetKey() (*FleetKey, error) {
+	keyStr := os.Getenv("AIOS_FLEET_KEY")
+	if keyStr == "" {
+		return nil, errors.New("AIOS_FLEET_KEY is not set")
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(keyStr)
+	if err != nil {
+		return nil, errors.New("invalid base64 fleet key")
+	}
+
+	return NewFleetKey(secret)
+}
+
+// NewFleetKey derives the signing and encryption keys from a fleet secret.
+func NewFleetKey(secret []byte) (*FleetKey, error) {
+	if len(secret) != 32 {
+		return nil, fmt.Errorf("fleet key must decode to 32 bytes, got %d", len(secret))
+	}
+
+	signSeed := deriveFleetKey(secret, "aios-fleet-sign")
+	signing := ed25519.NewKeyFromSeed(signSeed)
+	public := signing.Public().(ed25519.PublicKey)
+
+	id := sha256.Sum256(public)
+
+	return &FleetKey{
+		ID:         hex.EncodeToString(id[:8]),
+		signing:    signing,
+		public:     public,
+		encryption: deriveFleetKey(secret, "aios-fleet-encrypt"),
+	}, nil
+}
+
+func deriveFleetKey(secret []byte, label string) []byte {
+	h := sha256.New()
+	h.Write([]byte(label))
+	h.Write(secret)
+	return h.Sum(nil)
+}
@@ -5,10 +5,22 @@ package verification_persistence
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/apppath"
 )
 
+// VaultKeyFile holds the vault key of units provisioned without
+// APP_ENCRYPTION_KEY. It is generated once and kept in the config
+// directory, apart from the vault, so records sealed under it still open
+// after a restart and a copied vault directory does not carry its key.
+const VaultKeyFile = "vault.key"
+
 // KeyManager handles the generation, storage, and retrieval of cryptographic keys.
 func GenerateSecureKeyBase64() (string, error) {
 	key := make([]byte, 32)
@@ -21,22 +33,18 @@ func GenerateSecureKeyBase64() (string, error) {
 	return base64.StdEncoding.EncodeToString(key), nil
 }
 
-// LoadSecureKey fetches the encryption key from environment variables.
-// It ensures the key meets the 32-byte requirement for AES-256.
+// LoadSecureKey fetches the encryption key from environment variables,
+// falling back to the durable VaultKeyFile. It ensures the key meets the
+// 32-byte requirement for AES-256.
 func LoadSecureKey() []byte {
 	keyStr := os.Getenv("APP_ENCRYPTION_KEY")
 
 	if keyStr == "" {
-		// Auto-generate (dev or first bootstrap)
-		gen, err := GenerateSecureKeyBase64()
+		key, err := LoadOrCreateKeyFile(filepath.Join(apppath.GetConfigDir(), VaultKeyFile))
 		if err != nil {
-			log.Fatal("Failed to generate encryption key:", err)
+			log.Fatal("Failed to load vault key:", err)
 		}
-
-		log.Println("[verification] Generated ephemeral encryption key")
-
-		keyBytes, _ := base64.StdEncoding.DecodeString(gen)
-		return keyBytes
+		return key
 	}
 
 	key, err := base64.StdEncoding.DecodeString(keyStr)
@@ -50,3 +58,59 @@ func LoadSecureKey() []byte {
 
 	return key
 }
+
+// LoadOrCreateKeyFile reads the base64 key at path, generating it on first
+// use. Concurrent first uses agree on one key.
+func LoadOrCreateKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if err = createKeyFile(path); err == nil {
+			data, err = os.ReadFile(path)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%s is not a base64 32-byte key", path)
+	}
+	return key, nil
+}
+
+// createKeyFile writes a new key to a private temp file and links it into
+// place: the link fails if another process got there first, and nobody
+// ever reads a half-written key.
+func createKeyFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	gen, err := GenerateSecureKeyBase64()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), VaultKeyFile+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(gen + "\n")
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	switch err := os.Link(tmp.Name(), path); {
+	case err == nil:
+		log.Println("[verification] Generated vault key at", path)
+	case !errors.Is(err, os.ErrExist):
+		return err
+	}
+	return nil
+}
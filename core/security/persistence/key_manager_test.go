@@ -0,0 +1,56 @@
+//core/security/persistence/key_manager_test.go
+
+package verification_persistence
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadOrCreateKeyFileIsDurable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conf", VaultKeyFile)
+
+	first, err := LoadOrCreateKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 32 {
+		t.Fatalf("key is %d bytes", len(first))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("key file mode %v", info.Mode().Perm())
+	}
+
+	// A restart loads the same key, so sealed records still open.
+	sealed, err := Seal(first, []byte("seed"), []byte("device/identity_key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := LoadOrCreateKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, again) {
+		t.Fatal("key changed across loads")
+	}
+	if _, err := Open(again, sealed, []byte("device/identity_key")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadOrCreateKeyFileRejectsBadKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), VaultKeyFile)
+	if err := os.WriteFile(path, []byte("c2hvcnQ=\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOrCreateKeyFile(path); err == nil {
+		t.Fatal("short key accepted")
+	}
+}
@@ -4,8 +4,11 @@ package verification_persistence
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 func (v *IsolatedVault) Read(collection, key string, out interface{}) (bool, error) {
@@ -34,7 +37,60 @@ func (v *IsolatedVault) Write(collection, key string, value interface{}) error {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0600)
+	tmp, err := v.writeTemp(data)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	// Renaming over the record means readers see the old or the new
+	// record, never a partial one.
+	return os.Rename(tmp, path)
+}
+
+// Create writes a record that must not exist yet. The record is written to
+// a temp file and linked into place, so of several processes creating the
+// same key exactly one succeeds and the others get ErrRecordExists.
+func (v *IsolatedVault) Create(collection, key string, value interface{}) error {
+	path := filepath.Join(v.BaseDir, collection+"_"+key+".json")
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := v.writeTemp(data)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	if err := os.Link(tmp, path); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return ErrRecordExists
+		}
+		return err
+	}
+	return nil
+}
+
+// writeTemp stores data in a private temp file beside the records. Its
+// name has no collection prefix, so List never reports it.
+func (v *IsolatedVault) writeTemp(data []byte) (string, error) {
+	tmp, err := os.CreateTemp(v.BaseDir, ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
 }
 
 func (v *IsolatedVault) Exists(collection, key string) (bool, error) {
@@ -50,3 +106,38 @@ func (v *IsolatedVault) Exists(collection, key string) (bool, error) {
 
 	return true, nil
 }
+
+// List returns the keys stored in collection, sorted. Records of longer
+// collections sharing the prefix (lockout_policy for lockout) are not
+// included.
+func (v *IsolatedVault) List(collection string) ([]string, error) {
+	prefix := collection + "_"
+
+	matches, err := filepath.Glob(filepath.Join(v.BaseDir, prefix+"*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(matches))
+	for _, m := range matches {
+		name := strings.TrimSuffix(filepath.Base(m), ".json")
+		if !inCollection(collection, name) {
+			continue
+		}
+		keys = append(keys, strings.TrimPrefix(name, prefix))
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete removes a record. Deleting a missing record is not an error.
+func (v *IsolatedVault) Delete(collection, key string) error {
+	path := filepath.Join(v.BaseDir, collection+"_"+key+".json")
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
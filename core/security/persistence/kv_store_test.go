@@ -0,0 +1,41 @@
+//core/security/persistence/kv_store_test.go
+
+package verification_persistence
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsolatedVaultListExactCollection(t *testing.T) {
+	v := &IsolatedVault{BaseDir: t.TempDir()}
+
+	for _, r := range []struct{ collection, key string }{
+		{"lockout", "user:alice"},
+		{"lockout", "source:tty1"},
+		{"lockout_policy", "computer"},
+		{"audit", "00000001"},
+		{"audit_meta", "head"},
+		{"audit_checkpoints", "00000100"},
+	} {
+		if err := v.Write(r.collection, r.key, map[string]string{"k": r.key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for collection, want := range map[string][]string{
+		"lockout":        {"source:tty1", "user:alice"},
+		"lockout_policy": {"computer"},
+		"audit":          {"00000001"},
+		"audit_meta":     {"head"},
+		"users":          {},
+	} {
+		got, err := v.List(collection)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("List(%q) = %v, want %v", collection, got, want)
+		}
+	}
+}
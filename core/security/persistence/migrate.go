@@ -0,0 +1,115 @@
+//core/security/persistence/migrate.go
+
+package verification_persistence
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/pkg/logging"
+	bolt "go.etcd.io/bbolt"
+)
+
+// MigrationReport summarises what MigrateDirectoryToBolt copied.
+type MigrationReport struct {
+	Records  int
+	Configs  int
+	Golden   int
+	Markers  int
+	Skipped  []string
+	Database string
+}
+
+// MigrateDirectoryToBolt copies every record of a directory-of-JSON vault
+// into dst. The copy runs in a single transaction, so a failed migration
+// leaves dst untouched. The source directory is never modified.
+func MigrateDirectoryToBolt(src *IsolatedVault, dst *BoltVault) (*MigrationReport, error) {
+	report := &MigrationReport{Database: dst.Path}
+
+	err := dst.db.Update(func(tx *bolt.Tx) error {
+		return filepath.WalkDir(src.BaseDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(src.BaseDir, path)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+
+			if rel == BoltVaultFile {
+				return nil
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			bucket, key, ok := classifyVaultFile(rel)
+			if !ok {
+				report.Skipped = append(report.Skipped, rel)
+				return nil
+			}
+
+			switch bucket {
+			case bucketEnvConfig:
+				report.Configs++
+			case bucketGolden:
+				report.Golden++
+			case bucketMarkers:
+				report.Markers++
+			default:
+				report.Records++
+			}
+
+			return boltPutRaw(tx, bucket, key, data)
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("vault migration failed: %w", err)
+	}
+
+	logging.Info("[VAULT] migrated %d records, %d configs, %d golden hashes, %d markers into %s",
+		report.Records, report.Configs, report.Golden, report.Markers, dst.Path)
+
+	return report, nil
+}
+
+// classifyVaultFile maps a path relative to the vault directory onto the
+// bucket and key BoltVault uses for the same record.
+func classifyVaultFile(rel string) (bucket, key string, ok bool) {
+	switch {
+	case strings.Contains(rel, "/"):
+		// SaveConfig names such as env/<machine>/last-known.json
+		if !strings.HasSuffix(rel, ".json") {
+			return "", "", false
+		}
+		return bucketEnvConfig, strings.TrimSuffix(rel, ".json"), true
+
+	case strings.HasPrefix(rel, "golden-"):
+		return bucketGolden, strings.TrimPrefix(rel, "golden-"), true
+
+	case rel == firstBootVaultKey+".json":
+		return bucketMarkers, firstBootVaultKey, true
+
+	case strings.HasSuffix(rel, ".json"):
+		name := strings.TrimSuffix(rel, ".json")
+		collection, id, found := splitRecordName(name)
+		if !found {
+			return bucketEnvConfig, name, true
+		}
+		return collection, id, true
+
+	default:
+		// WriteMarker files carry no extension.
+		return bucketMarkers, rel, true
+	}
+}
@@ -0,0 +1,37 @@
+//core/security/persistence/migrate_test.go
+
+package verification_persistence
+
+import "testing"
+
+func TestClassifyVaultFile(t *testing.T) {
+	cases := []struct {
+		file, bucket, key string
+	}{
+		{"users_alice.json", "users", "alice"},
+		{"audit_00000001.json", "audit", "00000001"},
+		{"audit_checkpoints_00000100.json", "audit_checkpoints", "00000100"},
+		{"audit_meta_head.json", "audit_meta", "head"},
+		{"lockout_user:alice.json", "lockout", "user:alice"},
+		{"lockout_policy_computer.json", "lockout_policy", "computer"},
+		{"config_history_alice.json", "config_history", "alice"},
+		{"policy_versions_safety.json", "policy_versions", "safety"},
+		{"session_revocations_abc.json", "session_revocations", "abc"},
+		{"authenticator_config_default.json", "authenticator_config", "default"},
+		{"password_resets_r1.json", "password_resets", "r1"},
+		{"sessions_signing_key.json", "sessions", "signing_key"},
+		{"custom_a_b.json", "custom", "a_b"},
+		{"settings.json", bucketEnvConfig, "settings"},
+		{"env/m1/last-known.json", bucketEnvConfig, "env/m1/last-known"},
+		{"golden-m1", bucketGolden, "m1"},
+		{firstBootVaultKey + ".json", bucketMarkers, firstBootVaultKey},
+		{"provisioned", bucketMarkers, "provisioned"},
+	}
+
+	for _, c := range cases {
+		bucket, key, ok := classifyVaultFile(c.file)
+		if !ok || bucket != c.bucket || key != c.key {
+			t.Errorf("classifyVaultFile(%q) = %q, %q, %v; want %q, %q", c.file, bucket, key, ok, c.bucket, c.key)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+//core/security/persistence/seal.go
+
+package verification_persistence
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+)
+
+// Seal encrypts plaintext with AES-256-GCM under key. The random nonce is
+// prepended to the returned ciphertext. aad is authenticated but not
+// encrypted and must be supplied again to Open.
+func Seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// Open reverses Seal. It fails if the ciphertext or aad was altered.
+func Open(key, sealed, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("sealed_data_truncated")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, errors.New("sealed_data_tampered")
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, errors.New("seal key must be 32 bytes")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// KeyedStore is implemented by vault backends that hold the local
+// encryption key loaded by LoadSecureKey.
+type KeyedStore interface {
+	EncryptionKey() []byte
+}
+
+func (v *IsolatedVault) EncryptionKey() []byte { return v.Key }
+func (v *BoltVault) EncryptionKey() []byte     { return v.Key }
+
+// sealedRecord is how WriteSealed stores values: the JSON encoding of the
+// value, encrypted with the vault key and bound to its collection and key.
+type sealedRecord struct {
+	Sealed []byte `json:"sealed"`
+}
+
+// WriteSealed stores value encrypted under the vault key.
+func WriteSealed(v VaultTx, collection, key string, value interface{}) error {
+	vaultKey, err := vaultEncryptionKey(v)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := Seal(vaultKey, plaintext, []byte(collection+"/"+key))
+	if err != nil {
+		return err
+	}
+
+	return v.Write(collection, key, sealedRecord{Sealed: sealed})
+}
+
+// ReadSealed reads a record written by WriteSealed.
+func ReadSealed(v VaultTx, collection, key string, out interface{}) (bool, error) {
+	vaultKey, err := vaultEncryptionKey(v)
+	if err != nil {
+		return false, err
+	}
+
+	var rec sealedRecord
+	found, err := v.Read(collection, key, &rec)
+	if err != nil || !found {
+		return found, err
+	}
+
+	plaintext, err := Open(vaultKey, rec.Sealed, []byte(collection+"/"+key))
+	if err != nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(plaintext, out)
+}
+
+func vaultEncryptionKey(v VaultTx) ([]byte, error) {
+	switch ks := v.(type) {
+	case KeyedStore:
+		return ks.EncryptionKey(), nil
+	case *boltTx:
+		return ks.key, nil
+	default:
+		return nil, errors.New("vault backend has no encryption key")
+	}
+}
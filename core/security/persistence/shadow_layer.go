@@ -0,0 +1,138 @@
+//core/security/persistence/shadow_layer.go
+
+package verification_persistence
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// shadowLayer holds what was written or deleted through a ShadowVault. A
+// nil record marks a deletion. ShadowVault serializes every call.
+type shadowLayer interface {
+	get(collection, key string) ([]byte, bool, error)
+	set(collection, key string, data []byte) error
+	records(collection string) (map[string][]byte, error)
+	count() int
+	reset() error
+}
+
+// memLayer keeps the overlay in memory; it is gone with the process.
+type memLayer map[string]map[string][]byte
+
+func (m memLayer) get(collection, key string) ([]byte, bool, error) {
+	data, ok := m[collection][key]
+	return data, ok, nil
+}
+
+func (m memLayer) set(collection, key string, data []byte) error {
+	if m[collection] == nil {
+		m[collection] = map[string][]byte{}
+	}
+	m[collection][key] = data
+	return nil
+}
+
+func (m memLayer) records(collection string) (map[string][]byte, error) {
+	return m[collection], nil
+}
+
+func (m memLayer) count() int {
+	n := 0
+	for _, c := range m {
+		n += len(c)
+	}
+	return n
+}
+
+func (m memLayer) reset() error {
+	for c := range m {
+		delete(m, c)
+	}
+	return nil
+}
+
+// dirLayer keeps the overlay as files under one directory, one
+// subdirectory per collection. Names are hex encoded so any collection or
+// key stays inside the directory.
+type dirLayer string
+
+// Record files start with a marker byte.
+const (
+	layerRecord  = 'r'
+	layerDeleted = 'd'
+)
+
+func (d dirLayer) path(collection, key string) string {
+	return filepath.Join(string(d), hex.EncodeToString([]byte(collection)), hex.EncodeToString([]byte(key)))
+}
+
+func (d dirLayer) get(collection, key string) ([]byte, bool, error) {
+	raw, err := os.ReadFile(d.path(collection, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if len(raw) == 0 || raw[0] == layerDeleted {
+		return nil, true, nil
+	}
+	return raw[1:], true, nil
+}
+
+func (d dirLayer) set(collection, key string, data []byte) error {
+	path := d.path(collection, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	raw := []byte{layerDeleted}
+	if data != nil {
+		raw = append([]byte{layerRecord}, data...)
+	}
+	return os.WriteFile(path, raw, 0600)
+}
+
+func (d dirLayer) records(collection string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(filepath.Join(string(d), hex.EncodeToString([]byte(collection))))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]byte, len(entries))
+	for _, e := range entries {
+		key, err := hex.DecodeString(e.Name())
+		if err != nil {
+			continue
+		}
+		data, _, err := d.get(collection, string(key))
+		if err != nil {
+			return nil, err
+		}
+		out[string(key)] = data
+	}
+	return out, nil
+}
+
+func (d dirLayer) count() int {
+	n := 0
+	collections, _ := os.ReadDir(string(d))
+	for _, c := range collections {
+		entries, _ := os.ReadDir(filepath.Join(string(d), c.Name()))
+		n += len(entries)
+	}
+	return n
+}
+
+func (d dirLayer) reset() error {
+	if err := os.RemoveAll(string(d)); err != nil {
+		return err
+	}
+	return os.MkdirAll(string(d), 0700)
+}
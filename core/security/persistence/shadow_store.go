@@ -0,0 +1,230 @@
+//core/security/persistence/shadow_store.go
+
+package verification_persistence
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+
+	internal_boot "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/boot"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+)
+
+// Boot records are shadowed under collections no real caller uses.
+const (
+	shadowConfigs = "\x00config"
+	shadowGolden  = "\x00golden"
+	shadowMarker  = "\x00marker"
+)
+
+// ShadowVault overlays a vault with a private layer. Reads see the layer
+// first and fall through to the base; writes and deletes never reach the
+// base. Sandbox sessions run on one kept in memory and Discard it at
+// logout; guest sessions on one kept in their scratch area.
+type ShadowVault struct {
+	base VaultStore
+
+	mu    sync.RWMutex
+	layer shadowLayer
+}
+
+// NewShadowVault overlays base in memory.
+func NewShadowVault(base VaultStore) *ShadowVault {
+	return &ShadowVault{base: base, layer: memLayer{}}
+}
+
+// NewDirShadowVault overlays base with records kept under dir, e.g. a
+// guest's scratch area: removing dir removes every change.
+func NewDirShadowVault(base VaultStore, dir string) (*ShadowVault, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &ShadowVault{base: base, layer: dirLayer(dir)}, nil
+}
+
+// Discard drops every change made through the overlay.
+func (s *ShadowVault) Discard() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.layer.reset()
+}
+
+// Changes counts the records written or deleted through the overlay.
+func (s *ShadowVault) Changes() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.layer.count()
+}
+
+// EncryptionKey lets sealed records round-trip through the overlay.
+func (s *ShadowVault) EncryptionKey() []byte {
+	if ks, ok := s.base.(KeyedStore); ok {
+		return ks.EncryptionKey()
+	}
+	return nil
+}
+
+// lookup returns the shadowed bytes of a record and whether the overlay
+// decides its fate (present or deleted).
+func (s *ShadowVault) lookup(collection, key string) ([]byte, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.layer.get(collection, key)
+}
+
+func (s *ShadowVault) put(collection, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.layer.set(collection, key, data)
+}
+
+func (s *ShadowVault) Read(collection, key string, out interface{}) (bool, error) {
+	data, ok, err := s.lookup(collection, key)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		if data == nil {
+			return false, nil
+		}
+		return true, json.Unmarshal(data, out)
+	}
+	return s.base.Read(collection, key, out)
+}
+
+func (s *ShadowVault) Write(collection, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.put(collection, key, data)
+}
+
+// Create writes a record into the overlay unless it is already visible.
+func (s *ShadowVault) Create(collection, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shadowed, ok, err := s.layer.get(collection, key)
+	switch {
+	case err != nil:
+		return err
+	case ok && shadowed != nil:
+		return ErrRecordExists
+	case !ok:
+		exists, err := s.base.Exists(collection, key)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrRecordExists
+		}
+	}
+
+	return s.layer.set(collection, key, data)
+}
+
+func (s *ShadowVault) Exists(collection, key string) (bool, error) {
+	data, ok, err := s.lookup(collection, key)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		return data != nil, nil
+	}
+	return s.base.Exists(collection, key)
+}
+
+// List merges the base keys with the overlay, sorted.
+func (s *ShadowVault) List(collection string) ([]string, error) {
+	keys, err := s.base.List(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	shadow, err := s.layer.records(collection)
+	if err != nil {
+		return nil, err
+	}
+	merged := make([]string, 0, len(keys)+len(shadow))
+	for _, k := range keys {
+		if data, ok := shadow[k]; ok && data == nil {
+			continue
+		}
+		merged = append(merged, k)
+	}
+	for k, data := range shadow {
+		if data == nil {
+			continue
+		}
+		if found, _ := s.base.Exists(collection, k); !found {
+			merged = append(merged, k)
+		}
+	}
+
+	sort.Strings(merged)
+	return merged, nil
+}
+
+func (s *ShadowVault) Delete(collection, key string) error {
+	return s.put(collection, key, nil)
+}
+
+func (s *ShadowVault) LoadConfig(name string) (*internal_environment.EnvConfig, error) {
+	var cfg internal_environment.EnvConfig
+	found, err := s.Read(shadowConfigs, name, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return &cfg, nil
+	}
+	return s.base.LoadConfig(name)
+}
+
+func (s *ShadowVault) SaveConfig(name string, cfg *internal_environment.EnvConfig) error {
+	return s.Write(shadowConfigs, name, cfg)
+}
+
+func (s *ShadowVault) LoadGoldenHash(machine string) (string, error) {
+	var hash string
+	found, err := s.Read(shadowGolden, machine, &hash)
+	if err != nil || found {
+		return hash, err
+	}
+	return s.base.LoadGoldenHash(machine)
+}
+
+func (s *ShadowVault) SealGoldenHash(machine string, hash []byte) error {
+	return s.Write(shadowGolden, machine, string(hash))
+}
+
+func (s *ShadowVault) LoadFirstBootMarker() (*internal_boot.FirstBootMarker, error) {
+	var m internal_boot.FirstBootMarker
+	found, err := s.Read(shadowMarker, "first_boot", &m)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return &m, nil
+	}
+	return s.base.LoadFirstBootMarker()
+}
+
+func (s *ShadowVault) MarkFirstBoot(marker *internal_boot.FirstBootMarker) error {
+	if marker == nil {
+		return errors.New("nil first boot marker")
+	}
+	return s.Write(shadowMarker, "first_boot", marker)
+}
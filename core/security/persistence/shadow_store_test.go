@@ -0,0 +1,77 @@
+//core/security/persistence/shadow_store_test.go
+
+package verification_persistence
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestShadowVaultLeavesBaseUntouched(t *testing.T) {
+	base := &IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{1}, 32)}
+	if err := base.Write("profiles", "alice", "real"); err != nil {
+		t.Fatal(err)
+	}
+	if err := base.Write("profiles", "bob", "real"); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "guest", "vault")
+	dirVault, err := NewDirShadowVault(base, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, s := range map[string]*ShadowVault{"memory": NewShadowVault(base), "dir": dirVault} {
+		if err := s.Write("profiles", "alice", "shadow"); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Write("profiles", "carol", "shadow"); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Delete("profiles", "bob"); err != nil {
+			t.Fatal(err)
+		}
+
+		var got string
+		if found, err := s.Read("profiles", "alice", &got); err != nil || !found || got != "shadow" {
+			t.Errorf("%s: shadow read = %q, %v, %v", name, got, found, err)
+		}
+		if found, _ := s.Exists("profiles", "bob"); found {
+			t.Errorf("%s: deleted record still visible", name)
+		}
+		keys, err := s.List("profiles")
+		if err != nil || !reflect.DeepEqual(keys, []string{"alice", "carol"}) {
+			t.Errorf("%s: List = %v, %v", name, keys, err)
+		}
+		if s.Changes() != 3 {
+			t.Errorf("%s: Changes = %d, want 3", name, s.Changes())
+		}
+
+		if found, _ := base.Read("profiles", "alice", &got); !found || got != "real" {
+			t.Errorf("%s: base record changed to %q", name, got)
+		}
+		if found, _ := base.Exists("profiles", "bob"); !found {
+			t.Errorf("%s: base record deleted", name)
+		}
+
+		s.Discard()
+		if s.Changes() != 0 {
+			t.Errorf("%s: %d changes after Discard", name, s.Changes())
+		}
+	}
+
+	// The directory layer keeps its records under dir only.
+	if err := dirVault.Write("secrets", "x", "guest"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := dirVault.Exists("secrets", "x"); found {
+		t.Error("record outlived its directory")
+	}
+}
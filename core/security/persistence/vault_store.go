@@ -3,8 +3,10 @@
 package verification_persistence
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/apppath"
 	internal_boot "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/boot"
@@ -12,6 +14,12 @@ import (
 	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/pkg/logging"
 )
 
+// Vault backends selectable through AIOS_VAULT_BACKEND.
+const (
+	BackendDirectory = "dir"
+	BackendBolt      = "bolt"
+)
+
 type IsolatedVault struct {
 	BaseDir string
 	Key     []byte
@@ -32,6 +40,19 @@ func OpenVault() (*IsolatedVault, error) {
 	}, nil
 }
 
+// OpenStore opens the vault backend selected by AIOS_VAULT_BACKEND.
+// The directory-of-JSON layout remains the default.
+func OpenStore() (VaultStore, error) {
+	switch backend := os.Getenv("AIOS_VAULT_BACKEND"); backend {
+	case "", BackendDirectory:
+		return OpenVault()
+	case BackendBolt:
+		return OpenBoltVault(filepath.Join(apppath.GetVaultPath(), BoltVaultFile), LoadSecureKey())
+	default:
+		return nil, fmt.Errorf("unknown vault backend: %s", backend)
+	}
+}
+
 type VaultStore interface {
 	LoadConfig(key string) (*internal_environment.EnvConfig, error)
 	SaveConfig(key string, cfg *internal_environment.EnvConfig) error
@@ -42,4 +63,61 @@ type VaultStore interface {
 	Read(key, id string, out interface{}) (bool, error)
 	Write(key, id string, value interface{}) error
 	Exists(collection string, key string) (bool, error)
+	List(collection string) ([]string, error)
+	Delete(collection, key string) error
+}
+
+// VaultTx is the record view available inside a vault transaction.
+type VaultTx interface {
+	Read(collection, key string, out interface{}) (bool, error)
+	Write(collection, key string, value interface{}) error
+	Exists(collection, key string) (bool, error)
+	List(collection string) ([]string, error)
+	Delete(collection, key string) error
+}
+
+// TransactionalStore is implemented by backends that can commit several
+// records atomically and serve snapshot reads.
+type TransactionalStore interface {
+	VaultStore
+	Update(fn func(tx VaultTx) error) error
+	View(fn func(tx VaultTx) error) error
+}
+
+// ErrRecordExists is returned by Create when the record is already stored.
+var ErrRecordExists = errors.New("record_exists")
+
+// Creator is implemented by backends that can create a record only if it
+// does not exist, atomically across processes sharing the store.
+type Creator interface {
+	Create(collection, key string, value interface{}) error
+}
+
+// Create writes a record that must not exist yet. Inside a transaction the
+// existence check and the write commit together; backends without
+// transactions must implement Creator.
+func Create(tx VaultTx, collection, key string, value interface{}) error {
+	if c, ok := tx.(Creator); ok {
+		return c.Create(collection, key, value)
+	}
+	if _, ok := tx.(VaultStore); ok {
+		return fmt.Errorf("vault backend cannot create %s records exclusively", collection)
+	}
+	exists, err := tx.Exists(collection, key)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrRecordExists
+	}
+	return tx.Write(collection, key, value)
+}
+
+// Atomically runs fn in a single transaction when the backend supports it.
+// Other backends apply the writes directly, one record at a time.
+func Atomically(v VaultStore, fn func(tx VaultTx) error) error {
+	if ts, ok := v.(TransactionalStore); ok {
+		return ts.Update(fn)
+	}
+	return fn(v)
 }
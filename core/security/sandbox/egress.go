@@ -0,0 +1,91 @@
+//core/security/sandbox/egress.go
+
+package security_sandbox
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+var ErrEgressBlocked = errors.New("sandbox_outbound_network_disabled")
+
+var egressBlocked atomic.Bool
+
+// Every client built on http.DefaultTransport, or on a clone of it, dials
+// through Dial.
+func init() {
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		t.DialContext = Dial
+	}
+}
+
+// Dial is the dialer for outbound connections. While egress is blocked,
+// or for a context marked by WithSession for a sandbox session, it only
+// reaches loopback addresses, so local test fixtures keep working.
+func Dial(ctx context.Context, network, address string) (net.Conn, error) {
+	if (egressBlocked.Load() || sandboxed(ctx)) && !loopback(address) {
+		return nil, ErrEgressBlocked
+	}
+	return Dialer().DialContext(ctx, network, address)
+}
+
+// Dialer returns a dialer for clients that take a *net.Dialer instead of
+// a dial function (MQTT, DNS, websocket). It checks every address it
+// connects to, after resolution, while egress is blocked.
+func Dialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if egressBlocked.Load() && !loopback(address) {
+				return ErrEgressBlocked
+			}
+			return nil
+		},
+	}
+}
+
+// HTTPClient returns a client whose connections go through Dial. Use it,
+// or http.DefaultClient, for every outbound request.
+func HTTPClient() *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = Dial
+	return &http.Client{Transport: t}
+}
+
+// EgressBlocked reports whether outbound network is disabled.
+func EgressBlocked() bool {
+	return egressBlocked.Load()
+}
+
+// blockEgress disables outbound network for the process.
+func blockEgress() {
+	egressBlocked.Store(true)
+
+	// Drop kept-alive connections opened before the block
+	if c, ok := http.DefaultTransport.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+func allowEgress() {
+	egressBlocked.Store(false)
+}
+
+// loopback accepts literal loopback addresses only; resolving a name
+// would itself reach the network.
+func loopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,148 @@
+//core/security/sandbox/sandbox.go
+
+package security_sandbox
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/pkg/logging"
+	runtime_bus "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/bus"
+)
+
+var (
+	ErrNotSandboxSession = errors.New("session_not_sandboxed")
+	ErrSandboxActive     = errors.New("sandbox_already_active")
+)
+
+// Sandbox is the execution mode of a tester or mechanic session. While it
+// is active, actuator topics feed the Simulator, the session's vault is a
+// ShadowVault discarded on Exit, and outbound network is blocked.
+//
+// Egress and the bus are process-wide, so one sandbox runs at a time.
+type Sandbox struct {
+	SessionID string
+	UserID    string
+	Since     time.Time
+
+	Vault     *verification_persistence.ShadowVault
+	Simulator *Simulator
+
+	bus *runtime_bus.MessageBus
+}
+
+// Status is the sandbox summary for health output and the HMI.
+type Status struct {
+	Active         bool      `json:"active"`
+	SessionID      string    `json:"session_id"`
+	UserID         string    `json:"user_id"`
+	Since          time.Time `json:"since"`
+	DivertedTopics []string  `json:"diverted_topics"`
+	Simulated      int       `json:"simulated_messages"`
+	ShadowWrites   int       `json:"shadow_writes"`
+	EgressBlocked  bool      `json:"egress_blocked"`
+}
+
+var (
+	activeMu sync.Mutex
+	active   *Sandbox
+)
+
+// Enter switches the process into sandbox mode for session. vault is the
+// real store the overlay sits on; bus may be nil before the runtime exists.
+func Enter(session *user_setting.UserSession, vault verification_persistence.VaultStore, bus *runtime_bus.MessageBus) (*Sandbox, error) {
+	if !session.IsSandbox() {
+		return nil, ErrNotSandboxSession
+	}
+
+	activeMu.Lock()
+	defer activeMu.Unlock()
+
+	if active != nil {
+		return nil, ErrSandboxActive
+	}
+
+	sb := &Sandbox{
+		SessionID: session.Claims.SessionID,
+		UserID:    session.Claims.UserID,
+		Since:     time.Now().UTC(),
+		Vault:     verification_persistence.NewShadowVault(vault),
+	}
+	sb.Simulator = NewSimulator(bus)
+	sb.Attach(bus)
+	blockEgress()
+
+	active = sb
+	logging.Info("[SANDBOX] entered for %s (session %s)", sb.UserID, sb.SessionID)
+	return sb, nil
+}
+
+// Attach diverts the actuator topics of bus to the simulator, for a bus
+// created after Enter.
+func (s *Sandbox) Attach(bus *runtime_bus.MessageBus) {
+	if bus == nil {
+		return
+	}
+	s.bus = bus
+	s.Simulator.setBus(bus)
+	bus.Divert(runtime_bus.ActuatorTopics, s.Simulator.Receive)
+}
+
+// Exit restores actuators and network and discards every vault change
+// made during the session.
+func (s *Sandbox) Exit() {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+
+	if active != s {
+		return
+	}
+
+	if s.bus != nil {
+		s.bus.Restore()
+	}
+	allowEgress()
+
+	discarded := s.Vault.Changes()
+	s.Vault.Discard()
+
+	active = nil
+	logging.Info("[SANDBOX] exited for %s, %d shadow writes discarded", s.UserID, discarded)
+}
+
+// Status summarizes the sandbox.
+func (s *Sandbox) Status() Status {
+	st := Status{
+		Active:        true,
+		SessionID:     s.SessionID,
+		UserID:        s.UserID,
+		Since:         s.Since,
+		Simulated:     s.Simulator.Count(),
+		ShadowWrites:  s.Vault.Changes(),
+		EgressBlocked: EgressBlocked(),
+	}
+	if s.bus != nil {
+		st.DivertedTopics = s.bus.Diverted()
+	}
+	return st
+}
+
+// Active returns the running sandbox, or nil.
+func Active() *Sandbox {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+	return active
+}
+
+// ExitSession leaves sandbox mode if sessionID owns it and discards the
+// shadow vault of a per-request sandbox session; logout paths call it
+// without knowing whether a sandbox runs.
+func ExitSession(sessionID string) {
+	if sb := Active(); sb != nil && sb.SessionID == sessionID {
+		sb.Exit()
+	}
+	endSession(sessionID)
+}
@@ -0,0 +1,191 @@
+//core/security/sandbox/sandbox_test.go
+
+package security_sandbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+	runtime_bus "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/bus"
+)
+
+// unroutable is a documentation address (RFC 5737): a dial that got past
+// the checks would fail rather than reach anything.
+const unroutable = "192.0.2.1:80"
+
+func testVault(t *testing.T) verification_persistence.VaultStore {
+	t.Helper()
+	return &verification_persistence.IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{1}, 32)}
+}
+
+func testerSession(id string) *user_setting.UserSession {
+	return &user_setting.UserSession{Claims: user_setting.SessionClaims{SessionID: id, UserID: "mechanic", Sandbox: true}}
+}
+
+// received reports whether ch got a message within a short wait.
+func received(ch chan runtime_bus.Message) bool {
+	select {
+	case <-ch:
+		return true
+	case <-time.After(50 * time.Millisecond):
+		return false
+	}
+}
+
+func TestActuatorTopicsGoToTheSimulator(t *testing.T) {
+	bus := runtime_bus.NewMessageBus()
+	wheels := bus.Subscribe("vehicle_control")
+	telemetry := bus.Subscribe("telemetry")
+	simulated := bus.Subscribe(TopicSimulated)
+
+	if _, err := Enter(&user_setting.UserSession{}, testVault(t), bus); !errors.Is(err, ErrNotSandboxSession) {
+		t.Fatalf("Enter for a regular session = %v, want %v", err, ErrNotSandboxSession)
+	}
+
+	sb, err := Enter(testerSession("s-1"), testVault(t), bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sb.Exit()
+
+	if _, err := Enter(testerSession("s-2"), testVault(t), bus); !errors.Is(err, ErrSandboxActive) {
+		t.Errorf("second Enter = %v, want %v", err, ErrSandboxActive)
+	}
+
+	bus.Publish(runtime_bus.Message{Topic: "vehicle_control", Data: []byte(`{"throttle":1}`)})
+	if received(wheels) {
+		t.Fatal("actuator command reached its subscriber in the sandbox")
+	}
+	if !received(simulated) || sb.Simulator.Count() != 1 {
+		t.Fatalf("simulator absorbed %d commands", sb.Simulator.Count())
+	}
+	if h := sb.Simulator.History(); len(h) != 1 || h[0].Topic != "vehicle_control" || string(h[0].Data) != `{"throttle":1}` {
+		t.Errorf("history = %+v", h)
+	}
+
+	bus.Publish(runtime_bus.Message{Topic: "telemetry", Data: []byte("ok")})
+	if !received(telemetry) {
+		t.Error("non-actuator topic diverted")
+	}
+
+	sb.Exit()
+	if Active() != nil {
+		t.Fatal("sandbox still active after Exit")
+	}
+	bus.Publish(runtime_bus.Message{Topic: "vehicle_control", Data: []byte(`{"throttle":0}`)})
+	if !received(wheels) {
+		t.Error("actuator topic still diverted after Exit")
+	}
+}
+
+func TestSandboxBlocksEgress(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	sb, err := Enter(testerSession("s-1"), testVault(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sb.Exit()
+
+	if _, err := Dial(context.Background(), "tcp", unroutable); !errors.Is(err, ErrEgressBlocked) {
+		t.Errorf("outbound dial = %v, want %v", err, ErrEgressBlocked)
+	}
+	conn, err := Dial(context.Background(), "tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("loopback dial = %v", err)
+	}
+	conn.Close()
+
+	sb.Exit()
+	if EgressBlocked() {
+		t.Error("egress still blocked after Exit")
+	}
+}
+
+func TestScopeBlocksEgressOfSandboxRequests(t *testing.T) {
+	var dialErr error
+	var marked bool
+	h := Scope(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		marked = sandboxed(r.Context())
+		if marked {
+			_, dialErr = Dial(r.Context(), "tcp", unroutable)
+		}
+	}))
+
+	serve := func(claims *user_setting.SessionClaims) {
+		r := httptest.NewRequest(http.MethodGet, "/api/x", nil)
+		r = r.WithContext(verification_identity.WithSession(r.Context(), claims))
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	serve(&user_setting.SessionClaims{SessionID: "s-1", Sandbox: true})
+	if !marked || !errors.Is(dialErr, ErrEgressBlocked) {
+		t.Errorf("sandbox request: marked %v, dial %v", marked, dialErr)
+	}
+	if EgressBlocked() {
+		t.Error("a per-request sandbox blocked egress for the process")
+	}
+
+	serve(&user_setting.SessionClaims{SessionID: "s-2"})
+	if marked {
+		t.Error("regular request marked as sandboxed")
+	}
+}
+
+func TestExitSessionDiscardsTheShadowVault(t *testing.T) {
+	base := testVault(t)
+	claims := &user_setting.SessionClaims{SessionID: "s-1", Sandbox: true}
+
+	if VaultFor(&user_setting.SessionClaims{SessionID: "s-2"}, base) != base {
+		t.Fatal("regular session given a shadow vault")
+	}
+
+	shadow := VaultFor(claims, base)
+	if err := shadow.Write("users", "mallory", map[string]string{"id": "mallory"}); err != nil {
+		t.Fatal(err)
+	}
+	if VaultFor(claims, base) != shadow {
+		t.Fatal("second request of the session got another shadow")
+	}
+	if exists, _ := base.Exists("users", "mallory"); exists {
+		t.Fatal("sandbox write reached the real vault")
+	}
+
+	ExitSession(claims.SessionID)
+	if exists, _ := VaultFor(claims, base).Exists("users", "mallory"); exists {
+		t.Error("shadow vault kept after ExitSession")
+	}
+	ExitSession(claims.SessionID)
+
+	// Process-wide sandbox mode
+	sb, err := Enter(testerSession("s-3"), base, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sb.Vault.Write("users", "mallory", map[string]string{"id": "mallory"}); err != nil {
+		t.Fatal(err)
+	}
+	ExitSession("s-3")
+	if Active() != nil {
+		t.Fatal("ExitSession left sandbox mode running")
+	}
+	if sb.Vault.Changes() != 0 {
+		t.Errorf("%d shadow writes kept after ExitSession", sb.Vault.Changes())
+	}
+	if exists, _ := base.Exists("users", "mallory"); exists {
+		t.Error("sandbox write reached the real vault")
+	}
+}
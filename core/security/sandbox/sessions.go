@@ -0,0 +1,102 @@
+//core/security/sandbox/sessions.go
+
+package security_sandbox
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+	runtime_bus "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/bus"
+)
+
+// A sandbox session that logs in over the API while the unit runs
+// normally cannot switch the whole process into sandbox mode. It is
+// sandboxed per request instead: it writes to a shadow vault of its own,
+// its actuator commands go to a shared simulator, and Dial refuses the
+// outbound connections of its requests.
+
+var (
+	sessionsMu    sync.Mutex
+	sessionVaults = map[string]*verification_persistence.ShadowVault{}
+	sessionSim    = NewSimulator(nil)
+)
+
+// VaultFor returns the store claims may write to: base for a regular
+// session, the shadow of base kept until ExitSession for a sandbox session.
+// In process-wide sandbox mode base already is the shadow.
+func VaultFor(claims *user_setting.SessionClaims, base verification_persistence.VaultStore) verification_persistence.VaultStore {
+	if claims == nil || !claims.Sandbox {
+		return base
+	}
+	if sb := Active(); sb != nil {
+		return sb.Vault
+	}
+
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	v, ok := sessionVaults[claims.SessionID]
+	if !ok {
+		v = verification_persistence.NewShadowVault(base)
+		sessionVaults[claims.SessionID] = v
+	}
+	return v
+}
+
+// AttachSessions publishes what the simulator of per-request sandbox
+// sessions absorbs on bus.
+func AttachSessions(bus *runtime_bus.MessageBus) {
+	sessionSim.setBus(bus)
+}
+
+// Simulate absorbs an actuator command issued by a sandbox session in
+// place of dispatching it.
+func Simulate(topic string, data []byte) {
+	msg := runtime_bus.Message{Topic: topic, Data: data}
+	if sb := Active(); sb != nil {
+		sb.Simulator.Receive(msg)
+		return
+	}
+	sessionSim.Receive(msg)
+}
+
+// endSession discards the shadow vault of a per-request sandbox session.
+func endSession(sessionID string) {
+	sessionsMu.Lock()
+	v, ok := sessionVaults[sessionID]
+	delete(sessionVaults, sessionID)
+	sessionsMu.Unlock()
+
+	if ok {
+		v.Discard()
+	}
+}
+
+type sessionKey struct{}
+
+// WithSession marks ctx as acting for claims. Dial refuses outbound
+// connections made with the context of a sandbox session.
+func WithSession(ctx context.Context, claims *user_setting.SessionClaims) context.Context {
+	if claims == nil || !claims.Sandbox {
+		return ctx
+	}
+	return context.WithValue(ctx, sessionKey{}, claims.SessionID)
+}
+
+func sandboxed(ctx context.Context) bool {
+	_, ok := ctx.Value(sessionKey{}).(string)
+	return ok
+}
+
+// Scope applies WithSession to every request of a verified session; mount
+// it inside verification_identity.RequireSession.
+func Scope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := verification_identity.SessionFromContext(r.Context())
+		next.ServeHTTP(w, r.WithContext(WithSession(r.Context(), claims)))
+	})
+}
@@ -0,0 +1,85 @@
+//core/security/sandbox/simulator.go
+
+package security_sandbox
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	runtime_bus "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/bus"
+)
+
+// TopicSimulated carries every actuator command the simulator absorbed, so
+// test tooling can assert on what would have moved.
+const TopicSimulated = "sandbox.simulated"
+
+// simulatorHistory bounds the commands kept for inspection.
+const simulatorHistory = 256
+
+// SimulatedCommand is one diverted actuator message.
+type SimulatedCommand struct {
+	Topic string          `json:"topic"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Raw   []byte          `json:"raw,omitempty"` // when Data is not JSON
+	At    time.Time       `json:"at"`
+}
+
+// Simulator is the sink of diverted actuator topics. It acknowledges every
+// command without touching hardware and keeps the recent ones.
+type Simulator struct {
+	bus *runtime_bus.MessageBus
+
+	mu      sync.Mutex
+	count   int
+	history []SimulatedCommand
+}
+
+// NewSimulator creates a sink re-publishing on bus, which may be nil.
+func NewSimulator(bus *runtime_bus.MessageBus) *Simulator {
+	return &Simulator{bus: bus}
+}
+
+func (s *Simulator) setBus(bus *runtime_bus.MessageBus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bus = bus
+}
+
+// Receive absorbs one actuator message.
+func (s *Simulator) Receive(msg runtime_bus.Message) {
+	cmd := SimulatedCommand{Topic: msg.Topic, At: time.Now().UTC()}
+	if json.Valid(msg.Data) {
+		cmd.Data = msg.Data
+	} else {
+		cmd.Raw = msg.Data
+	}
+
+	s.mu.Lock()
+	s.count++
+	s.history = append(s.history, cmd)
+	if len(s.history) > simulatorHistory {
+		s.history = s.history[len(s.history)-simulatorHistory:]
+	}
+	bus := s.bus
+	s.mu.Unlock()
+
+	if bus != nil {
+		data, _ := json.Marshal(cmd)
+		bus.Publish(runtime_bus.Message{Topic: TopicSimulated, Data: data})
+	}
+}
+
+// Count is the number of commands absorbed so far.
+func (s *Simulator) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// History returns the most recent commands, oldest first.
+func (s *Simulator) History() []SimulatedCommand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SimulatedCommand(nil), s.history...)
+}
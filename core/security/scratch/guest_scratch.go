@@ -0,0 +1,141 @@
+//core/security/scratch/guest_scratch.go
+
+package security_scratch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/pkg/logging"
+)
+
+// Root holds one directory per guest session. It lives outside the vault
+// and the user data directories so nothing a guest writes can reach them.
+var Root = filepath.Join(os.TempDir(), "aios-guest")
+
+// Area is the scratch directory of one guest session.
+type Area struct {
+	SessionID string
+	path      string
+}
+
+// expiresFile records when the session owning an area expires, so Sweep
+// can wipe it even if the session never logs out.
+const expiresFile = ".expires"
+
+// Open creates the scratch area for sessionID, readable only by this
+// process's user, to be wiped once expires has passed. An existing area for
+// the same session is reused.
+func Open(sessionID string, expires time.Time) (*Area, error) {
+	dir, err := areaPath(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(Root, 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.Mkdir(dir, 0o700); err != nil && !errors.Is(err, os.ErrExist) {
+		return nil, err
+	}
+	stamp := []byte(expires.UTC().Format(time.RFC3339))
+	if err := os.WriteFile(filepath.Join(dir, expiresFile), stamp, 0o600); err != nil {
+		return nil, err
+	}
+
+	return &Area{SessionID: sessionID, path: dir}, nil
+}
+
+// Path is the directory guests may write to.
+func (a *Area) Path() string {
+	return a.path
+}
+
+// Vault overlays base with records kept in the area, so everything a guest
+// session stores vanishes when the area is wiped.
+func (a *Area) Vault(base verification_persistence.VaultStore) (*verification_persistence.ShadowVault, error) {
+	return verification_persistence.NewDirShadowVault(base, filepath.Join(a.path, "vault"))
+}
+
+// Wipe removes everything in the area.
+func (a *Area) Wipe() error {
+	return Wipe(a.SessionID)
+}
+
+// Wipe removes the scratch area of sessionID, if any.
+func Wipe(sessionID string) error {
+	dir, err := areaPath(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	logging.Info("[SCRATCH] guest area wiped for session %s", sessionID)
+	return nil
+}
+
+// Sweep wipes the areas whose session expired before now, and areas with
+// no readable expiry. It returns how many it removed.
+func Sweep(now time.Time) (int, error) {
+	entries, err := os.ReadDir(Root)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		stamp, err := os.ReadFile(filepath.Join(Root, e.Name(), expiresFile))
+		if err == nil {
+			if expires, perr := time.Parse(time.RFC3339, string(stamp)); perr == nil && now.Before(expires) {
+				continue
+			}
+		}
+		if err := Wipe(e.Name()); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Watch sweeps expired areas every interval until ctx ends.
+func Watch(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			if _, err := Sweep(now); err != nil {
+				logging.Warn("[SCRATCH] sweep failed: %v", err)
+			}
+		}
+	}
+}
+
+// WipeAll removes areas left behind by sessions that never logged out,
+// e.g. after a crash. Call it at boot before any guest logs in.
+func WipeAll() error {
+	return os.RemoveAll(Root)
+}
+
+func areaPath(sessionID string) (string, error) {
+	if sessionID == "" || strings.ContainsAny(sessionID, `/\.`) {
+		return "", errors.New("invalid session id for scratch area")
+	}
+	return filepath.Join(Root, sessionID), nil
+}
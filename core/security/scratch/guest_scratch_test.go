@@ -0,0 +1,135 @@
+//core/security/scratch/guest_scratch_test.go
+
+package security_scratch
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+func useRoot(t *testing.T) {
+	t.Helper()
+	saved := Root
+	Root = filepath.Join(t.TempDir(), "aios-guest")
+	t.Cleanup(func() { Root = saved })
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestAreasAreIsolatedPerSession(t *testing.T) {
+	useRoot(t)
+	base := &verification_persistence.IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{1}, 32)}
+	expires := time.Now().Add(time.Hour)
+
+	a, err := Open("s1", expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Open("s2", expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Path() == b.Path() {
+		t.Fatal("two sessions share a scratch directory")
+	}
+	if info, err := os.Stat(a.Path()); err != nil || info.Mode().Perm() != 0o700 {
+		t.Errorf("area mode = %v, %v; want 0700", info.Mode().Perm(), err)
+	}
+
+	va, err := a.Vault(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vb, err := b.Vault(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := va.Write("notes", "n1", map[string]string{"text": "guest a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var out map[string]string
+	if found, _ := vb.Read("notes", "n1", &out); found {
+		t.Error("one guest session reads another's records")
+	}
+	if found, _ := base.Read("notes", "n1", &out); found {
+		t.Error("guest record reached the base vault")
+	}
+
+	for _, bad := range []string{"", "..", "../s1", "a/b", `a\b`} {
+		if _, err := Open(bad, expires); err == nil {
+			t.Errorf("Open(%q) accepted", bad)
+		}
+	}
+}
+
+func TestWipeOnLogout(t *testing.T) {
+	useRoot(t)
+
+	a, err := Open("s1", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := Open("s2", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(a.Path(), "download.bin"), []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.Wipe(); err != nil {
+		t.Fatal(err)
+	}
+	if exists(a.Path()) {
+		t.Error("area survived Wipe")
+	}
+	if !exists(other.Path()) {
+		t.Error("Wipe removed another session's area")
+	}
+	if err := Wipe("s1"); err != nil {
+		t.Errorf("second Wipe: %v", err)
+	}
+}
+
+func TestSweepWipesExpiredSessions(t *testing.T) {
+	useRoot(t)
+	now := time.Now()
+
+	expired, err := Open("old", now.Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	live, err := Open("live", now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An area from before expiries were recorded has nothing to go by.
+	unknown := filepath.Join(Root, "unknown")
+	if err := os.Mkdir(unknown, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := Sweep(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || exists(expired.Path()) || exists(unknown) {
+		t.Errorf("Sweep removed %d; expired left: %v, unknown left: %v", n, exists(expired.Path()), exists(unknown))
+	}
+	if !exists(live.Path()) {
+		t.Error("Sweep removed a live session's area")
+	}
+
+	if n, err := Sweep(now.Add(2 * time.Hour)); err != nil || n != 1 || exists(live.Path()) {
+		t.Errorf("after expiry: removed %d, %v", n, err)
+	}
+}
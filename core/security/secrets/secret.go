@@ -0,0 +1,55 @@
+//core/security/secrets/secret.go
+
+package security_secrets
+
+import (
+	"crypto/subtle"
+	"fmt"
+)
+
+const redacted = "[REDACTED]"
+
+// Secret holds a sensitive value. Every formatting and encoding path
+// prints a placeholder, so a Secret that ends up in a log line, a %+v
+// dump or a JSON document does not leak. Use Reveal at the point of use.
+type Secret struct {
+	value []byte
+}
+
+func NewSecret(value []byte) Secret {
+	return Secret{value: append([]byte(nil), value...)}
+}
+
+// Reveal returns a copy of the secret bytes.
+func (s Secret) Reveal() []byte {
+	return append([]byte(nil), s.value...)
+}
+
+// RevealString returns the secret as a string, e.g. for a broker password.
+func (s Secret) RevealString() string {
+	return string(s.value)
+}
+
+func (s Secret) IsZero() bool {
+	return len(s.value) == 0
+}
+
+// Equal compares in constant time.
+func (s Secret) Equal(other Secret) bool {
+	return subtle.ConstantTimeCompare(s.value, other.value) == 1
+}
+
+func (s Secret) String() string   { return redacted }
+func (s Secret) GoString() string { return redacted }
+
+func (s Secret) Format(f fmt.State, _ rune) {
+	f.Write([]byte(redacted))
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
@@ -0,0 +1,207 @@
+//core/security/secrets/secret_store.go
+
+package security_secrets
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/pkg/logging"
+)
+
+const secretsCollection = "secrets"
+
+var (
+	ErrSecretNotFound = errors.New("secret_not_found")
+	ErrSecretDenied   = errors.New("secret_access_denied")
+)
+
+// Access outcomes reported to the audit sink.
+const (
+	AccessGranted = "granted"
+	AccessDenied  = "denied"
+	AccessMissing = "missing"
+)
+
+// record is the stored form of a secret. The whole record, access list
+// included, is sealed under the vault key so the list cannot be edited on
+// disk without detection.
+type record struct {
+	Name           string    `json:"name"`
+	Value          []byte    `json:"value"`
+	AllowedModules []string  `json:"allowed_modules"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// SecretInfo describes a secret without its value.
+type SecretInfo struct {
+	Name           string    `json:"name"`
+	AllowedModules []string  `json:"allowed_modules"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// AccessEvent is emitted for every secret read, allowed or not.
+type AccessEvent struct {
+	Module string    `json:"module"`
+	Secret string    `json:"secret"`
+	Result string    `json:"result"`
+	At     time.Time `json:"at"`
+}
+
+// AuditSink receives AccessEvents. It must not block.
+type AuditSink func(AccessEvent)
+
+// Store keeps module secrets in the vault.
+type Store struct {
+	vault verification_persistence.VaultStore
+	audit AuditSink
+}
+
+func NewStore(vault verification_persistence.VaultStore, audit AuditSink) *Store {
+	if audit == nil {
+		audit = logAccess
+	}
+	return &Store{vault: vault, audit: audit}
+}
+
+func logAccess(ev AccessEvent) {
+	logging.Info("[SECRETS] module=%s secret=%s result=%s", ev.Module, ev.Secret, ev.Result)
+}
+
+// Put creates or replaces a secret readable by allowedModules.
+func (s *Store) Put(name string, value []byte, allowedModules []string) error {
+	if name == "" {
+		return errors.New("secret name required")
+	}
+
+	return verification_persistence.Atomically(s.vault, func(tx verification_persistence.VaultTx) error {
+		now := time.Now().UTC()
+
+		var rec record
+		found, err := verification_persistence.ReadSealed(tx, secretsCollection, name, &rec)
+		if err != nil {
+			return err
+		}
+		if !found {
+			rec.CreatedAt = now
+		}
+
+		rec.Name = name
+		rec.Value = value
+		rec.AllowedModules = append([]string(nil), allowedModules...)
+		rec.UpdatedAt = now
+
+		return verification_persistence.WriteSealed(tx, secretsCollection, name, rec)
+	})
+}
+
+// Grant replaces the list of modules allowed to read name.
+func (s *Store) Grant(name string, allowedModules []string) error {
+	return verification_persistence.Atomically(s.vault, func(tx verification_persistence.VaultTx) error {
+		var rec record
+		found, err := verification_persistence.ReadSealed(tx, secretsCollection, name, &rec)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+		}
+
+		rec.AllowedModules = append([]string(nil), allowedModules...)
+		rec.UpdatedAt = time.Now().UTC()
+
+		return verification_persistence.WriteSealed(tx, secretsCollection, name, rec)
+	})
+}
+
+func (s *Store) Delete(name string) error {
+	return s.vault.Delete(secretsCollection, name)
+}
+
+// List returns every secret's name and access list, never its value.
+func (s *Store) List() ([]SecretInfo, error) {
+	names, err := s.vault.List(secretsCollection)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	out := make([]SecretInfo, 0, len(names))
+	for _, name := range names {
+		var rec record
+		if _, err := verification_persistence.ReadSealed(s.vault, secretsCollection, name, &rec); err != nil {
+			return nil, fmt.Errorf("secret %s unreadable: %w", name, err)
+		}
+		out = append(out, SecretInfo{
+			Name:           rec.Name,
+			AllowedModules: rec.AllowedModules,
+			UpdatedAt:      rec.UpdatedAt,
+		})
+	}
+
+	return out, nil
+}
+
+// ForModule returns the accessor handed to module through its runtime
+// context. The module name is fixed here, by the runtime, not by the caller
+// of Get.
+func (s *Store) ForModule(module string) *ModuleSecrets {
+	return &ModuleSecrets{store: s, module: module}
+}
+
+func (s *Store) get(module, name string) (Secret, error) {
+	var rec record
+	found, err := verification_persistence.ReadSealed(s.vault, secretsCollection, name, &rec)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	ev := AccessEvent{Module: module, Secret: name, At: time.Now().UTC()}
+
+	if !found {
+		ev.Result = AccessMissing
+		s.audit(ev)
+		return Secret{}, fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+
+	if !allowed(rec.AllowedModules, module) {
+		ev.Result = AccessDenied
+		s.audit(ev)
+		return Secret{}, fmt.Errorf("%w: %s may not read %s", ErrSecretDenied, module, name)
+	}
+
+	ev.Result = AccessGranted
+	s.audit(ev)
+	return NewSecret(rec.Value), nil
+}
+
+func allowed(list []string, module string) bool {
+	for _, m := range list {
+		if m == module {
+			return true
+		}
+	}
+	return false
+}
+
+// ModuleSecrets is a module's view of the secret store.
+type ModuleSecrets struct {
+	store  *Store
+	module string
+}
+
+func (m *ModuleSecrets) Module() string {
+	return m.module
+}
+
+// Get returns the named secret if this module is on its access list.
+func (m *ModuleSecrets) Get(name string) (Secret, error) {
+	if m == nil || m.store == nil {
+		return Secret{}, errors.New("secret store not available")
+	}
+	return m.store.get(m.module, name)
+}
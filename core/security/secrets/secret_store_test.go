@@ -0,0 +1,61 @@
+//core/security/secrets/secret_store_test.go
+
+package security_secrets
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+func TestModuleSecretsScopedToAccessList(t *testing.T) {
+	vault := &verification_persistence.IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{1}, 32)}
+
+	var events []AccessEvent
+	store := NewStore(vault, func(ev AccessEvent) { events = append(events, ev) })
+
+	if err := store.Put("can_key", []byte("s3cret"), []string{"IndustrialProtocol"}); err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := store.ForModule("IndustrialProtocol").Get("can_key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret.Reveal()) != "s3cret" {
+		t.Error("allowed module read the wrong value")
+	}
+
+	if _, err := store.ForModule("Telemetry").Get("can_key"); !errors.Is(err, ErrSecretDenied) {
+		t.Errorf("other module = %v, want %v", err, ErrSecretDenied)
+	}
+	if _, err := store.ForModule("Telemetry").Get("missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("missing secret = %v, want %v", err, ErrSecretNotFound)
+	}
+
+	want := []string{AccessGranted, AccessDenied, AccessMissing}
+	if len(events) != len(want) {
+		t.Fatalf("audited %d accesses, want %d", len(events), len(want))
+	}
+	for i, ev := range events {
+		if ev.Result != want[i] {
+			t.Errorf("access %d audited as %s, want %s", i, ev.Result, want[i])
+		}
+	}
+
+	if err := store.Grant("can_key", []string{"Telemetry"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.ForModule("IndustrialProtocol").Get("can_key"); !errors.Is(err, ErrSecretDenied) {
+		t.Errorf("revoked module = %v, want %v", err, ErrSecretDenied)
+	}
+}
+
+func TestNilModuleSecrets(t *testing.T) {
+	var m *ModuleSecrets
+	if _, err := m.Get("any"); err == nil {
+		t.Fatal("nil accessor returned a secret")
+	}
+}
@@ -0,0 +1,235 @@
+//core/security/totp/enrollment.go
+
+package security_totp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+const (
+	totpCollection = "totp"
+
+	secretSize         = 20
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+var (
+	ErrNotEnrolled      = errors.New("totp_not_enrolled")
+	ErrNotConfirmed     = errors.New("totp_enrollment_unconfirmed")
+	ErrInvalidCode      = errors.New("totp_code_invalid")
+	ErrCodeReplayed     = errors.New("totp_code_replayed")
+	ErrInvalidRecovery  = errors.New("totp_recovery_code_invalid")
+	ErrAlreadyConfirmed = errors.New("totp_already_enrolled")
+)
+
+// record is stored sealed under the vault key.
+type record struct {
+	Secret     []byte    `json:"secret"`
+	Confirmed  bool      `json:"confirmed"`
+	LastStep   int64     `json:"last_step"`
+	Recovery   []string  `json:"recovery"` // SHA-256 of unused recovery codes
+	EnrolledAt time.Time `json:"enrolled_at"`
+}
+
+// Enrollment is returned once, at enrollment time. Neither the secret nor
+// the recovery codes can be retrieved later.
+type Enrollment struct {
+	URI           string   `json:"uri"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// Enroll creates a new secret for userID. The enrollment is pending until
+// Confirm sees a valid code, so a user cannot lock themselves out by
+// mis-scanning the QR code. Re-enrolling a confirmed user requires reset.
+func Enroll(v verification_persistence.VaultStore, userID, issuer string) (*Enrollment, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = verification_persistence.Atomically(v, func(tx verification_persistence.VaultTx) error {
+		var existing record
+		found, err := verification_persistence.ReadSealed(tx, totpCollection, userID, &existing)
+		if err != nil {
+			return err
+		}
+		if found && existing.Confirmed {
+			return ErrAlreadyConfirmed
+		}
+
+		return verification_persistence.WriteSealed(tx, totpCollection, userID, record{
+			Secret:     secret,
+			Recovery:   hashes,
+			EnrolledAt: time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Enrollment{
+		URI:           URI(issuer, userID, secret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// Confirm activates a pending enrollment with the first code from the
+// user's authenticator.
+func Confirm(v verification_persistence.VaultStore, userID, code string) error {
+	return update(v, userID, func(r *record) error {
+		if r.Confirmed {
+			return ErrAlreadyConfirmed
+		}
+
+		step, ok := match(r.Secret, code, time.Now())
+		if !ok {
+			return ErrInvalidCode
+		}
+
+		r.Confirmed = true
+		r.LastStep = step
+		return nil
+	})
+}
+
+// Enrolled reports whether userID has a confirmed second factor.
+func Enrolled(v verification_persistence.VaultStore, userID string) (bool, error) {
+	var r record
+	found, err := verification_persistence.ReadSealed(v, totpCollection, userID, &r)
+	if err != nil {
+		return false, err
+	}
+	return found && r.Confirmed, nil
+}
+
+// Verify checks code for userID. Each time step is accepted at most once,
+// so an observed code cannot be replayed inside its window.
+func Verify(v verification_persistence.VaultStore, userID, code string) error {
+	return update(v, userID, func(r *record) error {
+		if !r.Confirmed {
+			return ErrNotConfirmed
+		}
+
+		step, ok := match(r.Secret, code, time.Now())
+		if !ok {
+			return ErrInvalidCode
+		}
+		if step <= r.LastStep {
+			return ErrCodeReplayed
+		}
+
+		r.LastStep = step
+		return nil
+	})
+}
+
+// UseRecoveryCode accepts one of the enrollment recovery codes in place of
+// a TOTP code. Each code works once. It returns how many remain.
+func UseRecoveryCode(v verification_persistence.VaultStore, userID, code string) (int, error) {
+	var remaining int
+
+	err := update(v, userID, func(r *record) error {
+		if !r.Confirmed {
+			return ErrNotConfirmed
+		}
+
+		sum := hashRecovery(code)
+		idx := -1
+		for i, h := range r.Recovery {
+			if subtle.ConstantTimeCompare([]byte(h), []byte(sum)) == 1 {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			return ErrInvalidRecovery
+		}
+
+		r.Recovery = append(r.Recovery[:idx], r.Recovery[idx+1:]...)
+		remaining = len(r.Recovery)
+		return nil
+	})
+
+	return remaining, err
+}
+
+// Reset removes the user's second factor, e.g. after a lost device.
+func Reset(v verification_persistence.VaultStore, userID string) error {
+	return v.Delete(totpCollection, userID)
+}
+
+// ResetTx is Reset inside a caller's transaction.
+func ResetTx(tx verification_persistence.VaultTx, userID string) error {
+	return tx.Delete(totpCollection, userID)
+}
+
+func update(v verification_persistence.VaultStore, userID string, fn func(r *record) error) error {
+	return verification_persistence.Atomically(v, func(tx verification_persistence.VaultTx) error {
+		var r record
+		found, err := verification_persistence.ReadSealed(tx, totpCollection, userID, &r)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrNotEnrolled
+		}
+
+		if err := fn(&r); err != nil {
+			return err
+		}
+
+		return verification_persistence.WriteSealed(tx, totpCollection, userID, r)
+	})
+}
+
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	buf := make([]byte, recoveryCodeLength)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+
+		var b strings.Builder
+		for j, c := range buf {
+			if j == recoveryCodeLength/2 {
+				b.WriteByte('-')
+			}
+			b.WriteByte(recoveryAlphabet[int(c)%len(recoveryAlphabet)])
+		}
+
+		codes[i] = b.String()
+		hashes[i] = hashRecovery(codes[i])
+	}
+
+	return codes, hashes, nil
+}
+
+func hashRecovery(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// String keeps recovery codes out of log output.
+func (e *Enrollment) String() string {
+	return fmt.Sprintf("totp enrollment (%d recovery codes)", len(e.RecoveryCodes))
+}
@@ -0,0 +1,151 @@
+//core/security/totp/totp_test.go
+
+package security_totp
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+)
+
+func testVault(t *testing.T) verification_persistence.VaultStore {
+	t.Helper()
+	return &verification_persistence.IsolatedVault{BaseDir: t.TempDir(), Key: bytes.Repeat([]byte{1}, 32)}
+}
+
+// enroll enrolls and confirms userID, returning the secret read back from
+// the otpauth URI the way an authenticator app would.
+func enroll(t *testing.T, v verification_persistence.VaultStore, userID string) ([]byte, *Enrollment) {
+	t.Helper()
+	e, err := Enroll(v, userID, "AIOS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(e.URI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := b32.DecodeString(u.Query().Get("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Confirm(v, userID, Code(secret, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	return secret, e
+}
+
+func TestCodeMatchesRFC6238(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	for unix, want := range map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	} {
+		if got := Code(secret, time.Unix(unix, 0)); got != want {
+			t.Errorf("Code at %d = %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestMatchAcceptsOneStepOfSkew(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1111111109, 0)
+	base := counter(now)
+
+	for d := -Skew; d <= Skew; d++ {
+		code := Code(secret, now.Add(time.Duration(d)*Period))
+		step, ok := match(secret, code, now)
+		if !ok || step != base+int64(d) {
+			t.Errorf("offset %d: step %d, %v; want %d", d, step, ok, base+int64(d))
+		}
+	}
+
+	for _, d := range []int{-Skew - 1, Skew + 1} {
+		if _, ok := match(secret, Code(secret, now.Add(time.Duration(d)*Period)), now); ok {
+			t.Errorf("code %d periods away accepted", d)
+		}
+	}
+
+	code := Code(secret, now)
+	if _, ok := match(secret, code[:3]+" "+code[3:], now); !ok {
+		t.Error("grouped code rejected")
+	}
+	if _, ok := match(secret, code[:5], now); ok {
+		t.Error("short code accepted")
+	}
+}
+
+func TestVerifyRejectsReplay(t *testing.T) {
+	v := testVault(t)
+	secret, _ := enroll(t, v, "alice")
+
+	// Confirm consumed the current step.
+	now := time.Now()
+	if err := Verify(v, "alice", Code(secret, now)); !errors.Is(err, ErrCodeReplayed) {
+		t.Fatalf("code used by Confirm = %v, want %v", err, ErrCodeReplayed)
+	}
+
+	next := Code(secret, now.Add(Period))
+	if err := Verify(v, "alice", next); err != nil {
+		t.Fatalf("next step = %v", err)
+	}
+	if err := Verify(v, "alice", next); !errors.Is(err, ErrCodeReplayed) {
+		t.Errorf("second use = %v, want %v", err, ErrCodeReplayed)
+	}
+	if err := Verify(v, "alice", Code(secret, now.Add(-Period))); !errors.Is(err, ErrCodeReplayed) {
+		t.Errorf("earlier step after a later one = %v, want %v", err, ErrCodeReplayed)
+	}
+	if err := Verify(v, "alice", "000000"); !errors.Is(err, ErrInvalidCode) && !errors.Is(err, ErrCodeReplayed) {
+		t.Errorf("wrong code = %v", err)
+	}
+	if err := Verify(v, "bob", next); !errors.Is(err, ErrNotEnrolled) {
+		t.Errorf("unknown user = %v, want %v", err, ErrNotEnrolled)
+	}
+}
+
+func TestPendingEnrollmentIsNotASecondFactor(t *testing.T) {
+	v := testVault(t)
+	e, err := Enroll(v, "alice", "AIOS")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, _ := Enrolled(v, "alice"); ok {
+		t.Error("unconfirmed enrollment reported as enrolled")
+	}
+	if _, err := UseRecoveryCode(v, "alice", e.RecoveryCodes[0]); !errors.Is(err, ErrNotConfirmed) {
+		t.Errorf("recovery before confirm = %v, want %v", err, ErrNotConfirmed)
+	}
+}
+
+func TestRecoveryCodesAreSingleUse(t *testing.T) {
+	v := testVault(t)
+	_, e := enroll(t, v, "alice")
+	if len(e.RecoveryCodes) != recoveryCodeCount {
+		t.Fatalf("%d recovery codes", len(e.RecoveryCodes))
+	}
+	if strings.Contains(e.String(), e.RecoveryCodes[0]) {
+		t.Error("String leaks recovery codes")
+	}
+
+	left, err := UseRecoveryCode(v, "alice", e.RecoveryCodes[0])
+	if err != nil || left != recoveryCodeCount-1 {
+		t.Fatalf("first use = %d, %v", left, err)
+	}
+	if _, err := UseRecoveryCode(v, "alice", e.RecoveryCodes[0]); !errors.Is(err, ErrInvalidRecovery) {
+		t.Errorf("second use = %v, want %v", err, ErrInvalidRecovery)
+	}
+
+	// Codes are typed by hand: case and the dash do not matter.
+	typed := strings.ToUpper(strings.ReplaceAll(e.RecoveryCodes[1], "-", ""))
+	if left, err := UseRecoveryCode(v, "alice", typed); err != nil || left != recoveryCodeCount-2 {
+		t.Errorf("retyped code = %d, %v", left, err)
+	}
+}
@@ -0,0 +1,269 @@
+//core/security/users/password_reset.go
+
+package security_users
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	security_pairing "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/pairing"
+	security_password "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/password"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+)
+
+const (
+	resetsCollection = "password_resets"
+
+	resetPrefix = "aiosr1"
+
+	// ResetURIPrefix marks a reset token handed over as a link.
+	ResetURIPrefix = "aios-reset:"
+
+	DefaultResetLifetime = 24 * time.Hour
+	MaxResetLifetime     = 7 * 24 * time.Hour
+)
+
+var (
+	ErrResetMalformed = errors.New("reset_token_malformed")
+	ErrResetSignature = errors.New("reset_token_signature_invalid")
+	ErrResetExpired   = errors.New("reset_token_expired")
+	ErrResetUsed      = errors.New("reset_token_used_or_superseded")
+	ErrNoDeviceKey    = errors.New("device_identity_not_provisioned")
+)
+
+// resetClaims is the signed body of a reset token.
+type resetClaims struct {
+	ID       string    `json:"id"`
+	UserID   string    `json:"user"`
+	IssuedBy string    `json:"issued_by"`
+	Issued   time.Time `json:"iat"`
+	Expires  time.Time `json:"exp"`
+}
+
+// resetRecord marks a token as redeemable. It is deleted on use, and
+// issuing a new token for the same user drops the previous one.
+type resetRecord struct {
+	ID        string    `json:"id"`
+	IssuedBy  string    `json:"issued_by"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ResetToken is an issued token with what the admin hands over.
+type ResetToken struct {
+	UserID    string    `json:"user_id"`
+	Token     string    `json:"token"`
+	URI       string    `json:"uri"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ResetOutcome reports what a redeemed reset revoked.
+type ResetOutcome struct {
+	UserID          string `json:"user_id"`
+	IssuedBy        string `json:"issued_by"`
+	PairingsRemoved int    `json:"pairings_removed"`
+}
+
+// IssueReset creates a single-use token that lets userID set a new
+// password without knowing the old one. Tokens are
+// aiosr1.<base64url claims>.<base64url ed25519>, signed with the device
+// key so they are only valid on this unit, and are meant to be delivered
+// out of band: printed, as a link (URI), or as a file.
+func (d *Directory) IssueReset(actor Actor, userID string, lifetime time.Duration) (*ResetToken, error) {
+	if err := d.authorize(actor, "user.reset_issue", userID); err != nil {
+		return nil, err
+	}
+	if _, err := d.read(userID); err != nil {
+		return nil, err
+	}
+
+	if lifetime <= 0 {
+		lifetime = DefaultResetLifetime
+	}
+	if lifetime > MaxResetLifetime {
+		lifetime = MaxResetLifetime
+	}
+
+	device, err := verification_identity.LoadDeviceIdentity(d.vault)
+	if err != nil {
+		return nil, err
+	}
+	if device == nil {
+		return nil, ErrNoDeviceKey
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	claims := resetClaims{
+		ID:       hex.EncodeToString(id),
+		UserID:   userID,
+		IssuedBy: actor.Name,
+		Issued:   now,
+		Expires:  now.Add(lifetime),
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	enc := resetPrefix + "." + base64.RawURLEncoding.EncodeToString(payload)
+	token := enc + "." + base64.RawURLEncoding.EncodeToString(device.Sign([]byte(enc)))
+
+	err = d.vault.Write(resetsCollection, userID, resetRecord{
+		ID:        claims.ID,
+		IssuedBy:  actor.Name,
+		ExpiresAt: claims.Expires,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	d.record(actor, "user.reset_issue", userID, "ok", map[string]interface{}{
+		"reset_id":   claims.ID,
+		"expires_at": claims.Expires,
+	})
+
+	return &ResetToken{
+		UserID:    userID,
+		Token:     token,
+		URI:       ResetURIPrefix + token,
+		ExpiresAt: claims.Expires,
+	}, nil
+}
+
+// RedeemReset sets a new password with a token from IssueReset. The token
+// is consumed, and every existing session and companion pairing of the
+// user is revoked, since whoever lost the password may have lost those
+// too.
+func (d *Directory) RedeemReset(token, password string) (*ResetOutcome, error) {
+	claims, err := d.parseReset(token)
+	if err != nil {
+		d.record(Actor{Name: "recovery"}, "user.password_reset", "", "denied", err.Error())
+		return nil, err
+	}
+
+	actor := Actor{Name: claims.UserID}
+	deny := func(err error) (*ResetOutcome, error) {
+		d.record(actor, "user.password_reset", claims.UserID, "denied", err.Error())
+		return nil, err
+	}
+
+	if password == "" {
+		return nil, errors.New("password required")
+	}
+	hash, err := security_password.Hash(password)
+	if err != nil {
+		return nil, err
+	}
+
+	var rec internal_environment.MachineIdentity
+	err = verification_persistence.Atomically(d.vault, func(tx verification_persistence.VaultTx) error {
+		var pending resetRecord
+		found, err := tx.Read(resetsCollection, claims.UserID, &pending)
+		if err != nil {
+			return err
+		}
+		if !found || pending.ID != claims.ID {
+			return ErrResetUsed
+		}
+
+		found, err = tx.Read(usersCollection, claims.UserID, &rec)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrUserNotFound
+		}
+
+		// The new password and the revocation of the old sessions commit
+		// together: a crash in between must not leave them valid
+		rec.PasswordHash = hash
+		if err := tx.Write(usersCollection, claims.UserID, &rec); err != nil {
+			return err
+		}
+		if err := verification_identity.RevokeUserTx(tx, claims.UserID, "password_reset"); err != nil {
+			return err
+		}
+		return tx.Delete(resetsCollection, claims.UserID)
+	})
+	if err != nil {
+		return deny(err)
+	}
+
+	out := &ResetOutcome{UserID: claims.UserID, IssuedBy: claims.IssuedBy}
+
+	if out.PairingsRemoved, err = security_pairing.UnpairAll(d.vault, claims.UserID); err != nil {
+		return nil, err
+	}
+
+	d.record(actor, "user.password_reset", claims.UserID, "ok", map[string]interface{}{
+		"reset_id":         claims.ID,
+		"issued_by":        claims.IssuedBy,
+		"sessions_revoked": true,
+		"pairings_removed": out.PairingsRemoved,
+	})
+	return out, nil
+}
+
+// CancelReset drops a pending reset token of userID.
+func (d *Directory) CancelReset(actor Actor, userID string) error {
+	if err := d.authorize(actor, "user.reset_cancel", userID); err != nil {
+		return err
+	}
+	if err := d.vault.Delete(resetsCollection, userID); err != nil {
+		return err
+	}
+	d.record(actor, "user.reset_cancel", userID, "ok", nil)
+	return nil
+}
+
+func (d *Directory) parseReset(token string) (*resetClaims, error) {
+	token = strings.TrimPrefix(strings.TrimSpace(token), ResetURIPrefix)
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != resetPrefix {
+		return nil, ErrResetMalformed
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrResetMalformed
+	}
+
+	device, err := verification_identity.LoadDeviceIdentity(d.vault)
+	if err != nil {
+		return nil, err
+	}
+	if device == nil {
+		return nil, ErrNoDeviceKey
+	}
+	if !verification_identity.VerifySignature(ed25519.PublicKey(device.PublicKey), []byte(parts[0]+"."+parts[1]), sig) {
+		return nil, ErrResetSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrResetMalformed
+	}
+
+	var claims resetClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.UserID == "" {
+		return nil, ErrResetMalformed
+	}
+	if !time.Now().Before(claims.Expires) {
+		return nil, ErrResetExpired
+	}
+
+	return &claims, nil
+}
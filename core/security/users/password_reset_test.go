@@ -0,0 +1,92 @@
+//core/security/users/password_reset_test.go
+
+package security_users
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+)
+
+func TestPasswordResetIsSingleUseAndRevokesSessions(t *testing.T) {
+	d, v, _ := testDirectory(t)
+	if _, err := verification_identity.ProvisionDeviceIdentity(v, "m1", verification_identity.HardwareClaim{Digest: "tpm=t;", Components: map[string]string{"tpm": "t"}}); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := verification_identity.NewTokenService(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := session(t, tokens, "alice")
+
+	reset, err := d.IssueReset(Actor{Name: "root", Admin: true}, "alice", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := d.RedeemReset(reset.Token, "new-alice-pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.UserID != "alice" || out.IssuedBy != "root" {
+		t.Errorf("outcome = %+v", out)
+	}
+
+	if _, err := tokens.Verify(old); !errors.Is(err, verification_identity.ErrTokenRevoked) {
+		t.Errorf("session from before the reset: %v, want %v", err, verification_identity.ErrTokenRevoked)
+	}
+	if err := d.Reverify("alice", "new-alice-pw", ""); err != nil {
+		t.Errorf("new password rejected: %v", err)
+	}
+	if err := d.Reverify("alice", "alice-pw", ""); err == nil {
+		t.Error("old password still accepted")
+	}
+
+	if _, err := d.RedeemReset(reset.Token, "again"); !errors.Is(err, ErrResetUsed) {
+		t.Errorf("second redeem: %v, want %v", err, ErrResetUsed)
+	}
+}
+
+func TestPasswordResetRefusesForgedAndSupersededTokens(t *testing.T) {
+	d, v, _ := testDirectory(t)
+	if _, err := verification_identity.ProvisionDeviceIdentity(v, "m1", verification_identity.HardwareClaim{Digest: "tpm=t;", Components: map[string]string{"tpm": "t"}}); err != nil {
+		t.Fatal(err)
+	}
+	root := Actor{Name: "root", Admin: true}
+
+	if _, err := d.IssueReset(Actor{Name: "bob"}, "alice", time.Hour); err == nil {
+		t.Fatal("reset issued by a non-admin")
+	}
+
+	first, err := d.IssueReset(root, "alice", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := d.IssueReset(root, "alice", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.RedeemReset(first.Token, "pw-1"); !errors.Is(err, ErrResetUsed) {
+		t.Errorf("superseded token: %v, want %v", err, ErrResetUsed)
+	}
+
+	forged := second.Token[:len(second.Token)-2] + "AA"
+	if forged == second.Token {
+		forged = second.Token[:len(second.Token)-2] + "BB"
+	}
+	if _, err := d.RedeemReset(forged, "pw-2"); !errors.Is(err, ErrResetSignature) && !errors.Is(err, ErrResetMalformed) {
+		t.Errorf("forged token: %v", err)
+	}
+	if _, err := d.RedeemReset("aiosr1.e30", "pw-2"); !errors.Is(err, ErrResetMalformed) {
+		t.Errorf("truncated token: %v, want %v", err, ErrResetMalformed)
+	}
+
+	if err := d.CancelReset(root, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.RedeemReset(second.URI, "pw-3"); !errors.Is(err, ErrResetUsed) {
+		t.Errorf("cancelled token: %v, want %v", err, ErrResetUsed)
+	}
+}
@@ -0,0 +1,623 @@
+//core/security/users/user_directory.go
+
+package security_users
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	security_authenticator "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/authenticator"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	security_lockout "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/lockout"
+	security_password "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/password"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	security_totp "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/totp"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+const (
+	usersCollection = "users"
+
+	// profilesCollection is owned by core/auth; the profile goes with the
+	// account.
+	profilesCollection = "profiles"
+)
+
+var (
+	ErrNotAdmin      = errors.New("admin_permission_required")
+	ErrUserNotFound  = errors.New("user_not_found")
+	ErrUserExists    = errors.New("user_already_exists")
+	ErrLastAdmin     = errors.New("last_admin")
+	ErrUserDisabled  = errors.New("user_disabled")
+	ErrBadCredential = errors.New("invalid_credentials")
+)
+
+// Auditor is satisfied by *security_audit.Log.
+type Auditor interface {
+	Append(ev security_audit.Event) (*security_audit.Entry, error)
+}
+
+// Actor is whoever asks for a change. Only actors holding PermAdmin may
+// change accounts.
+type Actor struct {
+	Name  string
+	Admin bool
+}
+
+// ActorFromClaims derives the actor from a verified session. Admin is
+// read from the account as it is now, not from the claims, so a demoted
+// or disabled admin's session stops administering at once.
+func (d *Directory) ActorFromClaims(claims *user_setting.SessionClaims) Actor {
+	if claims == nil {
+		return Actor{}
+	}
+	actor := Actor{Name: claims.UserID}
+	if rec, err := d.read(claims.UserID); err == nil && !rec.Disabled {
+		actor.Admin = rec.Admin
+	}
+	return actor
+}
+
+// Account is the administrative view of a stored user, without the
+// password hash.
+type Account struct {
+	UserID    string                `json:"user_id"`
+	Entity    string                `json:"entity"`
+	Tier      user_setting.TierType `json:"tier"`
+	Admin     bool                  `json:"admin"`
+	Disabled  bool                  `json:"disabled"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+// NewAccount describes a user to create.
+type NewAccount struct {
+	UserID   string                          `json:"user_id"`
+	Password string                          `json:"password"`
+	Entity   internal_environment.EntityKind `json:"entity"`
+	Tier     user_setting.TierType           `json:"tier"`
+	Admin    bool                            `json:"admin"`
+}
+
+// Directory administers the vault users collection.
+type Directory struct {
+	vault verification_persistence.VaultStore
+	audit Auditor
+
+	// Lockout limits AuthenticateAdmin like a login. Nil builds a guard
+	// from the computer platform policy stored in the vault.
+	Lockout *security_lockout.Guard
+}
+
+func NewDirectory(v verification_persistence.VaultStore, audit Auditor) *Directory {
+	return &Directory{vault: v, audit: audit}
+}
+
+// adminAuthSource is the lockout source of AuthenticateAdmin: local
+// tools share one counter, like a single remote address.
+const adminAuthSource = "admin-cli"
+
+// AuthenticateAdmin checks an admin's own password and returns the actor
+// for local tools that have no session. It runs under the login lockout:
+// a locked or backing-off account is refused before the password is
+// checked, and every wrong password counts. While no admin exists yet the
+// first one may be created without credentials; see Bootstrapping.
+func (d *Directory) AuthenticateAdmin(userID, password string) (Actor, error) {
+	guard, err := d.guard()
+	if err != nil {
+		return Actor{}, err
+	}
+	if err := guard.Check(userID, adminAuthSource); err != nil {
+		d.record(Actor{Name: userID}, "user.admin_auth", userID, "locked", err.Error())
+		return Actor{}, err
+	}
+
+	rec, err := d.read(userID)
+	if err != nil && !errors.Is(err, ErrUserNotFound) {
+		return Actor{}, err
+	}
+
+	if rec == nil || !verifies(password, rec.PasswordHash) {
+		d.record(Actor{Name: userID}, "user.admin_auth", userID, "denied", nil)
+		if lerr := guard.RecordFailure(userID, adminAuthSource); lerr != nil {
+			return Actor{}, lerr
+		}
+		return Actor{}, ErrBadCredential
+	}
+	_ = guard.RecordSuccess(userID)
+
+	if rec.Disabled {
+		return Actor{}, ErrUserDisabled
+	}
+	if !rec.Admin {
+		return Actor{}, ErrNotAdmin
+	}
+
+	return Actor{Name: userID, Admin: true}, nil
+}
+
+func verifies(password, hash string) bool {
+	_, err := security_password.Verify(password, hash)
+	return err == nil
+}
+
+func (d *Directory) guard() (*security_lockout.Guard, error) {
+	if d.Lockout != nil {
+		return d.Lockout, nil
+	}
+
+	policy, err := security_lockout.LoadPolicy(d.vault, internal_environment.PlatformComputer)
+	if err != nil {
+		return nil, err
+	}
+
+	var auditor security_lockout.Auditor
+	if d.audit != nil {
+		auditor = d.audit
+	}
+	d.Lockout = security_lockout.NewGuard(d.vault, policy, auditor)
+	return d.Lockout, nil
+}
+
+// Reverify re-authenticates userID for step-up or to unlock an idle
+// session. A second-factor code is accepted when the user is enrolled;
+// otherwise the password is required.
+func (d *Directory) Reverify(userID, password, code string) error {
+	rec, err := d.read(userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrBadCredential
+		}
+		return err
+	}
+	if rec.Disabled {
+		return ErrUserDisabled
+	}
+
+	ok := false
+	if code != "" {
+		ok = security_totp.Verify(d.vault, userID, code) == nil
+	}
+	if !ok && password != "" {
+		_, err := security_password.Verify(password, rec.PasswordHash)
+		ok = err == nil
+	}
+
+	result := "ok"
+	if !ok {
+		result = "denied"
+	}
+	d.record(Actor{Name: userID}, "auth.step_up", userID, result, nil)
+
+	if !ok {
+		return ErrBadCredential
+	}
+	return nil
+}
+
+// Bootstrapping reports whether no enabled admin exists, in which case
+// Add accepts an unauthenticated actor creating an admin account.
+func (d *Directory) Bootstrapping() (bool, error) {
+	n, err := countAdmins(d.vault, "")
+	return n == 0, err
+}
+
+// List returns every account sorted by user ID.
+func (d *Directory) List(actor Actor) ([]Account, error) {
+	if err := d.authorize(actor, "user.list", ""); err != nil {
+		return nil, err
+	}
+
+	ids, err := d.vault.List(usersCollection)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(ids)
+
+	out := make([]Account, 0, len(ids))
+	for _, id := range ids {
+		rec, err := d.read(id)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, accountOf(id, rec))
+	}
+	return out, nil
+}
+
+// Show returns a single account.
+func (d *Directory) Show(actor Actor, userID string) (*Account, error) {
+	if err := d.authorize(actor, "user.show", userID); err != nil {
+		return nil, err
+	}
+
+	rec, err := d.read(userID)
+	if err != nil {
+		return nil, err
+	}
+	acct := accountOf(userID, rec)
+	return &acct, nil
+}
+
+// Add creates an account.
+func (d *Directory) Add(actor Actor, n NewAccount) (*Account, error) {
+	if !actor.Admin {
+		bootstrapping, err := d.Bootstrapping()
+		if err != nil {
+			return nil, err
+		}
+		if !bootstrapping || !n.Admin {
+			d.record(actor, "user.add", n.UserID, "denied", nil)
+			return nil, ErrNotAdmin
+		}
+	}
+
+	if n.UserID == "" || n.Password == "" {
+		return nil, errors.New("user ID and password required")
+	}
+	if n.Tier == "" {
+		n.Tier = defaultTier(n.Entity)
+	}
+	if err := validTier(n.Tier); err != nil {
+		return nil, err
+	}
+
+	hash, err := security_password.Hash(n.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &internal_environment.MachineIdentity{
+		MachineID:    fmt.Sprintf("machine-%s", n.UserID),
+		EntityType:   n.Entity,
+		TierType:     n.Tier,
+		OS:           "unknown",
+		Arch:         "unknown",
+		PasswordHash: hash,
+		CreatedAt:    time.Now().UTC(),
+		Admin:        n.Admin,
+	}
+
+	err = verification_persistence.Atomically(d.vault, func(tx verification_persistence.VaultTx) error {
+		exists, err := tx.Exists(usersCollection, n.UserID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrUserExists
+		}
+		return tx.Write(usersCollection, n.UserID, rec)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	acct := accountOf(n.UserID, rec)
+	d.record(actor, "user.add", n.UserID, "ok", acct)
+	return &acct, nil
+}
+
+// SetDisabled disables or re-enables login for userID. The last enabled
+// admin cannot be disabled.
+func (d *Directory) SetDisabled(actor Actor, userID string, disabled bool) error {
+	action := "user.enable"
+	if disabled {
+		action = "user.disable"
+	}
+
+	if disabled {
+		if err := d.authorize(actor, action, userID); err != nil {
+			return err
+		}
+		rec, err := d.read(userID)
+		if err != nil {
+			return err
+		}
+		if rec.Admin && !rec.Disabled {
+			if err := keepAnAdmin(d.vault, userID); err != nil {
+				d.record(actor, action, userID, "refused", err.Error())
+				return err
+			}
+		}
+	}
+
+	return d.update(actor, action, userID, func(rec *internal_environment.MachineIdentity) error {
+		rec.Disabled = disabled
+		return nil
+	})
+}
+
+// SetPassword replaces the password of userID.
+func (d *Directory) SetPassword(actor Actor, userID, password string) error {
+	if password == "" {
+		return errors.New("password required")
+	}
+
+	hash, err := security_password.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	return d.update(actor, "user.passwd", userID, func(rec *internal_environment.MachineIdentity) error {
+		rec.PasswordHash = hash
+		return nil
+	})
+}
+
+// SetEntity changes the entity kind of userID.
+func (d *Directory) SetEntity(actor Actor, userID string, entity internal_environment.EntityKind) error {
+	return d.update(actor, "user.set_entity", userID, func(rec *internal_environment.MachineIdentity) error {
+		rec.EntityType = entity
+		return nil
+	})
+}
+
+// SetTier changes the tier of userID.
+func (d *Directory) SetTier(actor Actor, userID string, tier user_setting.TierType) error {
+	if err := validTier(tier); err != nil {
+		return err
+	}
+
+	return d.update(actor, "user.set_tier", userID, func(rec *internal_environment.MachineIdentity) error {
+		rec.TierType = tier
+		return nil
+	})
+}
+
+// Delete removes userID with its profile, second-factor enrollment,
+// authenticators and paired phones and pending password resets, and
+// revokes its sessions. It runs as one transaction, the last-admin check
+// included, so a failure leaves the account whole and two admins deleting
+// each other cannot both succeed. The last enabled admin cannot be
+// deleted.
+func (d *Directory) Delete(actor Actor, userID string) error {
+	if err := d.authorize(actor, "user.delete", userID); err != nil {
+		return err
+	}
+
+	var rec *internal_environment.MachineIdentity
+	err := verification_persistence.Atomically(d.vault, func(tx verification_persistence.VaultTx) error {
+		var err error
+		if rec, err = readUser(tx, userID); err != nil {
+			return err
+		}
+		if rec.Admin && !rec.Disabled {
+			if err := keepAnAdmin(tx, userID); err != nil {
+				return err
+			}
+		}
+
+		if err := verification_identity.RevokeUserTx(tx, userID, "user.delete"); err != nil {
+			return err
+		}
+		if err := tx.Delete(usersCollection, userID); err != nil {
+			return err
+		}
+		if err := security_totp.ResetTx(tx, userID); err != nil {
+			return err
+		}
+		if err := tx.Delete(profilesCollection, userID); err != nil {
+			return err
+		}
+		if err := security_authenticator.RevokeAllTx(tx, userID); err != nil {
+			return err
+		}
+		return tx.Delete(resetsCollection, userID)
+	})
+	if errors.Is(err, ErrLastAdmin) {
+		d.record(actor, "user.delete", userID, "refused", err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	d.record(actor, "user.delete", userID, "ok", accountOf(userID, rec))
+	return nil
+}
+
+// Identity returns the stored account of userID without its password
+// hash. It implements security_decision.Directory.
+func (d *Directory) Identity(userID string) (*internal_environment.MachineIdentity, error) {
+	rec, err := d.read(userID)
+	if err != nil {
+		return nil, err
+	}
+	rec.PasswordHash = ""
+	return rec, nil
+}
+
+// revokingActions change what a user's sessions may do; they revoke the
+// sessions in the same transaction, so none outlives the change with the
+// permissions it was issued.
+var revokingActions = map[string]bool{
+	"user.disable":    true,
+	"user.set_entity": true,
+	"user.set_tier":   true,
+}
+
+func (d *Directory) update(actor Actor, action, userID string, fn func(rec *internal_environment.MachineIdentity) error) error {
+	if err := d.authorize(actor, action, userID); err != nil {
+		return err
+	}
+
+	var after internal_environment.MachineIdentity
+	err := verification_persistence.Atomically(d.vault, func(tx verification_persistence.VaultTx) error {
+		found, err := tx.Read(usersCollection, userID, &after)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrUserNotFound
+		}
+		if err := fn(&after); err != nil {
+			return err
+		}
+		if err := tx.Write(usersCollection, userID, &after); err != nil {
+			return err
+		}
+		if revokingActions[action] {
+			return verification_identity.RevokeUserTx(tx, userID, action)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	d.record(actor, action, userID, "ok", accountOf(userID, &after))
+	return nil
+}
+
+func (d *Directory) authorize(actor Actor, action, userID string) error {
+	if actor.Admin {
+		return nil
+	}
+	d.record(actor, action, userID, "denied", nil)
+	return ErrNotAdmin
+}
+
+func (d *Directory) read(userID string) (*internal_environment.MachineIdentity, error) {
+	return readUser(d.vault, userID)
+}
+
+func readUser(r verification_persistence.VaultTx, userID string) (*internal_environment.MachineIdentity, error) {
+	var rec internal_environment.MachineIdentity
+	found, err := r.Read(usersCollection, userID, &rec)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrUserNotFound
+	}
+	return &rec, nil
+}
+
+// keepAnAdmin fails unless an enabled admin other than userID exists.
+func keepAnAdmin(r verification_persistence.VaultTx, userID string) error {
+	n, err := countAdmins(r, userID)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s is the only enabled admin", ErrLastAdmin, userID)
+	}
+	return nil
+}
+
+func countAdmins(r verification_persistence.VaultTx, except string) (int, error) {
+	ids, err := r.List(usersCollection)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, id := range ids {
+		if id == except {
+			continue
+		}
+		rec, err := readUser(r, id)
+		if err != nil {
+			return 0, err
+		}
+		if rec.Admin && !rec.Disabled {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (d *Directory) record(actor Actor, action, userID, result string, detail interface{}) {
+	if d.audit == nil {
+		return
+	}
+
+	ev := security_audit.Event{
+		Actor:    actor.Name,
+		Action:   action,
+		Resource: userID,
+		Result:   result,
+	}
+	if strings.HasPrefix(action, "user.") {
+		ev.Permission = string(user_setting.PermAdmin)
+	}
+	if detail != nil {
+		ev.Detail, _ = json.Marshal(detail)
+	}
+	_, _ = d.audit.Append(ev)
+}
+
+func accountOf(userID string, rec *internal_environment.MachineIdentity) Account {
+	tier := rec.TierType
+	if tier == "" {
+		tier = defaultTier(rec.EntityType)
+	}
+
+	return Account{
+		UserID:    userID,
+		Entity:    EntityName(rec.EntityType),
+		Tier:      tier,
+		Admin:     rec.Admin,
+		Disabled:  rec.Disabled,
+		CreatedAt: rec.CreatedAt,
+	}
+}
+
+// EntityName is the lower-case name of an entity kind.
+func EntityName(e internal_environment.EntityKind) string {
+	switch e {
+	case internal_environment.EntityPersonal:
+		return "personal"
+	case internal_environment.EntityOrganization:
+		return "organization"
+	case internal_environment.EntityStranger:
+		return "stranger"
+	case internal_environment.EntityTester:
+		return "tester"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseEntity is the inverse of EntityName.
+func ParseEntity(s string) (internal_environment.EntityKind, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "personal":
+		return internal_environment.EntityPersonal, nil
+	case "organization":
+		return internal_environment.EntityOrganization, nil
+	case "stranger":
+		return internal_environment.EntityStranger, nil
+	case "tester":
+		return internal_environment.EntityTester, nil
+	default:
+		return 0, fmt.Errorf("unknown entity %q", s)
+	}
+}
+
+// defaultTier mirrors the entity-to-tier mapping applied at login.
+func defaultTier(e internal_environment.EntityKind) user_setting.TierType {
+	switch e {
+	case internal_environment.EntityPersonal:
+		return user_setting.TierPersonal
+	case internal_environment.EntityOrganization:
+		return user_setting.TierEnterprise
+	case internal_environment.EntityTester:
+		return user_setting.TierTester
+	default:
+		return user_setting.TierUnknown
+	}
+}
+
+func validTier(t user_setting.TierType) error {
+	switch t {
+	case user_setting.TierPersonal, user_setting.TierEnterprise, user_setting.TierTester, user_setting.TierUnknown:
+		return nil
+	default:
+		return fmt.Errorf("unknown tier %q", t)
+	}
+}
@@ -0,0 +1,270 @@
+//core/security/users/user_directory_test.go
+
+package security_users
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	security_authenticator "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/authenticator"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	security_lockout "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/lockout"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	security_totp "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/totp"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
+)
+
+// recorder keeps the audit events of a directory.
+type recorder struct {
+	events []security_audit.Event
+}
+
+func (r *recorder) Append(ev security_audit.Event) (*security_audit.Entry, error) {
+	r.events = append(r.events, ev)
+	return &security_audit.Entry{}, nil
+}
+
+func (r *recorder) has(action, userID, result string) bool {
+	for _, ev := range r.events {
+		if ev.Action == action && ev.Resource == userID && ev.Result == result {
+			return true
+		}
+	}
+	return false
+}
+
+// testDirectory is a directory on a transactional vault with one admin,
+// root, and one user, alice.
+func testDirectory(t *testing.T) (*Directory, *verification_persistence.BoltVault, *recorder) {
+	t.Helper()
+	v, err := verification_persistence.OpenBoltVault(filepath.Join(t.TempDir(), "vault.db"), bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { v.Close() })
+
+	audit := &recorder{}
+	d := NewDirectory(v, audit)
+	// No back-off, so one test's wrong password does not delay the next.
+	d.Lockout = security_lockout.NewGuard(v, security_lockout.Policy{Window: time.Hour}, nil)
+
+	if _, err := d.Add(Actor{}, NewAccount{UserID: "root", Password: "root-pw", Admin: true}); err != nil {
+		t.Fatalf("bootstrap admin: %v", err)
+	}
+	if _, err := d.Add(Actor{Name: "root", Admin: true}, NewAccount{UserID: "alice", Password: "alice-pw", Entity: internal_environment.EntityPersonal}); err != nil {
+		t.Fatal(err)
+	}
+	return d, v, audit
+}
+
+func session(t *testing.T, tokens *verification_identity.TokenService, userID string) string {
+	t.Helper()
+	token, err := tokens.Issue(&user_setting.SessionClaims{UserID: userID, CreatedAt: time.Now().UTC().Add(-time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestOnlyTheFirstAdminNeedsNoAdmin(t *testing.T) {
+	d, _, audit := testDirectory(t)
+
+	if _, err := d.Add(Actor{Name: "alice"}, NewAccount{UserID: "eve", Password: "pw", Admin: true}); !errors.Is(err, ErrNotAdmin) {
+		t.Fatalf("second admin without an admin: %v, want %v", err, ErrNotAdmin)
+	}
+	if !audit.has("user.add", "eve", "denied") {
+		t.Error("refused add not audited")
+	}
+
+	if _, err := d.AuthenticateAdmin("root", "wrong"); !errors.Is(err, ErrBadCredential) {
+		t.Errorf("wrong admin password: %v", err)
+	}
+	if _, err := d.AuthenticateAdmin("alice", "alice-pw"); !errors.Is(err, ErrNotAdmin) {
+		t.Errorf("non-admin authenticated as admin: %v", err)
+	}
+}
+
+func TestLastAdminIsKept(t *testing.T) {
+	d, _, _ := testDirectory(t)
+	root := Actor{Name: "root", Admin: true}
+
+	if err := d.SetDisabled(root, "root", true); !errors.Is(err, ErrLastAdmin) {
+		t.Errorf("disable last admin: %v, want %v", err, ErrLastAdmin)
+	}
+	if err := d.Delete(root, "root"); !errors.Is(err, ErrLastAdmin) {
+		t.Errorf("delete last admin: %v, want %v", err, ErrLastAdmin)
+	}
+
+	if _, err := d.Add(root, NewAccount{UserID: "ops", Password: "ops-pw", Admin: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetDisabled(root, "root", true); err != nil {
+		t.Errorf("disable an admin with another left: %v", err)
+	}
+}
+
+func TestDisablingRevokesSessionsAndAdminRights(t *testing.T) {
+	d, v, audit := testDirectory(t)
+	tokens, err := verification_identity.NewTokenService(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := Actor{Name: "root", Admin: true}
+
+	if _, err := d.Add(root, NewAccount{UserID: "ops", Password: "ops-pw", Admin: true}); err != nil {
+		t.Fatal(err)
+	}
+	token := session(t, tokens, "ops")
+	claims, err := tokens.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.ActorFromClaims(claims).Admin {
+		t.Fatal("enabled admin session not admin")
+	}
+
+	if err := d.SetDisabled(root, "ops", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokens.Verify(token); !errors.Is(err, verification_identity.ErrTokenRevoked) {
+		t.Errorf("session of a disabled account: %v, want %v", err, verification_identity.ErrTokenRevoked)
+	}
+	if d.ActorFromClaims(claims).Admin {
+		t.Error("disabled admin still administers")
+	}
+	if !audit.has("user.disable", "ops", "ok") {
+		t.Error("disable not audited")
+	}
+}
+
+func TestDeleteRemovesTheAccountAndItsSessions(t *testing.T) {
+	d, v, _ := testDirectory(t)
+	tokens, err := verification_identity.NewTokenService(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := session(t, tokens, "alice")
+
+	if err := d.Delete(Actor{Name: "alice"}, "alice"); !errors.Is(err, ErrNotAdmin) {
+		t.Fatalf("delete by a non-admin: %v", err)
+	}
+	if err := d.Delete(Actor{Name: "root", Admin: true}, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.Identity("alice"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("deleted account still stored: %v", err)
+	}
+	if _, err := tokens.Verify(token); !errors.Is(err, verification_identity.ErrTokenRevoked) {
+		t.Errorf("session of a deleted account: %v, want %v", err, verification_identity.ErrTokenRevoked)
+	}
+}
+
+func TestAdminAuthenticationIsLockedOut(t *testing.T) {
+	d, v, audit := testDirectory(t)
+	d.Lockout = security_lockout.NewGuard(v, security_lockout.Policy{
+		Threshold: 3, SourceThreshold: 100, LockoutDuration: time.Hour, Window: time.Hour,
+	}, nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := d.AuthenticateAdmin("root", "guess"); !errors.Is(err, ErrBadCredential) {
+			t.Fatalf("attempt %d: %v, want %v", i+1, err, ErrBadCredential)
+		}
+	}
+	var locked *security_lockout.LockedError
+	if _, err := d.AuthenticateAdmin("root", "guess"); !errors.As(err, &locked) || !locked.Lockout {
+		t.Fatalf("third wrong password: %v, want a lockout", err)
+	}
+
+	if _, err := d.AuthenticateAdmin("root", "root-pw"); !errors.As(err, &locked) {
+		t.Fatalf("right password while locked: %v, want a lockout", err)
+	}
+	if !audit.has("user.admin_auth", "root", "locked") {
+		t.Error("locked attempt not audited")
+	}
+
+	// Unknown accounts count too, so probing names is limited as well.
+	for i := 0; i < 3; i++ {
+		_, _ = d.AuthenticateAdmin("nobody", "guess")
+	}
+	if _, err := d.AuthenticateAdmin("nobody", "guess"); !errors.As(err, &locked) {
+		t.Errorf("unknown account: %v, want a lockout", err)
+	}
+}
+
+func TestDeleteRemovesEverythingOfTheAccount(t *testing.T) {
+	d, v, _ := testDirectory(t)
+	root := Actor{Name: "root", Admin: true}
+
+	if _, err := security_totp.Enroll(v, "alice", "AIOS"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := security_authenticator.EnrollHMAC(v, "alice", security_authenticator.KindCompanionApp, "phone", "phone"); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Write(profilesCollection, "alice", map[string]string{"theme": "dark"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Write(resetsCollection, "alice", map[string]string{"token": "x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Delete(root, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []string{usersCollection, profilesCollection, resetsCollection, "totp", "authenticators"} {
+		if ok, _ := v.Exists(c, "alice"); ok {
+			t.Errorf("%s record survived the delete", c)
+		}
+	}
+}
+
+// failingVault fails deletes of one collection inside transactions.
+type failingVault struct {
+	*verification_persistence.BoltVault
+	collection string
+}
+
+type failingTx struct {
+	verification_persistence.VaultTx
+	collection string
+}
+
+func (f failingVault) Update(fn func(tx verification_persistence.VaultTx) error) error {
+	return f.BoltVault.Update(func(tx verification_persistence.VaultTx) error {
+		return fn(failingTx{tx, f.collection})
+	})
+}
+
+func (f failingTx) Delete(collection, key string) error {
+	if collection == f.collection {
+		return errors.New("disk full")
+	}
+	return f.VaultTx.Delete(collection, key)
+}
+
+func TestFailedDeleteLeavesTheAccountWhole(t *testing.T) {
+	d, v, _ := testDirectory(t)
+	tokens, err := verification_identity.NewTokenService(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := session(t, tokens, "alice")
+
+	failing := NewDirectory(failingVault{v, "authenticators"}, nil)
+	if err := failing.Delete(Actor{Name: "root", Admin: true}, "alice"); err == nil {
+		t.Fatal("delete succeeded with a failing step")
+	}
+
+	if _, err := d.Identity("alice"); err != nil {
+		t.Errorf("account lost by a failed delete: %v", err)
+	}
+	if _, err := tokens.Verify(token); err != nil {
+		t.Errorf("session revoked by a failed delete: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+// core/security/verification/golden_manifest.go
+package core_verification
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/pkg/logging"
+)
+
+// Set at release time with
+// -ldflags "-X .../core/security/verification.BuildVersion=1.4.2 -X .../core/security/verification.ReleasePublicKey=<base64>"
+var (
+	BuildVersion     = "dev"
+	ReleasePublicKey = ""
+)
+
+var (
+	ErrBaselineMissing   = errors.New("baseline_missing")
+	ErrUnknownBuild      = errors.New("binary_unknown_build")
+	ErrRevokedBuild      = errors.New("binary_revoked_build")
+	ErrTamperedBuild     = errors.New("binary_tamper_detected")
+	ErrBuildDowngrade    = errors.New("binary_downgrade_rejected")
+	ErrManifestSignature = errors.New("golden_manifest_signature_invalid")
+	ErrManifestRollback  = errors.New("golden_manifest_rollback")
+	ErrReleaseKeyMissing = errors.New("release_key_not_provisioned")
+)
+
+const (
+	releaseCollection = "release"
+	manifestKey       = "manifest"
+	counterKey        = "counter"
+)
+
+// BuildEntry is one released binary. Sequence increases with every release
+// and is what downgrade protection compares.
+type BuildEntry struct {
+	Version  string `json:"version"`
+	Sequence uint64 `json:"sequence"`
+	Digest   string `json:"digest"` // hex SHA-256 of the executable
+	Revoked  bool   `json:"revoked,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// GoldenManifest lists the binaries a unit may run, signed with the
+// release key. Serial increases with every manifest published.
+type GoldenManifest struct {
+	Serial    uint64       `json:"serial"`
+	IssuedAt  time.Time    `json:"issued_at"`
+	Builds    []BuildEntry `json:"builds"`
+	Signature []byte       `json:"signature"`
+}
+
+// ReleaseCounter is the monotonic state kept in the vault.
+type ReleaseCounter struct {
+	ManifestSerial uint64    `json:"manifest_serial"`
+	BuildSequence  uint64    `json:"build_sequence"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (m *GoldenManifest) signedBytes() []byte {
+	unsigned := *m
+	unsigned.Signature = nil
+	data, _ := json.Marshal(unsigned)
+	return data
+}
+
+// SignManifest is used by release tooling to seal a manifest.
+func SignManifest(m *GoldenManifest, key ed25519.PrivateKey) {
+	m.Signature = ed25519.Sign(key, m.signedBytes())
+}
+
+// VerifyManifest checks the manifest signature against pub.
+func VerifyManifest(m *GoldenManifest, pub ed25519.PublicKey) error {
+	if len(m.Signature) == 0 || !ed25519.Verify(pub, m.signedBytes(), m.Signature) {
+		return ErrManifestSignature
+	}
+	return nil
+}
+
+// LoadReleaseKey returns the ed25519 release key compiled into the binary,
+// or AIOS_RELEASE_PUBKEY when no key was compiled in. Release builds are
+// provisioned by the ldflags above; development units export the base64
+// public key in AIOS_RELEASE_PUBKEY. Without either, manifests cannot be
+// checked and the unit boots degraded (see Unverified).
+func LoadReleaseKey() (ed25519.PublicKey, error) {
+	keyStr := ReleasePublicKey
+	if keyStr == "" {
+		keyStr = os.Getenv("AIOS_RELEASE_PUBKEY")
+	}
+	if keyStr == "" {
+		return nil, ErrReleaseKeyMissing
+	}
+
+	key, err := base64.StdEncoding.DecodeString(keyStr)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid release public key")
+	}
+
+	return ed25519.PublicKey(key), nil
+}
+
+// InstallManifest verifies m and stores it. A manifest whose serial is
+// lower than the last installed one is rejected as a rollback.
+func InstallManifest(v verification_persistence.VaultStore, m *GoldenManifest, pub ed25519.PublicKey) error {
+	if err := VerifyManifest(m, pub); err != nil {
+		return err
+	}
+
+	return verification_persistence.Atomically(v, func(tx verification_persistence.VaultTx) error {
+		var counter ReleaseCounter
+		if _, err := tx.Read(releaseCollection, counterKey, &counter); err != nil {
+			return err
+		}
+
+		if m.Serial < counter.ManifestSerial {
+			return fmt.Errorf("%w: serial %d < installed %d", ErrManifestRollback, m.Serial, counter.ManifestSerial)
+		}
+
+		counter.ManifestSerial = m.Serial
+		counter.UpdatedAt = time.Now().UTC()
+
+		if err := tx.Write(releaseCollection, manifestKey, m); err != nil {
+			return err
+		}
+		return tx.Write(releaseCollection, counterKey, counter)
+	})
+}
+
+// LoadManifest returns the installed manifest, or nil if none is installed.
+func LoadManifest(v verification_persistence.VaultStore) (*GoldenManifest, error) {
+	var m GoldenManifest
+	found, err := v.Read(releaseCollection, manifestKey, &m)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// LoadReleaseCounter returns the monotonic release state.
+func LoadReleaseCounter(v verification_persistence.VaultStore) (*ReleaseCounter, error) {
+	var counter ReleaseCounter
+	if _, err := v.Read(releaseCollection, counterKey, &counter); err != nil {
+		return nil, err
+	}
+	return &counter, nil
+}
+
+// ClassifyBuild looks the measured digest up in the manifest. A digest
+// the manifest lists is a released build, or a revoked one. A digest it
+// does not list is tampered when the binary claims a version the manifest
+// does list, and otherwise an unknown build that boots degraded.
+func ClassifyBuild(m *GoldenManifest, digest []byte, version string) (*BuildEntry, error) {
+	hexDigest := hex.EncodeToString(digest)
+
+	for i := range m.Builds {
+		b := &m.Builds[i]
+		if b.Digest != hexDigest {
+			continue
+		}
+		if b.Revoked {
+			return b, fmt.Errorf("%w: %s (%s)", ErrRevokedBuild, b.Version, b.Reason)
+		}
+		return b, nil
+	}
+
+	for _, b := range m.Builds {
+		if b.Version == version {
+			return nil, fmt.Errorf("%w: version %s digest %s", ErrTamperedBuild, version, hexDigest)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: version %s digest %s", ErrUnknownBuild, version, hexDigest)
+}
+
+// verifyAgainstManifest checks the running binary against the signed
+// manifest and advances the build counter, rejecting downgrades.
+func verifyAgainstManifest(v verification_persistence.VaultStore, m *GoldenManifest, digest []byte) error {
+	pub, err := LoadReleaseKey()
+	if err != nil {
+		return err
+	}
+	if err := VerifyManifest(m, pub); err != nil {
+		return err
+	}
+
+	entry, err := ClassifyBuild(m, digest, BuildVersion)
+	if err != nil {
+		return err
+	}
+
+	return verification_persistence.Atomically(v, func(tx verification_persistence.VaultTx) error {
+		var counter ReleaseCounter
+		if _, err := tx.Read(releaseCollection, counterKey, &counter); err != nil {
+			return err
+		}
+
+		if m.Serial < counter.ManifestSerial {
+			return ErrManifestRollback
+		}
+
+		if entry.Sequence < counter.BuildSequence {
+			return fmt.Errorf("%w: build %s (seq %d) older than %d", ErrBuildDowngrade, entry.Version, entry.Sequence, counter.BuildSequence)
+		}
+
+		if entry.Sequence == counter.BuildSequence {
+			return nil
+		}
+
+		logging.Info("[verification] Release counter advanced to %s (seq %d)", entry.Version, entry.Sequence)
+		counter.BuildSequence = entry.Sequence
+		counter.UpdatedAt = time.Now().UTC()
+		return tx.Write(releaseCollection, counterKey, counter)
+	})
+}
@@ -0,0 +1,136 @@
+// core/security/verification/golden_manifest_test.go
+package core_verification
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func testManifest(t *testing.T) (*GoldenManifest, ed25519.PublicKey, [][32]byte) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digests := [][32]byte{sha256.Sum256([]byte("1.0")), sha256.Sum256([]byte("1.1"))}
+	m := &GoldenManifest{
+		Serial: 4,
+		Builds: []BuildEntry{
+			{Version: "1.0", Sequence: 1, Digest: hex.EncodeToString(digests[0][:]), Revoked: true, Reason: "cve"},
+			{Version: "1.1", Sequence: 2, Digest: hex.EncodeToString(digests[1][:])},
+		},
+	}
+	SignManifest(m, priv)
+	return m, pub, digests
+}
+
+func TestClassifyReleasedBuild(t *testing.T) {
+	m, _, digests := testManifest(t)
+
+	// The digest decides; a wrong version claim does not matter.
+	if b, err := ClassifyBuild(m, digests[1][:], "dev"); err != nil || b.Version != "1.1" {
+		t.Errorf("released build = %v, %v", b, err)
+	}
+}
+
+func TestClassifyRevokedBuild(t *testing.T) {
+	m, _, digests := testManifest(t)
+
+	_, err := ClassifyBuild(m, digests[0][:], "1.0")
+	if !errors.Is(err, ErrRevokedBuild) {
+		t.Fatalf("revoked build = %v, want %v", err, ErrRevokedBuild)
+	}
+	if Unverified(err) {
+		t.Error("a revoked build must not boot")
+	}
+}
+
+func TestClassifyTamperedBuild(t *testing.T) {
+	m, _, _ := testManifest(t)
+
+	patched := sha256.Sum256([]byte("patched 1.1"))
+	_, err := ClassifyBuild(m, patched[:], "1.1")
+	if !errors.Is(err, ErrTamperedBuild) {
+		t.Fatalf("listed version with another digest = %v, want %v", err, ErrTamperedBuild)
+	}
+	if Unverified(err) {
+		t.Error("a tampered build must not boot")
+	}
+}
+
+func TestClassifyUnknownBuild(t *testing.T) {
+	m, _, _ := testManifest(t)
+
+	next := sha256.Sum256([]byte("1.2"))
+	_, err := ClassifyBuild(m, next[:], "1.2")
+	if !errors.Is(err, ErrUnknownBuild) {
+		t.Fatalf("unlisted version = %v, want %v", err, ErrUnknownBuild)
+	}
+	if !Unverified(err) {
+		t.Error("an unlisted version boots degraded, not refused")
+	}
+}
+
+func TestBaselineAfterUpdate(t *testing.T) {
+	sealedBin := sha256.Sum256([]byte("1.0"))
+	updated := sha256.Sum256([]byte("1.1"))
+	sealed := "1.0:" + hex.EncodeToString(sealedBin[:])
+
+	if err := checkBaseline(sealed, sealedBin[:], "1.0"); err != nil {
+		t.Errorf("sealed binary = %v", err)
+	}
+	if err := checkBaseline(sealed, updated[:], "1.0"); !errors.Is(err, ErrTamperedBuild) {
+		t.Errorf("sealed version with another digest = %v, want %v", err, ErrTamperedBuild)
+	}
+	if err := checkBaseline(sealed, updated[:], "1.1"); !errors.Is(err, ErrUnknownBuild) {
+		t.Errorf("update = %v, want %v", err, ErrUnknownBuild)
+	}
+
+	// Baselines sealed before versions were recorded.
+	legacy := hex.EncodeToString(sealedBin[:])
+	if err := checkBaseline(legacy, sealedBin[:], "1.1"); err != nil {
+		t.Errorf("legacy sealed binary = %v", err)
+	}
+	if err := checkBaseline(string(sealedBin[:]), sealedBin[:], "1.1"); err != nil {
+		t.Errorf("legacy raw baseline = %v", err)
+	}
+	if err := checkBaseline(legacy, updated[:], "1.1"); !Unverified(err) {
+		t.Errorf("update over a legacy baseline = %v, want degraded", err)
+	}
+
+	devBin := "dev:" + hex.EncodeToString(sealedBin[:])
+	if err := checkBaseline(devBin, updated[:], "dev"); !Unverified(err) {
+		t.Errorf("rebuilt dev binary = %v, want degraded", err)
+	}
+}
+
+func TestVerifyManifestSignature(t *testing.T) {
+	m, pub, _ := testManifest(t)
+	if err := VerifyManifest(m, pub); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Builds[0].Revoked = false
+	if err := VerifyManifest(m, pub); !errors.Is(err, ErrManifestSignature) {
+		t.Errorf("edited manifest = %v, want %v", err, ErrManifestSignature)
+	}
+	if Unverified(ErrManifestSignature) || Unverified(ErrTamperedBuild) || Unverified(ErrBuildDowngrade) {
+		t.Error("evidence against the binary must refuse the boot")
+	}
+}
+
+func TestReleaseKeyMissingIsUnverified(t *testing.T) {
+	t.Setenv("AIOS_RELEASE_PUBKEY", "")
+	saved := ReleasePublicKey
+	ReleasePublicKey = ""
+	defer func() { ReleasePublicKey = saved }()
+
+	_, err := LoadReleaseKey()
+	if !errors.Is(err, ErrReleaseKeyMissing) || !Unverified(err) {
+		t.Errorf("LoadReleaseKey without a key = %v", err)
+	}
+}
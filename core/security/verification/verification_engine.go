@@ -4,10 +4,12 @@ package core_verification
 import (
 	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
 	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/pkg/logging"
@@ -34,42 +36,83 @@ func MeasureSelf() ([]byte, error) {
 }
 
 func VerifyEnvironment(v verification_persistence.VaultStore, machineID string) error {
+	return VerifyAgainstGolden(v, machineID)
+}
 
-	hash, err := MeasureSelf()
+// VerifyAgainstGolden checks the running binary against the signed golden
+// manifest. Units provisioned before manifests existed fall back to the
+// per-machine golden hash sealed at first boot.
+func VerifyAgainstGolden(v verification_persistence.VaultStore, machineID string) error {
+	currentHash, err := MeasureSelf()
 	if err != nil {
-		return fmt.Errorf("binary_measurement_failed: %w", err)
+		return fmt.Errorf("failed_to_measure_binary: %w", err)
 	}
 
-	expected, err := v.LoadGoldenHash(machineID)
+	manifest, err := LoadManifest(v)
 	if err != nil {
-		return errors.New("baseline_missing")
+		return fmt.Errorf("golden_manifest_unreadable: %w", err)
 	}
 
-	expectedBytes := []byte(expected)
-
-	if subtle.ConstantTimeCompare(hash, expectedBytes) != 1 {
-		return errors.New("binary_tamper_detected")
+	if manifest != nil {
+		if err := verifyAgainstManifest(v, manifest, currentHash); err != nil {
+			return err
+		}
+		logging.Info("[verification] Binary integrity verified against manifest %d (build %s).", manifest.Serial, BuildVersion)
+		return nil
 	}
 
+	sealed, err := v.LoadGoldenHash(machineID)
+	if err != nil {
+		return ErrBaselineMissing
+	}
+	if err := checkBaseline(sealed, currentHash, BuildVersion); err != nil {
+		return err
+	}
+	logging.Info("[verification] Binary integrity verified.")
 	return nil
 }
 
-func VerifyAgainstGolden(v verification_persistence.VaultStore, machineID string) error {
-	currentHash, err := MeasureSelf()
-	if err != nil {
-		return fmt.Errorf("failed_to_measure_binary: %w", err)
+// checkBaseline compares digest with the baseline sealed at first boot. A
+// different digest under the sealed version is tampered. Under another
+// version it is an update nothing vouches for yet: it boots degraded, and
+// is not resealed, since the version string is the binary's own claim.
+// Baselines sealed before versions were recorded boot an update degraded
+// too; "dev" builds never claim a version.
+func checkBaseline(sealed string, digest []byte, version string) error {
+	sealedVersion, sealedDigest := "", sealed
+	if i := strings.LastIndexByte(sealed, ':'); i >= 0 {
+		sealedVersion, sealedDigest = sealed[:i], sealed[i+1:]
 	}
 
-	expected, err := v.LoadGoldenHash(machineID)
-	if err != nil {
-		return errors.New("baseline_missing")
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(digest)), []byte(sealedDigest)) == 1 ||
+		subtle.ConstantTimeCompare(digest, []byte(sealed)) == 1 {
+		return nil
 	}
 
-	expectedBytes := []byte(expected)
+	if sealedVersion != "" && sealedVersion != "dev" && sealedVersion == version {
+		return fmt.Errorf("%w: version %s", ErrTamperedBuild, version)
+	}
+	return fmt.Errorf("%w: version %s differs from baseline", ErrUnknownBuild, version)
+}
 
-	if subtle.ConstantTimeCompare(currentHash, expectedBytes) != 1 {
-		return errors.New("binary_tamper_detected")
+// Unverified reports whether err only means that nothing vouches for the
+// running binary: it is not in the manifest, no baseline was sealed, or
+// no release key is provisioned to check the manifest. Such a binary boots
+// degraded. Any other verification error is evidence against the binary
+// and refuses the boot.
+func Unverified(err error) bool {
+	return errors.Is(err, ErrUnknownBuild) ||
+		errors.Is(err, ErrBaselineMissing) ||
+		errors.Is(err, ErrReleaseKeyMissing)
+}
+
+// SealBaseline records the running binary and its version as the golden
+// hash of machineID. Units without a release manifest are verified against
+// it on every later boot.
+func SealBaseline(v verification_persistence.VaultStore, machineID string) error {
+	digest, err := MeasureSelf()
+	if err != nil {
+		return fmt.Errorf("failed_to_measure_binary: %w", err)
 	}
-	logging.Info("[verification] Binary integrity verified.")
-	return nil
+	return v.SealGoldenHash(machineID, []byte(BuildVersion+":"+hex.EncodeToString(digest)))
 }
@@ -10,6 +10,9 @@ type EnvAttestation struct {
 	Level         BootTrust     `json:"level"` // "strong" | "weak" | "invalid"
 	EnvHash       string        `json:"env_hash"`
 	SessionToken  string        `json:"session_token,omitempty"`
+	BootID        string        `json:"boot_id,omitempty"`     // measurement event log of this boot
+	Measurement   string        `json:"measurement,omitempty"` // final hash-extended digest
+	Degraded      string        `json:"degraded,omitempty"`    // why the binary was not verified
 }
 
 type SchemaInfo struct {
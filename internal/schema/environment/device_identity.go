@@ -39,4 +39,8 @@ type MachineIdentity struct {
 
 	PasswordHash string    `json:"password_hash,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
+
+	// Admin accounts receive PermAdmin at login; Disabled accounts cannot log in.
+	Admin    bool `json:"admin,omitempty"`
+	Disabled bool `json:"disabled,omitempty"`
 }
@@ -0,0 +1,272 @@
+//internal/schema/user/config.go
+
+package user_setting
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ConfigVersion is the schema version written by Migrate.
+const ConfigVersion = "v2"
+
+type PowerMode string
+
+const (
+	PowerLow      PowerMode = "low"
+	PowerBalanced PowerMode = "balanced"
+	PowerHigh     PowerMode = "high"
+)
+
+type PrivacyMode string
+
+const (
+	PrivacyStandard PrivacyMode = "standard"
+	PrivacyStrict   PrivacyMode = "strict"
+	PrivacyOffline  PrivacyMode = "offline"
+)
+
+type UpdateMode string
+
+const (
+	UpdateAuto   UpdateMode = "auto"
+	UpdateManual UpdateMode = "manual"
+)
+
+// ModeAuto lets the interaction mode follow the device capabilities.
+const ModeAuto InteractionMode = "auto"
+
+// ConfigKey names a user setting for get/set and diffs.
+type ConfigKey string
+
+const (
+	KeyMainLang      ConfigKey = "main_lang"
+	KeyPowerMode     ConfigKey = "power_mode"
+	KeyPrivacyMode   ConfigKey = "privacy_mode"
+	KeyUpdateMode    ConfigKey = "update_mode"
+	KeyPreferredMode ConfigKey = "preferred_mode"
+)
+
+// ConfigKeys lists the settings in display order.
+var ConfigKeys = []ConfigKey{KeyMainLang, KeyPowerMode, KeyPrivacyMode, KeyUpdateMode, KeyPreferredMode}
+
+// ConfigChoices are the accepted values of each enumerated setting.
+// main_lang is free-form and checked against langTag instead.
+var ConfigChoices = map[ConfigKey][]string{
+	KeyPowerMode:   {string(PowerLow), string(PowerBalanced), string(PowerHigh)},
+	KeyPrivacyMode: {string(PrivacyStandard), string(PrivacyStrict), string(PrivacyOffline)},
+	KeyUpdateMode:  {string(UpdateAuto), string(UpdateManual)},
+	KeyPreferredMode: {
+		string(ModeAuto),
+		string(ModeCLIonly), string(ModeTUIonly), string(ModeGUIonly), string(ModeFull),
+		string(ModeCT), string(ModeCG), string(ModeCVo), string(ModeCVi),
+		string(ModeTG), string(ModeTVi), string(ModeTVo),
+		string(ModeGVo), string(ModeGVi), string(ModeGVio),
+		string(ModeTVio), string(ModeGTVio), string(ModeVio),
+	},
+}
+
+// langTag accepts a language with an optional region, e.g. en or pt-BR.
+var langTag = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z]{2,4})?$`)
+
+// ConfigChange is one setting that differs between two configs.
+type ConfigChange struct {
+	Key  ConfigKey `json:"key"`
+	From string    `json:"from"`
+	To   string    `json:"to"`
+}
+
+// TopicConfigChanged is the bus topic announcing a saved user config.
+// Running modules re-read the settings they depend on.
+const TopicConfigChanged = "config.changed"
+
+// ConfigChanged is the payload published on TopicConfigChanged.
+type ConfigChanged struct {
+	UserID       string           `json:"user_id"`
+	Revision     int              `json:"revision"`
+	Hash         string           `json:"hash"`
+	PreviousHash string           `json:"previous_hash,omitempty"`
+	Changes      []ConfigChange   `json:"changes"`
+	Config       CustomizedConfig `json:"config"`
+	At           time.Time        `json:"at"`
+}
+
+// WithDefaults fills unset settings in place and returns c.
+func (c *CustomizedConfig) WithDefaults() *CustomizedConfig {
+	if c.MainLang == "" {
+		c.MainLang = "en"
+	}
+	if c.PowerMode == "" {
+		c.PowerMode = PowerBalanced
+	}
+	if c.PrivacyMode == "" {
+		c.PrivacyMode = PrivacyStandard
+	}
+	if c.UpdateMode == "" {
+		c.UpdateMode = UpdateAuto
+	}
+	if c.PreferredMode == "" {
+		c.PreferredMode = ModeAuto
+	}
+	return c
+}
+
+// Migrate brings a config saved by an older version up to ConfigVersion.
+// v1 configs were typed in freely; values are normalized and anything
+// still not recognized falls back to its default.
+func (c *CustomizedConfig) Migrate() {
+	if c.Version == ConfigVersion {
+		return
+	}
+
+	for _, key := range ConfigKeys {
+		v, _ := c.Get(key)
+		if err := c.Set(key, v); err != nil {
+			c.clear(key)
+		}
+	}
+	c.WithDefaults()
+	c.Version = ConfigVersion
+}
+
+// Validate checks every setting against the schema.
+func (c *CustomizedConfig) Validate() error {
+	for _, key := range ConfigKeys {
+		v, _ := c.Get(key)
+		if err := validSetting(key, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns a setting by key.
+func (c *CustomizedConfig) Get(key ConfigKey) (string, error) {
+	switch key {
+	case KeyMainLang:
+		return c.MainLang, nil
+	case KeyPowerMode:
+		return string(c.PowerMode), nil
+	case KeyPrivacyMode:
+		return string(c.PrivacyMode), nil
+	case KeyUpdateMode:
+		return string(c.UpdateMode), nil
+	case KeyPreferredMode:
+		return string(c.PreferredMode), nil
+	default:
+		return "", fmt.Errorf("unknown setting %q", key)
+	}
+}
+
+// Set validates and assigns a setting by key. Enumerated values are
+// matched case-insensitively.
+func (c *CustomizedConfig) Set(key ConfigKey, value string) error {
+	value = strings.TrimSpace(value)
+	if key != KeyMainLang {
+		value = strings.ToLower(value)
+	}
+	if err := validSetting(key, value); err != nil {
+		return err
+	}
+
+	switch key {
+	case KeyMainLang:
+		c.MainLang = value
+	case KeyPowerMode:
+		c.PowerMode = PowerMode(value)
+	case KeyPrivacyMode:
+		c.PrivacyMode = PrivacyMode(value)
+	case KeyUpdateMode:
+		c.UpdateMode = UpdateMode(value)
+	case KeyPreferredMode:
+		c.PreferredMode = InteractionMode(value)
+	}
+	return nil
+}
+
+func (c *CustomizedConfig) clear(key ConfigKey) {
+	switch key {
+	case KeyMainLang:
+		c.MainLang = ""
+	case KeyPowerMode:
+		c.PowerMode = ""
+	case KeyPrivacyMode:
+		c.PrivacyMode = ""
+	case KeyUpdateMode:
+		c.UpdateMode = ""
+	case KeyPreferredMode:
+		c.PreferredMode = ""
+	}
+}
+
+func validSetting(key ConfigKey, value string) error {
+	if key == KeyMainLang {
+		if !langTag.MatchString(value) {
+			return fmt.Errorf("invalid %s %q: want a language tag such as en or pt-BR", key, value)
+		}
+		return nil
+	}
+
+	choices, ok := ConfigChoices[key]
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	for _, c := range choices {
+		if value == c {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid %s %q: want one of %s", key, value, strings.Join(choices, "|"))
+}
+
+// Hash identifies the settings of c, ignoring LastModified, so saving an
+// unchanged config is recognizable.
+func (c *CustomizedConfig) Hash() string {
+	type stable struct {
+		Version       string
+		MainLang      string
+		PowerMode     string
+		PrivacyMode   string
+		UpdateMode    string
+		PreferredMode string
+	}
+
+	s := stable{
+		Version:       c.Version,
+		MainLang:      c.MainLang,
+		PowerMode:     string(c.PowerMode),
+		PrivacyMode:   string(c.PrivacyMode),
+		UpdateMode:    string(c.UpdateMode),
+		PreferredMode: string(c.PreferredMode),
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// DiffConfig lists the settings that differ from a to b. A nil a counts
+// as empty.
+func DiffConfig(a, b *CustomizedConfig) []ConfigChange {
+	if a == nil {
+		a = &CustomizedConfig{}
+	}
+
+	var out []ConfigChange
+	for _, key := range ConfigKeys {
+		from, _ := a.Get(key)
+		to, _ := b.Get(key)
+		if from != to {
+			out = append(out, ConfigChange{Key: key, From: from, To: to})
+		}
+	}
+	return out
+}
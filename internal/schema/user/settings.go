@@ -3,7 +3,8 @@
 package user_setting
 
 import (
-	"fmt"
+	"crypto/rand"
+	"encoding/hex"
 	"time"
 
 	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
@@ -53,12 +54,14 @@ func (s *UserSession) HasPermission(p internal_verification.PermissionMask) bool
 	return s.PermMask&p != 0
 }
 
+// UserCoreConfig holds the user-editable settings; see config.go for the
+// accepted values.
 type UserCoreConfig struct {
 	MainLang      string
-	PowerMode     string
-	PrivacyMode   string
-	UpdateMode    string
-	PreferredMode string
+	PowerMode     PowerMode
+	PrivacyMode   PrivacyMode
+	UpdateMode    UpdateMode
+	PreferredMode InteractionMode
 }
 
 type CustomizedConfig struct {
@@ -82,11 +85,44 @@ type UserSession struct {
 	Config      UserCoreConfig
 	Preferences *UserPreferences
 
-	Claims SessionClaims
+	Claims   SessionClaims
+	PermMask internal_verification.PermissionMask
+
+	// Token is the signed session token proving Claims to the API and the
+	// router.
+	Token string
+
+	// ScratchDir is the only writable storage of a guest session; it is
+	// wiped on logout. Empty for regular users.
+	ScratchDir string
+}
+
+// IsGuest reports whether the session belongs to an unauthenticated guest.
+func (s *UserSession) IsGuest() bool {
+	return s != nil && s.Claims.Guest
+}
+
+// IsSandbox reports whether the session runs in sandbox mode.
+func (s *UserSession) IsSandbox() bool {
+	return s != nil && s.Claims.Sandbox
+}
+
+// Marker is the banner interface adapters show for restricted sessions,
+// or "" for regular users.
+func (s *UserSession) Marker() string {
+	switch {
+	case s.IsGuest():
+		return "GUEST (until " + s.Claims.ExpiresAt.Local().Format("15:04") + ")"
+	case s.IsSandbox():
+		return "SANDBOX - actuators simulated, changes discarded at logout"
+	default:
+		return ""
+	}
 }
 
 type SessionClaims struct {
 	SessionID string
+	UserID    string
 	Platform  internal_environment.PlatformClass
 	Entity    internal_environment.EntityKind
 	Tier      TierType
@@ -94,6 +130,14 @@ type SessionClaims struct {
 
 	Permissions map[PermissionKey]bool
 
+	// Guest sessions carry GuestPermissions only and cannot be refreshed.
+	Guest bool
+
+	// Sandbox sessions (testers, mechanics) drive a simulator instead of
+	// actuators, write to a discarded vault overlay and have no outbound
+	// network.
+	Sandbox bool
+
 	CreatedAt time.Time
 	ExpiresAt time.Time
 }
@@ -186,21 +230,82 @@ func (b *SessionBuilder) Build(
 	permissions map[PermissionKey]bool,
 ) *SessionClaims {
 
+	id := make([]byte, 16)
+	rand.Read(id)
+
 	return &SessionClaims{
-		SessionID:   fmt.Sprintf("%d", time.Now().UnixNano()),
+		SessionID:   hex.EncodeToString(id),
+		UserID:      ctx.UserID,
 		Platform:    ctx.Platform,
 		Entity:      ctx.Entity,
 		Tier:        ctx.Tier,
 		Service:     ctx.Service,
 		Permissions: permissions,
+		Sandbox:     ctx.Sandbox,
 		CreatedAt:   time.Now(),
 		ExpiresAt:   time.Now().Add(24 * time.Hour),
 	}
 }
 
+// ------------------------------------------------------------
+// User Switching
+// ------------------------------------------------------------
+
+// TopicUserSwitched is the bus topic announcing a change of active user.
+// Modules holding per-user state reload it from the new user's profile.
+const TopicUserSwitched = "session.user_switched"
+
+// UserSwitched is the payload published on TopicUserSwitched.
+type UserSwitched struct {
+	PreviousUserID    string    `json:"previous_user_id,omitempty"`
+	PreviousSessionID string    `json:"previous_session_id,omitempty"`
+	UserID            string    `json:"user_id"`
+	SessionID         string    `json:"session_id"`
+	Guest             bool      `json:"guest,omitempty"`
+	Sandbox           bool      `json:"sandbox,omitempty"`
+	At                time.Time `json:"at"`
+}
+
+// ------------------------------------------------------------
+// Guest Sessions
+// ------------------------------------------------------------
+
+const (
+	DefaultGuestLifetime = 30 * time.Minute
+	MaxGuestLifetime     = 4 * time.Hour
+)
+
+// GuestPermissions is the fixed permission set of a guest session. Guests
+// never receive config edit, hardware IO or diagnostics, whatever the
+// platform would otherwise grant.
+func GuestPermissions() map[PermissionKey]bool {
+	return map[PermissionKey]bool{
+		PermUser:         true,
+		PermBasicRuntime: true,
+	}
+}
+
+// BuildGuest creates claims for a guest session expiring after lifetime,
+// clamped to MaxGuestLifetime.
+func (b *SessionBuilder) BuildGuest(ctx *BuildContext, lifetime time.Duration) *SessionClaims {
+	if lifetime <= 0 {
+		lifetime = DefaultGuestLifetime
+	}
+	if lifetime > MaxGuestLifetime {
+		lifetime = MaxGuestLifetime
+	}
+
+	claims := b.Build(ctx, GuestPermissions())
+	claims.Guest = true
+	claims.ExpiresAt = claims.CreatedAt.Add(lifetime)
+	return claims
+}
+
 type BuildContext struct {
+	UserID   string
 	Platform internal_environment.PlatformClass
 	Entity   internal_environment.EntityKind
 	Tier     TierType
 	Service  ServiceType
+	Sandbox  bool
 }
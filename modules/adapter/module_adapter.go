@@ -29,6 +29,10 @@ func AdaptModules(mods []domain_shared.DomainModule, rtx *runtime_engine.Runtime
 			rm.SetRuntime(rtx)
 		}
 
+		if sm, ok := m.(domain_shared.SecretsAware); ok {
+			sm.SetSecrets(rtx.SecretsFor(m.Name()))
+		}
+
 		out = append(out, &Adapter{legacy: m})
 	}
 
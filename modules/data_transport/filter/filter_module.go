@@ -3,23 +3,61 @@
 package transport_filter
 
 import (
+	"fmt"
+
+	security_decision "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/decision"
+	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
 	domain_shared "github.com/MIAUSEproject-founderKJ/multi-platform-AI/modules/domain/shared"
 	runtime_types "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/types"
 )
 
-func FilterModules(all []domain_shared.DomainModule, ctx runtime_types.ExecutionContext) []domain_shared.DomainModule {
+// capabilityBound modules only run on devices with every capability they
+// require.
+type capabilityBound interface {
+	RequiredCapabilities() internal_environment.CapabilitySet
+}
+
+// permissionGated modules decide from the boot's execution context whether
+// the session may run them.
+type permissionGated interface {
+	Allowed(ctx runtime_types.ExecutionContext) bool
+}
+
+// permissionNamed gated modules name the permission Allowed checks, so a
+// refusal is explained by the policy rules that decided it.
+type permissionNamed interface {
+	RequiredPermission() user_setting.PermissionKey
+}
+
+// FilterModules keeps the modules ctx permits. Modules implementing
+// neither check always run. Every module left out is refused to subject
+// through decisions, so the audit log says why it did not start; decisions
+// may be nil.
+func FilterModules(all []domain_shared.DomainModule, ctx runtime_types.ExecutionContext, decisions *security_decision.DecisionPoint, subject security_decision.Subject) []domain_shared.DomainModule {
 
 	var out []domain_shared.DomainModule
 
-	for _, m := range all {
+	refuse := func(m domain_shared.DomainModule, perm user_setting.PermissionKey, reason string) {
+		if decisions != nil {
+			decisions.RefuseModule(subject, m.Name(), perm, reason)
+		}
+	}
 
-		required := m.RequiredCapabilities()
+	for _, m := range all {
 
-		if !ctx.Capabilities.HasAll(required) {
+		if c, ok := m.(capabilityBound); ok && !ctx.Capabilities().HasAll(c.RequiredCapabilities()) {
+			missing := c.RequiredCapabilities() &^ ctx.Capabilities()
+			refuse(m, "", fmt.Sprintf("device lacks required capabilities %#x", uint64(missing)))
 			continue
 		}
 
-		if !m.Allowed(ctx) {
+		if g, ok := m.(permissionGated); ok && !g.Allowed(ctx) {
+			var perm user_setting.PermissionKey
+			if n, ok := m.(permissionNamed); ok {
+				perm = n.RequiredPermission()
+			}
+			refuse(m, perm, "module refused the session")
 			continue
 		}
 
@@ -4,8 +4,11 @@ package transport_audit
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+
 	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
 	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
 	domain_shared "github.com/MIAUSEproject-founderKJ/multi-platform-AI/modules/domain/shared"
@@ -40,7 +43,11 @@ func (m *AuditModule) Optional() bool {
 }
 
 func (m *AuditModule) Allowed(ctx runtime_types.ExecutionContext) bool {
-	return ctx.HasPermission(user_setting.PermDiagnostics)
+	return ctx.HasPermission(m.RequiredPermission())
+}
+
+func (m *AuditModule) RequiredPermission() user_setting.PermissionKey {
+	return user_setting.PermDiagnostics
 }
 
 func (m *AuditModule) Category() ModuleCategory {
@@ -59,6 +66,9 @@ func (m *AuditModule) Init(ctx runtime_types.ExecutionContext) error {
 	if m.runtime == nil {
 		return fmt.Errorf("runtime not set")
 	}
+	if m.runtime.Audit == nil {
+		return fmt.Errorf("audit log not configured")
+	}
 	return nil
 }
 
@@ -67,7 +77,7 @@ func (m *AuditModule) Run(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	m.cancel = cancel
 
-	ch := m.runtime.Bus.Subscribe("audit.events")
+	ch := m.runtime.Bus.Subscribe(security_audit.Topic)
 
 	for {
 		select {
@@ -75,7 +85,9 @@ func (m *AuditModule) Run(ctx context.Context) error {
 			return nil
 
 		case msg := <-ch:
-			fmt.Printf("[Audit] Topic=%s Data=%s\n", msg.Topic, string(msg.Data))
+			if _, err := m.runtime.Audit.Append(decodeAuditEvent(msg.Data)); err != nil {
+				fmt.Printf("[Audit] append failed: %v\n", err)
+			}
 		}
 	}
 }
@@ -90,3 +102,20 @@ func (m *AuditModule) Stop() error {
 func (m *AuditModule) SetRuntime(rtx *runtime_engine.RuntimeContext) {
 	m.runtime = rtx
 }
+
+// decodeAuditEvent accepts a structured security_audit.Event, or records
+// anything else verbatim so no published event is dropped.
+func decodeAuditEvent(data []byte) security_audit.Event {
+	var ev security_audit.Event
+	if err := json.Unmarshal(data, &ev); err == nil && ev.Actor != "" && ev.Action != "" {
+		return ev
+	}
+
+	ev = security_audit.Event{Actor: "unknown", Action: "bus_event"}
+	if json.Valid(data) {
+		ev.Detail = data
+	} else {
+		ev.Detail, _ = json.Marshal(string(data))
+	}
+	return ev
+}
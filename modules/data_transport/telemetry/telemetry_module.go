@@ -39,9 +39,7 @@ func (m *TelemetryModule) Init(ctx runtime_types.ExecutionContext) error {
 	m.ctx = ctx
 	m.healthy.Store(true)
 
-	if m.ctx != nil && m.ctx.Logger != nil {
-		m.ctx.Logger.Info("TelemetryModule initialized")
-	}
+	m.LogInfo("TelemetryModule initialized")
 
 	return nil
 }
@@ -49,17 +47,13 @@ func (m *TelemetryModule) Init(ctx runtime_types.ExecutionContext) error {
 func (m *TelemetryModule) Run(ctx context.Context) error {
 	m.running.Store(true)
 
-	if m.ctx != nil && m.ctx.Logger != nil {
-		m.ctx.Logger.Info("TelemetryModule started")
-	}
+	m.LogInfo("TelemetryModule started")
 
 	<-ctx.Done()
 
 	m.running.Store(false)
 
-	if m.ctx != nil && m.ctx.Logger != nil {
-		m.ctx.Logger.Info("TelemetryModule stopped")
-	}
+	m.LogInfo("TelemetryModule stopped")
 
 	return nil
 }
@@ -63,9 +63,7 @@ func (m *IndustrialProtocolModule) Run(ctx context.Context) error {
 }
 
 func (m *IndustrialProtocolModule) Handle(ctx context.Context, payload []byte) error {
-	if m.ctx != nil && m.ctx.Logger != nil {
-		m.ctx.Logger.Info("industrial protocol message")
-	}
+	m.LogInfo("industrial protocol message")
 	return nil
 }
 
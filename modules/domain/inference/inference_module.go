@@ -196,9 +196,6 @@ func (m *InferenceModule) processBatch(ctx context.Context, batch []PredictionRe
 
 			msg.Topic = "database"
 			m.runtime.Bus.Publish(msg)
-
-			msg.Topic = "audit"
-			m.runtime.Bus.Publish(msg)
 		}
 	}
 }
@@ -1,7 +1,11 @@
 // modules/domain/shared/interfaces.go
 package domain_shared
 
-import "context"
+import (
+	"context"
+
+	security_secrets "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/secrets"
+)
 
 // Legacy module contract (existing system)
 type DomainModule interface {
@@ -14,3 +18,9 @@ type DomainModule interface {
 type RuntimeAware interface {
 	SetRuntime(ctx any) // or *runtime_engine.RuntimeContext (preferred)
 }
+
+// Optional secret injection. The adapter passes an accessor bound to the
+// module's registered name, so a module can only read secrets granted to it.
+type SecretsAware interface {
+	SetSecrets(s *security_secrets.ModuleSecrets)
+}
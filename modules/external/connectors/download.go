@@ -2,13 +2,24 @@
 package external_connectors
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"os"
+
+	security_sandbox "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/sandbox"
 )
 
-func DownloadModule(url string, dest string) error {
-	resp, err := http.Get(url)
+// DownloadModule fetches url into dest. The request goes through the
+// sandbox dialer, so it fails while egress is blocked or when ctx belongs
+// to a sandbox session.
+func DownloadModule(ctx context.Context, url string, dest string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := security_sandbox.HTTPClient().Do(req)
 	if err != nil {
 		return err
 	}
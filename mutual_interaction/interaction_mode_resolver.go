@@ -11,8 +11,8 @@ func ResolveInteractionMode(
 	cap internal_environment.CapabilitySet,
 ) user_setting.InteractionMode {
 
-	if cfg != nil && cfg.PreferredMode != "" && cfg.PreferredMode != "auto" {
-		return user_setting.InteractionMode(cfg.PreferredMode)
+	if cfg != nil && cfg.PreferredMode != "" && cfg.PreferredMode != user_setting.ModeAuto {
+		return cfg.PreferredMode
 	}
 
 	switch {
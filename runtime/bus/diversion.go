@@ -0,0 +1,46 @@
+//runtime/bus/diversion.go
+
+package runtime_bus
+
+// ActuatorTopics carry commands that move physical hardware. Sandbox
+// sessions divert them so nothing reaches a real actuator.
+var ActuatorTopics = []string{
+	"vehicle_control",
+	"robot_control",
+	"industrial_control",
+	"actuator.command",
+}
+
+// Divert hands every message on topics to sink instead of the topic's
+// subscribers, replacing any previous diversion.
+func (b *MessageBus) Divert(topics []string, sink func(Message)) {
+	diverted := make(map[string]bool, len(topics))
+	for _, t := range topics {
+		diverted[t] = true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.diverted = diverted
+	b.sink = sink
+}
+
+// Restore delivers diverted topics to their subscribers again.
+func (b *MessageBus) Restore() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.diverted = nil
+	b.sink = nil
+}
+
+// Diverted lists the topics currently handed to a sink.
+func (b *MessageBus) Diverted() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	topics := make([]string, 0, len(b.diverted))
+	for t := range b.diverted {
+		topics = append(topics, t)
+	}
+	return topics
+}
@@ -2,8 +2,15 @@
 
 package runtime_bus
 
+import "sync"
+
 type MessageBus struct {
 	subscribers map[string][]chan Message
+
+	// diverted topics go to sink instead of their subscribers (sandbox)
+	mu       sync.RWMutex
+	diverted map[string]bool
+	sink     func(Message)
 }
 
 type Message struct {
@@ -18,6 +25,16 @@ func NewMessageBus() *MessageBus {
 }
 
 func (b *MessageBus) Publish(msg Message) {
+	b.mu.RLock()
+	sink := b.sink
+	diverted := b.diverted[msg.Topic]
+	b.mu.RUnlock()
+
+	if diverted {
+		sink(msg)
+		return
+	}
+
 	if subs, ok := b.subscribers[msg.Topic]; ok {
 		for _, ch := range subs {
 			ch <- msg
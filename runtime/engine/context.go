@@ -3,7 +3,13 @@
 package runtime_engine
 
 import (
+	"encoding/json"
+
 	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/router"
+	security_audit "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/audit"
+	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
+	security_secrets "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/secrets"
+	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
 	runtime_bus "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/bus"
 	runtime_supervisor "github.com/MIAUSEproject-founderKJ/multi-platform-AI/runtime/supervisor"
 )
@@ -13,5 +19,52 @@ type RuntimeContext struct {
 	Bus        *runtime_bus.MessageBus
 	Supervisor *runtime_supervisor.Supervisor
 
+	// Secrets is not handed to modules directly; each module receives
+	// SecretsFor(its registered name) from the adapter.
+	Secrets *security_secrets.Store
+
+	// Audit is the durable log behind the audit.events topic.
+	Audit *security_audit.Log
+
 	Modules map[string]runtime_supervisor.Module
 }
+
+// SecretsFor returns the secret accessor scoped to module.
+func (r *RuntimeContext) SecretsFor(module string) *security_secrets.ModuleSecrets {
+	if r.Secrets == nil {
+		return nil
+	}
+	return r.Secrets.ForModule(module)
+}
+
+// NewSecretStore opens the module secret store over vault. Every access is
+// published on the audit topic.
+func NewSecretStore(vault verification_persistence.VaultStore, bus *runtime_bus.MessageBus) *security_secrets.Store {
+	return security_secrets.NewStore(vault, func(ev security_secrets.AccessEvent) {
+		data, _ := json.Marshal(security_audit.Event{
+			Actor:    ev.Module,
+			Action:   "secret.read",
+			Resource: ev.Secret,
+			Result:   ev.Result,
+		})
+		bus.Publish(runtime_bus.Message{Topic: security_audit.Topic, Data: data})
+	})
+}
+
+// UserSwitchPublisher announces user switches on the bus so modules can
+// reload per-user preferences. Pass it to auth.NewSessionSwitcher.
+func UserSwitchPublisher(bus *runtime_bus.MessageBus) func(user_setting.UserSwitched) {
+	return func(ev user_setting.UserSwitched) {
+		data, _ := json.Marshal(ev)
+		bus.Publish(runtime_bus.Message{Topic: user_setting.TopicUserSwitched, Data: data})
+	}
+}
+
+// ConfigChangePublisher announces saved user configs on the bus so running
+// modules pick up new settings. Pass it to auth.SubscribeConfig.
+func ConfigChangePublisher(bus *runtime_bus.MessageBus) func(user_setting.ConfigChanged) {
+	return func(ev user_setting.ConfigChanged) {
+		data, _ := json.Marshal(ev)
+		bus.Publish(runtime_bus.Message{Topic: user_setting.TopicConfigChanged, Data: data})
+	}
+}
@@ -14,19 +14,25 @@ import (
 	"go.uber.org/zap"
 )
 
-type CLIAuth struct{}
+// CLIAuth collects credentials through Provider.
+type CLIAuth struct {
+	Provider auth.CredentialProvider
+}
 
 func NewCLIAuth() auth.AuthInterface {
-	return &CLIAuth{}
+	return &CLIAuth{Provider: auth.DefaultProvider(auth.NewTerminalProvider())}
 }
 func (c *CLIAuth) Authenticate() error {
 	return nil
 }
 
-type CLIAdapter struct{}
+type CLIAdapter struct {
+	session *user_setting.UserSession
+}
 
 func (c *CLIAdapter) Start(session *user_setting.UserSession) error {
-	fmt.Println("CLI session started:", user_setting.UserIdentity)
+	c.session = session
+	fmt.Println(withMarker(session, "CLI session started: "+session.Identity.Username))
 	return nil
 }
 
@@ -36,11 +42,12 @@ func (c *CLIAdapter) Stop(ctx context.Context) error {
 }
 
 func (c *CLIAuth) StartAuthFlow(am *auth.AuthManager) (*user_setting.UserSession, error) {
-	return am.LoginOrSignUpInteractive()
+	am.Provider = c.Provider
+	return am.LoginOrSignUp(context.Background())
 }
 
 func (c *CLIAdapter) Notify(msg string) {
-	fmt.Println("[CLI]", msg)
+	fmt.Println("[CLI]", withMarker(c.session, msg))
 }
 
 type CLIModule struct{}
@@ -2,30 +2,59 @@
 package interface_adapter
 
 import (
+	"context"
 	auth "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/auth"
 	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
 
 	"fmt"
+	"net/http"
+	"time"
 )
 
-type GUIAdapter struct{}
+type GUIAdapter struct {
+	session *user_setting.UserSession
+}
 
 func (g *GUIAdapter) Start(session *user_setting.UserSession) error {
-	// Launch window
+	// Launch window; the title bar carries the guest marker
+	g.session = session
 	return nil
 }
 
 func (c *GUIAdapter) Notify(msg string) {
-	fmt.Println("[GUI]", msg)
+	fmt.Println("[GUI]", withMarker(c.session, msg))
 }
+
+// GUILoginAddr is where the GUI front-end posts login requests. It is
+// bound to loopback only and served for the duration of the auth flow.
+const GUILoginAddr = "127.0.0.1:8081"
+
 func (g *GUIAuth) StartAuthFlow(am *auth.AuthManager) (*user_setting.UserSession, error) {
-	return am.LoginOrSignUpInteractive()
+	am.Provider = g.Provider
+
+	if hp, ok := g.Provider.(*auth.HTTPProvider); ok {
+		mux := http.NewServeMux()
+		mux.Handle("/login", hp.Handler())
+
+		srv := &http.Server{Addr: GUILoginAddr, Handler: mux, ReadHeaderTimeout: 2 * time.Second}
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fmt.Println("[GUI] login endpoint failed:", err)
+			}
+		}()
+		defer srv.Close()
+	}
+
+	return am.LoginOrSignUp(context.Background())
 }
 
-type GUIAuth struct{}
+// GUIAuth collects credentials through Provider.
+type GUIAuth struct {
+	Provider auth.CredentialProvider
+}
 
 func NewGUIAuth() auth.AuthInterface {
-	return &GUIAuth{}
+	return &GUIAuth{Provider: auth.DefaultProvider(auth.NewHTTPProvider())}
 }
 func (g *GUIAuth) Authenticate() error {
 	return nil
@@ -9,17 +9,20 @@ import (
 )
 
 // ===================Screen Adapter
-type ScreenAdapter struct{}
+type ScreenAdapter struct {
+	session *user_setting.UserSession
+}
 
 func NewScreenAdapter() *ScreenAdapter {
 	return &ScreenAdapter{}
 }
 
 func (s *ScreenAdapter) Start(session *user_setting.UserSession) error {
-	fmt.Println("Screen adapter started")
+	s.session = session
+	fmt.Println(withMarker(session, "Screen adapter started"))
 	return nil
 }
 
 func (s *ScreenAdapter) Notify(msg string) {
-	fmt.Println("[SCREEN]", msg)
+	fmt.Println("[SCREEN]", withMarker(s.session, msg))
 }
@@ -29,3 +29,12 @@ func (orch *Orchestrator) Broadcast(msg string) {
 		adapt.Notify(msg)
 	}
 }
+
+// withMarker prefixes msg with the session marker, so a restricted
+// session is visible in every interface.
+func withMarker(session *user_setting.UserSession, msg string) string {
+	if m := session.Marker(); m != "" {
+		return "[" + m + "] " + msg
+	}
+	return msg
+}
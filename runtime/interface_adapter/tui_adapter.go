@@ -3,30 +3,38 @@
 package interface_adapter
 
 import (
+	"context"
 	"fmt"
 
 	auth "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/auth"
 	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
 )
 
-type TUIAdapter struct{}
+type TUIAdapter struct {
+	session *user_setting.UserSession
+}
 
 func (t *TUIAdapter) Start(session *user_setting.UserSession) error {
-	// integrate charmbracelet/bubbletea
+	// integrate charmbracelet/bubbletea; the status line carries the guest marker
+	t.session = session
 	return nil
 }
 func (c *TUIAdapter) Notify(msg string) {
-	fmt.Println("[TUI]", msg)
+	fmt.Println("[TUI]", withMarker(c.session, msg))
 }
 
 func (t *TUIAuth) StartAuthFlow(am *auth.AuthManager) (*user_setting.UserSession, error) {
-	return am.LoginOrSignUpInteractive()
+	am.Provider = t.Provider
+	return am.LoginOrSignUp(context.Background())
 }
 
-type TUIAuth struct{}
+// TUIAuth collects credentials through Provider.
+type TUIAuth struct {
+	Provider auth.CredentialProvider
+}
 
 func NewTUIAuth() auth.AuthInterface {
-	return &TUIAuth{}
+	return &TUIAuth{Provider: auth.DefaultProvider(auth.NewTUIProvider())}
 }
 
 func (t *TUIAuth) Authenticate() error {
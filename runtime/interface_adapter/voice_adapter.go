@@ -3,18 +3,49 @@
 package interface_adapter
 
 import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
 	auth "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/auth"
 	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
 )
 
 func (v *VoiceAuth) StartAuthFlow(am *auth.AuthManager) (*user_setting.UserSession, error) {
-	return am.LoginOrSignUpInteractive()
+	am.Provider = v.Provider
+	return am.LoginOrSignUp(context.Background())
 }
 
-type VoiceAuth struct{}
+// VoiceAuth collects credentials through Provider.
+type VoiceAuth struct {
+	Provider auth.CredentialProvider
+}
 
 func NewVoiceAuth() auth.AuthInterface {
-	return &VoiceAuth{}
+	return &VoiceAuth{Provider: auth.DefaultProvider(&auth.VoiceProvider{IO: newConsoleVoice()})}
+}
+
+// consoleVoice stands in for speech until the voice engine has real
+// STT/TTS: prompts are printed as spoken, transcripts are typed.
+type consoleVoice struct {
+	lines *bufio.Scanner
+}
+
+func newConsoleVoice() *consoleVoice {
+	return &consoleVoice{lines: bufio.NewScanner(os.Stdin)}
+}
+
+func (c *consoleVoice) Say(msg string) {
+	fmt.Println("[VOICE] Speaking:", msg)
+}
+
+func (c *consoleVoice) Hear(ctx context.Context) (string, error) {
+	if !c.lines.Scan() {
+		return "", io.EOF
+	}
+	return c.lines.Text(), nil
 }
 func (v *VoiceAuth) Authenticate() error {
 	return nil
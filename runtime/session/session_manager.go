@@ -8,10 +8,11 @@ import (
 	"time"
 
 	"github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/auth"
+	verification_identity "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/identity"
+	security_password "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/password"
 	verification_persistence "github.com/MIAUSEproject-founderKJ/multi-platform-AI/core/security/persistence"
 	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
 	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // UserProfile defines the human operator
@@ -21,6 +22,8 @@ type UserProfile struct {
 	Entity       internal_environment.EntityKind
 	Tier         user_setting.TierType
 	CreatedAt    time.Time
+	Admin        bool
+	Disabled     bool
 }
 
 // AuthSession represents an active login
@@ -47,20 +50,42 @@ func (am *MyAuthManager) Login(username, password string) (*AuthSession, error)
 		return nil, errors.New("user not found")
 	}
 
-	// 2. Verify Password (Using SHA256 for demo; use bcrypt in prod)
-	hash, err := hashPassword(password)
+	// 2. Verify Password, upgrading legacy hashes in place
+	needsRehash, err := security_password.Verify(password, user.PasswordHash)
 	if err != nil {
-		return nil, err
-	}
-	if user.PasswordHash != hash {
 		return nil, errors.New("invalid credentials")
 	}
+	if user.Disabled {
+		return nil, errors.New("account disabled")
+	}
+	if needsRehash {
+		if hash, err := hashPassword(password); err == nil {
+			user.PasswordHash = hash
+			_ = am.Vault.Write("users", username, user)
+		}
+	}
 
 	// 3. Create Session
+	tokens, err := verification_identity.NewTokenService(am.Vault)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &user_setting.SessionClaims{
+		UserID: user.Username,
+		Entity: user.Entity,
+		Tier:   user.Tier,
+	}
+
+	token, err := tokens.Issue(claims)
+	if err != nil {
+		return nil, err
+	}
+
 	return &AuthSession{
 		User:      user,
-		Token:     generateToken(),
-		ExpiresAt: time.Now().Add(12 * time.Hour),
+		Token:     token,
+		ExpiresAt: claims.ExpiresAt,
 	}, nil
 }
 
@@ -101,10 +126,5 @@ func (am *auth.AuthManager) Signup(
 
 // Helpers
 func hashPassword(pw string) (string, error) {
-	b, err := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost)
-	return string(b), err
-}
-
-func generateToken() string {
-	return fmt.Sprintf("sess_%d", time.Now().UnixNano())
+	return security_password.Hash(pw)
 }
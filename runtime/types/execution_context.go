@@ -3,16 +3,137 @@
 package runtime_types
 
 import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
 	internal_environment "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/environment"
 	user_setting "github.com/MIAUSEproject-founderKJ/multi-platform-AI/internal/schema/user"
 )
 
-type ExecutionContext interface {
-	Platform() internal_environment.PlatformClass
-	Capabilities() internal_environment.CapabilitySet
-	SecurityTier() user_setting.TrustLevel
+// ErrContextNotDecodable is returned when JSON is decoded into an
+// ExecutionContext.
+var ErrContextNotDecodable = errors.New("execution_context_not_decodable")
+
+// ExecutionContext is what the policy decision point granted a session on
+// this device: the attested environment, the subject and the permissions.
+// It is a value built once by NewExecutionContext; nothing can change it
+// afterwards. It serialises to JSON for display but never decodes from it:
+// only the decision point issues contexts. The zero value grants nothing.
+type ExecutionContext struct {
+	g Grant
+}
+
+// Grant is the serialised form of an ExecutionContext.
+type Grant struct {
+	// Attested environment
+	Platform     internal_environment.PlatformClass `json:"platform"`
+	Capabilities internal_environment.CapabilitySet `json:"capabilities"`
+	Trust        user_setting.TrustLevel            `json:"trust"`
+	EnvHash      string                             `json:"env_hash,omitempty"`
+	BootID       string                             `json:"boot_id,omitempty"`
+
+	// Subject
+	UserID    string                          `json:"user_id,omitempty"`
+	SessionID string                          `json:"session_id,omitempty"`
+	Entity    internal_environment.EntityKind `json:"entity"`
+	Tier      user_setting.TierType           `json:"tier,omitempty"`
+	Service   user_setting.ServiceType        `json:"service,omitempty"`
+	Guest     bool                            `json:"guest,omitempty"`
+	Sandbox   bool                            `json:"sandbox,omitempty"`
+
+	// Decision
+	Permissions []user_setting.PermissionKey `json:"permissions"`
+	Reason      string                       `json:"reason,omitempty"`
+	Matched     []string                     `json:"matched,omitempty"`
+	Policies    []string                     `json:"policies,omitempty"`
+	IssuedAt    time.Time                    `json:"issued_at"`
+}
+
+// NewExecutionContext freezes g. Permissions are deduplicated and sorted.
+func NewExecutionContext(g Grant) ExecutionContext {
+	g.Permissions = sortedPermissions(g.Permissions)
+	g.Matched = append([]string(nil), g.Matched...)
+	g.Policies = append([]string(nil), g.Policies...)
+	return ExecutionContext{g: g}
+}
+
+// Valid reports whether the context was issued by the decision point.
+func (c ExecutionContext) Valid() bool { return !c.g.IssuedAt.IsZero() }
+
+func (c ExecutionContext) Platform() internal_environment.PlatformClass { return c.g.Platform }
+
+func (c ExecutionContext) Capabilities() internal_environment.CapabilitySet {
+	return c.g.Capabilities
+}
+
+// SecurityTier is the trust the decision point assigned.
+func (c ExecutionContext) SecurityTier() user_setting.TrustLevel { return c.g.Trust }
+
+func (c ExecutionContext) ServiceType() user_setting.ServiceType { return c.g.Service }
+
+func (c ExecutionContext) HasPermission(p user_setting.PermissionKey) bool {
+	for _, k := range c.g.Permissions {
+		if k == p {
+			return true
+		}
+	}
+	return false
+}
+
+func (c ExecutionContext) UserID() string    { return c.g.UserID }
+func (c ExecutionContext) SessionID() string { return c.g.SessionID }
+
+func (c ExecutionContext) Entity() internal_environment.EntityKind { return c.g.Entity }
+
+func (c ExecutionContext) Tier() user_setting.TierType { return c.g.Tier }
+
+func (c ExecutionContext) IsGuest() bool   { return c.g.Guest }
+func (c ExecutionContext) IsSandbox() bool { return c.g.Sandbox }
+
+// Reason explains the decision, naming the rules that granted it.
+func (c ExecutionContext) Reason() string { return c.g.Reason }
+
+func (c ExecutionContext) IssuedAt() time.Time { return c.g.IssuedAt }
+
+// Permissions returns the granted permissions as the map sessions carry.
+func (c ExecutionContext) Permissions() map[user_setting.PermissionKey]bool {
+	out := make(map[user_setting.PermissionKey]bool, len(c.g.Permissions))
+	for _, p := range c.g.Permissions {
+		out[p] = true
+	}
+	return out
+}
+
+// Grant returns a copy of the serialised form.
+func (c ExecutionContext) Grant() Grant {
+	g := c.g
+	g.Permissions = append([]user_setting.PermissionKey(nil), g.Permissions...)
+	g.Matched = append([]string(nil), g.Matched...)
+	g.Policies = append([]string(nil), g.Policies...)
+	return g
+}
+
+func (c ExecutionContext) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.g)
+}
+
+// UnmarshalJSON refuses: a context read from JSON was not issued by the
+// decision point and must not grant anything.
+func (c *ExecutionContext) UnmarshalJSON(data []byte) error {
+	return ErrContextNotDecodable
+}
 
-	// optional (very useful)
-	HasPermission(user_setting.PermissionKey) bool
-	ServiceType() user_setting.ServiceType
+func sortedPermissions(in []user_setting.PermissionKey) []user_setting.PermissionKey {
+	seen := make(map[user_setting.PermissionKey]bool, len(in))
+	out := make([]user_setting.PermissionKey, 0, len(in))
+	for _, p := range in {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
 }